@@ -0,0 +1,287 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	miniflux "miniflux.app/v2/client"
+)
+
+// actionSteps lists the primitive actions a rule or macro can perform
+var actionSteps = []string{"read", "unread", "remove", "star", "unstar", "save", "digest", "notify", "email", "export", "wallabag", "readwise", "disable_feed", "refresh_feed", "rewrite_title"}
+
+// builtinMacros are action macros available without configuration
+// User macros of the same name take precedence
+var builtinMacros = map[string][]string{
+	"read_later": {"star", "read"},
+}
+
+// DigestItem is an entry collected by the digest action
+type DigestItem struct {
+	Rule      string
+	EntryID   int64
+	FeedTitle string
+	Title     string
+	URL       string
+}
+
+// isPrimitiveStep reports whether name is a primitive action, webhook:<name>, notify:<name> or bookmark:<name> step
+func isPrimitiveStep(name string) bool {
+	if _, ok := webhookName(name); ok {
+		return true
+	}
+	if _, ok := notifierName(name); ok {
+		return true
+	}
+	if _, ok := bookmarkName(name); ok {
+		return true
+	}
+	return slices.Contains(actionSteps, strings.ToLower(name))
+}
+
+// expandAction resolves an action name into its primitive steps
+// Macros may reference other macros, which are expanded in place
+func expandAction(action string, macros map[string][]string) ([]string, error) {
+	return expandMacro(strings.ToLower(action), macros, nil)
+}
+
+// expandMacro expands action, tracking the macros being expanded to detect cycles
+func expandMacro(action string, macros map[string][]string, expanding []string) ([]string, error) {
+	if slices.Contains(actionSteps, action) {
+		return []string{action}, nil
+	}
+	if name, ok := webhookName(action); ok && name != "" {
+		return []string{action}, nil
+	}
+	if name, ok := notifierName(action); ok && name != "" {
+		return []string{action}, nil
+	}
+	if name, ok := bookmarkName(action); ok && name != "" {
+		return []string{action}, nil
+	}
+	if slices.Contains(expanding, action) {
+		return nil, fmt.Errorf("macro cycle: %s -> %s", strings.Join(expanding, " -> "), action)
+	}
+
+	steps, ok := macros[action]
+	if !ok {
+		steps, ok = builtinMacros[action]
+	}
+	if !ok {
+		return nil, fmt.Errorf("action must be one of %s, webhook:<name>, notify:<name>, bookmark:<name> or a macro name, got '%s'", strings.Join(actionSteps, ", "), action)
+	}
+
+	expanding = append(expanding, action)
+	var expanded []string
+	for _, step := range steps {
+		stepActions, err := expandMacro(strings.ToLower(step), macros, expanding)
+		if err != nil {
+			return nil, err
+		}
+		expanded = append(expanded, stepActions...)
+	}
+	return expanded, nil
+}
+
+// validateMacros checks that user macros are non-empty and resolve to primitive actions
+func validateMacros(macros map[string][]string) error {
+	for name, steps := range macros {
+		if slices.Contains(actionSteps, strings.ToLower(name)) {
+			return fmt.Errorf("macro '%s': name shadows a built-in action", name)
+		}
+		if len(steps) == 0 {
+			return fmt.Errorf("macro '%s': at least one action is required", name)
+		}
+		if _, err := expandAction(name, macros); err != nil {
+			return fmt.Errorf("macro '%s': %w", name, err)
+		}
+	}
+	return nil
+}
+
+// applyStep performs a single primitive action on an entry
+// It returns false if the step failed and later steps should not run
+func (p *Processor) applyStep(entry *miniflux.Entry, step string, rule *Rule, stats *ProcessStats) bool {
+	feedTitle := ""
+	if entry.Feed != nil {
+		feedTitle = entry.Feed.Title
+	}
+
+	if name, ok := webhookName(step); ok {
+		return p.applyWebhook(entry, name, rule, stats)
+	}
+	if name, ok := notifierName(step); ok {
+		return p.applyNotifier(entry, name, rule, stats)
+	}
+	if name, ok := bookmarkName(step); ok {
+		return p.applyBookmark(entry, name, rule, stats)
+	}
+	if step == "notify" {
+		return p.applyNotify(entry, rule, stats)
+	}
+	if step == "email" {
+		return p.applyEmail(entry, rule, stats)
+	}
+	if step == "export" {
+		return p.applyExport(entry, rule, stats)
+	}
+	if step == "wallabag" {
+		return p.applyWallabag(entry, rule, stats)
+	}
+	if step == "readwise" {
+		return p.applyReadwise(entry, rule, stats)
+	}
+	if step == "rewrite_title" {
+		return p.applyRewriteTitle(entry, rule, stats)
+	}
+	if step == "disable_feed" || step == "refresh_feed" {
+		return p.applyFeedAction(entry, step, rule, stats)
+	}
+
+	switch step {
+	case "read":
+		stats.MarkedRead++
+	case "unread":
+		if entry.Status == miniflux.EntryStatusUnread {
+			return true
+		}
+		stats.MarkedUnread++
+	case "remove":
+		if p.quarantines() {
+			stats.Quarantined++
+		} else {
+			stats.Removed++
+		}
+	case "star":
+		if entry.Starred {
+			return true
+		}
+		stats.Starred++
+	case "unstar":
+		if !entry.Starred {
+			return true
+		}
+		stats.Unstarred++
+	case "save":
+		// Entries are pushed to integrations once, not again on every matching run
+		if p.notificationSent("save", entry, "") {
+			return true
+		}
+		stats.Saved++
+	case "digest":
+		// Digests only report entries, so they are collected in dry runs too
+		if !p.shouldNotify("digest", entry, rule.Name) {
+			return true
+		}
+		stats.Digest = append(stats.Digest, DigestItem{
+			Rule:      rule.Name,
+			EntryID:   entry.ID,
+			FeedTitle: feedTitle,
+			Title:     entry.Title,
+			URL:       entry.URL,
+		})
+		p.audit(entry, step, rule.Name)
+		return true
+	default:
+		p.entryLogger(entry, rule).Warn("Unknown action", "action", step)
+		stats.Errors++
+		return false
+	}
+
+	// Quarantined removals are only marked read until purged
+	applied := step
+	if step == "remove" && p.quarantines() {
+		applied = "quarantine"
+	}
+
+	if p.dryRun {
+		p.entryLogger(entry, rule).Info("Dry run: would apply action", "action", applied, "title", entry.Title)
+		p.audit(entry, applied, rule.Name)
+		return true
+	}
+
+	var err error
+	switch applied {
+	case "read", "quarantine":
+		err = p.client.UpdateEntries([]int64{entry.ID}, miniflux.EntryStatusRead)
+	case "unread":
+		err = p.client.UpdateEntries([]int64{entry.ID}, miniflux.EntryStatusUnread)
+	case "remove":
+		err = p.client.UpdateEntries([]int64{entry.ID}, miniflux.EntryStatusRemoved)
+	case "star", "unstar":
+		err = p.client.ToggleStarred(entry.ID)
+	case "save":
+		err = p.client.SaveEntry(entry.ID)
+	}
+	if err != nil {
+		p.entryLogger(entry, rule).Error("Failed to update entry", "action", applied, "error", err)
+		stats.Errors++
+		return false
+	}
+	if applied == "save" {
+		// Recorded only once saved, so a failed save is retried on the next run
+		p.recordNotification("save", entry, "")
+	}
+
+	p.recordAction(entry, step, rule)
+	p.audit(entry, applied, rule.Name)
+	stats.Changes = append(stats.Changes, ChangeItem{
+		Rule:      rule.Name,
+		Action:    applied,
+		EntryID:   entry.ID,
+		FeedTitle: feedTitle,
+		Title:     entry.Title,
+		URL:       entry.URL,
+	})
+
+	switch applied {
+	case "read":
+		entry.Status = miniflux.EntryStatusRead
+	case "unread":
+		entry.Status = miniflux.EntryStatusUnread
+	case "remove":
+		entry.Status = miniflux.EntryStatusRemoved
+	case "quarantine":
+		entry.Status = miniflux.EntryStatusRead
+		p.state.Quarantine(entry.ID, QuarantinedEntry{Rule: rule.Name, At: p.now()})
+	case "star":
+		entry.Starred = true
+	case "unstar":
+		entry.Starred = false
+	}
+	p.entryLogger(entry, rule).Info("Applied action", "action", applied)
+	return true
+}
+
+// recordAction adds a successfully applied step to the action history
+// Only steps that reprocessing can revert are kept
+func (p *Processor) recordAction(entry *miniflux.Entry, step string, rule *Rule) {
+	if p.state == nil || step == "save" {
+		return
+	}
+	action := AppliedAction{
+		EntryID:        entry.ID,
+		Rule:           rule.Name,
+		Action:         step,
+		PreviousStatus: entry.Status,
+		At:             p.now(),
+		Run:            p.runStarted,
+	}
+	if step == "rewrite_title" {
+		action.PreviousTitle = entry.Title
+	}
+	p.state.RecordAction(action)
+}
+
+// logDigest reports the entries collected by digest actions during the run
+func (p *Processor) logDigest(stats *ProcessStats) {
+	if len(stats.Digest) == 0 {
+		return
+	}
+
+	p.logger.Info("Digest", "entries", len(stats.Digest))
+	for _, item := range stats.Digest {
+		p.logger.Info("Digest entry", "rule", item.Rule, "entry_id", item.EntryID, "feed", item.FeedTitle, "title", item.Title, "url", item.URL)
+	}
+}
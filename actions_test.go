@@ -0,0 +1,258 @@
+package main
+
+import (
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	miniflux "miniflux.app/v2/client"
+)
+
+func TestProcessorReadLater(t *testing.T) {
+	mockClient := &MockClient{
+		entries: []*miniflux.Entry{
+			{ID: 1, Title: "Long read", URL: "https://example.com/long"},
+			{ID: 2, Title: "Long read, already starred", Starred: true},
+		},
+	}
+
+	matcher, err := NewMatcher([]Rule{
+		{Name: "Save long reads", Title: "Long read", Action: "read_later_digest"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create matcher: %v", err)
+	}
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	processor := NewProcessor(mockClient, matcher, logger, false)
+	processor.SetOptions(ProcessorOptions{
+		Macros: map[string][]string{"read_later_digest": {"star", "read", "digest"}},
+	})
+
+	stats, err := processor.Process()
+	if err != nil {
+		t.Fatalf("Process failed: %v", err)
+	}
+
+	if len(mockClient.starredIDs) != 1 || mockClient.starredIDs[0] != 1 {
+		t.Errorf("Expected only entry 1 to be starred, got %v", mockClient.starredIDs)
+	}
+	if stats.MarkedRead != 2 || len(mockClient.updatedIDs) != 2 {
+		t.Errorf("Expected both entries to be marked read, got %v", mockClient.updatedIDs)
+	}
+	if len(stats.Digest) != 2 || stats.Digest[0].URL != "https://example.com/long" {
+		t.Errorf("Expected both entries in the digest, got %+v", stats.Digest)
+	}
+}
+
+func TestExpandAction(t *testing.T) {
+	steps, err := expandAction("Read_Later", nil)
+	if err != nil {
+		t.Fatalf("Failed to expand built-in macro: %v", err)
+	}
+	if len(steps) != 2 || steps[0] != "star" || steps[1] != "read" {
+		t.Errorf("Expected [star read], got %v", steps)
+	}
+
+	if _, err := expandAction("archive", nil); err == nil {
+		t.Error("Expected error for unknown action")
+	}
+
+	if err := validateMacros(map[string][]string{"archive": {"read", "explode"}}); err == nil {
+		t.Error("Expected error for macro with unknown step")
+	}
+}
+
+func TestNestedMacros(t *testing.T) {
+	macros := map[string][]string{
+		"archive":    {"digest", "read"},
+		"keep_later": {"archive", "read_later"},
+	}
+	if err := validateMacros(macros); err != nil {
+		t.Fatalf("Expected valid macros: %v", err)
+	}
+
+	steps, err := expandAction("keep_later", macros)
+	if err != nil {
+		t.Fatalf("Failed to expand macro: %v", err)
+	}
+	expected := []string{"digest", "read", "star", "read"}
+	if len(steps) != len(expected) {
+		t.Fatalf("Expected %v, got %v", expected, steps)
+	}
+	for i := range expected {
+		if steps[i] != expected[i] {
+			t.Errorf("Expected %v, got %v", expected, steps)
+			break
+		}
+	}
+
+	cyclic := map[string][]string{"a": {"b"}, "b": {"read", "a"}}
+	if err := validateMacros(cyclic); err == nil {
+		t.Error("Expected error for cyclic macros")
+	}
+}
+
+func TestProcessorDigestNotSentTwice(t *testing.T) {
+	mockClient := &MockClient{
+		entries: []*miniflux.Entry{
+			{ID: 1, Title: "Newsletter"},
+		},
+	}
+
+	matcher, err := NewMatcher([]Rule{
+		{Name: "Newsletters", Title: "Newsletter", Action: "digest"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create matcher: %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "state.json")
+	state, err := LoadState(path)
+	if err != nil {
+		t.Fatalf("Failed to load state: %v", err)
+	}
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	processor := NewProcessor(mockClient, matcher, logger, false)
+	processor.SetState(state)
+
+	stats, err := processor.Process()
+	if err != nil {
+		t.Fatalf("Process failed: %v", err)
+	}
+	if len(stats.Digest) != 1 {
+		t.Fatalf("Expected 1 digest item, got %d", len(stats.Digest))
+	}
+
+	// A restarted process reprocessing the same entry stays quiet
+	state, err = LoadState(path)
+	if err != nil {
+		t.Fatalf("Failed to reload state: %v", err)
+	}
+	processor = NewProcessor(mockClient, matcher, logger, false)
+	processor.SetState(state)
+
+	stats, err = processor.Process()
+	if err != nil {
+		t.Fatalf("Process failed: %v", err)
+	}
+	if len(stats.Digest) != 0 {
+		t.Errorf("Expected no repeated digest item, got %d", len(stats.Digest))
+	}
+}
+
+func TestProcessorUnstarOldEntries(t *testing.T) {
+	now := time.Now()
+	mockClient := &MockClient{
+		entries: []*miniflux.Entry{
+			{ID: 1, Title: "Old star", Starred: true, Status: miniflux.EntryStatusRead, Date: now.Add(-100 * 24 * time.Hour)},
+			{ID: 2, Title: "Recent star", Starred: true, Status: miniflux.EntryStatusRead, Date: now.Add(-time.Hour)},
+		},
+	}
+
+	starred := true
+	matcher, err := NewMatcher([]Rule{
+		{
+			Name:      "Clear old stars",
+			Starred:   &starred,
+			OlderThan: 90 * 24 * time.Hour,
+			Status:    StringList{"all"},
+			Action:    "unstar",
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create matcher: %v", err)
+	}
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	processor := NewProcessor(mockClient, matcher, logger, false)
+	processor.SetOptions(ProcessorOptions{SkipStarred: true})
+
+	stats, err := processor.Process()
+	if err != nil {
+		t.Fatalf("Process failed: %v", err)
+	}
+
+	if mockClient.lastFilter.Starred != miniflux.FilterOnlyStarred {
+		t.Errorf("Expected only starred entries to be fetched, got '%s'", mockClient.lastFilter.Starred)
+	}
+	if stats.Unstarred != 1 || len(mockClient.starredIDs) != 1 || mockClient.starredIDs[0] != 1 {
+		t.Errorf("Expected only entry 1 to be unstarred, got %v", mockClient.starredIDs)
+	}
+}
+
+func TestProcessorMarkUnread(t *testing.T) {
+	mockClient := &MockClient{
+		entries: []*miniflux.Entry{
+			{ID: 1, Title: "Weekly newsletter", Status: miniflux.EntryStatusRead},
+		},
+	}
+
+	matcher, err := NewMatcher([]Rule{
+		{Name: "Resurface newsletter", Title: "newsletter", Action: "unread"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create matcher: %v", err)
+	}
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	processor := NewProcessor(mockClient, matcher, logger, false)
+
+	stats, err := processor.Process()
+	if err != nil {
+		t.Fatalf("Process failed: %v", err)
+	}
+
+	if statuses := mockClient.lastFilter.Statuses; len(statuses) != 1 || statuses[0] != miniflux.EntryStatusRead {
+		t.Errorf("Expected read entries to be fetched, got %v", statuses)
+	}
+	if stats.MarkedUnread != 1 || mockClient.updatedStatus != miniflux.EntryStatusUnread {
+		t.Errorf("Expected entry to be marked unread, got status '%s'", mockClient.updatedStatus)
+	}
+}
+
+func TestProcessorSaveOnce(t *testing.T) {
+	mockClient := &MockClient{
+		entries: []*miniflux.Entry{
+			{ID: 1, Title: "Long read"},
+		},
+	}
+
+	matcher, err := NewMatcher([]Rule{
+		{Name: "Save long reads", Title: "Long read", Action: "save"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create matcher: %v", err)
+	}
+
+	state, err := LoadState(filepath.Join(t.TempDir(), "state.json"))
+	if err != nil {
+		t.Fatalf("Failed to load state: %v", err)
+	}
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	processor := NewProcessor(mockClient, matcher, logger, false)
+	processor.SetState(state)
+
+	// A failed save is not recorded, so the next run retries it
+	mockClient.updateErr = errors.New("integration unavailable")
+	if stats, err := processor.Process(); err != nil || stats.Errors != 1 {
+		t.Fatalf("Expected the save to fail, got %+v, %v", stats, err)
+	}
+	mockClient.updateErr = nil
+
+	for range 2 {
+		if _, err := processor.Process(); err != nil {
+			t.Fatalf("Process failed: %v", err)
+		}
+	}
+
+	// The entry stays unread, but is only pushed to integrations once
+	if len(mockClient.savedIDs) != 1 || mockClient.savedIDs[0] != 1 {
+		t.Errorf("Expected entry 1 to be saved once, got %v", mockClient.savedIDs)
+	}
+}
@@ -0,0 +1,217 @@
+package main
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// lastRun is the outcome of an instance's latest run
+type lastRun struct {
+	started  time.Time
+	finished time.Time
+	stats    *ProcessStats
+	err      error
+}
+
+// runHistory keeps the latest run of an instance for the admin API and health checks
+type runHistory struct {
+	mu          sync.Mutex
+	last        *lastRun
+	lastSuccess time.Time // end of the latest run without error
+}
+
+// record stores the outcome of a run
+func (h *runHistory) record(run *lastRun) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.last = run
+	if run.err == nil {
+		h.lastSuccess = run.finished
+	}
+}
+
+// succeeded returns when the latest successful run finished, zero before the first one
+func (h *runHistory) succeeded() time.Time {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.lastSuccess
+}
+
+// latest returns the latest run, or nil before the first one finished
+func (h *runHistory) latest() *lastRun {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.last
+}
+
+// adminAPI lets orchestration and dashboards drive the daemon in loop mode: trigger a run,
+// reload the config, read the last run's stats and list the loaded rules
+// Every request needs the admin token as a bearer token.
+type adminAPI struct {
+	token     string
+	runs      chan struct{} // requested runs, picked up by the processing loop
+	reloader  *configReloader
+	instances []*instance
+}
+
+// newAdminAPI creates the admin API of the daemon's instances
+func newAdminAPI(token string, reloader *configReloader, instances []*instance) *adminAPI {
+	return &adminAPI{
+		token:     token,
+		runs:      make(chan struct{}, 1),
+		reloader:  reloader,
+		instances: instances,
+	}
+}
+
+// register adds the admin routes to the server's routes
+func (a *adminAPI) register(mux *http.ServeMux) {
+	mux.HandleFunc("POST /admin/run", a.authorized(a.handleRun))
+	mux.HandleFunc("POST /admin/reload", a.authorized(a.handleReload))
+	mux.HandleFunc("GET /admin/stats", a.authorized(a.handleStats))
+	mux.HandleFunc("GET /admin/rules", a.authorized(a.handleRules))
+}
+
+// authorized rejects requests without the admin token
+func (a *adminAPI) authorized(handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), []byte("Bearer "+a.token)) != 1 {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		handler(w, r)
+	}
+}
+
+// AdminRunResponse is returned by POST /admin/run
+type AdminRunResponse struct {
+	Queued bool `json:"queued"` // false when a requested run is already waiting
+}
+
+// handleRun asks the processing loop for a run right away, without moving the next scheduled run
+func (a *adminAPI) handleRun(w http.ResponseWriter, r *http.Request) {
+	queued := true
+	select {
+	case a.runs <- struct{}{}:
+	default:
+		queued = false
+	}
+	writeJSONResponse(w, http.StatusAccepted, AdminRunResponse{Queued: queued})
+}
+
+// AdminReloadResponse is returned by POST /admin/reload
+type AdminReloadResponse struct {
+	Changed    bool   `json:"changed"`
+	ConfigHash string `json:"config_hash"`
+}
+
+// handleReload reloads the config like SIGHUP, keeping the previous rules if it is invalid
+func (a *adminAPI) handleReload(w http.ResponseWriter, r *http.Request) {
+	a.reloader.logger.Info("Reloading config on admin request")
+	changed, err := a.reloader.reload()
+	if err != nil {
+		a.reloader.logger.Error("Failed to reload config, keeping the previous rules", "error", err)
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+	writeJSONResponse(w, http.StatusOK, AdminReloadResponse{Changed: changed, ConfigHash: a.reloader.activeHash()})
+}
+
+// AdminRunStats is the latest run of an instance, as returned by GET /admin/stats
+type AdminRunStats struct {
+	Instance     string    `json:"instance,omitempty"` // empty for the account of miniflux_url
+	Started      time.Time `json:"started"`
+	Finished     time.Time `json:"finished"`
+	Error        string    `json:"error,omitempty"`
+	ConfigHash   string    `json:"config_hash,omitempty"`
+	Checked      int       `json:"checked"`
+	Matched      int       `json:"matched"`
+	MarkedRead   int       `json:"marked_read"`
+	MarkedUnread int       `json:"marked_unread"`
+	Removed      int       `json:"removed"`
+	Quarantined  int       `json:"quarantined"`
+	Starred      int       `json:"starred"`
+	Unstarred    int       `json:"unstarred"`
+	Saved        int       `json:"saved"`
+	Notified     int       `json:"notified"`
+	Retitled     int       `json:"retitled"`
+	Errors       int       `json:"errors"`
+	SkippedPages int       `json:"skipped_pages"`
+}
+
+// handleStats lists the latest run of each instance that has completed one
+func (a *adminAPI) handleStats(w http.ResponseWriter, r *http.Request) {
+	runs := []AdminRunStats{}
+	for _, inst := range a.instances {
+		run := inst.runs.latest()
+		if run == nil {
+			continue
+		}
+		stats := run.stats
+		out := AdminRunStats{
+			Instance:     inst.name,
+			Started:      run.started,
+			Finished:     run.finished,
+			ConfigHash:   stats.ConfigHash,
+			Checked:      stats.TotalEntries,
+			Matched:      stats.MatchedEntries,
+			MarkedRead:   stats.MarkedRead,
+			MarkedUnread: stats.MarkedUnread,
+			Removed:      stats.Removed,
+			Quarantined:  stats.Quarantined,
+			Starred:      stats.Starred,
+			Unstarred:    stats.Unstarred,
+			Saved:        stats.Saved,
+			Notified:     stats.Notified,
+			Retitled:     stats.Retitled,
+			Errors:       stats.Errors,
+			SkippedPages: stats.SkippedPages,
+		}
+		if run.err != nil {
+			out.Error = run.err.Error()
+		}
+		runs = append(runs, out)
+	}
+	writeJSONResponse(w, http.StatusOK, runs)
+}
+
+// AdminRule describes a loaded rule, as returned by GET /admin/rules
+type AdminRule struct {
+	Instance    string   `json:"instance,omitempty"`
+	Name        string   `json:"name"`
+	Description string   `json:"description,omitempty"`
+	Labels      []string `json:"labels,omitempty"`
+	Action      string   `json:"action"`
+	Disabled    bool     `json:"disabled"` // consumed one-off rules and rules turned off after failures
+}
+
+// handleRules lists the rules each instance currently runs
+func (a *adminAPI) handleRules(w http.ResponseWriter, r *http.Request) {
+	rules := []AdminRule{}
+	for _, inst := range a.instances {
+		rules = append(rules, inst.processor.loadedRules(inst.name)...)
+	}
+	writeJSONResponse(w, http.StatusOK, rules)
+}
+
+// loadedRules lists the processor's rules, waiting for a run in progress to finish
+func (p *Processor) loadedRules(instance string) []AdminRule {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var rules []AdminRule
+	for _, rule := range p.matcher.Rules() {
+		rules = append(rules, AdminRule{
+			Instance:    instance,
+			Name:        rule.Name,
+			Description: rule.Description,
+			Labels:      rule.Labels,
+			Action:      ruleActionLabel(&rule),
+			Disabled:    p.matcher.disabled[rule.Name],
+		})
+	}
+	return rules
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestAdminAPI(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "rules.yaml")
+	writeConfig := func(rules string) {
+		content := "miniflux_url: https://miniflux.example.com\nrules:\n" + rules
+		if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write config: %v", err)
+		}
+	}
+	writeConfig("  - name: Promos\n    labels: [ads]\n    title: Promo\n    action: read\n")
+
+	config, err := LoadConfig(configPath)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	mockClient := &MockClient{}
+	matcher, err := buildMatcher(config, mockClient, logger)
+	if err != nil {
+		t.Fatalf("Failed to create matcher: %v", err)
+	}
+	processor := NewProcessor(mockClient, matcher, logger, true)
+	processor.SetOptions(processorOptions(config))
+	instances := []*instance{{processor: processor, logger: logger}}
+	reloader := &configReloader{path: configPath, instances: instances, logger: logger, hash: config.Hash}
+	admin := newAdminAPI("t0ken", reloader, instances)
+	server := NewServer(processor, logger)
+	server.SetAdmin(admin)
+	handler := server.Handler()
+
+	request := func(method, path, token string, response any) int {
+		req := httptest.NewRequest(method, path, nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		if response != nil && rec.Code < 300 {
+			if err := json.NewDecoder(rec.Body).Decode(response); err != nil {
+				t.Fatalf("Failed to decode %s response: %v", path, err)
+			}
+		}
+		return rec.Code
+	}
+
+	if code := request(http.MethodPost, "/admin/run", "", nil); code != http.StatusUnauthorized {
+		t.Errorf("Expected status 401 without the token, got %d", code)
+	}
+	if code := request(http.MethodGet, "/admin/stats", "wrong", nil); code != http.StatusUnauthorized {
+		t.Errorf("Expected status 401 with a wrong token, got %d", code)
+	}
+
+	var rules []AdminRule
+	if code := request(http.MethodGet, "/admin/rules", "t0ken", &rules); code != http.StatusOK {
+		t.Fatalf("Expected status 200 for the rules, got %d", code)
+	}
+	if len(rules) != 1 || rules[0].Name != "Promos" || rules[0].Action != "read" || len(rules[0].Labels) != 1 {
+		t.Errorf("Unexpected rules: %+v", rules)
+	}
+
+	var stats []AdminRunStats
+	if request(http.MethodGet, "/admin/stats", "t0ken", &stats); len(stats) != 0 {
+		t.Errorf("Expected no stats before the first run, got %+v", stats)
+	}
+
+	// A run already waiting to start absorbs further requests
+	var run AdminRunResponse
+	if code := request(http.MethodPost, "/admin/run", "t0ken", &run); code != http.StatusAccepted || !run.Queued {
+		t.Errorf("Expected the run to be queued, got %d %+v", code, run)
+	}
+	if request(http.MethodPost, "/admin/run", "t0ken", &run); run.Queued {
+		t.Error("Expected a second run request not to be queued")
+	}
+
+	schedule, err := newLoopSchedule(&Config{Schedule: "0 3 * * *"})
+	if err != nil {
+		t.Fatalf("Failed to create schedule: %v", err)
+	}
+	sigChan := make(chan os.Signal, 1)
+	done := make(chan error)
+	go func() { done <- runLoop(instances, logger, schedule, sigChan, admin.runs, reportTarget{}) }()
+	for deadline := time.Now().Add(5 * time.Second); instances[0].runs.latest() == nil; {
+		if time.Now().After(deadline) {
+			t.Fatal("Expected the requested run to start")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	sigChan <- syscall.SIGTERM
+	if err := <-done; err != nil {
+		t.Fatalf("runLoop failed: %v", err)
+	}
+
+	if request(http.MethodGet, "/admin/stats", "t0ken", &stats); len(stats) != 1 || stats[0].ConfigHash != config.Hash {
+		t.Errorf("Expected the stats of the requested run, got %+v", stats)
+	}
+
+	writeConfig("  - name: Promos\n    title: Promo\n    action: read\n  - name: Ads\n    title: \"(\"\n    action: remove\n")
+	if code := request(http.MethodPost, "/admin/reload", "t0ken", nil); code != http.StatusUnprocessableEntity {
+		t.Errorf("Expected status 422 for an invalid config, got %d", code)
+	}
+	writeConfig("  - name: Promos\n    title: Promo\n    action: read\n  - name: Ads\n    title: Ad\n    action: remove\n")
+	var reload AdminReloadResponse
+	if code := request(http.MethodPost, "/admin/reload", "t0ken", &reload); code != http.StatusOK || !reload.Changed || reload.ConfigHash == config.Hash {
+		t.Errorf("Expected the config to be reloaded, got %d %+v", code, reload)
+	}
+	if request(http.MethodGet, "/admin/rules", "t0ken", &rules); len(rules) != 2 {
+		t.Errorf("Expected 2 rules after the reload, got %+v", rules)
+	}
+}
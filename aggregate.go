@@ -0,0 +1,171 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	miniflux "miniflux.app/v2/client"
+)
+
+// FeedAlert is raised when a feed crosses an aggregate rule threshold
+type FeedAlert struct {
+	Aggregate string
+	Action    string // "notify" or "suggest_unsubscribe"
+	FeedID    int64
+	FeedTitle string
+	Matched   int
+	Total     int
+}
+
+// Ratio returns the share of the feed's entries that matched
+func (a FeedAlert) Ratio() float64 {
+	if a.Total == 0 {
+		return 0
+	}
+	return float64(a.Matched) / float64(a.Total)
+}
+
+// recordFeedActivity stores the match outcome of an entry for aggregate rules
+func (p *Processor) recordFeedActivity(entry *miniflux.Entry, result MatchResult) {
+	if p.state == nil || p.dryRun || !p.tracksFeedActivity() {
+		return
+	}
+
+	feedID := entryFeedID(entry)
+	if feedID == 0 {
+		return
+	}
+	if entry.Feed != nil && entry.Feed.Title != "" {
+		p.feedTitles[feedID] = entry.Feed.Title
+	}
+
+	rule := ""
+	if result.Matched {
+		rule = result.Rule.Name
+	}
+	p.state.RecordFeedActivity(feedID, entry.ID, rule, p.now())
+}
+
+// evaluateAggregates checks every feed against the aggregate rules
+// A feed alerts at most once per aggregate window
+func (p *Processor) evaluateAggregates(stats *ProcessStats) {
+	if p.state == nil || len(p.options.Aggregates) == 0 {
+		return
+	}
+
+	now := p.now()
+
+	for _, agg := range p.options.Aggregates {
+		cutoff := now.Add(-agg.Window)
+
+		for feedID, entries := range p.state.FeedActivity {
+			alert := FeedAlert{
+				Aggregate: agg.Name,
+				Action:    strings.ToLower(agg.Action),
+				FeedID:    feedID,
+				FeedTitle: p.feedTitles[feedID],
+			}
+			for _, activity := range entries {
+				if activity.At.Before(cutoff) {
+					continue
+				}
+				alert.Total++
+				if activity.Rule != "" && (len(agg.Rules) == 0 || slices.Contains(agg.Rules, activity.Rule)) {
+					alert.Matched++
+				}
+			}
+
+			if alert.Total == 0 || alert.Total < agg.MinEntries || alert.Ratio() < agg.Threshold {
+				continue
+			}
+
+			key := fmt.Sprintf("%s/%d", agg.Name, feedID)
+			if last, ok := p.state.AggregateAlerts[key]; ok && now.Sub(last) < agg.Window {
+				continue
+			}
+			// An alert that could not be pushed is not recorded, so the next run retries it
+			if alert.Action == "notify" && !p.notifyFeedAlert(alert, agg.Notifier, stats) {
+				continue
+			}
+			if !p.dryRun {
+				p.state.AggregateAlerts[key] = now
+			}
+
+			p.logFeedAlert(alert)
+			stats.FeedAlerts = append(stats.FeedAlerts, alert)
+		}
+	}
+}
+
+// tracksFeedActivity reports whether any feature needs per-feed activity in state
+func (p *Processor) tracksFeedActivity() bool {
+	return len(p.options.Aggregates) > 0 || p.options.ReadReport.Enabled
+}
+
+// pruneFeedActivity drops feed activity older than any feature still needs
+func (p *Processor) pruneFeedActivity() {
+	if p.state == nil || p.dryRun || !p.tracksFeedActivity() {
+		return
+	}
+
+	var retention time.Duration
+	for _, agg := range p.options.Aggregates {
+		retention = max(retention, agg.Window)
+	}
+	if p.options.ReadReport.Enabled {
+		retention = max(retention, p.options.ReadReport.interval())
+	}
+
+	p.state.PruneFeedActivity(p.now().Add(-retention))
+}
+
+// notifyFeedAlert pushes a triggered aggregate rule through its notifier
+// It returns false if the push failed
+func (p *Processor) notifyFeedAlert(alert FeedAlert, name string, stats *ProcessStats) bool {
+	logger := p.logger.With("aggregate", alert.Aggregate, "feed_id", alert.FeedID, "notifier", name)
+	cfg, ok := findNotifier(p.options.Notifiers, name)
+	if !ok {
+		logger.Warn("Unknown notifier")
+		stats.Errors++
+		return false
+	}
+
+	if p.dryRun {
+		logger.Info("Dry run: would push aggregate alert to notifier")
+		return true
+	}
+
+	msg := notification{
+		Title:   alert.Aggregate,
+		Message: fmt.Sprintf("%d of %d entries from [%s] matched (%.0f%%)", alert.Matched, alert.Total, alert.FeedTitle, alert.Ratio()*100),
+	}
+	if err := sendNotification(p.httpClient, cfg, msg); err != nil {
+		logger.Error("Failed to push aggregate alert to notifier", "error", err)
+		stats.Errors++
+		return false
+	}
+
+	stats.Notified++
+	logger.Info("Pushed aggregate alert to notifier")
+	return true
+}
+
+// logFeedAlert reports a triggered aggregate rule
+func (p *Processor) logFeedAlert(alert FeedAlert) {
+	logger := p.logger.With(
+		"aggregate", alert.Aggregate,
+		"feed_id", alert.FeedID,
+		"feed", alert.FeedTitle,
+		"matched", alert.Matched,
+		"total", alert.Total,
+		"ratio", alert.Ratio(),
+	)
+	switch alert.Action {
+	case "suggest_unsubscribe":
+		logger.Warn("Aggregate rule matched, consider unsubscribing from the feed", "action", alert.Action)
+	default:
+		logger.Info("Aggregate rule matched")
+	}
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	miniflux "miniflux.app/v2/client"
+)
+
+func TestProcessorAggregateAlert(t *testing.T) {
+	entries := make([]*miniflux.Entry, 0, 10)
+	for i := 1; i <= 10; i++ {
+		title := "Spam offer"
+		if i > 9 {
+			title = "Real news"
+		}
+		feedID := int64(1)
+		if i%2 == 0 {
+			feedID = 2
+		}
+		entries = append(entries, &miniflux.Entry{
+			ID:     int64(i),
+			Title:  title,
+			FeedID: feedID,
+			Feed:   &miniflux.Feed{ID: feedID, Title: "Feed"},
+		})
+	}
+	// Feed 2 gets an extra legitimate entry to drop below the threshold
+	entries = append(entries, &miniflux.Entry{ID: 11, Title: "Real news", FeedID: 2})
+	entries = append(entries, &miniflux.Entry{ID: 12, Title: "Real news", FeedID: 2})
+
+	mockClient := &MockClient{entries: entries}
+
+	matcher, err := NewMatcher([]Rule{
+		{Name: "Spam", Title: "Spam", Action: "read"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create matcher: %v", err)
+	}
+
+	state, err := LoadState(filepath.Join(t.TempDir(), "state.json"))
+	if err != nil {
+		t.Fatalf("Failed to load state: %v", err)
+	}
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	processor := NewProcessor(mockClient, matcher, logger, false)
+	processor.SetState(state)
+	processor.SetOptions(ProcessorOptions{
+		Aggregates: []AggregateRule{
+			{
+				Name:       "Spammy feeds",
+				Rules:      []string{"Spam"},
+				Threshold:  0.8,
+				Window:     7 * 24 * time.Hour,
+				MinEntries: 3,
+				Action:     "suggest_unsubscribe",
+			},
+		},
+	})
+
+	stats, err := processor.Process()
+	if err != nil {
+		t.Fatalf("Process failed: %v", err)
+	}
+
+	// Feed 1: 5 of 5 spam; feed 2: 4 of 7 spam
+	if len(stats.FeedAlerts) != 1 {
+		t.Fatalf("Expected 1 feed alert, got %d", len(stats.FeedAlerts))
+	}
+	alert := stats.FeedAlerts[0]
+	if alert.FeedID != 1 || alert.Matched != 5 || alert.Total != 5 {
+		t.Errorf("Unexpected alert: %+v", alert)
+	}
+
+	// The same feed does not alert again within the window
+	stats, err = processor.Process()
+	if err != nil {
+		t.Fatalf("Process failed: %v", err)
+	}
+	if len(stats.FeedAlerts) != 0 {
+		t.Errorf("Expected no repeated alert, got %d", len(stats.FeedAlerts))
+	}
+}
+
+func TestProcessorAggregateNotify(t *testing.T) {
+	var messages []string
+	failing := true
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if failing {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		messages = append(messages, r.Header.Get("Title")+": "+string(body))
+	}))
+	defer server.Close()
+
+	entries := make([]*miniflux.Entry, 0, 4)
+	for i := 1; i <= 4; i++ {
+		entries = append(entries, &miniflux.Entry{ID: int64(i), Title: "Spam offer", FeedID: 1, Feed: &miniflux.Feed{ID: 1, Title: "Deals"}})
+	}
+	mockClient := &MockClient{entries: entries}
+
+	matcher, err := NewMatcher([]Rule{{Name: "Spam", Title: "Spam", Action: "read"}})
+	if err != nil {
+		t.Fatalf("Failed to create matcher: %v", err)
+	}
+
+	state, err := LoadState(filepath.Join(t.TempDir(), "state.json"))
+	if err != nil {
+		t.Fatalf("Failed to load state: %v", err)
+	}
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	processor := NewProcessor(mockClient, matcher, logger, false)
+	processor.SetState(state)
+	processor.SetOptions(ProcessorOptions{
+		Aggregates: []AggregateRule{
+			{Name: "Spammy feeds", Threshold: 0.8, Window: 7 * 24 * time.Hour, Action: "notify", Notifier: "phone"},
+		},
+		Notifiers: map[string]NotifierConfig{
+			"phone": {Type: "ntfy", Server: server.URL, Topic: "alerts"},
+		},
+	})
+
+	// A failed push is not recorded as alerted, so the next run sends it
+	stats, err := processor.Process()
+	if err != nil {
+		t.Fatalf("Process failed: %v", err)
+	}
+	if len(stats.FeedAlerts) != 0 || stats.Errors != 1 {
+		t.Errorf("Expected the failed push to be an error, got %+v", stats)
+	}
+
+	failing = false
+	stats, err = processor.Process()
+	if err != nil {
+		t.Fatalf("Process failed: %v", err)
+	}
+	if len(stats.FeedAlerts) != 1 || stats.Notified != 1 {
+		t.Errorf("Expected the alert to be pushed, got %+v", stats)
+	}
+	if len(messages) != 1 || messages[0] != "Spammy feeds: 4 of 4 entries from [Deals] matched (100%)" {
+		t.Errorf("Unexpected pushes: %q", messages)
+	}
+}
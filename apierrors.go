@@ -0,0 +1,148 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Classes of failed Miniflux API requests
+const (
+	apiErrorAuth      = "auth"       // 401 and 403: bad or revoked API key
+	apiErrorRateLimit = "rate_limit" // 429
+	apiErrorServer    = "server"     // 5xx: Miniflux or its proxy is failing
+	apiErrorClient    = "client"     // other 4xx
+	apiErrorNetwork   = "network"    // connection failures and timeouts
+	apiErrorTooLarge  = "too_large"  // response exceeded max_response_size
+)
+
+// APIErrorKey identifies a class of failures on one endpoint
+type APIErrorKey struct {
+	Class    string
+	Endpoint string // method and path with IDs replaced, e.g. "GET /v1/entries/:id"
+}
+
+// String formats the key for logs
+func (k APIErrorKey) String() string {
+	return k.Class + " " + k.Endpoint
+}
+
+// APIErrors counts failed Miniflux API requests since startup
+type APIErrors struct {
+	mu     sync.Mutex
+	counts map[APIErrorKey]int
+}
+
+// NewAPIErrors creates an empty counter
+func NewAPIErrors() *APIErrors {
+	return &APIErrors{counts: make(map[APIErrorKey]int)}
+}
+
+// Record counts a failed request
+func (e *APIErrors) Record(class, endpoint string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.counts[APIErrorKey{Class: class, Endpoint: endpoint}]++
+}
+
+// Snapshot returns a copy of the counts
+func (e *APIErrors) Snapshot() map[APIErrorKey]int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return maps.Clone(e.counts)
+}
+
+// WritePrometheus writes the counts in the Prometheus text exposition format
+func (e *APIErrors) WritePrometheus(w io.Writer) {
+	writeAPIErrorsHeader(w)
+	e.writeSamples(w, "")
+}
+
+// writeAPIErrorsHeader writes the HELP and TYPE lines of the API errors metric
+func writeAPIErrorsHeader(w io.Writer) {
+	writeMetricHeader(w, "miniflux_jobs_api_errors_total", "counter", "Failed Miniflux API requests by error class and endpoint.")
+}
+
+// writeSamples writes the counts, labelled with the instance name unless it is empty
+func (e *APIErrors) writeSamples(w io.Writer, instance string) {
+	counts := e.Snapshot()
+	for _, key := range sortedAPIErrorKeys(counts) {
+		fmt.Fprintf(w, "miniflux_jobs_api_errors_total%s %d\n", promLabels("instance", instance, "class", key.Class, "endpoint", key.Endpoint), counts[key])
+	}
+}
+
+// sortedAPIErrorKeys returns the keys in a stable order
+func sortedAPIErrorKeys(counts map[APIErrorKey]int) []APIErrorKey {
+	return slices.SortedFunc(maps.Keys(counts), func(a, b APIErrorKey) int {
+		return strings.Compare(a.String(), b.String())
+	})
+}
+
+// apiErrorsSince returns the counts added after the before snapshot
+func apiErrorsSince(before, after map[APIErrorKey]int) map[APIErrorKey]int {
+	delta := make(map[APIErrorKey]int)
+	for key, count := range after {
+		if diff := count - before[key]; diff > 0 {
+			delta[key] = diff
+		}
+	}
+	return delta
+}
+
+// classifyStatus returns the error class of an HTTP status, or "" for success
+func classifyStatus(code int) string {
+	switch {
+	case code == http.StatusUnauthorized || code == http.StatusForbidden:
+		return apiErrorAuth
+	case code == http.StatusTooManyRequests:
+		return apiErrorRateLimit
+	case code >= 500:
+		return apiErrorServer
+	case code >= 400:
+		return apiErrorClient
+	default:
+		return ""
+	}
+}
+
+// endpointPattern replaces numeric path segments so entries share one endpoint label
+func endpointPattern(method, path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if _, err := strconv.ParseInt(segment, 10, 64); err == nil {
+			segments[i] = ":id"
+		}
+	}
+	return method + " " + strings.Join(segments, "/")
+}
+
+// errorTrackingTransport records failed API requests by class and endpoint
+type errorTrackingTransport struct {
+	base   http.RoundTripper
+	errors *APIErrors
+}
+
+// RoundTrip performs the request and classifies failures
+func (t *errorTrackingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.base.RoundTrip(req)
+	endpoint := endpointPattern(req.Method, req.URL.Path)
+
+	var tooLarge *ResponseTooLargeError
+	switch {
+	case errors.As(err, &tooLarge):
+		t.errors.Record(apiErrorTooLarge, endpoint)
+	case err != nil:
+		t.errors.Record(apiErrorNetwork, endpoint)
+	default:
+		if class := classifyStatus(resp.StatusCode); class != "" {
+			t.errors.Record(class, endpoint)
+		}
+	}
+	return resp, err
+}
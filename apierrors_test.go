@@ -0,0 +1,62 @@
+package main
+
+import (
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestClassifyStatus(t *testing.T) {
+	tests := map[int]string{
+		200: "",
+		401: apiErrorAuth,
+		403: apiErrorAuth,
+		404: apiErrorClient,
+		429: apiErrorRateLimit,
+		502: apiErrorServer,
+	}
+	for code, expected := range tests {
+		if class := classifyStatus(code); class != expected {
+			t.Errorf("classifyStatus(%d) = %q, expected %q", code, class, expected)
+		}
+	}
+
+	if endpoint := endpointPattern("PUT", "/v1/entries/42/bookmark"); endpoint != "PUT /v1/entries/:id/bookmark" {
+		t.Errorf("Unexpected endpoint: %s", endpoint)
+	}
+}
+
+func TestProcessorAPIErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"error_message": "access unauthorized"}`, http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	client := NewClientWrapper(server.URL, "revoked", 0)
+	matcher, err := NewMatcher([]Rule{{Name: "Spam", Title: "Spam", Action: "read"}})
+	if err != nil {
+		t.Fatalf("Failed to create matcher: %v", err)
+	}
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	processor := NewProcessor(client, matcher, logger, false)
+
+	stats, err := processor.Process()
+	if err == nil {
+		t.Fatal("Expected the run to fail")
+	}
+
+	key := APIErrorKey{Class: apiErrorAuth, Endpoint: "GET /v1/entries"}
+	if len(stats.APIErrors) != 1 || stats.APIErrors[key] != 1 {
+		t.Errorf("Expected 1 auth error on GET /v1/entries, got %v", stats.APIErrors)
+	}
+
+	var metrics strings.Builder
+	client.APIErrors().WritePrometheus(&metrics)
+	if !strings.Contains(metrics.String(), `miniflux_jobs_api_errors_total{class="auth",endpoint="GET /v1/entries"} 1`) {
+		t.Errorf("Unexpected metrics:\n%s", metrics.String())
+	}
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	miniflux "miniflux.app/v2/client"
+)
+
+// auditRecord is one action appended to the audit log
+type auditRecord struct {
+	Time       time.Time `json:"time"`
+	Rule       string    `json:"rule"`
+	Action     string    `json:"action"`
+	EntryID    int64     `json:"entry_id"`
+	FeedID     int64     `json:"feed_id,omitempty"`
+	Title      string    `json:"title"`
+	URL        string    `json:"url"`
+	DryRun     bool      `json:"dry_run"`
+	ConfigHash string    `json:"config_hash,omitempty"` // config the action was decided under
+}
+
+// appendAudit appends the record to the audit log as one JSON line
+func appendAudit(path string, record auditRecord) error {
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open audit log: %w", err)
+	}
+	defer file.Close()
+
+	if err := json.NewEncoder(file).Encode(record); err != nil {
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+	return nil
+}
+
+// audit records an action taken, or proposed in a dry run, on an entry by a rule
+// A failed write is logged but does not fail the action, which already happened
+func (p *Processor) audit(entry *miniflux.Entry, action, rule string) {
+	if p.options.AuditLog == "" {
+		return
+	}
+
+	record := auditRecord{
+		Time:       p.now(),
+		Rule:       rule,
+		Action:     action,
+		EntryID:    entry.ID,
+		FeedID:     entryFeedID(entry),
+		Title:      entry.Title,
+		URL:        entry.URL,
+		DryRun:     p.dryRun,
+		ConfigHash: p.options.ConfigHash,
+	}
+	if err := appendAudit(p.options.AuditLog, record); err != nil {
+		p.logger.Error("Failed to audit action", "rule", rule, "entry_id", entry.ID, "action", action, "error", err)
+	}
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"encoding/json"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	miniflux "miniflux.app/v2/client"
+)
+
+func TestProcessorAuditLog(t *testing.T) {
+	auditPath := filepath.Join(t.TempDir(), "audit.jsonl")
+
+	for _, dryRun := range []bool{true, false} {
+		mockClient := &MockClient{
+			entries: []*miniflux.Entry{
+				{ID: 1, FeedID: 3, Title: "Sponsored: gadgets", URL: "https://example.com/1", Status: miniflux.EntryStatusUnread},
+				{ID: 2, FeedID: 3, Title: "Release notes", URL: "https://example.com/2", Status: miniflux.EntryStatusUnread, Starred: true},
+			},
+		}
+		matcher, err := NewMatcher([]Rule{
+			{Name: "Remove sponsored", Title: "Sponsored", Action: "remove"},
+			{Name: "Star releases", Title: "Release", Action: "star"},
+		})
+		if err != nil {
+			t.Fatalf("Failed to create matcher: %v", err)
+		}
+
+		logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+		processor := NewProcessor(mockClient, matcher, logger, dryRun)
+		processor.SetOptions(ProcessorOptions{AuditLog: auditPath, ConfigHash: "abc123"})
+		if _, err := processor.Process(); err != nil {
+			t.Fatalf("Process failed: %v", err)
+		}
+	}
+
+	data, err := os.ReadFile(auditPath)
+	if err != nil {
+		t.Fatalf("Failed to read audit log: %v", err)
+	}
+
+	// The already starred entry is left alone, so only the removal is audited, once per run
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 audit records, got %d:\n%s", len(lines), data)
+	}
+	for i, dryRun := range []bool{true, false} {
+		var record auditRecord
+		if err := json.Unmarshal([]byte(lines[i]), &record); err != nil {
+			t.Fatalf("Invalid audit record: %v", err)
+		}
+		if record.Rule != "Remove sponsored" || record.Action != "remove" || record.EntryID != 1 ||
+			record.URL != "https://example.com/1" || record.Title != "Sponsored: gadgets" || record.DryRun != dryRun || record.ConfigHash != "abc123" {
+			t.Errorf("Unexpected audit record %d: %+v", i, record)
+		}
+		if record.Time.IsZero() {
+			t.Errorf("Expected audit record %d to be timestamped", i)
+		}
+	}
+}
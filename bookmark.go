@@ -0,0 +1,187 @@
+package main
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+	"unicode"
+
+	miniflux "miniflux.app/v2/client"
+)
+
+// bookmarkStepPrefix marks action steps that add a bookmark to a configured service, e.g. "bookmark:linkding"
+const bookmarkStepPrefix = "bookmark:"
+
+// Supported bookmark services
+const (
+	bookmarkLinkding = "linkding"
+	bookmarkShaarli  = "shaarli"
+)
+
+// BookmarkConfig defines a bookmark manager that matched entries are added to
+type BookmarkConfig struct {
+	Type    string     `yaml:"type"`    // "linkding" or "shaarli"
+	URL     string     `yaml:"url"`     // base URL of the instance
+	Token   string     `yaml:"token"`   // linkding API token or Shaarli API secret
+	Tags    StringList `yaml:"tags"`    // tags added besides the one derived from the rule name
+	Private bool       `yaml:"private"` // create private Shaarli links or unshared linkding bookmarks
+}
+
+// bookmark is the link added to a bookmark service
+type bookmark struct {
+	URL   string
+	Title string
+	Tags  []string
+}
+
+// bookmarkName returns the bookmark service referenced by an action step, if any
+func bookmarkName(step string) (string, bool) {
+	return strings.CutPrefix(step, bookmarkStepPrefix)
+}
+
+// findBookmark looks up a bookmark service by case-insensitive name
+func findBookmark(bookmarks map[string]BookmarkConfig, name string) (BookmarkConfig, bool) {
+	for key, bookmark := range bookmarks {
+		if strings.EqualFold(key, name) {
+			return bookmark, true
+		}
+	}
+	return BookmarkConfig{}, false
+}
+
+// validate checks that the service has a known type, URL and token
+func (b BookmarkConfig) validate() error {
+	switch strings.ToLower(b.Type) {
+	case bookmarkLinkding, bookmarkShaarli:
+	default:
+		return fmt.Errorf("type must be '%s' or '%s', got '%s'", bookmarkLinkding, bookmarkShaarli, b.Type)
+	}
+	if b.URL == "" || b.Token == "" {
+		return fmt.Errorf("%s requires url and token", b.Type)
+	}
+	return nil
+}
+
+// ruleTag derives a bookmark tag from a rule name, e.g. "Long reads" becomes "long-reads"
+func ruleTag(rule string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(rule) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if dash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(r)
+			dash = false
+		} else {
+			dash = true
+		}
+	}
+	return b.String()
+}
+
+// shaarliToken signs the short-lived JWT the Shaarli API expects
+func shaarliToken(secret string, now time.Time) string {
+	encode := base64.RawURLEncoding.EncodeToString
+	header := encode([]byte(`{"typ":"JWT","alg":"HS512"}`))
+	payload := encode(fmt.Appendf(nil, `{"iat":%d}`, now.Unix()))
+
+	mac := hmac.New(sha512.New, []byte(secret))
+	mac.Write([]byte(header + "." + payload))
+	return header + "." + payload + "." + encode(mac.Sum(nil))
+}
+
+// sendBookmark adds the link through the service's REST API
+func sendBookmark(client *http.Client, cfg BookmarkConfig, link bookmark) error {
+	var endpoint, auth string
+	var body any
+
+	base := strings.TrimSuffix(cfg.URL, "/")
+	switch strings.ToLower(cfg.Type) {
+	case bookmarkLinkding:
+		endpoint = base + "/api/bookmarks/"
+		auth = "Token " + cfg.Token
+		body = map[string]any{
+			"url":       link.URL,
+			"title":     link.Title,
+			"tag_names": link.Tags,
+			"shared":    !cfg.Private,
+		}
+	case bookmarkShaarli:
+		endpoint = base + "/api/v1/links"
+		auth = "Bearer " + shaarliToken(cfg.Token, time.Now())
+		body = map[string]any{
+			"url":     link.URL,
+			"title":   link.Title,
+			"tags":    link.Tags,
+			"private": cfg.Private,
+		}
+	default:
+		return fmt.Errorf("unknown bookmark type '%s'", cfg.Type)
+	}
+
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, endpoint, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", auth)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s request failed: %w", cfg.Type, err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("%s request failed: status %d", cfg.Type, resp.StatusCode)
+	}
+	return nil
+}
+
+// applyBookmark adds an entry to the named bookmark service, once per entry and service
+func (p *Processor) applyBookmark(entry *miniflux.Entry, name string, rule *Rule, stats *ProcessStats) bool {
+	cfg, ok := findBookmark(p.options.Bookmarks, name)
+	if !ok {
+		p.entryLogger(entry, rule).Warn("Unknown bookmark service", "action", bookmarkStepPrefix+name)
+		stats.Errors++
+		return false
+	}
+	if entry.URL == "" {
+		p.entryLogger(entry, rule).Warn("Cannot bookmark entry without a URL", "action", bookmarkStepPrefix+name)
+		stats.Errors++
+		return false
+	}
+
+	if !p.shouldNotify(bookmarkStepPrefix+name, entry, "") {
+		return true
+	}
+
+	if p.dryRun {
+		p.entryLogger(entry, rule).Info("Dry run: would bookmark entry", "action", bookmarkStepPrefix+name)
+		p.audit(entry, bookmarkStepPrefix+name, rule.Name)
+		return true
+	}
+
+	tags := append([]string{ruleTag(rule.Name)}, cfg.Tags...)
+	if err := sendBookmark(p.httpClient, cfg, bookmark{URL: entry.URL, Title: entry.Title, Tags: tags}); err != nil {
+		p.entryLogger(entry, rule).Error("Failed to bookmark entry", "action", bookmarkStepPrefix+name, "error", err)
+		stats.Errors++
+		return false
+	}
+
+	stats.Saved++
+	p.entryLogger(entry, rule).Info("Bookmarked entry", "action", bookmarkStepPrefix+name)
+	p.audit(entry, bookmarkStepPrefix+name, rule.Name)
+	return true
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	miniflux "miniflux.app/v2/client"
+)
+
+func TestSendBookmark(t *testing.T) {
+	link := bookmark{URL: "https://example.com/post", Title: "A post", Tags: []string{"long-reads", "rss"}}
+
+	tests := []struct {
+		cfg   BookmarkConfig
+		path  string
+		auth  string
+		tags  string
+		extra string
+	}{
+		{BookmarkConfig{Type: "linkding", Token: "abc"}, "/api/bookmarks/", "Token abc", "tag_names", "shared"},
+		{BookmarkConfig{Type: "shaarli", Token: "secret", Private: true}, "/api/v1/links", "Bearer ", "tags", "private"},
+	}
+
+	for _, tt := range tests {
+		var got map[string]any
+		var path, auth string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			path, auth = r.URL.Path, r.Header.Get("Authorization")
+			json.NewDecoder(r.Body).Decode(&got)
+			w.WriteHeader(http.StatusCreated)
+		}))
+		tt.cfg.URL = server.URL + "/"
+
+		if err := sendBookmark(server.Client(), tt.cfg, link); err != nil {
+			t.Errorf("%s: send failed: %v", tt.cfg.Type, err)
+		}
+		if path != tt.path || !strings.HasPrefix(auth, tt.auth) {
+			t.Errorf("%s: unexpected request to %s with auth %q", tt.cfg.Type, path, auth)
+		}
+		if got["url"] != link.URL || len(got[tt.tags].([]any)) != 2 {
+			t.Errorf("%s: unexpected body %v", tt.cfg.Type, got)
+		}
+		if _, ok := got[tt.extra]; !ok {
+			t.Errorf("%s: expected %s in body %v", tt.cfg.Type, tt.extra, got)
+		}
+		server.Close()
+	}
+}
+
+func TestShaarliToken(t *testing.T) {
+	token := shaarliToken("secret", time.Unix(1700000000, 0))
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		t.Fatalf("Expected a three-part JWT, got %q", token)
+	}
+	if parts[1] != "eyJpYXQiOjE3MDAwMDAwMDB9" {
+		t.Errorf("Unexpected payload %q", parts[1])
+	}
+	if token != shaarliToken("secret", time.Unix(1700000000, 0)) || token == shaarliToken("other", time.Unix(1700000000, 0)) {
+		t.Error("Expected the signature to depend only on the secret and time")
+	}
+}
+
+func TestRuleTag(t *testing.T) {
+	tests := map[string]string{
+		"Long reads":             "long-reads",
+		"Star + bookmark: Alice": "star-bookmark-alice",
+		"  Rust  ":               "rust",
+	}
+	for name, expected := range tests {
+		if got := ruleTag(name); got != expected {
+			t.Errorf("ruleTag(%q) = %q, expected %q", name, got, expected)
+		}
+	}
+}
+
+func TestProcessorBookmarkAction(t *testing.T) {
+	var tags [][]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			TagNames []string `json:"tag_names"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		tags = append(tags, body.TagNames)
+	}))
+	defer server.Close()
+
+	mockClient := &MockClient{
+		entries: []*miniflux.Entry{
+			{ID: 1, Title: "Notes on Go", Author: "Alice", URL: "https://example.com/go", Status: miniflux.EntryStatusUnread},
+		},
+	}
+
+	state, err := LoadState(filepath.Join(t.TempDir(), "state.json"))
+	if err != nil {
+		t.Fatalf("Failed to load state: %v", err)
+	}
+	matcher, err := NewMatcher([]Rule{
+		{Name: "Authors I follow", Authors: []string{"Alice"}, Actions: []ActionStep{{Action: "star"}, {Action: "bookmark:links"}}},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create matcher: %v", err)
+	}
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	processor := NewProcessor(mockClient, matcher, logger, false)
+	processor.SetState(state)
+	processor.SetOptions(ProcessorOptions{Bookmarks: map[string]BookmarkConfig{
+		"links": {Type: "linkding", URL: server.URL, Token: "abc", Tags: StringList{"miniflux"}},
+	}})
+
+	// The second run must not bookmark the same entry again
+	for range 2 {
+		if _, err := processor.Process(); err != nil {
+			t.Fatalf("Process failed: %v", err)
+		}
+	}
+
+	if len(tags) != 1 || !slices.Equal(tags[0], []string{"authors-i-follow", "miniflux"}) {
+		t.Errorf("Expected one bookmark tagged with the rule name, got %v", tags)
+	}
+	if !slices.Equal(mockClient.starredIDs, []int64{1}) {
+		t.Errorf("Expected entry 1 to be starred, got %v", mockClient.starredIDs)
+	}
+}
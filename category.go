@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CategoryPolicy is a category's default action for entries no rule matches
+type CategoryPolicy struct {
+	Category   string        `yaml:"category"`    // category title, case-insensitive
+	CategoryID IDList        `yaml:"category_id"` // alternative to category
+	Action     string        `yaml:"action"`      // action or macro, e.g. "read" or "remove"
+	After      time.Duration `yaml:"after"`       // act on entries published longer ago than, e.g. 72h
+}
+
+// label names the policy in logs and reports
+func (c CategoryPolicy) label() string {
+	if c.Category != "" {
+		return c.Category
+	}
+	ids := make([]string, 0, len(c.CategoryID))
+	for _, id := range c.CategoryID {
+		ids = append(ids, strconv.FormatInt(id, 10))
+	}
+	return "category " + strings.Join(ids, ", ")
+}
+
+// rule expresses the policy as a rule matching unread entries of the category
+func (c CategoryPolicy) rule() Rule {
+	rule := Rule{
+		Name:       "Category default: " + c.label(),
+		CategoryID: c.CategoryID,
+		Action:     c.Action,
+		OlderThan:  c.After,
+	}
+	if c.Category != "" {
+		rule.Category = "(?i)^" + regexp.QuoteMeta(c.Category) + "$"
+	}
+	return rule
+}
+
+// validate checks that the policy names a category and a known action
+func (c CategoryPolicy) validate(macros map[string][]string) error {
+	if c.Category == "" && len(c.CategoryID) == 0 {
+		return fmt.Errorf("category or category_id is required")
+	}
+	if c.Category != "" && len(c.CategoryID) > 0 {
+		return fmt.Errorf("category and category_id are mutually exclusive")
+	}
+	if c.After < 0 {
+		return fmt.Errorf("after must be >= 0")
+	}
+	if _, err := expandAction(c.Action, macros); err != nil {
+		return err
+	}
+	return nil
+}
+
+// AddCategoryDefaults appends category policies, tried only for entries no rule matched
+func (m *Matcher) AddCategoryDefaults(policies []CategoryPolicy) error {
+	for _, policy := range policies {
+		cr, err := compileRule(policy.rule())
+		if err != nil {
+			return err
+		}
+		cr.fallback = true
+		m.compiledRules = append(m.compiledRules, cr)
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	miniflux "miniflux.app/v2/client"
+)
+
+func TestMatcherCategoryDefaults(t *testing.T) {
+	matcher, err := NewMatcher([]Rule{
+		{Name: "Star releases", Title: "Release", Action: "star"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create matcher: %v", err)
+	}
+	err = matcher.AddCategoryDefaults([]CategoryPolicy{
+		{Category: "newsletters", Action: "read", After: 72 * time.Hour},
+		{CategoryID: IDList{7}, Action: "remove", After: 24 * time.Hour},
+	})
+	if err != nil {
+		t.Fatalf("Failed to add category defaults: %v", err)
+	}
+
+	newsletters := &miniflux.Feed{Category: &miniflux.Category{ID: 3, Title: "Newsletters"}}
+	deals := &miniflux.Feed{Category: &miniflux.Category{ID: 7, Title: "Deals"}}
+
+	tests := []struct {
+		name     string
+		entry    *miniflux.Entry
+		expected string
+	}{
+		{"old newsletter", &miniflux.Entry{Title: "Weekly issue", Feed: newsletters, Date: time.Now().Add(-96 * time.Hour)}, "Category default: newsletters"},
+		{"recent newsletter", &miniflux.Entry{Title: "Weekly issue", Feed: newsletters, Date: time.Now().Add(-time.Hour)}, ""},
+		{"content rule wins", &miniflux.Entry{Title: "Release notes", Feed: newsletters, Date: time.Now().Add(-96 * time.Hour)}, "Star releases"},
+		{"category by ID", &miniflux.Entry{Title: "50% off", Feed: deals, Date: time.Now().Add(-48 * time.Hour)}, "Category default: category 7"},
+		{"other category", &miniflux.Entry{Title: "Weekly issue", Feed: &miniflux.Feed{}, Date: time.Now().Add(-96 * time.Hour)}, ""},
+	}
+
+	for _, tt := range tests {
+		results := matcher.MatchAll(tt.entry)
+		got := ""
+		if len(results) > 0 {
+			got = results[0].Rule.Name
+		}
+		if len(results) > 1 {
+			t.Errorf("%s: expected at most one match, got %d", tt.name, len(results))
+		}
+		if got != tt.expected {
+			t.Errorf("%s: expected match '%s', got '%s'", tt.name, tt.expected, got)
+		}
+	}
+}
+
+func TestLoadConfigCategoryDefaults(t *testing.T) {
+	tests := []struct {
+		policy string
+		errMsg string
+	}{
+		{"{category: Newsletters, action: read, after: 72h}", ""},
+		{"{action: read}", "category or category_id is required"},
+		{"{category: Deals, category_id: 7, action: remove}", "mutually exclusive"},
+		{"{category: Deals, action: shred}", "action must be one of"},
+	}
+
+	for _, tt := range tests {
+		configPath := filepath.Join(t.TempDir(), "rules.yaml")
+		content := "miniflux_url: https://miniflux.example.com\ncategory_defaults:\n  - " + tt.policy + "\n"
+		if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write config: %v", err)
+		}
+
+		_, err := LoadConfig(configPath)
+		if tt.errMsg == "" {
+			if err != nil {
+				t.Errorf("%s: unexpected error: %v", tt.policy, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), tt.errMsg) {
+			t.Errorf("%s: expected error containing %q, got %v", tt.policy, tt.errMsg, err)
+		}
+	}
+}
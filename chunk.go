@@ -0,0 +1,53 @@
+package main
+
+import (
+	miniflux "miniflux.app/v2/client"
+)
+
+// chunked reports whether runs are limited by max_entries_per_run
+func (p *Processor) chunked() bool {
+	return p.options.MaxEntriesPerRun > 0
+}
+
+// resumeChunk fetches entries oldest first, after the last entry handled by a previous
+// run that stopped at max_entries_per_run
+func (p *Processor) resumeChunk(filter *miniflux.Filter) {
+	if !p.chunked() {
+		return
+	}
+	filter.Order = "id"
+	filter.Direction = "asc"
+	if p.state != nil && p.state.ResumeAfterID > filter.AfterEntryID {
+		p.logger.Info("Resuming after the last entry of the previous run", "entry_id", p.state.ResumeAfterID)
+		filter.AfterEntryID = p.state.ResumeAfterID
+	}
+}
+
+// truncatePage cuts a page at max_entries_per_run, given how many entries the run already handled
+// It reports whether the limit was reached, which stops fetching
+func (p *Processor) truncatePage(page []*miniflux.Entry, handled int) ([]*miniflux.Entry, bool) {
+	limit := p.options.MaxEntriesPerRun
+	if limit == 0 || handled+len(page) < limit {
+		return page, false
+	}
+	return page[:limit-handled], true
+}
+
+// recordChunk remembers where the next run resumes, or starts over once a run got through
+// every entry. Dry runs record nothing so the real run still handles the same entries.
+func (p *Processor) recordChunk(last int64, truncated bool) {
+	if !p.chunked() {
+		return
+	}
+	if truncated {
+		p.logger.Info("Reached max_entries_per_run, the next run resumes after the last entry", "max_entries_per_run", p.options.MaxEntriesPerRun, "entry_id", last)
+	}
+	if p.state == nil || p.dryRun {
+		return
+	}
+	if truncated {
+		p.state.ResumeAfterID = last
+	} else {
+		p.state.ResumeAfterID = 0
+	}
+}
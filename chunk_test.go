@@ -0,0 +1,118 @@
+package main
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	miniflux "miniflux.app/v2/client"
+)
+
+func TestProcessorMaxEntriesPerRun(t *testing.T) {
+	var entries []*miniflux.Entry
+	for i := 1; i <= 250; i++ {
+		entries = append(entries, &miniflux.Entry{ID: int64(i), Title: "Post", Status: miniflux.EntryStatusUnread})
+	}
+	mockClient := &MockClient{entries: entries}
+
+	state, err := LoadState(filepath.Join(t.TempDir(), "state.json"))
+	if err != nil {
+		t.Fatalf("Failed to load state: %v", err)
+	}
+	matcher, err := NewMatcher([]Rule{{Name: "Any", Title: ".", Action: "read"}})
+	if err != nil {
+		t.Fatalf("Failed to create matcher: %v", err)
+	}
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	processor := NewProcessor(mockClient, matcher, logger, false)
+	processor.SetState(state)
+	processor.SetOptions(ProcessorOptions{MaxEntriesPerRun: 120})
+
+	// The backlog is worked through in bounded chunks, then the next run starts over
+	for _, expected := range []struct {
+		checked int
+		resume  int64
+	}{{120, 120}, {120, 240}, {10, 0}, {120, 120}} {
+		stats, err := processor.Process()
+		if err != nil {
+			t.Fatalf("Process failed: %v", err)
+		}
+		if stats.TotalEntries != expected.checked || state.ResumeAfterID != expected.resume {
+			t.Errorf("Expected %d entries checked and to resume after %d, got %d and %d",
+				expected.checked, expected.resume, stats.TotalEntries, state.ResumeAfterID)
+		}
+		if mockClient.lastFilter.Order != "id" || mockClient.lastFilter.Direction != "asc" {
+			t.Errorf("Expected entries to be fetched oldest first, got %+v", mockClient.lastFilter)
+		}
+	}
+}
+
+func TestLoadConfigMaxEntriesPerRun(t *testing.T) {
+	tests := []struct {
+		settings string
+		errMsg   string
+	}{
+		{"state_file: state.json\nmax_entries_per_run: 500\n", ""},
+		{"max_entries_per_run: 500\n", "requires state_file or state_backend"},
+		{"state_file: state.json\nmax_entries_per_run: 500\nfetch_strategy: round_robin_feeds\n", "requires the sequential fetch_strategy"},
+		{"state_file: state.json\nmax_entries_per_run: 500\nexclude_feeds: Noise\n", "cannot be used with categories or exclude_feeds"},
+		{"state_file: state.json\nmax_entries_per_run: 500\nfetch_direction: desc\n", "cannot be used with fetch_order or fetch_direction"},
+	}
+
+	for _, tt := range tests {
+		configPath := filepath.Join(t.TempDir(), "rules.yaml")
+		content := "miniflux_url: https://miniflux.example.com\n" + tt.settings
+		if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write config: %v", err)
+		}
+
+		_, err := LoadConfig(configPath)
+		if tt.errMsg == "" {
+			if err != nil {
+				t.Errorf("%q: unexpected error: %v", tt.settings, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), tt.errMsg) {
+			t.Errorf("%q: expected error containing %q, got %v", tt.settings, tt.errMsg, err)
+		}
+	}
+}
+
+func TestProcessorMaxEntriesPerRunOnceRule(t *testing.T) {
+	var entries []*miniflux.Entry
+	for i := 1; i <= 250; i++ {
+		entries = append(entries, &miniflux.Entry{ID: int64(i), Title: "Post", Status: miniflux.EntryStatusUnread})
+	}
+	mockClient := &MockClient{entries: entries}
+
+	state, err := LoadState(filepath.Join(t.TempDir(), "state.json"))
+	if err != nil {
+		t.Fatalf("Failed to load state: %v", err)
+	}
+	matcher, err := NewMatcher([]Rule{{Name: "Cleanup", Title: ".", Action: "read", Once: true}})
+	if err != nil {
+		t.Fatalf("Failed to create matcher: %v", err)
+	}
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	processor := NewProcessor(mockClient, matcher, logger, false)
+	processor.SetState(state)
+	processor.SetOptions(ProcessorOptions{MaxEntriesPerRun: 120})
+
+	// The one-off rule stays active until the last chunk has been worked through
+	for run, consumed := range []bool{false, false, true} {
+		if _, err := processor.Process(); err != nil {
+			t.Fatalf("Process failed: %v", err)
+		}
+		if state.IsConsumed("Cleanup") != consumed {
+			t.Errorf("Run %d: expected consumed to be %v", run+1, consumed)
+		}
+	}
+	if len(mockClient.updatedIDs) != 250 {
+		t.Errorf("Expected every entry to be marked read, got %d", len(mockClient.updatedIDs))
+	}
+}
@@ -1,26 +1,72 @@
 package main
 
 import (
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+
 	miniflux "miniflux.app/v2/client"
 )
 
+// defaultMaxResponseSize caps Miniflux API responses when max_response_size is unset
+const defaultMaxResponseSize = 64 << 20
+
 // MinifluxClient defines the interface for interacting with Miniflux API
 // This interface allows for easy mocking in tests
 type MinifluxClient interface {
 	Entries(filter *miniflux.Filter) (*miniflux.EntryResultSet, error)
+	Entry(entryID int64) (*miniflux.Entry, error)
 	UpdateEntries(entryIDs []int64, status string) error
+	ToggleStarred(entryID int64) error
+	SaveEntry(entryID int64) error
+	FetchEntryOriginalContent(entryID int64) (string, error)
+	UpdateEntryTitle(entryID int64, title string) error
 	Feeds() (miniflux.Feeds, error)
+	FeedCounters() (*miniflux.FeedCounters, error)
+	Me() (*miniflux.User, error)
+	DisableFeed(feedID int64) error
+	RefreshFeed(feedID int64) error
+	FlushHistory() error
+	SetBlockFilterRules(feedID int64, rules string) error
 }
 
 // ClientWrapper wraps the actual Miniflux client to implement MinifluxClient interface
 type ClientWrapper struct {
-	client *miniflux.Client
+	client     *miniflux.Client
+	httpClient *http.Client
+	errors     *APIErrors
 }
 
 // NewClientWrapper creates a new ClientWrapper with the given Miniflux client
-func NewClientWrapper(endpoint, apiKey string) *ClientWrapper {
-	client := miniflux.NewClient(endpoint, apiKey)
-	return &ClientWrapper{client: client}
+// Responses larger than maxResponseSize bytes fail instead of being buffered
+func NewClientWrapper(endpoint, apiKey string, maxResponseSize int64) *ClientWrapper {
+	if maxResponseSize == 0 {
+		maxResponseSize = defaultMaxResponseSize
+	}
+	apiErrors := NewAPIErrors()
+	httpClient := &http.Client{
+		Transport: &errorTrackingTransport{
+			base:   &limitedTransport{base: http.DefaultTransport, limit: maxResponseSize},
+			errors: apiErrors,
+		},
+	}
+	client := miniflux.NewClientWithOptions(
+		endpoint,
+		miniflux.WithAPIKey(apiKey),
+		miniflux.WithHTTPClient(httpClient),
+	)
+	return &ClientWrapper{client: client, httpClient: httpClient, errors: apiErrors}
+}
+
+// LogRequests logs each API request at trace level
+func (c *ClientWrapper) LogRequests(logger *slog.Logger) {
+	logRequests(c.httpClient, logger)
+}
+
+// APIErrors returns the failed requests counted since startup
+func (c *ClientWrapper) APIErrors() *APIErrors {
+	return c.errors
 }
 
 // Entries fetches entries from Miniflux with the given filter
@@ -28,12 +74,132 @@ func (c *ClientWrapper) Entries(filter *miniflux.Filter) (*miniflux.EntryResultS
 	return c.client.Entries(filter)
 }
 
+// Entry fetches a single entry by ID, whatever its status
+func (c *ClientWrapper) Entry(entryID int64) (*miniflux.Entry, error) {
+	return c.client.Entry(entryID)
+}
+
 // UpdateEntries updates the status of the given entries
 func (c *ClientWrapper) UpdateEntries(entryIDs []int64, status string) error {
 	return c.client.UpdateEntries(entryIDs, status)
 }
 
+// ToggleStarred flips the starred flag of the given entry
+func (c *ClientWrapper) ToggleStarred(entryID int64) error {
+	return c.client.ToggleStarred(entryID)
+}
+
+// SaveEntry sends the given entry to the third-party integrations enabled in Miniflux
+func (c *ClientWrapper) SaveEntry(entryID int64) error {
+	return c.client.SaveEntry(entryID)
+}
+
+// FetchEntryOriginalContent returns the original article of an entry, scraped by Miniflux
+// The entry itself is left unchanged
+func (c *ClientWrapper) FetchEntryOriginalContent(entryID int64) (string, error) {
+	return c.client.FetchEntryOriginalContent(entryID)
+}
+
+// UpdateEntryTitle replaces the title of an entry
+func (c *ClientWrapper) UpdateEntryTitle(entryID int64, title string) error {
+	_, err := c.client.UpdateEntry(entryID, &miniflux.EntryModificationRequest{Title: &title})
+	return err
+}
+
 // Feeds fetches all feeds from Miniflux
 func (c *ClientWrapper) Feeds() (miniflux.Feeds, error) {
 	return c.client.Feeds()
 }
+
+// FeedCounters fetches the read and unread entry counts of each feed
+func (c *ClientWrapper) FeedCounters() (*miniflux.FeedCounters, error) {
+	return c.client.FetchCounters()
+}
+
+// Me fetches the user of the API key, checking that Miniflux is reachable and accepts the key
+func (c *ClientWrapper) Me() (*miniflux.User, error) {
+	return c.client.Me()
+}
+
+// DisableFeed stops Miniflux from polling the given feed
+func (c *ClientWrapper) DisableFeed(feedID int64) error {
+	disabled := true
+	_, err := c.client.UpdateFeed(feedID, &miniflux.FeedModificationRequest{Disabled: &disabled})
+	return err
+}
+
+// RefreshFeed asks Miniflux to poll the given feed now
+func (c *ClientWrapper) RefreshFeed(feedID int64) error {
+	return c.client.RefreshFeed(feedID)
+}
+
+// FlushHistory removes all read entries except starred ones
+func (c *ClientWrapper) FlushHistory() error {
+	return c.client.FlushHistory()
+}
+
+// SetBlockFilterRules replaces the feed's block filter rules, applied by Miniflux when fetching entries
+func (c *ClientWrapper) SetBlockFilterRules(feedID int64, rules string) error {
+	_, err := c.client.UpdateFeed(feedID, &miniflux.FeedModificationRequest{BlockFilterEntryRules: &rules})
+	return err
+}
+
+// ResponseTooLargeError reports an API response exceeding the configured size limit
+type ResponseTooLargeError struct {
+	Limit int64
+}
+
+func (e *ResponseTooLargeError) Error() string {
+	return fmt.Sprintf("response exceeds max_response_size of %d bytes", e.Limit)
+}
+
+// limitedTransport fails responses whose body is larger than limit bytes
+type limitedTransport struct {
+	base  http.RoundTripper
+	limit int64
+}
+
+// RoundTrip performs the request and guards the response body
+func (t *limitedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.ContentLength > t.limit {
+		resp.Body.Close()
+		return nil, &ResponseTooLargeError{Limit: t.limit}
+	}
+
+	resp.Body = &limitedBody{body: resp.Body, remaining: t.limit, limit: t.limit}
+	return resp, nil
+}
+
+// limitedBody returns a ResponseTooLargeError once more than limit bytes are read
+type limitedBody struct {
+	body      io.ReadCloser
+	remaining int64
+	limit     int64
+}
+
+// Read reads from the body until the limit is exceeded
+func (b *limitedBody) Read(p []byte) (int, error) {
+	if b.remaining < 0 {
+		return 0, &ResponseTooLargeError{Limit: b.limit}
+	}
+	// Read one byte past the limit to detect oversized bodies
+	if int64(len(p)) > b.remaining+1 {
+		p = p[:b.remaining+1]
+	}
+	n, err := b.body.Read(p)
+	b.remaining -= int64(n)
+	if b.remaining < 0 {
+		return n, &ResponseTooLargeError{Limit: b.limit}
+	}
+	return n, err
+}
+
+// Close closes the underlying body
+func (b *limitedBody) Close() error {
+	return b.body.Close()
+}
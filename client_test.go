@@ -0,0 +1,36 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	miniflux "miniflux.app/v2/client"
+)
+
+func TestClientWrapperMaxResponseSize(t *testing.T) {
+	content := strings.Repeat("x", 4096)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Chunked encoding hides the size until the body is read
+		w.(http.Flusher).Flush()
+		fmt.Fprintf(w, `{"total": 1, "entries": [{"id": 1, "content": %q}]}`, content)
+	}))
+	defer server.Close()
+
+	client := NewClientWrapper(server.URL, "key", 8192)
+	result, err := client.Entries(&miniflux.Filter{})
+	if err != nil {
+		t.Fatalf("Expected response within limit to succeed: %v", err)
+	}
+	if len(result.Entries) != 1 || result.Entries[0].Content != content {
+		t.Errorf("Unexpected entries: %+v", result.Entries)
+	}
+
+	client = NewClientWrapper(server.URL, "key", 1024)
+	_, err = client.Entries(&miniflux.Filter{})
+	if err == nil || !strings.Contains(err.Error(), "max_response_size") {
+		t.Errorf("Expected size limit error, got %v", err)
+	}
+}
@@ -1,46 +1,391 @@
 package main
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"os"
+	"slices"
 	"strings"
+	"time"
 
+	"github.com/robfig/cron/v3"
 	"gopkg.in/yaml.v3"
+	miniflux "miniflux.app/v2/client"
 )
 
 // Rule defines a single filtering rule for entries
 type Rule struct {
-	Name    string `yaml:"name"`
-	Feed    string `yaml:"feed"`    // regex pattern for feed title
-	Author  string `yaml:"author"`  // regex pattern for author
-	Title   string `yaml:"title"`   // regex pattern for entry title
-	Content string `yaml:"content"` // regex pattern for entry content
-	Action  string `yaml:"action"`  // "read" or "remove"
+	Name        string   `yaml:"name"`
+	Description string   `yaml:"description"`  // what the rule is for, shown by the docs subcommand
+	Labels      []string `yaml:"labels"`       // free-form tags used by --only-rules and --skip-rules
+	Feed        string   `yaml:"feed"`         // regex pattern for feed title
+	Category    string   `yaml:"category"`     // regex pattern for category title
+	Author      string   `yaml:"author"`       // regex pattern for author
+	Authors     []string `yaml:"authors"`      // exact author names, case-insensitive
+	Title       string   `yaml:"title"`        // regex pattern for entry title
+	Content     string   `yaml:"content"`      // regex pattern for entry content
+	CommentsURL string   `yaml:"comments_url"` // regex pattern for entry comments URL
+	Action      string   `yaml:"action"`       // action or macro name, e.g. "read", "remove" or "read_later"
+	Once        bool     `yaml:"once"`         // apply in a single run, then mark consumed in state
+	Continue    bool     `yaml:"continue"`     // keep evaluating later rules after this one matches
+
+	CaseInsensitive *bool  `yaml:"case_insensitive"` // match the regex fields ignoring case (default: defaults.case_insensitive)
+	MatchMode       string `yaml:"match_mode"`       // "regex" (default), "literal" or "word" for the regex fields
+
+	Actions []ActionStep `yaml:"actions"` // alternative to action: steps with requires and on_error
+
+	ExceptURLs    StringList `yaml:"except_urls"`    // entry URLs the rule never matches, compared canonically
+	ExceptAuthors StringList `yaml:"except_authors"` // authors the rule never matches, case-insensitive
+
+	Delay time.Duration `yaml:"delay"` // act only if the entry is still unread this long after first matching, e.g. 24h
+
+	// Named patterns from the top-level patterns map, resolved into the fields above
+	FeedPattern        string `yaml:"feed_pattern"`
+	AuthorPattern      string `yaml:"author_pattern"`
+	TitlePattern       string `yaml:"title_pattern"`
+	ContentPattern     string `yaml:"content_pattern"`
+	CommentsURLPattern string `yaml:"comments_url_pattern"`
+
+	Transliterate bool `yaml:"transliterate"` // also match feed, author, title and content transliterated from Cyrillic or Greek to Latin
+
+	FetchFullContent bool `yaml:"fetch_full_content"` // match content against the original article, for feeds carrying only a summary
+
+	TitleAllCaps        bool `yaml:"title_all_caps"`        // title letters are all upper case
+	TitleEmojiCountGT   *int `yaml:"title_emoji_count_gt"`  // title has more than N emoji
+	TitleExclamationsGT *int `yaml:"title_exclamations_gt"` // title has more than N exclamation marks
+
+	IncludeStarred bool `yaml:"include_starred"` // apply even to starred entries when skip_starred is set
+	DeadLinkCheck  bool `yaml:"dead_link_check"` // match only entries whose URL returns 404/410
+
+	ChangedSinceLastSeen bool `yaml:"changed_since_last_seen"` // match entries whose content changed since last run
+	SeenTitleBefore      bool `yaml:"seen_title_before"`       // match entries whose title or URL was seen on another entry
+	DuplicateContent     bool `yaml:"duplicate_content"`       // match entries whose normalized content was seen on another entry
+
+	VideoDurationLT time.Duration `yaml:"video_duration_lt"` // YouTube video shorter than, e.g. 60s
+	VideoDurationGT time.Duration `yaml:"video_duration_gt"` // YouTube video longer than, e.g. 2h
+
+	EnclosureDurationLT time.Duration `yaml:"enclosure_duration_lt"` // podcast episode shorter than, e.g. 5m
+	EnclosureDurationGT time.Duration `yaml:"enclosure_duration_gt"` // podcast episode longer than, e.g. 3h
+
+	MinWords int `yaml:"min_words"` // minimum words in the HTML-stripped content
+	MaxWords int `yaml:"max_words"` // maximum words in the HTML-stripped content (0 = no limit)
+
+	Starred   *bool         `yaml:"starred"`    // match only starred (true) or unstarred (false) entries
+	OlderThan time.Duration `yaml:"older_than"` // published longer ago than, e.g. 2160h
+	NewerThan time.Duration `yaml:"newer_than"` // published more recently than, e.g. 24h
+
+	Status StringList `yaml:"status"` // entry statuses to process: unread, read or all (default unread)
+
+	FeedID     IDList `yaml:"feed_id"`     // feed IDs, matched numerically
+	CategoryID IDList `yaml:"category_id"` // category IDs, matched numerically
+
+	SimilarTo *SimilarityCondition `yaml:"similar_to"` // embedding similarity to example texts
+
+	Topic StringList `yaml:"topic"` // topics from the local classifier, e.g. "sports|crypto"
+
+	Notify *NotifyConfig `yaml:"notify"` // overrides the top-level notify settings for notify actions
+
+	RewriteTitle *TitleRewrite `yaml:"rewrite_title"` // find/replace used by the rewrite_title action
+}
+
+// SimilarityCondition matches entries semantically similar to example texts
+type SimilarityCondition struct {
+	Examples  []string `yaml:"examples"`  // example article texts
+	Threshold float64  `yaml:"threshold"` // minimum cosine similarity (default 0.8)
+}
+
+// EmbeddingsConfig configures the OpenAI-compatible embeddings API used by similar_to
+type EmbeddingsConfig struct {
+	Endpoint  string        `yaml:"endpoint"`   // e.g. http://localhost:11434/v1/embeddings
+	Model     string        `yaml:"model"`      // embedding model name
+	APIKey    string        `yaml:"api_key"`    // optional bearer token
+	CacheFile string        `yaml:"cache_file"` // on-disk vector cache
+	CacheTTL  time.Duration `yaml:"cache_ttl"`  // how long an unused vector stays cached (default 720h)
+}
+
+// StringList is a list of strings that may be written as a single YAML scalar
+type StringList []string
+
+// UnmarshalYAML accepts either a scalar or a sequence of scalars
+func (l *StringList) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		*l = StringList{value.Value}
+		return nil
+	}
+
+	var list []string
+	if err := value.Decode(&list); err != nil {
+		return err
+	}
+	*l = list
+	return nil
+}
+
+// IDList is a list of numeric IDs that may be written as a single YAML scalar
+type IDList []int64
+
+// UnmarshalYAML accepts either a scalar or a sequence of integers
+func (l *IDList) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		var id int64
+		if err := value.Decode(&id); err != nil {
+			return err
+		}
+		*l = IDList{id}
+		return nil
+	}
+
+	var list []int64
+	if err := value.Decode(&list); err != nil {
+		return err
+	}
+	*l = list
+	return nil
+}
+
+// Statuses returns the normalized entry statuses a rule applies to
+func (r *Rule) Statuses() []string {
+	if len(r.Status) == 0 {
+		return []string{miniflux.EntryStatusUnread}
+	}
+
+	var statuses []string
+	for _, status := range r.Status {
+		status = strings.ToLower(status)
+		if status == "all" {
+			return []string{miniflux.EntryStatusUnread, miniflux.EntryStatusRead}
+		}
+		if !slices.Contains(statuses, status) {
+			statuses = append(statuses, status)
+		}
+	}
+	return statuses
+}
+
+// OnlyStarred reports whether the rule explicitly targets starred entries
+func (r *Rule) OnlyStarred() bool {
+	return r.Starred != nil && *r.Starred
+}
+
+// Topics returns the normalized topics a rule matches, splitting "a|b" alternatives
+func (r *Rule) Topics() []string {
+	var topics []string
+	for _, item := range r.Topic {
+		for _, topic := range strings.Split(item, "|") {
+			if topic = strings.ToLower(strings.TrimSpace(topic)); topic != "" && !slices.Contains(topics, topic) {
+				topics = append(topics, topic)
+			}
+		}
+	}
+	return topics
+}
+
+// Selected reports whether any of the selectors names the rule or one of its labels
+func (r *Rule) Selected(selectors []string) bool {
+	for _, selector := range selectors {
+		if r.Name == selector || slices.Contains(r.Labels, selector) {
+			return true
+		}
+	}
+	return false
+}
+
+// SelectRules returns the rules chosen by --only-rules and not excluded by --skip-rules
+// An empty only list selects every rule
+func SelectRules(rules []Rule, only, skip []string) []Rule {
+	selected := make([]Rule, 0, len(rules))
+	for _, rule := range rules {
+		if len(only) > 0 && !rule.Selected(only) {
+			continue
+		}
+		if rule.Selected(skip) {
+			continue
+		}
+		selected = append(selected, rule)
+	}
+	return selected
+}
+
+// SelectRules keeps the rules chosen by --only-rules and not excluded by --skip-rules
+// Category defaults are dropped as well: a run limited to some rules must not apply
+// every category policy to the entries those rules leave alone.
+func (c *Config) SelectRules(only, skip []string) {
+	c.Rules = SelectRules(c.Rules, only, skip)
+	c.CategoryDefaults = nil
+}
+
+// AggregateRule triggers a feed-level action when too many of a feed's entries match rules
+type AggregateRule struct {
+	Name       string        `yaml:"name"`
+	Rules      []string      `yaml:"rules"`       // rule names counted as matches (empty = any rule)
+	Threshold  float64       `yaml:"threshold"`   // matched ratio that triggers the action, e.g. 0.8
+	Window     time.Duration `yaml:"window"`      // how far back entries are counted, e.g. 168h
+	MinEntries int           `yaml:"min_entries"` // minimum entries in the window before triggering
+	Action     string        `yaml:"action"`      // "notify" or "suggest_unsubscribe"
+	Notifier   string        `yaml:"notifier"`    // notifier the notify action pushes the alert to
+}
+
+// LinkCheckConfig tunes the HTTP checks made for dead_link_check rules
+type LinkCheckConfig struct {
+	RateLimit time.Duration `yaml:"rate_limit"` // minimum delay between requests
+	CacheTTL  time.Duration `yaml:"cache_ttl"`  // how long a result is reused
+}
+
+// ReadReportConfig configures the periodic per-feed read-percentage report
+type ReadReportConfig struct {
+	Enabled          bool          `yaml:"enabled"`
+	Interval         time.Duration `yaml:"interval"`          // time between reports and window covered (default 720h)
+	MinEntries       int           `yaml:"min_entries"`       // feeds with fewer entries are not rated (default 10)
+	UnsubscribeBelow float64       `yaml:"unsubscribe_below"` // read ratio suggesting unsubscribing (default 0.1)
+	DowngradeBelow   float64       `yaml:"downgrade_below"`   // read ratio suggesting a downgrade (default 0.3)
+}
+
+// SafeModeConfig forces dry-run mode after repeated unclean restarts
+type SafeModeConfig struct {
+	MaxRestarts int           `yaml:"max_restarts"` // unclean restarts tolerated within the window (0 = disabled)
+	Window      time.Duration `yaml:"window"`       // how far back restarts are counted (default 10m)
+	Notifier    string        `yaml:"notifier"`     // notifier alerted when safe mode starts
+	Email       bool          `yaml:"email"`        // also email the alert to the email block's recipients
 }
 
 // Config holds the application configuration
 type Config struct {
 	MinifluxURL string `yaml:"miniflux_url"`
-	Interval    int    `yaml:"interval"` // seconds between runs (0 = run once)
+	Interval    int    `yaml:"interval"`     // seconds between runs (0 = run once)
+	Schedule    string `yaml:"schedule"`     // cron expression for runs in the timezone, e.g. "*/15 7-23 * * *", instead of interval
+	StateFile   string `yaml:"state_file"`   // path to persistent state file
+	SkipStarred bool   `yaml:"skip_starred"` // never apply actions to starred entries
+	Timezone    string `yaml:"timezone"`     // IANA zone for time-based features, e.g. Europe/Berlin (default: local)
+	Listen      string `yaml:"listen"`       // HTTP address for serve mode, e.g. :8080 (empty = disabled)
 	Rules       []Rule `yaml:"rules"`
+
+	Defaults RuleDefaults `yaml:"defaults"` // action, case_insensitive, match_mode and labels rules inherit unless they set their own
+
+	WebhookSecret string `yaml:"webhook_secret"` // secret of the Miniflux webhook posting new entries to /miniflux/webhook on listen
+	AdminToken    string `yaml:"admin_token"`    // bearer token of the admin API served on listen in loop mode (empty = disabled)
+	MetricsListen string `yaml:"metrics_listen"` // HTTP address serving only /metrics, e.g. 127.0.0.1:9100 (default: /metrics on listen)
+
+	HealthGrace time.Duration `yaml:"health_grace"` // how late a run may be before /healthz on listen fails (default 10m)
+
+	DryRun   bool              `yaml:"dry_run"`  // apply no changes, like -dry-run, e.g. in a staging profile
+	Profiles map[string]Config `yaml:"profiles"` // named settings merged over the others when selected with -profile
+
+	Include  []string `yaml:"include"`   // files or globs of further rules, relative to this file
+	RulesDir string   `yaml:"rules_dir"` // directory whose .yaml, .yml, .toml and .json files hold further rules
+
+	CategoryDefaults []CategoryPolicy `yaml:"category_defaults"` // baseline actions per category for entries no rule matches
+
+	MaxResponseSize int64   `yaml:"max_response_size"` // bytes allowed per Miniflux API response (default 64 MiB)
+	MaxChangeRatio  float64 `yaml:"max_change_ratio"`  // abort a run modifying more than this share of entries, e.g. 0.3
+	FetchStrategy   string  `yaml:"fetch_strategy"`    // "sequential", "round_robin_feeds" or "round_robin_categories"
+
+	FetchOrder     string `yaml:"fetch_order"`     // entry field entries are fetched by, e.g. published_at or id (default: Miniflux's)
+	FetchDirection string `yaml:"fetch_direction"` // "asc" for oldest first or "desc" for newest first (default: Miniflux's)
+
+	Concurrency int `yaml:"concurrency"` // pages fetched and entries matched in parallel (default 1)
+
+	Quarantine bool `yaml:"quarantine"` // remove actions mark entries read until the purge subcommand removes them
+
+	FeedCacheTTL time.Duration `yaml:"feed_cache_ttl"` // reuse the feed list used to complete entries across runs, e.g. 1h (default: fetched every run)
+
+	PageRetries     int  `yaml:"page_retries"`      // retries of a failed page fetch, with a growing delay
+	SkipFailedPages bool `yaml:"skip_failed_pages"` // skip pages that still fail after retries instead of aborting the run
+
+	MaxActionsPerRun  int `yaml:"max_actions_per_run"`  // abort a run, exiting non-zero, that would change more entries
+	MaxRemovalsPerRun int `yaml:"max_removals_per_run"` // abort a run, exiting non-zero, that would remove more entries
+
+	MaxEntriesPerRun int `yaml:"max_entries_per_run"` // process at most this many entries per run, oldest first, resuming where the last run stopped
+
+	Statuses     StringList `yaml:"statuses"`      // entry statuses to fetch, unread or read (default: those targeted by the rules)
+	Categories   StringList `yaml:"categories"`    // category titles or IDs to fetch (default: all)
+	ExcludeFeeds StringList `yaml:"exclude_feeds"` // feed titles or IDs never fetched
+
+	SkipEvaluated bool   `yaml:"skip_evaluated"` // only fetch entries newer than the last evaluated one, recorded in state
+	FetchSince    string `yaml:"fetch_since"`    // "published" or "changed": only fetch entries published or changed since the last run
+
+	Patterns map[string]string   `yaml:"patterns"` // named regexes referenced by *_pattern rule fields
+	Macros   map[string][]string `yaml:"macros"`   // named action sequences usable as rule actions
+
+	Webhooks map[string]WebhookConfig `yaml:"webhooks"` // targets for webhook:<name> actions
+	Notify   NotifyConfig             `yaml:"notify"`   // default target and template for notify actions
+
+	Notifiers map[string]NotifierConfig `yaml:"notifiers"` // push targets for notify:<name> actions
+	Email     EmailConfig               `yaml:"email"`     // SMTP settings for email actions and run digests
+	Wallabag  WallabagConfig            `yaml:"wallabag"`  // server and credentials for wallabag actions
+	Readwise  ReadwiseConfig            `yaml:"readwise"`  // Readwise Reader account for readwise actions
+	Bookmarks map[string]BookmarkConfig `yaml:"bookmarks"` // linkding and Shaarli targets for bookmark:<name> actions
+
+	ExportPath   string `yaml:"export_path"`   // file that export actions append matched entries to
+	ExportFormat string `yaml:"export_format"` // "jsonl" or "csv" (default: from the export_path extension)
+
+	AuditLog string `yaml:"audit_log"` // JSONL file every action, applied or dry run, is appended to
+
+	ActionOrder []string `yaml:"action_order"` // order of an entry's steps across rules, e.g. [save, notify, remove]
+
+	Aggregates []AggregateRule  `yaml:"aggregates"`
+	ReadReport ReadReportConfig `yaml:"read_report"`
+	SafeMode   SafeModeConfig   `yaml:"safe_mode"`
+
+	FlushHistory FlushHistoryConfig `yaml:"flush_history"` // scheduled removal of read entries from history
+
+	StateBackend   StateBackendConfig   `yaml:"state_backend"` // alternative to state_file, e.g. shared redis state
+	LeaderElection LeaderElectionConfig `yaml:"leader_election"`
+
+	Instances []InstanceConfig `yaml:"instances"` // further Miniflux accounts maintained with the same rules
+
+	LinkCheck  LinkCheckConfig  `yaml:"link_check"`
+	Embeddings EmbeddingsConfig `yaml:"embeddings"`
+	TopicModel string           `yaml:"topic_model"` // optional topic model file (default: bundled model)
+
+	FeedScope     *feedSelector `yaml:"-"` // set by -feed
+	CategoryScope *feedSelector `yaml:"-"` // set by -category
+
+	Hash    string   `yaml:"-"` // SHA-256 of the config file and the rule files it includes, set by LoadConfig
+	Sources []string `yaml:"-"` // the config file and the rule files it includes, set by LoadConfig
 }
 
-// LoadConfig reads and parses the YAML configuration file
+// LoadConfig reads and parses the configuration file, in the format given by its extension
 func LoadConfig(path string) (*Config, error) {
-	data, err := os.ReadFile(path)
+	return LoadConfigProfile(path, "", "")
+}
+
+// LoadConfigProfile reads and parses the configuration file as yaml, toml or json,
+// with the settings of the named profile, if any, merged over the others
+// An empty format is taken from the file extension, defaulting to yaml.
+// The path may also be an http(s) URL of a centrally managed config.
+func LoadConfigProfile(path, format, profile string) (*Config, error) {
+	format, err := detectConfigFormat(path, format)
+	if err != nil {
+		return nil, err
+	}
+	data, err := readConfig(path)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read config file: %w", err)
 	}
 
 	var config Config
-	if err := yaml.Unmarshal(data, &config); err != nil {
+	if err := unmarshalConfig(data, format, profile, &config); err != nil {
 		return nil, fmt.Errorf("failed to parse config file: %w", err)
 	}
 
+	digest := sha256.New()
+	digest.Write(data)
+	config.Sources = []string{path}
+	if err := config.loadIncludes(path, digest); err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+	config.Hash = hex.EncodeToString(digest.Sum(nil))
+
 	if envURL := os.Getenv("MINIFLUX_URL"); envURL != "" {
 		config.MinifluxURL = envURL
 	}
 
+	config.applyRuleDefaults()
+	if err := config.resolvePatterns(); err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+
 	if err := config.Validate(); err != nil {
 		return nil, fmt.Errorf("invalid config: %w", err)
 	}
@@ -57,18 +402,488 @@ func (c *Config) Validate() error {
 	if c.Interval < 0 {
 		return fmt.Errorf("interval must be >= 0")
 	}
+	if c.Schedule != "" {
+		if c.Interval > 0 {
+			return fmt.Errorf("schedule and interval are mutually exclusive")
+		}
+		if _, err := cron.ParseStandard(c.Schedule); err != nil {
+			return fmt.Errorf("invalid schedule: %w", err)
+		}
+	}
+	if c.WebhookSecret != "" && c.Listen == "" {
+		return fmt.Errorf("webhook_secret requires listen")
+	}
+	if c.AdminToken != "" && (c.Listen == "" || c.Interval == 0 && c.Schedule == "") {
+		return fmt.Errorf("admin_token requires listen and interval or schedule")
+	}
+
+	if err := c.StateBackend.validate(); err != nil {
+		return err
+	}
+
+	if err := c.validateInstances(); err != nil {
+		return err
+	}
+
+	if c.LeaderElection.Enabled && c.StateBackend.Type != backendRedis {
+		return fmt.Errorf("leader_election requires the redis state_backend")
+	}
+
+	if c.MaxChangeRatio < 0 || c.MaxChangeRatio > 1 {
+		return fmt.Errorf("max_change_ratio must be between 0 and 1")
+	}
+
+	switch strings.ToLower(c.FetchStrategy) {
+	case "", fetchSequential, fetchRoundRobinFeeds, fetchRoundRobinCategories:
+	default:
+		return fmt.Errorf("fetch_strategy must be '%s', '%s' or '%s'", fetchSequential, fetchRoundRobinFeeds, fetchRoundRobinCategories)
+	}
+
+	if c.FetchOrder != "" && !slices.Contains(fetchOrders, strings.ToLower(c.FetchOrder)) {
+		return fmt.Errorf("fetch_order must be one of: %s", strings.Join(fetchOrders, ", "))
+	}
+	switch strings.ToLower(c.FetchDirection) {
+	case "", fetchAscending, fetchDescending:
+	default:
+		return fmt.Errorf("fetch_direction must be '%s' or '%s'", fetchAscending, fetchDescending)
+	}
+
+	for _, status := range c.Statuses {
+		switch strings.ToLower(status) {
+		case miniflux.EntryStatusUnread, miniflux.EntryStatusRead:
+		default:
+			return fmt.Errorf("statuses must be '%s' or '%s', got '%s'", miniflux.EntryStatusUnread, miniflux.EntryStatusRead, status)
+		}
+	}
 
+	if c.MaxActionsPerRun < 0 || c.MaxRemovalsPerRun < 0 {
+		return fmt.Errorf("max_actions_per_run and max_removals_per_run must be >= 0")
+	}
+
+	if c.MaxEntriesPerRun < 0 {
+		return fmt.Errorf("max_entries_per_run must be >= 0")
+	}
+	if c.MaxEntriesPerRun > 0 {
+		if !c.HasState() {
+			return fmt.Errorf("max_entries_per_run requires state_file or state_backend to be set")
+		}
+		// Runs resume after the last entry ID handled, which only holds when all feeds are fetched in ID order
+		if s := strings.ToLower(c.FetchStrategy); s != "" && s != fetchSequential {
+			return fmt.Errorf("max_entries_per_run requires the sequential fetch_strategy")
+		}
+		if len(c.Categories) > 0 || len(c.ExcludeFeeds) > 0 {
+			return fmt.Errorf("max_entries_per_run cannot be used with categories or exclude_feeds")
+		}
+		if c.FetchOrder != "" || c.FetchDirection != "" {
+			return fmt.Errorf("max_entries_per_run fetches oldest first and cannot be used with fetch_order or fetch_direction")
+		}
+	}
+
+	if c.Concurrency < 0 {
+		return fmt.Errorf("concurrency must be >= 0")
+	}
+	if c.PageRetries < 0 {
+		return fmt.Errorf("page_retries must be >= 0")
+	}
+	if c.FeedCacheTTL < 0 {
+		return fmt.Errorf("feed_cache_ttl must be >= 0")
+	}
+	if c.HealthGrace < 0 {
+		return fmt.Errorf("health_grace must be >= 0")
+	}
+	if c.Quarantine && !c.HasState() {
+		return fmt.Errorf("quarantine requires state_file or state_backend to be set")
+	}
+
+	switch c.FetchSince {
+	case "", fetchSincePublished, fetchSinceChanged:
+	default:
+		return fmt.Errorf("fetch_since must be '%s' or '%s'", fetchSincePublished, fetchSinceChanged)
+	}
+
+	if c.SkipEvaluated && !c.HasState() {
+		return fmt.Errorf("skip_evaluated requires state_file or state_backend to be set")
+	}
+	if c.FetchSince != "" && !c.HasState() {
+		return fmt.Errorf("fetch_since requires state_file or state_backend to be set")
+	}
+	if c.SkipEvaluated || c.FetchSince != "" {
+		for i, rule := range c.Rules {
+			if rule.reevaluates() {
+				return fmt.Errorf("rule %d (%s): delay, older_than, changed_since_last_seen and dead_link_check cannot be used with skip_evaluated or fetch_since", i, rule.Name)
+			}
+		}
+		for i, policy := range c.CategoryDefaults {
+			if policy.After > 0 {
+				return fmt.Errorf("category_defaults %d (%s): after cannot be used with skip_evaluated or fetch_since", i, policy.label())
+			}
+		}
+	}
+
+	if c.MaxResponseSize < 0 {
+		return fmt.Errorf("max_response_size must be >= 0")
+	}
+
+	if c.Timezone != "" {
+		if _, err := time.LoadLocation(c.Timezone); err != nil {
+			return fmt.Errorf("invalid timezone: %w", err)
+		}
+	}
+
+	if err := validateMacros(c.Macros); err != nil {
+		return err
+	}
+
+	for _, action := range c.ActionOrder {
+		if !isPrimitiveStep(action) {
+			return fmt.Errorf("action_order: unknown action '%s'", action)
+		}
+	}
+
+	for name, webhook := range c.Webhooks {
+		if webhook.URL == "" {
+			return fmt.Errorf("webhook '%s': url is required", name)
+		}
+		if webhook.MaxContentLength < 0 {
+			return fmt.Errorf("webhook '%s': max_content_length must be >= 0", name)
+		}
+	}
+
+	for name, notifier := range c.Notifiers {
+		if err := notifier.validate(); err != nil {
+			return fmt.Errorf("notifier '%s': %w", name, err)
+		}
+	}
+
+	for name, bookmark := range c.Bookmarks {
+		if err := bookmark.validate(); err != nil {
+			return fmt.Errorf("bookmark '%s': %w", name, err)
+		}
+	}
+
+	if c.Email.Host != "" || c.Email.Digest {
+		if err := c.Email.validate(); err != nil {
+			return err
+		}
+	}
+
+	if c.Wallabag.URL != "" {
+		if err := c.Wallabag.validate(); err != nil {
+			return err
+		}
+	}
+
+	if c.Readwise.Token != "" || c.Readwise.Location != "" {
+		if err := c.Readwise.validate(); err != nil {
+			return err
+		}
+	}
+
+	switch strings.ToLower(c.ExportFormat) {
+	case "", exportJSONL, exportCSV:
+	default:
+		return fmt.Errorf("export_format must be '%s' or '%s'", exportJSONL, exportCSV)
+	}
+
+	if c.LinkCheck.RateLimit < 0 || c.LinkCheck.CacheTTL < 0 {
+		return fmt.Errorf("link_check durations must be >= 0")
+	}
+
+	if err := validateMatchMode(c.Defaults.MatchMode); err != nil {
+		return fmt.Errorf("defaults: %w", err)
+	}
 	for i, rule := range c.Rules {
 		if rule.Name == "" {
 			return fmt.Errorf("rule %d: name is required", i)
 		}
+		if err := validateMatchMode(rule.MatchMode); err != nil {
+			return fmt.Errorf("rule %d (%s): %w", i, rule.Name, err)
+		}
+
+		if err := validateSteps(&rule, c.Macros); err != nil {
+			return fmt.Errorf("rule %d (%s): %w", i, rule.Name, err)
+		}
+		steps, _ := expandRule(&rule, c.Macros)
+		for _, step := range steps {
+			if name, ok := webhookName(step); ok {
+				if _, ok := findWebhook(c.Webhooks, name); !ok {
+					return fmt.Errorf("rule %d (%s): unknown webhook '%s'", i, rule.Name, name)
+				}
+			}
+			if name, ok := notifierName(step); ok {
+				if _, ok := findNotifier(c.Notifiers, name); !ok {
+					return fmt.Errorf("rule %d (%s): unknown notifier '%s'", i, rule.Name, name)
+				}
+			}
+			if name, ok := bookmarkName(step); ok {
+				if _, ok := findBookmark(c.Bookmarks, name); !ok {
+					return fmt.Errorf("rule %d (%s): unknown bookmark service '%s'", i, rule.Name, name)
+				}
+			}
+			if step == "email" && c.Email.Host == "" {
+				return fmt.Errorf("rule %d (%s): email requires the email block to be configured", i, rule.Name)
+			}
+			if step == "wallabag" && c.Wallabag.URL == "" {
+				return fmt.Errorf("rule %d (%s): wallabag requires the wallabag block to be configured", i, rule.Name)
+			}
+			if step == "export" && c.ExportPath == "" {
+				return fmt.Errorf("rule %d (%s): export requires export_path to be set", i, rule.Name)
+			}
+			if step == "readwise" && c.Readwise.Token == "" {
+				return fmt.Errorf("rule %d (%s): readwise requires the readwise block to be configured", i, rule.Name)
+			}
+			if step == "rewrite_title" && rule.RewriteTitle == nil {
+				return fmt.Errorf("rule %d (%s): rewrite_title requires the rewrite_title block to be configured", i, rule.Name)
+			}
+			if step == "notify" {
+				notify := ruleNotify(&rule, c.Notify)
+				if notify.URL == "" {
+					return fmt.Errorf("rule %d (%s): notify requires a url in the rule or top-level notify block", i, rule.Name)
+				}
+				if _, err := notify.parseTemplate(); err != nil {
+					return fmt.Errorf("rule %d (%s): invalid notify template: %w", i, rule.Name, err)
+				}
+			}
+		}
+
+		if rule.RewriteTitle != nil {
+			if err := rule.RewriteTitle.validate(); err != nil {
+				return fmt.Errorf("rule %d (%s): %w", i, rule.Name, err)
+			}
+		}
+
+		for _, status := range rule.Status {
+			switch strings.ToLower(status) {
+			case "unread", "read", "all":
+			default:
+				return fmt.Errorf("rule %d (%s): status must be 'unread', 'read' or 'all'", i, rule.Name)
+			}
+		}
+
+		if (rule.TitleEmojiCountGT != nil && *rule.TitleEmojiCountGT < 0) ||
+			(rule.TitleExclamationsGT != nil && *rule.TitleExclamationsGT < 0) {
+			return fmt.Errorf("rule %d (%s): title_emoji_count_gt and title_exclamations_gt must be >= 0", i, rule.Name)
+		}
+
+		if rule.VideoDurationLT < 0 || rule.VideoDurationGT < 0 {
+			return fmt.Errorf("rule %d (%s): video durations must be >= 0", i, rule.Name)
+		}
+		if rule.EnclosureDurationLT < 0 || rule.EnclosureDurationGT < 0 {
+			return fmt.Errorf("rule %d (%s): enclosure durations must be >= 0", i, rule.Name)
+		}
+
+		if rule.OlderThan < 0 || rule.NewerThan < 0 {
+			return fmt.Errorf("rule %d (%s): older_than and newer_than must be >= 0", i, rule.Name)
+		}
+
+		if rule.MinWords < 0 || rule.MaxWords < 0 {
+			return fmt.Errorf("rule %d (%s): min_words and max_words must be >= 0", i, rule.Name)
+		}
+		if rule.MaxWords > 0 && rule.MaxWords < rule.MinWords {
+			return fmt.Errorf("rule %d (%s): max_words must be >= min_words", i, rule.Name)
+		}
+
+		if rule.Once && !c.HasState() {
+			return fmt.Errorf("rule %d (%s): once requires state_file or state_backend to be set", i, rule.Name)
+		}
 
-		action := strings.ToLower(rule.Action)
-		if action != "read" && action != "remove" {
-			return fmt.Errorf("rule %d (%s): action must be 'read' or 'remove'", i, rule.Name)
+		if rule.ChangedSinceLastSeen && !c.HasState() {
+			return fmt.Errorf("rule %d (%s): changed_since_last_seen requires state_file or state_backend to be set", i, rule.Name)
 		}
+
+		if rule.SeenTitleBefore && !c.HasState() {
+			return fmt.Errorf("rule %d (%s): seen_title_before requires state_file or state_backend to be set", i, rule.Name)
+		}
+
+		if rule.Delay < 0 {
+			return fmt.Errorf("rule %d (%s): delay must be >= 0", i, rule.Name)
+		}
+		if rule.Delay > 0 && !c.HasState() {
+			return fmt.Errorf("rule %d (%s): delay requires state_file or state_backend to be set", i, rule.Name)
+		}
+
+		if rule.DuplicateContent && !c.HasState() {
+			return fmt.Errorf("rule %d (%s): duplicate_content requires state_file or state_backend to be set", i, rule.Name)
+		}
+
+		if rule.FetchFullContent && rule.Content == "" && rule.ContentPattern == "" {
+			return fmt.Errorf("rule %d (%s): fetch_full_content requires content or content_pattern", i, rule.Name)
+		}
+
+		if rule.SimilarTo != nil {
+			if len(rule.SimilarTo.Examples) == 0 {
+				return fmt.Errorf("rule %d (%s): similar_to requires at least one example", i, rule.Name)
+			}
+			if rule.SimilarTo.Threshold < 0 || rule.SimilarTo.Threshold > 1 {
+				return fmt.Errorf("rule %d (%s): similar_to threshold must be between 0 and 1", i, rule.Name)
+			}
+			if c.Embeddings.Endpoint == "" || c.Embeddings.Model == "" {
+				return fmt.Errorf("rule %d (%s): similar_to requires embeddings endpoint and model", i, rule.Name)
+			}
+		}
+	}
+	if c.Embeddings.CacheTTL < 0 {
+		return fmt.Errorf("embeddings cache_ttl must be >= 0")
 	}
 
+	for i, policy := range c.CategoryDefaults {
+		if err := policy.validate(c.Macros); err != nil {
+			return fmt.Errorf("category_defaults %d (%s): %w", i, policy.label(), err)
+		}
+	}
+
+	if err := c.validateAggregates(); err != nil {
+		return err
+	}
+
+	if err := c.validateReadReport(); err != nil {
+		return err
+	}
+
+	if c.FlushHistory.Enabled {
+		if !c.HasState() {
+			return fmt.Errorf("flush_history requires state_file or state_backend to be set")
+		}
+		if c.FlushHistory.Interval < 0 || c.FlushHistory.OlderThan < 0 {
+			return fmt.Errorf("flush_history interval and older_than must be >= 0")
+		}
+	}
+
+	if c.SafeMode.MaxRestarts < 0 || c.SafeMode.Window < 0 {
+		return fmt.Errorf("safe_mode max_restarts and window must be >= 0")
+	}
+	if c.SafeMode.MaxRestarts > 0 && !c.HasState() {
+		return fmt.Errorf("safe_mode requires state_file or state_backend to be set")
+	}
+	if c.SafeMode.Notifier != "" {
+		if _, ok := findNotifier(c.Notifiers, c.SafeMode.Notifier); !ok {
+			return fmt.Errorf("safe_mode: unknown notifier '%s'", c.SafeMode.Notifier)
+		}
+	}
+	if c.SafeMode.Email && c.Email.Host == "" {
+		return fmt.Errorf("safe_mode: email requires the email block to be configured")
+	}
+
+	return nil
+}
+
+// resolvePatterns replaces named pattern references in rules with their regexes
+func (c *Config) resolvePatterns() error {
+	for i := range c.Rules {
+		rule := &c.Rules[i]
+		refs := []struct {
+			field string
+			name  string
+			dest  *string
+		}{
+			{"feed", rule.FeedPattern, &rule.Feed},
+			{"author", rule.AuthorPattern, &rule.Author},
+			{"title", rule.TitlePattern, &rule.Title},
+			{"content", rule.ContentPattern, &rule.Content},
+			{"comments_url", rule.CommentsURLPattern, &rule.CommentsURL},
+		}
+
+		for _, ref := range refs {
+			if ref.name == "" {
+				continue
+			}
+			if *ref.dest != "" {
+				return fmt.Errorf("rule %d (%s): %s and %s_pattern are mutually exclusive", i, rule.Name, ref.field, ref.field)
+			}
+			pattern, ok := c.Patterns[ref.name]
+			if !ok {
+				return fmt.Errorf("rule %d (%s): unknown pattern '%s'", i, rule.Name, ref.name)
+			}
+			*ref.dest = pattern
+		}
+	}
+
+	return nil
+}
+
+// validateAggregates checks aggregate rules and their references to rules
+func (c *Config) validateAggregates() error {
+	ruleNames := make(map[string]bool, len(c.Rules))
+	for _, rule := range c.Rules {
+		ruleNames[rule.Name] = true
+	}
+
+	for i, agg := range c.Aggregates {
+		if agg.Name == "" {
+			return fmt.Errorf("aggregate %d: name is required", i)
+		}
+		if !c.HasState() {
+			return fmt.Errorf("aggregate %d (%s): aggregates require state_file or state_backend to be set", i, agg.Name)
+		}
+		if agg.Threshold <= 0 || agg.Threshold > 1 {
+			return fmt.Errorf("aggregate %d (%s): threshold must be between 0 and 1", i, agg.Name)
+		}
+		if agg.Window <= 0 {
+			return fmt.Errorf("aggregate %d (%s): window must be > 0", i, agg.Name)
+		}
+		if agg.MinEntries < 0 {
+			return fmt.Errorf("aggregate %d (%s): min_entries must be >= 0", i, agg.Name)
+		}
+
+		action := strings.ToLower(agg.Action)
+		if action != "notify" && action != "suggest_unsubscribe" {
+			return fmt.Errorf("aggregate %d (%s): action must be 'notify' or 'suggest_unsubscribe'", i, agg.Name)
+		}
+		if action == "notify" {
+			if agg.Notifier == "" {
+				return fmt.Errorf("aggregate %d (%s): notify requires notifier", i, agg.Name)
+			}
+			if _, ok := findNotifier(c.Notifiers, agg.Notifier); !ok {
+				return fmt.Errorf("aggregate %d (%s): unknown notifier '%s'", i, agg.Name, agg.Notifier)
+			}
+		}
+
+		for _, name := range agg.Rules {
+			if !ruleNames[name] {
+				return fmt.Errorf("aggregate %d (%s): unknown rule '%s'", i, agg.Name, name)
+			}
+		}
+	}
+
+	return nil
+}
+
+// HasState reports whether persistent state is configured
+func (c *Config) HasState() bool {
+	return c.StateFile != "" || c.StateBackend.Type != ""
+}
+
+// Location returns the configured timezone, or the local timezone if unset
+// The timezone must already have been checked by Validate
+func (c *Config) Location() *time.Location {
+	if c.Timezone == "" {
+		return time.Local
+	}
+	loc, err := time.LoadLocation(c.Timezone)
+	if err != nil {
+		return time.Local
+	}
+	return loc
+}
+
+// validateReadReport checks the read report settings
+func (c *Config) validateReadReport() error {
+	report := c.ReadReport
+	if !report.Enabled {
+		return nil
+	}
+	if !c.HasState() {
+		return fmt.Errorf("read_report requires state_file or state_backend to be set")
+	}
+	if report.Interval < 0 {
+		return fmt.Errorf("read_report interval must be >= 0")
+	}
+	if report.MinEntries < 0 {
+		return fmt.Errorf("read_report min_entries must be >= 0")
+	}
+	if report.UnsubscribeBelow < 0 || report.UnsubscribeBelow > 1 || report.DowngradeBelow < 0 || report.DowngradeBelow > 1 {
+		return fmt.Errorf("read_report thresholds must be between 0 and 1")
+	}
 	return nil
 }
 
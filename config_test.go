@@ -1,6 +1,8 @@
 package main
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"os"
 	"path/filepath"
 	"testing"
@@ -130,6 +132,67 @@ rules:
 	}
 }
 
+func TestLoadConfigOnceRequiresStateFile(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "rules.yaml")
+
+	configContent := `
+miniflux_url: "https://miniflux.example.com"
+rules:
+  - name: "Purge"
+    feed: "Old"
+    action: "remove"
+    once: true
+`
+	if err := os.WriteFile(configPath, []byte(configContent), 0o644); err != nil {
+		t.Fatalf("Failed to write test config: %v", err)
+	}
+
+	_, err := LoadConfig(configPath)
+	if err == nil {
+		t.Error("Expected error for once rule without state_file")
+	}
+}
+
+func TestLoadConfigScalarOrList(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "rules.yaml")
+
+	configContent := `
+miniflux_url: "https://miniflux.example.com"
+rules:
+  - name: "Scalar"
+    action: "remove"
+    status: all
+  - name: "List"
+    action: "remove"
+    status: [read]
+    feed_id: [1, 2]
+    category_id: 5
+`
+	if err := os.WriteFile(configPath, []byte(configContent), 0o644); err != nil {
+		t.Fatalf("Failed to write test config: %v", err)
+	}
+
+	config, err := LoadConfig(configPath)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	if statuses := config.Rules[0].Statuses(); len(statuses) != 2 {
+		t.Errorf("Expected 'all' to expand to 2 statuses, got %v", statuses)
+	}
+	if statuses := config.Rules[1].Statuses(); len(statuses) != 1 || statuses[0] != "read" {
+		t.Errorf("Expected [read], got %v", statuses)
+	}
+	if ids := config.Rules[1].FeedID; len(ids) != 2 || ids[1] != 2 {
+		t.Errorf("Expected feed_id [1 2], got %v", ids)
+	}
+	if ids := config.Rules[1].CategoryID; len(ids) != 1 || ids[0] != 5 {
+		t.Errorf("Expected category_id [5], got %v", ids)
+	}
+}
+
 func TestGetAPIKey(t *testing.T) {
 	// Test with MINIFLUX_API_KEY
 	os.Setenv("MINIFLUX_API_KEY", "test-api-key")
@@ -176,3 +239,146 @@ func TestGetAPIKeyMissing(t *testing.T) {
 		t.Error("Expected error when no API key is configured")
 	}
 }
+
+func TestLoadConfigTimezone(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "rules.yaml")
+
+	configContent := `
+miniflux_url: "https://miniflux.example.com"
+timezone: "Europe/Berlin"
+`
+	if err := os.WriteFile(configPath, []byte(configContent), 0o644); err != nil {
+		t.Fatalf("Failed to write test config: %v", err)
+	}
+
+	config, err := LoadConfig(configPath)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if loc := config.Location(); loc.String() != "Europe/Berlin" {
+		t.Errorf("Expected location Europe/Berlin, got %s", loc)
+	}
+
+	config.Timezone = "Mars/Olympus_Mons"
+	if err := config.Validate(); err == nil {
+		t.Error("Expected error for unknown timezone")
+	}
+}
+
+func TestLoadConfigPatterns(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "rules.yaml")
+
+	configContent := `
+miniflux_url: "https://miniflux.example.com"
+patterns:
+  sponsored_words: "(?i)sponsored|advertisement"
+rules:
+  - name: "Sponsored titles"
+    title_pattern: sponsored_words
+    action: "read"
+  - name: "Sponsored content"
+    content_pattern: sponsored_words
+    action: "remove"
+`
+	if err := os.WriteFile(configPath, []byte(configContent), 0o644); err != nil {
+		t.Fatalf("Failed to write test config: %v", err)
+	}
+
+	config, err := LoadConfig(configPath)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if config.Rules[0].Title != "(?i)sponsored|advertisement" {
+		t.Errorf("Expected title pattern to be resolved, got '%s'", config.Rules[0].Title)
+	}
+	if config.Rules[1].Content != "(?i)sponsored|advertisement" {
+		t.Errorf("Expected content pattern to be resolved, got '%s'", config.Rules[1].Content)
+	}
+}
+
+func TestLoadConfigUnknownPattern(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "rules.yaml")
+
+	configContent := `
+miniflux_url: "https://miniflux.example.com"
+rules:
+  - name: "Sponsored titles"
+    title_pattern: sponsored_words
+    action: "read"
+`
+	if err := os.WriteFile(configPath, []byte(configContent), 0o644); err != nil {
+		t.Fatalf("Failed to write test config: %v", err)
+	}
+
+	if _, err := LoadConfig(configPath); err == nil {
+		t.Error("Expected error for unknown pattern")
+	}
+}
+
+func TestLoadConfigHash(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "rules.yaml")
+
+	configContent := `miniflux_url: "https://miniflux.example.com"
+`
+	if err := os.WriteFile(configPath, []byte(configContent), 0o644); err != nil {
+		t.Fatalf("Failed to write test config: %v", err)
+	}
+
+	config, err := LoadConfig(configPath)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	sum := sha256.Sum256([]byte(configContent))
+	if expected := hex.EncodeToString(sum[:]); config.Hash != expected {
+		t.Errorf("Expected hash %s, got %s", expected, config.Hash)
+	}
+}
+
+func TestSelectRules(t *testing.T) {
+	rules := []Rule{
+		{Name: "Cleanup old", Labels: []string{"cleanup"}},
+		{Name: "Aggressive cleanup", Labels: []string{"cleanup", "aggressive"}},
+		{Name: "Daily filter"},
+	}
+
+	names := func(rules []Rule) []string {
+		var names []string
+		for _, rule := range rules {
+			names = append(names, rule.Name)
+		}
+		return names
+	}
+
+	if selected := names(SelectRules(rules, nil, nil)); len(selected) != 3 {
+		t.Errorf("Expected all rules without selectors, got %v", selected)
+	}
+	if selected := names(SelectRules(rules, []string{"cleanup"}, []string{"aggressive"})); len(selected) != 1 || selected[0] != "Cleanup old" {
+		t.Errorf("Expected only 'Cleanup old', got %v", selected)
+	}
+	if selected := names(SelectRules(rules, nil, []string{"Daily filter"})); len(selected) != 2 {
+		t.Errorf("Expected rule names to be skippable, got %v", selected)
+	}
+}
+
+func TestConfigSelectRulesSkipsCategoryDefaults(t *testing.T) {
+	config := &Config{
+		Rules: []Rule{
+			{Name: "Cleanup old", Labels: []string{"cleanup"}, Action: "remove"},
+			{Name: "Daily filter", Action: "read"},
+		},
+		CategoryDefaults: []CategoryPolicy{{Category: "News", Action: "read"}},
+	}
+
+	config.SelectRules([]string{"cleanup"}, nil)
+	if len(config.Rules) != 1 || config.Rules[0].Name != "Cleanup old" {
+		t.Errorf("Expected only 'Cleanup old', got %+v", config.Rules)
+	}
+	if len(config.CategoryDefaults) != 0 {
+		t.Errorf("Expected category defaults to be skipped, got %+v", config.CategoryDefaults)
+	}
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"os"
+	"regexp"
+
+	"gopkg.in/yaml.v3"
+)
+
+// envReference matches ${VAR} references in config values; $${VAR} stands for a literal ${VAR}
+var envReference = regexp.MustCompile(`\$?\$\{[A-Za-z_][A-Za-z0-9_]*\}`)
+
+// expandEnv replaces ${VAR} references in the values of a parsed config document
+// with environment variables, so secrets and per-environment settings stay out of the file
+// References to unset variables are left as written, which keeps ${name} group
+// references in rewrite_title replacements working
+func expandEnv(node *yaml.Node) {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		if value := expandEnvValue(node.Value); value != node.Value {
+			node.Value = value
+			// Plain values are resolved again, so a reference can also fill a number or bool
+			if node.Style == 0 {
+				node.Tag = ""
+			}
+		}
+	case yaml.MappingNode:
+		// Only values are expanded, keys are config fields and names
+		for i := 1; i < len(node.Content); i += 2 {
+			expandEnv(node.Content[i])
+		}
+	default:
+		for _, child := range node.Content {
+			expandEnv(child)
+		}
+	}
+}
+
+// expandEnvValue replaces the ${VAR} references in a value with the variables that are set
+func expandEnvValue(value string) string {
+	return envReference.ReplaceAllStringFunc(value, func(ref string) string {
+		if ref[1] == '$' {
+			return ref[1:]
+		}
+		if env, ok := os.LookupEnv(ref[2 : len(ref)-1]); ok {
+			return env
+		}
+		return ref
+	})
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadConfigExpandsEnv(t *testing.T) {
+	t.Setenv("TEST_MINIFLUX_HOST", "miniflux.example.com")
+	t.Setenv("TEST_INTERVAL", "300")
+	t.Setenv("TEST_WEBHOOK_TOKEN", "s3cret")
+	t.Setenv("MINIFLUX_URL", "")
+
+	configPath := filepath.Join(t.TempDir(), "rules.yaml")
+	content := `miniflux_url: https://${TEST_MINIFLUX_HOST}
+interval: ${TEST_INTERVAL}
+webhooks:
+  chat:
+    url: "https://hooks.example.com/${TEST_WEBHOOK_TOKEN}"
+rules:
+  - name: Prices
+    title: '\$\d+$'
+    rewrite_title:
+      find: '^\[(?P<tag>\w+)\] (.*)'
+      replace: '${tag}: $2 $${TEST_INTERVAL}'
+    action: rewrite_title
+`
+	if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	config, err := LoadConfig(configPath)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if config.MinifluxURL != "https://miniflux.example.com" || config.Interval != 300 {
+		t.Errorf("Expected the URL and interval to be expanded, got %q and %d", config.MinifluxURL, config.Interval)
+	}
+	if got := config.Webhooks["chat"].URL; got != "https://hooks.example.com/s3cret" {
+		t.Errorf("Expected the webhook URL to be expanded, got %q", got)
+	}
+	rule := config.Rules[0]
+	if rule.Title != `\$\d+$` || rule.RewriteTitle.Replace != "${tag}: $2 ${TEST_INTERVAL}" {
+		t.Errorf("Expected patterns without references to be kept, got %q and %q", rule.Title, rule.RewriteTitle.Replace)
+	}
+}
@@ -0,0 +1,196 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// Config file formats
+const (
+	formatYAML = "yaml"
+	formatTOML = "toml"
+	formatJSON = "json"
+)
+
+// detectConfigFormat returns the format of a config or rules file, from format if set
+// or else the file extension, ignoring an .age suffix; files without a .toml or .json extension are yaml
+func detectConfigFormat(path, format string) (string, error) {
+	if format == "" {
+		name := strings.TrimSuffix(strings.ToLower(configFileName(path)), ".age")
+		switch filepath.Ext(name) {
+		case ".toml":
+			return formatTOML, nil
+		case ".json":
+			return formatJSON, nil
+		}
+		return formatYAML, nil
+	}
+	switch strings.ToLower(format) {
+	case formatYAML, "yml":
+		return formatYAML, nil
+	case formatTOML:
+		return formatTOML, nil
+	case formatJSON:
+		return formatJSON, nil
+	}
+	return "", fmt.Errorf("unknown config format %q, expected yaml, toml or json", format)
+}
+
+// isRulesFile reports whether a rules_dir file is in a supported format
+func isRulesFile(name string) bool {
+	switch filepath.Ext(strings.TrimSuffix(strings.ToLower(name), ".age")) {
+	case ".yaml", ".yml", ".toml", ".json":
+		return true
+	}
+	return false
+}
+
+// unmarshalConfig decodes a config or rules file in the given format, decrypting it if encrypted
+// with age or sops, rejecting unknown keys, applying the profile if set and expanding
+// ${VAR} references. Keys are the same
+// in every format. JSON is a subset of YAML and decoded as such; TOML is converted to YAML
+// so the yaml tags and custom decoders of the config apply.
+func unmarshalConfig(data []byte, format, profile string, out any) error {
+	data, err := decryptAge(data)
+	if err != nil {
+		return err
+	}
+	if format == formatTOML {
+		var doc map[string]any
+		if err := toml.Unmarshal(data, &doc); err != nil {
+			return err
+		}
+		converted, err := yaml.Marshal(doc)
+		if err != nil {
+			return err
+		}
+		data = converted
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return err
+	}
+	if doc.Kind == 0 {
+		return nil
+	}
+
+	// Keys are checked on the decrypted document, without the sops metadata
+	encrypted, err := decryptSOPS(&doc)
+	if err != nil {
+		return err
+	}
+	if encrypted {
+		if data, err = yaml.Marshal(&doc); err != nil {
+			return err
+		}
+	}
+	if err := checkKnownFields(data, format, out); err != nil {
+		return err
+	}
+	if err := applyProfile(&doc, profile); err != nil {
+		return err
+	}
+
+	expandEnv(&doc)
+	return doc.Decode(out)
+}
+
+// unknownField matches yaml's error for a key that no config field takes
+var unknownField = regexp.MustCompile(`^line (\d+): field (\S+) not found in type (\S+)$`)
+
+// checkKnownFields rejects keys that no config field takes, such as a misspelt condition,
+// suggesting the closest known key. It reads the document as written, before ${VAR}
+// references fill in values, and only reports unknown keys; other errors are left to decoding.
+func checkKnownFields(data []byte, format string, out any) error {
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	outType := reflect.TypeOf(out).Elem()
+	var typeErr *yaml.TypeError
+	if err := decoder.Decode(reflect.New(outType).Interface()); !errors.As(err, &typeErr) {
+		return nil
+	}
+
+	keys := make(map[string][]string)
+	collectKeys(outType, keys)
+
+	var problems []string
+	for _, msg := range typeErr.Errors {
+		match := unknownField.FindStringSubmatch(msg)
+		if match == nil {
+			continue
+		}
+		problem := fmt.Sprintf("unknown key '%s'", match[2])
+		// Lines of a converted TOML document do not match the file
+		if format != formatTOML {
+			problem = fmt.Sprintf("line %s: %s", match[1], problem)
+		}
+		if suggestion := closestKey(match[2], keys[match[3]]); suggestion != "" {
+			problem += fmt.Sprintf(" (did you mean '%s'?)", suggestion)
+		}
+		problems = append(problems, problem)
+	}
+	if len(problems) == 0 {
+		return nil
+	}
+	return errors.New(strings.Join(problems, "; "))
+}
+
+// collectKeys records the config keys of each struct type reachable from t, by type name
+func collectKeys(t reflect.Type, keys map[string][]string) {
+	switch t.Kind() {
+	case reflect.Pointer, reflect.Slice, reflect.Array, reflect.Map:
+		collectKeys(t.Elem(), keys)
+	case reflect.Struct:
+		if _, ok := keys[t.String()]; ok {
+			return
+		}
+		keys[t.String()] = []string{}
+		for _, field := range reflect.VisibleFields(t) {
+			if name, ok := yamlFieldName(field); ok {
+				keys[t.String()] = append(keys[t.String()], name)
+				collectKeys(field.Type, keys)
+			}
+		}
+	}
+}
+
+// closestKey returns the known key nearest to a misspelt one, or "" if none is close
+func closestKey(key string, known []string) string {
+	best, bestDistance := "", 3
+	for _, candidate := range known {
+		if distance := editDistance(key, candidate); distance < bestDistance {
+			best, bestDistance = candidate, distance
+		}
+	}
+	return best
+}
+
+// editDistance returns the Levenshtein distance between two keys
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr := make([]int, len(b)+1)
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev = curr
+	}
+	return prev[len(b)]
+}
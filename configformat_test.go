@@ -0,0 +1,48 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoadConfigFormats(t *testing.T) {
+	configs := map[string]string{
+		"rules.yaml": "miniflux_url: https://miniflux.example.com\nfeed_cache_ttl: 1h\nstatuses: unread\nrules:\n  - name: Promos\n    title: Promo\n    action: read\n",
+		"rules.toml": "miniflux_url = \"https://miniflux.example.com\"\nfeed_cache_ttl = \"1h\"\nstatuses = \"unread\"\n\n[[rules]]\nname = \"Promos\"\ntitle = \"Promo\"\naction = \"read\"\n",
+		"rules.json": "{\n\t\"miniflux_url\": \"https://miniflux.example.com\",\n\t\"feed_cache_ttl\": \"1h\",\n\t\"statuses\": \"unread\",\n\t\"rules\": [{\"name\": \"Promos\", \"title\": \"Promo\", \"action\": \"read\"}]\n}\n",
+	}
+
+	for name, content := range configs {
+		configPath := filepath.Join(t.TempDir(), name)
+		if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write config: %v", err)
+		}
+
+		config, err := LoadConfig(configPath)
+		if err != nil {
+			t.Errorf("%s: failed to load config: %v", name, err)
+			continue
+		}
+		if config.FeedCacheTTL != time.Hour || len(config.Statuses) != 1 || len(config.Rules) != 1 || config.Rules[0].Title != "Promo" {
+			t.Errorf("%s: unexpected config %+v", name, config)
+		}
+	}
+}
+
+func TestLoadConfigFormatOverride(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "rules.conf")
+	content := "miniflux_url = \"https://miniflux.example.com\"\n"
+	if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	if _, err := LoadConfigProfile(configPath, "toml", ""); err != nil {
+		t.Errorf("Expected the toml format to be used, got %v", err)
+	}
+	if _, err := LoadConfigProfile(configPath, "ini", ""); err == nil || !strings.Contains(err.Error(), "unknown config format") {
+		t.Errorf("Expected an unknown format to be rejected, got %v", err)
+	}
+}
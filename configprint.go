@@ -0,0 +1,156 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// redacted replaces secret values in the printed config
+const redacted = "REDACTED"
+
+// secretKeys are the config keys whose values are credentials
+var secretKeys = map[string]bool{
+	"admin_token":    true,
+	"api_key":        true,
+	"client_secret":  true,
+	"password":       true,
+	"token":          true,
+	"webhook_secret": true,
+}
+
+// configCommand runs the config subcommand:
+// miniflux-jobs config print --profile staging
+func configCommand(args []string) {
+	logger := commandLogger(os.Stderr)
+	if len(args) == 0 || args[0] != "print" {
+		fatal(logger, "Usage: miniflux-jobs config print [-config path] [-config-format format] [-profile name]")
+	}
+
+	flags := flag.NewFlagSet("config print", flag.ExitOnError)
+	configPath := flags.String("config", defaultConfigPath(), "Path or http(s) URL of the rules configuration file")
+	configFormat := flags.String("config-format", "", "Format of the configuration file: \"yaml\", \"toml\" or \"json\" (default: from the file extension)")
+	profile := flags.String("profile", "", "Name of the config profile whose settings are merged over the others")
+	flags.Parse(args[1:])
+
+	config, err := LoadConfigProfile(*configPath, *configFormat, *profile)
+	if err != nil {
+		fatal(logger, "Failed to load config", "error", err)
+	}
+	if err := writeEffectiveConfig(os.Stdout, config); err != nil {
+		fatal(logger, "Failed to print config", "error", err)
+	}
+}
+
+// writeEffectiveConfig writes the config as loaded, with includes, the profile and environment
+// variables applied, as YAML headed by the files it was read from
+// Unset settings are omitted and credentials are redacted.
+func writeEffectiveConfig(w io.Writer, config *Config) error {
+	var doc yaml.Node
+	if err := doc.Encode(config); err != nil {
+		return err
+	}
+	pruneConfigNode(&doc)
+	redactConfigNode(&doc, "")
+
+	var sources strings.Builder
+	sources.WriteString("Loaded from:")
+	for _, source := range config.Sources {
+		sources.WriteString("\n  " + source)
+	}
+	fmt.Fprintf(&sources, "\nConfig hash: %s", config.Hash)
+	doc.HeadComment = sources.String()
+
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&doc); err != nil {
+		return err
+	}
+	return encoder.Close()
+}
+
+// pruneConfigNode drops unset settings, reporting whether nothing is left of the node
+func pruneConfigNode(node *yaml.Node) bool {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		switch node.Tag {
+		case "!!null":
+			return true
+		case "!!str":
+			// Durations are written as strings, e.g. 0s
+			return node.Value == "" || node.Value == "0s"
+		case "!!int", "!!float":
+			return node.Value == "0"
+		case "!!bool":
+			return node.Value == "false"
+		}
+		return false
+	case yaml.MappingNode:
+		content := node.Content[:0]
+		for i := 0; i < len(node.Content); i += 2 {
+			if !pruneConfigNode(node.Content[i+1]) {
+				content = append(content, node.Content[i], node.Content[i+1])
+			}
+		}
+		node.Content = content
+		return len(content) == 0
+	case yaml.SequenceNode:
+		// Items keep their position, only their unset settings are dropped
+		for _, item := range node.Content {
+			pruneConfigNode(item)
+		}
+		return len(node.Content) == 0
+	case yaml.DocumentNode:
+		for _, child := range node.Content {
+			pruneConfigNode(child)
+		}
+	}
+	return false
+}
+
+// redactConfigNode hides credentials: the values of secret keys, the passwords of URLs,
+// and the paths of webhook URLs, which usually embed a token
+func redactConfigNode(node *yaml.Node, parent string) {
+	if node.Kind != yaml.MappingNode {
+		for _, child := range node.Content {
+			redactConfigNode(child, parent)
+		}
+		return
+	}
+
+	for i := 0; i < len(node.Content); i += 2 {
+		key, value := node.Content[i].Value, node.Content[i+1]
+		switch {
+		case value.Kind != yaml.ScalarNode:
+			next := parent
+			if parent == "" {
+				next = key
+			}
+			redactConfigNode(value, next)
+		case secretKeys[key]:
+			value.Value, value.Tag, value.Style = redacted, "!!str", 0
+		case key == "url" || key == "server" || key == "endpoint" || key == "miniflux_url":
+			value.Value = redactURL(value.Value, parent == "webhooks")
+		}
+	}
+}
+
+// redactURL hides the password of a URL and, for webhooks, its path and query
+func redactURL(value string, redactPath bool) string {
+	u, err := url.Parse(value)
+	if err != nil || u.Host == "" {
+		return value
+	}
+	if _, ok := u.User.Password(); ok {
+		u.User = url.UserPassword(u.User.Username(), redacted)
+	}
+	if redactPath && (u.Path != "" || u.RawQuery != "") {
+		u.Path, u.RawPath, u.RawQuery = "/"+redacted, "", ""
+	}
+	return u.String()
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWriteEffectiveConfig(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "rules.yaml")
+	content := `miniflux_url: ${TEST_MINIFLUX_URL}
+state_backend:
+  type: redis
+  url: redis://:hunter2@localhost:6379/0
+webhooks:
+  chat:
+    url: https://hooks.example.com/services/T000/XXXX
+readwise:
+  token: s3cret
+include: [extra.yaml]
+rules:
+  - name: Promos
+    title: Sponsored
+    action: read
+`
+	if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	extraPath := filepath.Join(dir, "extra.yaml")
+	if err := os.WriteFile(extraPath, []byte("rules:\n  - name: Deals\n    title: Deal\n    action: star\n"), 0644); err != nil {
+		t.Fatalf("Failed to write rules file: %v", err)
+	}
+
+	t.Setenv("TEST_MINIFLUX_URL", "https://miniflux.example.com")
+	config, err := LoadConfig(configPath)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	var out strings.Builder
+	if err := writeEffectiveConfig(&out, config); err != nil {
+		t.Fatalf("Failed to print config: %v", err)
+	}
+	printed := out.String()
+
+	for _, expected := range []string{
+		"#   " + extraPath,
+		"miniflux_url: https://miniflux.example.com",
+		"  - name: Deals",
+		"url: redis://:REDACTED@localhost:6379/0",
+		"url: https://hooks.example.com/REDACTED",
+		"token: REDACTED",
+	} {
+		if !strings.Contains(printed, expected) {
+			t.Errorf("Expected %q in the printed config:\n%s", expected, printed)
+		}
+	}
+	for _, unexpected := range []string{"hunter2", "s3cret", "T000", "interval:", "skip_starred:"} {
+		if strings.Contains(printed, unexpected) {
+			t.Errorf("Expected no %q in the printed config:\n%s", unexpected, printed)
+		}
+	}
+}
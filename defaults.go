@@ -0,0 +1,69 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// Match modes of rule patterns
+const (
+	matchModeRegex   = "regex"   // patterns are regular expressions
+	matchModeLiteral = "literal" // patterns are plain text found anywhere in the field
+	matchModeWord    = "word"    // patterns are plain text found as whole words
+)
+
+// RuleDefaults holds the settings rules inherit unless they set their own
+type RuleDefaults struct {
+	Action          string   `yaml:"action"`           // action of rules without action or actions
+	CaseInsensitive bool     `yaml:"case_insensitive"` // match patterns ignoring case
+	MatchMode       string   `yaml:"match_mode"`       // "regex" (default), "literal" or "word"
+	Labels          []string `yaml:"labels"`           // labels of rules without labels
+}
+
+// applyRuleDefaults fills in the settings each rule leaves unset from the defaults section
+func (c *Config) applyRuleDefaults() {
+	for i := range c.Rules {
+		rule := &c.Rules[i]
+		if rule.Action == "" && len(rule.Actions) == 0 {
+			rule.Action = c.Defaults.Action
+		}
+		if rule.CaseInsensitive == nil && c.Defaults.CaseInsensitive {
+			caseInsensitive := true
+			rule.CaseInsensitive = &caseInsensitive
+		}
+		if rule.MatchMode == "" {
+			rule.MatchMode = c.Defaults.MatchMode
+		}
+		if rule.Labels == nil {
+			rule.Labels = c.Defaults.Labels
+		}
+	}
+}
+
+// validateMatchMode checks a match_mode setting
+func validateMatchMode(mode string) error {
+	switch mode {
+	case "", matchModeRegex, matchModeLiteral, matchModeWord:
+		return nil
+	}
+	return fmt.Errorf("match_mode must be '%s', '%s' or '%s'", matchModeRegex, matchModeLiteral, matchModeWord)
+}
+
+// effectivePattern returns the regex a pattern of the rule stands for, given its
+// match_mode and case_insensitive settings
+// patternRef is the named pattern the field was resolved from, if any: named patterns are
+// regexes whatever the match_mode, so only case_insensitive applies to them.
+func (r *Rule) effectivePattern(pattern, patternRef string) string {
+	if patternRef == "" {
+		switch r.MatchMode {
+		case matchModeLiteral:
+			pattern = regexp.QuoteMeta(pattern)
+		case matchModeWord:
+			pattern = `\b` + regexp.QuoteMeta(pattern) + `\b`
+		}
+	}
+	if r.CaseInsensitive != nil && *r.CaseInsensitive {
+		pattern = "(?i)" + pattern
+	}
+	return pattern
+}
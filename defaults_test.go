@@ -0,0 +1,163 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	miniflux "miniflux.app/v2/client"
+)
+
+func TestLoadConfigRuleDefaults(t *testing.T) {
+	os.Unsetenv("MINIFLUX_URL")
+
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "rules.yaml")
+
+	configContent := `
+miniflux_url: https://miniflux.example.com
+defaults:
+  action: read
+  case_insensitive: true
+  match_mode: word
+  labels: [noise]
+rules:
+  - name: "Inherits"
+    title: "ad"
+  - name: "Overrides"
+    title: "C++"
+    action: remove
+    case_insensitive: false
+    match_mode: literal
+    labels: []
+  - name: "Steps"
+    title: "(?i)crypto"
+    match_mode: regex
+    actions:
+      - action: star
+`
+	if err := os.WriteFile(configPath, []byte(configContent), 0o644); err != nil {
+		t.Fatalf("Failed to write test config: %v", err)
+	}
+
+	config, err := LoadConfig(configPath)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	inherits := config.Rules[0]
+	if inherits.Action != "read" || !slices.Equal(inherits.Labels, []string{"noise"}) || inherits.MatchMode != matchModeWord {
+		t.Errorf("Expected the defaults to be inherited, got %+v", inherits)
+	}
+	if inherits.CaseInsensitive == nil || !*inherits.CaseInsensitive {
+		t.Error("Expected case_insensitive to be inherited")
+	}
+
+	overrides := config.Rules[1]
+	if overrides.Action != "remove" || len(overrides.Labels) != 0 || overrides.MatchMode != matchModeLiteral || *overrides.CaseInsensitive {
+		t.Errorf("Expected the rule's own settings to win, got %+v", overrides)
+	}
+	if steps := config.Rules[2]; steps.Action != "" {
+		t.Errorf("Expected no default action for a rule with actions, got %q", steps.Action)
+	}
+
+	matcher, err := NewMatcher(config.Rules)
+	if err != nil {
+		t.Fatalf("Failed to create matcher: %v", err)
+	}
+	tests := []struct {
+		title string
+		rule  string
+	}{
+		{"New AD campaign", "Inherits"},
+		{"Loading the admin page", ""},
+		{"What's new in C++26", "Overrides"},
+		{"What's new in c++26", ""},
+		{"Crypto news", "Steps"},
+	}
+	for _, tt := range tests {
+		result := matcher.Match(&miniflux.Entry{Title: tt.title, Feed: &miniflux.Feed{Title: "News"}})
+		got := ""
+		if result.Matched {
+			got = result.Rule.Name
+		}
+		if got != tt.rule {
+			t.Errorf("%q: expected rule %q, got %q", tt.title, tt.rule, got)
+		}
+	}
+}
+
+func TestLoadConfigInvalidMatchMode(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "rules.yaml")
+
+	configContent := `
+miniflux_url: https://miniflux.example.com
+defaults:
+  match_mode: glob
+rules:
+  - name: "Test Rule"
+    title: "test"
+    action: read
+`
+	if err := os.WriteFile(configPath, []byte(configContent), 0o644); err != nil {
+		t.Fatalf("Failed to write test config: %v", err)
+	}
+
+	if _, err := LoadConfig(configPath); err == nil {
+		t.Error("Expected error for an unknown match_mode")
+	}
+}
+
+func TestMatchModeKeepsNamedPatterns(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "rules.yaml")
+
+	configContent := `
+miniflux_url: https://miniflux.example.com
+patterns:
+  sponsored: "sponsor(ed)?|promot(ed|ion)"
+defaults:
+  action: read
+  case_insensitive: true
+  match_mode: word
+rules:
+  - name: "Named"
+    title_pattern: sponsored
+  - name: "Inline"
+    title: "ad"
+`
+	if err := os.WriteFile(configPath, []byte(configContent), 0o644); err != nil {
+		t.Fatalf("Failed to write test config: %v", err)
+	}
+
+	config, err := LoadConfig(configPath)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	matcher, err := NewMatcher(config.Rules)
+	if err != nil {
+		t.Fatalf("Failed to create matcher: %v", err)
+	}
+
+	tests := []struct {
+		title string
+		rule  string
+	}{
+		{"Promoted: new phones", "Named"},
+		{"A SPONSORED post", "Named"},
+		{"Weekly roundup", ""},
+		{"New ad campaign", "Inline"},
+	}
+	for _, tt := range tests {
+		result := matcher.Match(&miniflux.Entry{Title: tt.title, Feed: &miniflux.Feed{Title: "News"}})
+		got := ""
+		if result.Matched {
+			got = result.Rule.Name
+		}
+		if got != tt.rule {
+			t.Errorf("%q: expected rule %q, got %q", tt.title, tt.rule, got)
+		}
+	}
+}
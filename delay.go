@@ -0,0 +1,35 @@
+package main
+
+import miniflux "miniflux.app/v2/client"
+
+// delayElapsed reports whether a delayed rule may act on the entry now
+// The first match starts the grace period; the rule acts once it has passed
+// and only if the entry is still unread
+func (p *Processor) delayElapsed(entry *miniflux.Entry, rule *Rule) bool {
+	if rule.Delay <= 0 || p.state == nil {
+		return true
+	}
+
+	now := p.now()
+	due, ok := p.state.PendingDue(entry.ID, rule.Name)
+	if !ok {
+		due = now.Add(rule.Delay)
+		if !p.dryRun {
+			p.state.AddPending(entry.ID, rule.Name, due)
+		}
+		p.entryLogger(entry, rule).Info("Delaying rule", "until", due)
+		return false
+	}
+	if now.Before(due) {
+		return false
+	}
+
+	if !p.dryRun {
+		p.state.RemovePending(entry.ID, rule.Name)
+	}
+	if entry.Status != miniflux.EntryStatusUnread {
+		p.entryLogger(entry, rule).Info("Dropping delayed rule, the entry is no longer unread")
+		return false
+	}
+	return true
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	miniflux "miniflux.app/v2/client"
+)
+
+func TestProcessorDelayedAction(t *testing.T) {
+	mockClient := &MockClient{
+		entries: []*miniflux.Entry{
+			{ID: 1, Title: "Borderline post", Status: miniflux.EntryStatusUnread},
+		},
+	}
+
+	matcher, err := NewMatcher([]Rule{
+		{Name: "Borderline", Title: "Borderline", Action: "remove", Delay: 24 * time.Hour},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create matcher: %v", err)
+	}
+
+	state, err := LoadState(filepath.Join(t.TempDir(), "state.json"))
+	if err != nil {
+		t.Fatalf("Failed to load state: %v", err)
+	}
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	processor := NewProcessor(mockClient, matcher, logger, false)
+	processor.SetState(state)
+
+	// The first match only starts the grace period
+	stats, err := processor.Process()
+	if err != nil {
+		t.Fatalf("Process failed: %v", err)
+	}
+	if stats.Removed != 0 {
+		t.Fatalf("Expected no removal during the delay, got %d", stats.Removed)
+	}
+	due, ok := state.PendingDue(1, "Borderline")
+	if !ok || time.Until(due) < 23*time.Hour {
+		t.Fatalf("Expected entry to be pending for 24h, got %v (%v)", due, ok)
+	}
+
+	// Once the delay has passed, the still unread entry is removed
+	state.AddPending(1, "Borderline", time.Now().Add(-time.Minute))
+	if stats, err = processor.Process(); err != nil {
+		t.Fatalf("Process failed: %v", err)
+	}
+	if stats.Removed != 1 {
+		t.Errorf("Expected removal after the delay, got %d", stats.Removed)
+	}
+	if _, ok := state.PendingDue(1, "Borderline"); ok {
+		t.Error("Expected pending match to be cleared")
+	}
+}
+
+func TestProcessorDelayedActionEntryRead(t *testing.T) {
+	entry := &miniflux.Entry{ID: 1, Title: "Borderline post", Status: miniflux.EntryStatusRead}
+	mockClient := &MockClient{entries: []*miniflux.Entry{entry}}
+
+	matcher, err := NewMatcher([]Rule{
+		{Name: "Borderline", Title: "Borderline", Action: "remove", Status: StringList{"all"}, Delay: time.Hour},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create matcher: %v", err)
+	}
+
+	state, err := LoadState(filepath.Join(t.TempDir(), "state.json"))
+	if err != nil {
+		t.Fatalf("Failed to load state: %v", err)
+	}
+	state.AddPending(1, "Borderline", time.Now().Add(-time.Minute))
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	processor := NewProcessor(mockClient, matcher, logger, false)
+	processor.SetState(state)
+
+	stats, err := processor.Process()
+	if err != nil {
+		t.Fatalf("Process failed: %v", err)
+	}
+	if stats.Removed != 0 {
+		t.Errorf("Expected entry read during the delay to be left alone, got %d removed", stats.Removed)
+	}
+	if _, ok := state.PendingDue(1, "Borderline"); ok {
+		t.Error("Expected pending match to be dropped")
+	}
+}
@@ -0,0 +1,282 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"html/template"
+	"io"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// docsFields are rule fields shown in a rule's heading or group rather than its conditions
+var docsFields = map[string]bool{
+	"name": true, "description": true, "labels": true, "action": true, "actions": true, "notify": true, "rewrite_title": true,
+	"feed": true, "feed_id": true, "category": true, "category_id": true,
+}
+
+// ruleDoc describes one rule in the generated documentation
+type ruleDoc struct {
+	Name        string
+	Description string
+	Action      string
+	Labels      []string
+	Conditions  []string
+	Hits        int
+}
+
+// ruleDocGroup holds the rules applying to the same feeds or categories
+type ruleDocGroup struct {
+	Scope string
+	Rules []ruleDoc
+}
+
+// rulesDocument is the data rendered by the docs subcommand
+type rulesDocument struct {
+	Generated time.Time
+	HitsSince time.Time // zero without state, when hit counts are unknown
+	Groups    []ruleDocGroup
+}
+
+// ruleScope describes the feeds and categories a rule is limited to
+func ruleScope(rule *Rule) string {
+	var parts []string
+	if rule.Feed != "" {
+		parts = append(parts, "feed `"+rule.Feed+"`")
+	}
+	if len(rule.FeedID) > 0 {
+		parts = append(parts, "feed ID "+joinIDs(rule.FeedID))
+	}
+	if rule.Category != "" {
+		parts = append(parts, "category `"+rule.Category+"`")
+	}
+	if len(rule.CategoryID) > 0 {
+		parts = append(parts, "category ID "+joinIDs(rule.CategoryID))
+	}
+	if len(parts) == 0 {
+		return "All feeds"
+	}
+	return strings.Join(parts, ", ")
+}
+
+// joinIDs formats IDs as a comma-separated list
+func joinIDs(ids []int64) string {
+	formatted := make([]string, 0, len(ids))
+	for _, id := range ids {
+		formatted = append(formatted, strconv.FormatInt(id, 10))
+	}
+	return strings.Join(formatted, ", ")
+}
+
+// ruleConditions lists the rule's set fields other than its scope, e.g. "title: `(?i)sponsored`"
+// Fields are read by reflection so new rule options show up without changes here
+func ruleConditions(rule *Rule) []string {
+	var conditions []string
+	value := reflect.ValueOf(*rule)
+	for i := range value.NumField() {
+		field := value.Type().Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+		if name == "" || docsFields[name] || strings.HasSuffix(name, "_pattern") || value.Field(i).IsZero() {
+			continue
+		}
+		conditions = append(conditions, name+": "+formatDocValue(value.Field(i)))
+	}
+	return conditions
+}
+
+// formatDocValue renders a rule field value for the documentation
+func formatDocValue(v reflect.Value) string {
+	if v.Kind() == reflect.Pointer {
+		v = v.Elem()
+	}
+	switch value := v.Interface().(type) {
+	case string:
+		return "`" + value + "`"
+	case time.Duration:
+		return value.String()
+	case SimilarityCondition:
+		return fmt.Sprintf("%d examples", len(value.Examples))
+	}
+	if v.Kind() == reflect.Slice {
+		items := make([]string, 0, v.Len())
+		for i := range v.Len() {
+			items = append(items, formatDocValue(v.Index(i)))
+		}
+		return strings.Join(items, ", ")
+	}
+	return fmt.Sprint(v.Interface())
+}
+
+// ruleHits counts the entries each rule acted on since the given time
+func ruleHits(state *State, since time.Time) map[string]int {
+	entries := make(map[string]map[int64]bool)
+	for _, action := range state.ActionsSince("", since) {
+		if entries[action.Rule] == nil {
+			entries[action.Rule] = make(map[int64]bool)
+		}
+		entries[action.Rule][action.EntryID] = true
+	}
+
+	hits := make(map[string]int, len(entries))
+	for rule, ids := range entries {
+		hits[rule] = len(ids)
+	}
+	return hits
+}
+
+// buildRulesDocument groups the rules and category defaults by scope, in config order
+func buildRulesDocument(config *Config, hits map[string]int) rulesDocument {
+	type scopedRule struct {
+		rule  Rule
+		scope string
+	}
+	var rules []scopedRule
+	for _, rule := range config.Rules {
+		rules = append(rules, scopedRule{rule, ruleScope(&rule)})
+	}
+	for _, policy := range config.CategoryDefaults {
+		rule := policy.rule()
+		rule.Description = "Default for entries no rule matches"
+		scope := policy.label()
+		if policy.Category != "" {
+			scope = "category " + policy.Category
+		}
+		rules = append(rules, scopedRule{rule, scope})
+	}
+
+	var doc rulesDocument
+	index := make(map[string]int)
+	for _, sr := range rules {
+		g, ok := index[sr.scope]
+		if !ok {
+			g = len(doc.Groups)
+			index[sr.scope] = g
+			doc.Groups = append(doc.Groups, ruleDocGroup{Scope: sr.scope})
+		}
+		doc.Groups[g].Rules = append(doc.Groups[g].Rules, ruleDoc{
+			Name:        sr.rule.Name,
+			Description: sr.rule.Description,
+			Action:      ruleActionLabel(&sr.rule),
+			Labels:      sr.rule.Labels,
+			Conditions:  ruleConditions(&sr.rule),
+			Hits:        hits[sr.rule.Name],
+		})
+	}
+	return doc
+}
+
+// writeRulesMarkdown renders the rules document as Markdown
+func writeRulesMarkdown(w io.Writer, doc rulesDocument) error {
+	var b strings.Builder
+	b.WriteString("# Miniflux filtering rules\n\n")
+	fmt.Fprintf(&b, "Generated %s", doc.Generated.Format("2006-01-02 15:04"))
+	if !doc.HitsSince.IsZero() {
+		fmt.Fprintf(&b, ", hit counts since %s", doc.HitsSince.Format("2006-01-02"))
+	}
+	b.WriteString(".\n")
+
+	for _, group := range doc.Groups {
+		fmt.Fprintf(&b, "\n## %s\n", group.Scope)
+		for _, rule := range group.Rules {
+			fmt.Fprintf(&b, "\n### %s\n\n", rule.Name)
+			if rule.Description != "" {
+				fmt.Fprintf(&b, "%s\n\n", rule.Description)
+			}
+			fmt.Fprintf(&b, "- Action: %s\n", rule.Action)
+			if !doc.HitsSince.IsZero() {
+				fmt.Fprintf(&b, "- Hits: %d\n", rule.Hits)
+			}
+			if len(rule.Labels) > 0 {
+				fmt.Fprintf(&b, "- Labels: %s\n", strings.Join(rule.Labels, ", "))
+			}
+			for _, condition := range rule.Conditions {
+				fmt.Fprintf(&b, "- %s\n", condition)
+			}
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// rulesHTMLTemplate renders the rules document as a self-contained page
+var rulesHTMLTemplate = template.Must(template.New("rules").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Miniflux filtering rules</title>
+<style>
+body { font-family: system-ui, sans-serif; max-width: 60rem; margin: 2rem auto; padding: 0 1rem; color: #222; }
+h2 { font-size: 1.2rem; margin-top: 2rem; border-bottom: 1px solid #ddd; padding-bottom: .25rem; }
+h3 { font-size: 1rem; margin-bottom: .25rem; }
+.meta { color: #666; }
+</style>
+</head>
+<body>
+<h1>Miniflux filtering rules</h1>
+<p class="meta">Generated {{.Generated.Format "2006-01-02 15:04"}}{{if not .HitsSince.IsZero}}, hit counts since {{.HitsSince.Format "2006-01-02"}}{{end}}.</p>
+{{range .Groups}}
+<h2>{{.Scope}}</h2>{{range .Rules}}
+<h3>{{.Name}}</h3>
+{{with .Description}}<p>{{.}}</p>{{end}}
+<ul>
+<li>Action: {{.Action}}</li>{{if not $.HitsSince.IsZero}}
+<li>Hits: {{.Hits}}</li>{{end}}{{with .Labels}}
+<li>Labels: {{range $i, $l := .}}{{if $i}}, {{end}}{{$l}}{{end}}</li>{{end}}{{range .Conditions}}
+<li>{{.}}</li>{{end}}
+</ul>{{end}}
+{{end}}
+</body>
+</html>
+`))
+
+// docsCommand runs the docs subcommand, printing the ruleset as Markdown or HTML
+func docsCommand(args []string) {
+	flags := flag.NewFlagSet("docs", flag.ExitOnError)
+	configPath := flags.String("config", defaultConfigPath(), "Path to the rules configuration file")
+	format := flags.String("format", "markdown", "Output format: markdown or html")
+	flags.Parse(args)
+
+	logger := commandLogger(os.Stderr)
+
+	config, err := LoadConfig(*configPath)
+	if err != nil {
+		fatal(logger, "Failed to load config", "error", err)
+	}
+
+	now := time.Now()
+	var hits map[string]int
+	var hitsSince time.Time
+	if config.HasState() {
+		store, err := OpenStateStore(config)
+		if err != nil {
+			fatal(logger, "Failed to open state store", "error", err)
+		}
+		defer store.Close()
+		state, err := LoadStateFrom(store)
+		if err != nil {
+			fatal(logger, "Failed to load state", "error", err)
+		}
+		hitsSince = now.Add(-seenRetention)
+		hits = ruleHits(state, hitsSince)
+	}
+
+	doc := buildRulesDocument(config, hits)
+	doc.Generated = now
+	doc.HitsSince = hitsSince
+
+	switch *format {
+	case "markdown":
+		err = writeRulesMarkdown(os.Stdout, doc)
+	case "html":
+		err = rulesHTMLTemplate.Execute(os.Stdout, doc)
+	default:
+		fatal(logger, "Unknown -format, expected markdown or html", "format", *format)
+	}
+	if err != nil {
+		fatal(logger, "Failed to write docs", "error", err)
+	}
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBuildRulesDocument(t *testing.T) {
+	config := &Config{
+		Rules: []Rule{
+			{Name: "Sponsored", Description: "Drop sponsored posts", Feed: "Tech", Title: "(?i)sponsored", Action: "remove"},
+			{Name: "Shorts", VideoDurationLT: time.Minute, Action: "read"},
+			{Name: "Tech promos", Feed: "Tech", Authors: []string{"Ads", "Promo"}, Actions: []ActionStep{{Action: "save"}, {Action: "remove"}}},
+		},
+		CategoryDefaults: []CategoryPolicy{
+			{Category: "Deals", Action: "remove", After: 24 * time.Hour},
+		},
+	}
+
+	doc := buildRulesDocument(config, map[string]int{"Sponsored": 12})
+
+	var scopes []string
+	for _, group := range doc.Groups {
+		scopes = append(scopes, group.Scope)
+	}
+	if strings.Join(scopes, "|") != "feed `Tech`|All feeds|category Deals" {
+		t.Fatalf("Unexpected groups: %v", scopes)
+	}
+
+	tech := doc.Groups[0].Rules
+	if len(tech) != 2 || tech[0].Hits != 12 || tech[1].Action != "save, remove" {
+		t.Errorf("Unexpected feed group rules: %+v", tech)
+	}
+	if got := strings.Join(tech[1].Conditions, "; "); got != "authors: `Ads`, `Promo`" {
+		t.Errorf("Unexpected conditions: %s", got)
+	}
+	if got := strings.Join(doc.Groups[2].Rules[0].Conditions, "; "); got != "older_than: 24h0m0s" {
+		t.Errorf("Unexpected category default conditions: %s", got)
+	}
+}
+
+func TestWriteRulesMarkdown(t *testing.T) {
+	config := &Config{
+		Rules: []Rule{
+			{Name: "Sponsored", Description: "Drop sponsored posts", Title: "(?i)sponsored", Action: "remove", Labels: []string{"ads"}},
+		},
+	}
+	doc := buildRulesDocument(config, map[string]int{"Sponsored": 3})
+	doc.Generated = time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC)
+	doc.HitsSince = doc.Generated.Add(-seenRetention)
+
+	var buf bytes.Buffer
+	if err := writeRulesMarkdown(&buf, doc); err != nil {
+		t.Fatalf("writeRulesMarkdown failed: %v", err)
+	}
+
+	output := buf.String()
+	for _, expected := range []string{
+		"hit counts since 2024-04-01",
+		"## All feeds\n\n### Sponsored\n\nDrop sponsored posts\n",
+		"- Action: remove\n- Hits: 3\n- Labels: ads\n- title: `(?i)sponsored`\n",
+	} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("Expected output to contain %q, got:\n%s", expected, output)
+		}
+	}
+
+	buf.Reset()
+	if err := rulesHTMLTemplate.Execute(&buf, doc); err != nil {
+		t.Fatalf("HTML template failed: %v", err)
+	}
+	if !strings.Contains(buf.String(), "<h3>Sponsored</h3>") || !strings.Contains(buf.String(), "<li>Hits: 3</li>") {
+		t.Errorf("Unexpected HTML output:\n%s", buf.String())
+	}
+}
+
+func TestRuleHits(t *testing.T) {
+	state := &State{}
+	state.init()
+	now := time.Now()
+	state.RecordAction(AppliedAction{EntryID: 1, Rule: "Later", Action: "star", At: now})
+	state.RecordAction(AppliedAction{EntryID: 1, Rule: "Later", Action: "read", At: now})
+	state.RecordAction(AppliedAction{EntryID: 2, Rule: "Later", Action: "star", At: now})
+	state.RecordAction(AppliedAction{EntryID: 3, Rule: "Later", Action: "star", At: now.Add(-48 * time.Hour)})
+
+	hits := ruleHits(state, now.Add(-time.Hour))
+	if hits["Later"] != 2 {
+		t.Errorf("Expected 2 entries hit by rule 'Later', got %d", hits["Later"])
+	}
+}
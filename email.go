@@ -0,0 +1,200 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	miniflux "miniflux.app/v2/client"
+)
+
+// defaultSMTPPort applies when the email block omits a port
+const defaultSMTPPort = 587
+
+// Default templates for emails about single entries and run digests
+const (
+	defaultEmailSubject = `[miniflux-jobs] {{.Rule}}: {{.Title}}`
+	defaultEmailBody    = `{{.Title}}
+{{.URL}}
+
+Feed: {{.Feed}}
+Rule: {{.Rule}}
+`
+	defaultDigestSubject = `[miniflux-jobs] {{len .Changes}} entries changed`
+	defaultDigestBody    = `{{range .Changes}}{{.Action}}: [{{.FeedTitle}}] {{.Title}}
+  {{.URL}} (rule '{{.Rule}}')
+{{end}}`
+)
+
+// EmailConfig configures SMTP delivery for email actions and run digests
+type EmailConfig struct {
+	Host     string     `yaml:"host"`
+	Port     int        `yaml:"port"` // default 587
+	Username string     `yaml:"username"`
+	Password string     `yaml:"password"`
+	From     string     `yaml:"from"`
+	To       StringList `yaml:"to"`
+
+	Subject string `yaml:"subject"` // Go template for email actions (fields as in notify templates)
+	Body    string `yaml:"body"`    // Go template for email actions
+
+	Digest        bool   `yaml:"digest"`         // email a summary of the entries changed by each run
+	DigestSubject string `yaml:"digest_subject"` // Go template over .Changes
+	DigestBody    string `yaml:"digest_body"`    // Go template over .Changes
+}
+
+// ChangeItem is an entry changed by an action during a run
+type ChangeItem struct {
+	Rule      string
+	Action    string
+	EntryID   int64
+	FeedTitle string
+	Title     string
+	URL       string
+}
+
+// emailDigest is the data available to digest templates
+type emailDigest struct {
+	Changes []ChangeItem
+}
+
+// mailer delivers a message with the given subject and plain-text body
+type mailer func(cfg EmailConfig, subject, body string) error
+
+// validate checks the SMTP settings and templates
+func (e EmailConfig) validate() error {
+	if e.Host == "" || e.From == "" || len(e.To) == 0 {
+		return fmt.Errorf("email requires host, from and to")
+	}
+	if e.Port < 0 {
+		return fmt.Errorf("email port must be >= 0")
+	}
+	for _, text := range []string{e.Subject, e.Body, e.DigestSubject, e.DigestBody} {
+		if _, err := parseEmailTemplate(text, ""); err != nil {
+			return fmt.Errorf("invalid email template: %w", err)
+		}
+	}
+	return nil
+}
+
+// parseEmailTemplate compiles text, falling back to the default if empty
+func parseEmailTemplate(text, fallback string) (*template.Template, error) {
+	if text == "" {
+		text = fallback
+	}
+	return template.New("email").Funcs(notifyFuncs).Option("missingkey=error").Parse(text)
+}
+
+// renderEmail executes the subject and body templates
+func renderEmail(subjectText, bodyText, subjectFallback, bodyFallback string, data any) (string, string, error) {
+	var rendered [2]bytes.Buffer
+	texts := [2][2]string{{subjectText, subjectFallback}, {bodyText, bodyFallback}}
+	for i, text := range texts {
+		tmpl, err := parseEmailTemplate(text[0], text[1])
+		if err != nil {
+			return "", "", fmt.Errorf("invalid email template: %w", err)
+		}
+		if err := tmpl.Execute(&rendered[i], data); err != nil {
+			return "", "", fmt.Errorf("failed to render email template: %w", err)
+		}
+	}
+	return strings.TrimSpace(rendered[0].String()), rendered[1].String(), nil
+}
+
+// sendSMTP delivers a plain-text email through the configured SMTP server
+func sendSMTP(cfg EmailConfig, subject, body string) error {
+	port := cfg.Port
+	if port == 0 {
+		port = defaultSMTPPort
+	}
+
+	var auth smtp.Auth
+	if cfg.Username != "" {
+		auth = smtp.PlainAuth("", cfg.Username, cfg.Password, cfg.Host)
+	}
+
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", cfg.From)
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(cfg.To, ", "))
+	fmt.Fprintf(&msg, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(&msg, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/plain; charset=utf-8\r\n\r\n")
+	msg.WriteString(strings.ReplaceAll(body, "\n", "\r\n"))
+
+	addr := net.JoinHostPort(cfg.Host, strconv.Itoa(port))
+	if err := smtp.SendMail(addr, auth, cfg.From, cfg.To, msg.Bytes()); err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+	return nil
+}
+
+// applyEmail emails an entry matched by rule, once per entry and rule
+func (p *Processor) applyEmail(entry *miniflux.Entry, rule *Rule, stats *ProcessStats) bool {
+	cfg := p.options.Email
+
+	data := NotifyData{
+		Rule:        rule.Name,
+		EntryID:     entry.ID,
+		Title:       entry.Title,
+		URL:         entry.URL,
+		Author:      entry.Author,
+		PublishedAt: entry.Date,
+	}
+	if entry.Feed != nil {
+		data.Feed = entry.Feed.Title
+	}
+
+	subject, body, err := renderEmail(cfg.Subject, cfg.Body, defaultEmailSubject, defaultEmailBody, data)
+	if err != nil {
+		p.entryLogger(entry, rule).Error("Failed to render email", "action", "email", "error", err)
+		stats.Errors++
+		return false
+	}
+
+	if !p.shouldNotify("email", entry, rule.Name) {
+		return true
+	}
+
+	if p.dryRun {
+		p.entryLogger(entry, rule).Info("Dry run: would email entry", "action", "email", "to", strings.Join(cfg.To, ", "))
+		p.audit(entry, "email", rule.Name)
+		return true
+	}
+
+	if err := p.mailer(cfg, subject, body); err != nil {
+		p.entryLogger(entry, rule).Error("Failed to email entry", "action", "email", "error", err)
+		stats.Errors++
+		return false
+	}
+
+	stats.Notified++
+	p.entryLogger(entry, rule).Info("Emailed entry", "action", "email", "to", strings.Join(cfg.To, ", "))
+	p.audit(entry, "email", rule.Name)
+	return true
+}
+
+// sendEmailDigest emails a summary of the entries changed during the run
+func (p *Processor) sendEmailDigest(stats *ProcessStats) {
+	cfg := p.options.Email
+	if !cfg.Digest || p.dryRun || len(stats.Changes) == 0 {
+		return
+	}
+
+	subject, body, err := renderEmail(cfg.DigestSubject, cfg.DigestBody, defaultDigestSubject, defaultDigestBody, emailDigest{Changes: stats.Changes})
+	if err != nil {
+		p.logger.Error("Failed to render email digest", "error", err)
+		return
+	}
+	if err := p.mailer(cfg, subject, body); err != nil {
+		p.logger.Error("Failed to send email digest", "error", err)
+		return
+	}
+	p.logger.Info("Emailed digest of changed entries", "entries", len(stats.Changes))
+}
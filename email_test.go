@@ -0,0 +1,84 @@
+package main
+
+import (
+	"log/slog"
+	"os"
+	"strings"
+	"testing"
+
+	miniflux "miniflux.app/v2/client"
+)
+
+type sentEmail struct {
+	subject string
+	body    string
+}
+
+func TestProcessorEmailActionAndDigest(t *testing.T) {
+	mockClient := &MockClient{
+		entries: []*miniflux.Entry{
+			{ID: 1, Title: "Security advisory", URL: "https://example.com/advisory", Feed: &miniflux.Feed{Title: "CERT"}},
+			{ID: 2, Title: "Sponsored post", URL: "https://example.com/ad", Feed: &miniflux.Feed{Title: "Blog"}},
+		},
+	}
+
+	matcher, err := NewMatcher([]Rule{
+		{Name: "Advisories", Title: "advisory", Action: "email"},
+		{Name: "Ads", Title: "Sponsored", Action: "read"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create matcher: %v", err)
+	}
+
+	var sent []sentEmail
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	processor := NewProcessor(mockClient, matcher, logger, false)
+	processor.mailer = func(cfg EmailConfig, subject, body string) error {
+		sent = append(sent, sentEmail{subject: subject, body: body})
+		return nil
+	}
+	processor.SetOptions(ProcessorOptions{
+		Email: EmailConfig{
+			Host:    "smtp.example.com",
+			From:    "jobs@example.com",
+			To:      StringList{"me@example.com"},
+			Subject: "Alert: {{.Title}}",
+			Digest:  true,
+		},
+	})
+
+	stats, err := processor.Process()
+	if err != nil {
+		t.Fatalf("Process failed: %v", err)
+	}
+
+	if stats.Notified != 1 || len(sent) != 2 {
+		t.Fatalf("Expected an entry email and a digest, got %d emails", len(sent))
+	}
+	if sent[0].subject != "Alert: Security advisory" || !strings.Contains(sent[0].body, "https://example.com/advisory") {
+		t.Errorf("Unexpected entry email: %+v", sent[0])
+	}
+	if sent[1].subject != "[miniflux-jobs] 1 entries changed" || !strings.Contains(sent[1].body, "read: [Blog] Sponsored post") {
+		t.Errorf("Unexpected digest: %+v", sent[1])
+	}
+}
+
+func TestValidateEmail(t *testing.T) {
+	config := &Config{
+		MinifluxURL: "https://miniflux.example.com",
+		Rules:       []Rule{{Name: "Advisories", Action: "email"}},
+	}
+	if err := config.Validate(); err == nil {
+		t.Error("Expected error for email action without email block")
+	}
+
+	config.Email = EmailConfig{Host: "smtp.example.com", From: "jobs@example.com"}
+	if err := config.Validate(); err == nil {
+		t.Error("Expected error for email without recipients")
+	}
+
+	config.Email.To = StringList{"me@example.com"}
+	if err := config.Validate(); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,246 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	// maxEmbeddingInput limits how much entry text is sent to the embedding model
+	maxEmbeddingInput = 8000
+
+	// defaultSimilarityThreshold applies when similar_to omits a threshold
+	defaultSimilarityThreshold = 0.8
+
+	// defaultEmbeddingCacheTTL applies when embeddings omit a cache_ttl
+	defaultEmbeddingCacheTTL = 30 * 24 * time.Hour
+)
+
+// cachedEmbedding is a vector in the on-disk cache
+type cachedEmbedding struct {
+	Vector []float64 `json:"vector"`
+	UsedAt time.Time `json:"used_at"` // last time the vector was needed, for pruning
+}
+
+// Embedder computes text embeddings through an OpenAI-compatible API
+// Local models served by Ollama or llama.cpp expose the same endpoint
+type Embedder struct {
+	endpoint  string
+	model     string
+	apiKey    string
+	cachePath string
+	cacheTTL  time.Duration
+	client    *http.Client
+	logger    *slog.Logger
+	now       func() time.Time
+
+	mu    sync.Mutex
+	cache map[string]cachedEmbedding
+	dirty bool
+}
+
+// NewEmbedder creates an Embedder and loads its on-disk vector cache
+func NewEmbedder(cfg EmbeddingsConfig, logger *slog.Logger) (*Embedder, error) {
+	e := &Embedder{
+		endpoint:  cfg.Endpoint,
+		model:     cfg.Model,
+		apiKey:    cfg.APIKey,
+		cachePath: cfg.CacheFile,
+		cacheTTL:  cfg.CacheTTL,
+		client:    &http.Client{Timeout: 30 * time.Second},
+		logger:    logger,
+		now:       time.Now,
+		cache:     make(map[string]cachedEmbedding),
+	}
+	if e.cacheTTL == 0 {
+		e.cacheTTL = defaultEmbeddingCacheTTL
+	}
+
+	if e.cachePath == "" {
+		return e, nil
+	}
+
+	data, err := os.ReadFile(e.cachePath)
+	if errors.Is(err, os.ErrNotExist) {
+		return e, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read embedding cache: %w", err)
+	}
+	if err := json.Unmarshal(data, &e.cache); err != nil {
+		return nil, fmt.Errorf("failed to parse embedding cache: %w", err)
+	}
+
+	return e, nil
+}
+
+// Embed returns the embedding vector for text, using the cache when possible
+func (e *Embedder) Embed(text string) ([]float64, error) {
+	if len(text) > maxEmbeddingInput {
+		// Cut on a rune boundary so no multi-byte character is split
+		cut := maxEmbeddingInput
+		for cut > 0 && !utf8.RuneStart(text[cut]) {
+			cut--
+		}
+		text = text[:cut]
+	}
+
+	sum := sha256.Sum256([]byte(e.model + "\x00" + text))
+	key := hex.EncodeToString(sum[:])
+
+	e.mu.Lock()
+	cached, ok := e.cache[key]
+	if ok {
+		cached.UsedAt = e.now()
+		e.cache[key] = cached
+		e.dirty = true
+	}
+	e.mu.Unlock()
+	if ok {
+		return cached.Vector, nil
+	}
+
+	vector, err := e.request(text)
+	if err != nil {
+		return nil, err
+	}
+
+	e.mu.Lock()
+	e.cache[key] = cachedEmbedding{Vector: vector, UsedAt: e.now()}
+	e.dirty = true
+	e.mu.Unlock()
+
+	return vector, nil
+}
+
+// request calls the embeddings endpoint for a single input
+func (e *Embedder) request(text string) ([]float64, error) {
+	body, err := json.Marshal(map[string]string{"model": e.model, "input": text})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, e.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if e.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+e.apiKey)
+	}
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("embedding request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("embedding request failed: status %d", resp.StatusCode)
+	}
+
+	var result struct {
+		Data []struct {
+			Embedding []float64 `json:"embedding"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode embedding response: %w", err)
+	}
+	if len(result.Data) == 0 || len(result.Data[0].Embedding) == 0 {
+		return nil, fmt.Errorf("embedding response contained no vector")
+	}
+
+	return result.Data[0].Embedding, nil
+}
+
+// MaxSimilarity returns the highest cosine similarity between text and the examples
+func (e *Embedder) MaxSimilarity(text string, examples []string) (float64, error) {
+	vector, err := e.Embed(text)
+	if err != nil {
+		return 0, err
+	}
+
+	best := -1.0
+	for _, example := range examples {
+		exampleVector, err := e.Embed(example)
+		if err != nil {
+			return 0, err
+		}
+		best = max(best, cosineSimilarity(vector, exampleVector))
+	}
+
+	return best, nil
+}
+
+// Similar reports whether text is at least threshold-similar to any example
+// Errors are logged and treated as no match
+func (e *Embedder) Similar(text string, examples []string, threshold float64) bool {
+	similarity, err := e.MaxSimilarity(text, examples)
+	if err != nil {
+		e.logger.Error("Embedding similarity check failed", "error", err)
+		return false
+	}
+	return similarity >= threshold
+}
+
+// SaveCache writes the cache file, dropping vectors unused for longer than the cache TTL
+func (e *Embedder) SaveCache() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.cachePath == "" {
+		return nil
+	}
+	cutoff := e.now().Add(-e.cacheTTL)
+	for key, cached := range e.cache {
+		if cached.UsedAt.Before(cutoff) {
+			delete(e.cache, key)
+			e.dirty = true
+		}
+	}
+	if !e.dirty {
+		return nil
+	}
+
+	data, err := json.Marshal(e.cache)
+	if err != nil {
+		return fmt.Errorf("failed to encode embedding cache: %w", err)
+	}
+	if err := os.WriteFile(e.cachePath, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write embedding cache: %w", err)
+	}
+
+	e.dirty = false
+	return nil
+}
+
+// cosineSimilarity returns the cosine of the angle between two vectors
+func cosineSimilarity(a, b []float64) float64 {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0
+	}
+
+	var dot, normA, normB float64
+	for i := range a {
+		dot += a[i] * b[i]
+		normA += a[i] * a[i]
+		normB += b[i] * b[i]
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
@@ -0,0 +1,169 @@
+package main
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	miniflux "miniflux.app/v2/client"
+)
+
+// fakeEmbeddingServer embeds text as a vector of keyword counts
+func fakeEmbeddingServer(t *testing.T, requests *int) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*requests++
+		var req struct {
+			Input string `json:"input"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("Failed to decode request: %v", err)
+		}
+		text := strings.ToLower(req.Input)
+		vector := []float64{
+			float64(strings.Count(text, "crypto")),
+			float64(strings.Count(text, "garden")),
+			0.1,
+		}
+		json.NewEncoder(w).Encode(map[string]any{
+			"data": []map[string]any{{"embedding": vector}},
+		})
+	}))
+}
+
+func TestMatcherSimilarTo(t *testing.T) {
+	requests := 0
+	server := fakeEmbeddingServer(t, &requests)
+	defer server.Close()
+
+	cachePath := filepath.Join(t.TempDir(), "vectors.json")
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	embedder, err := NewEmbedder(EmbeddingsConfig{Endpoint: server.URL, Model: "test", CacheFile: cachePath}, logger)
+	if err != nil {
+		t.Fatalf("Failed to create embedder: %v", err)
+	}
+
+	matcher, err := NewMatcher([]Rule{
+		{
+			Name:      "Boring crypto",
+			SimilarTo: &SimilarityCondition{Examples: []string{"Crypto prices crypto news"}, Threshold: 0.9},
+			Action:    "read",
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create matcher: %v", err)
+	}
+	matcher.SetEmbedder(embedder)
+
+	if !matcher.Match(&miniflux.Entry{ID: 1, Title: "Crypto", Content: "<p>More crypto</p>"}).Matched {
+		t.Error("Expected similar entry to match")
+	}
+	if matcher.Match(&miniflux.Entry{ID: 2, Title: "Garden", Content: "<p>Garden tips</p>"}).Matched {
+		t.Error("Expected dissimilar entry not to match")
+	}
+
+	if err := matcher.SaveCaches(); err != nil {
+		t.Fatalf("Failed to save caches: %v", err)
+	}
+
+	// A fresh embedder serves known texts from the on-disk cache
+	before := requests
+	embedder, err = NewEmbedder(EmbeddingsConfig{Endpoint: server.URL, Model: "test", CacheFile: cachePath}, logger)
+	if err != nil {
+		t.Fatalf("Failed to create embedder: %v", err)
+	}
+	matcher.SetEmbedder(embedder)
+	matcher.Match(&miniflux.Entry{ID: 1, Title: "Crypto", Content: "<p>More crypto</p>"})
+	if requests != before {
+		t.Errorf("Expected cached vectors to be reused, got %d new requests", requests-before)
+	}
+}
+
+func TestCosineSimilarity(t *testing.T) {
+	if sim := cosineSimilarity([]float64{1, 0}, []float64{2, 0}); sim < 0.999 {
+		t.Errorf("Expected parallel vectors to have similarity 1, got %f", sim)
+	}
+	if sim := cosineSimilarity([]float64{1, 0}, []float64{0, 1}); sim != 0 {
+		t.Errorf("Expected orthogonal vectors to have similarity 0, got %f", sim)
+	}
+	if sim := cosineSimilarity([]float64{1}, []float64{1, 2}); sim != 0 {
+		t.Errorf("Expected mismatched vectors to have similarity 0, got %f", sim)
+	}
+}
+
+func TestEmbedderTruncatesOnRuneBoundary(t *testing.T) {
+	var input string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Input string `json:"input"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		input = req.Input
+		json.NewEncoder(w).Encode(map[string]any{
+			"data": []map[string]any{{"embedding": []float64{1}}},
+		})
+	}))
+	defer server.Close()
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	embedder, err := NewEmbedder(EmbeddingsConfig{Endpoint: server.URL, Model: "test"}, logger)
+	if err != nil {
+		t.Fatalf("Failed to create embedder: %v", err)
+	}
+
+	// Two-byte characters after an odd prefix put the byte limit mid-character
+	if _, err := embedder.Embed("x" + strings.Repeat("é", maxEmbeddingInput)); err != nil {
+		t.Fatalf("Embed failed: %v", err)
+	}
+	if !utf8.ValidString(input) || len(input) != maxEmbeddingInput-1 {
+		t.Errorf("Expected %d bytes of valid UTF-8, got %d bytes (valid: %v)", maxEmbeddingInput-1, len(input), utf8.ValidString(input))
+	}
+}
+
+func TestEmbedderCachePrune(t *testing.T) {
+	requests := 0
+	server := fakeEmbeddingServer(t, &requests)
+	defer server.Close()
+
+	cachePath := filepath.Join(t.TempDir(), "vectors.json")
+	cfg := EmbeddingsConfig{Endpoint: server.URL, Model: "test", CacheFile: cachePath, CacheTTL: 24 * time.Hour}
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	embedder, err := NewEmbedder(cfg, logger)
+	if err != nil {
+		t.Fatalf("Failed to create embedder: %v", err)
+	}
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	embedder.now = func() time.Time { return now }
+
+	for _, text := range []string{"crypto", "garden"} {
+		if _, err := embedder.Embed(text); err != nil {
+			t.Fatalf("Embed failed: %v", err)
+		}
+	}
+
+	// Only the vector still in use within the TTL survives the next save
+	now = now.Add(36 * time.Hour)
+	if _, err := embedder.Embed("garden"); err != nil {
+		t.Fatalf("Embed failed: %v", err)
+	}
+	if err := embedder.SaveCache(); err != nil {
+		t.Fatalf("Failed to save cache: %v", err)
+	}
+
+	embedder, err = NewEmbedder(cfg, logger)
+	if err != nil {
+		t.Fatalf("Failed to create embedder: %v", err)
+	}
+	if len(embedder.cache) != 1 {
+		t.Errorf("Expected the unused vector to be pruned, got %d cached", len(embedder.cache))
+	}
+	if requests != 2 {
+		t.Errorf("Expected the used vector to come from the cache, got %d requests", requests)
+	}
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"time"
+
+	miniflux "miniflux.app/v2/client"
+)
+
+// fetch_since values
+const (
+	fetchSincePublished = "published"
+	fetchSinceChanged   = "changed"
+)
+
+// fetchSinceOverlap widens fetch_since windows to absorb clock skew with the Miniflux server
+const fetchSinceOverlap = 5 * time.Minute
+
+// reevaluates reports whether a rule's outcome for an entry can change after it was
+// first evaluated, which rules out skip_evaluated and fetch_since
+func (r *Rule) reevaluates() bool {
+	return r.Delay > 0 || r.OlderThan > 0 || r.ChangedSinceLastSeen || r.DeadLinkCheck
+}
+
+// incremental reports whether runs fetch only entries added or changed since earlier runs
+// Runs scoped by -feed or -category evaluate every entry of their feeds and, having left
+// out the other feeds, record nothing for later runs.
+func (p *Processor) incremental() bool {
+	return (p.options.SkipEvaluated || p.options.FetchSince != "") && !p.adHocScoped()
+}
+
+// restrictToUnevaluated narrows filter to entries newer than those evaluated by previous runs
+// A config change since the last run requires every entry to be evaluated again
+func (p *Processor) restrictToUnevaluated(filter *miniflux.Filter) {
+	if !p.incremental() || p.state == nil || p.state.EvaluatedConfigHash == "" {
+		return
+	}
+	if p.state.EvaluatedConfigHash != p.options.ConfigHash {
+		p.logger.Info("Config changed since the last run, evaluating all entries")
+		return
+	}
+
+	if p.options.SkipEvaluated {
+		filter.AfterEntryID = p.state.LastEvaluatedID
+	}
+	if !p.state.LastEvaluatedAt.IsZero() {
+		since := p.state.LastEvaluatedAt.Add(-fetchSinceOverlap).Unix()
+		switch p.options.FetchSince {
+		case fetchSincePublished:
+			filter.PublishedAfter = since
+		case fetchSinceChanged:
+			filter.ChangedAfter = since
+		}
+	}
+}
+
+// recordEvaluated remembers the newest entry evaluated by a completed run and when it started
+// Dry runs record nothing so the real run still evaluates the same entries
+func (p *Processor) recordEvaluated(newest int64, started time.Time) {
+	if !p.incremental() || p.state == nil || p.dryRun {
+		return
+	}
+	p.state.LastEvaluatedID = max(p.state.LastEvaluatedID, newest)
+	p.state.LastEvaluatedAt = started
+	p.state.EvaluatedConfigHash = p.options.ConfigHash
+}
+
+// newestEntryID returns the highest ID in a page of entries
+func newestEntryID(page []*miniflux.Entry) int64 {
+	var newest int64
+	for _, entry := range page {
+		newest = max(newest, entry.ID)
+	}
+	return newest
+}
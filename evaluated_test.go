@@ -0,0 +1,142 @@
+package main
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	miniflux "miniflux.app/v2/client"
+)
+
+func TestProcessorSkipEvaluated(t *testing.T) {
+	mockClient := &MockClient{
+		entries: []*miniflux.Entry{
+			{ID: 1, Title: "Old news", Status: miniflux.EntryStatusUnread},
+			{ID: 2, Title: "Old news", Status: miniflux.EntryStatusUnread},
+		},
+	}
+
+	state, err := LoadState(filepath.Join(t.TempDir(), "state.json"))
+	if err != nil {
+		t.Fatalf("Failed to load state: %v", err)
+	}
+	matcher, err := NewMatcher([]Rule{
+		{Name: "Star releases", Title: "Release", Action: "star"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create matcher: %v", err)
+	}
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	processor := NewProcessor(mockClient, matcher, logger, false)
+	processor.SetState(state)
+	processor.SetOptions(ProcessorOptions{SkipEvaluated: true, ConfigHash: "abc"})
+
+	stats, err := processor.Process()
+	if err != nil {
+		t.Fatalf("Process failed: %v", err)
+	}
+	if stats.TotalEntries != 2 || state.LastEvaluatedID != 2 {
+		t.Fatalf("Expected the first run to evaluate 2 entries up to ID 2, got %d up to %d", stats.TotalEntries, state.LastEvaluatedID)
+	}
+
+	mockClient.entries = append(mockClient.entries, &miniflux.Entry{ID: 3, Title: "Release 1.0", Status: miniflux.EntryStatusUnread})
+	stats, err = processor.Process()
+	if err != nil {
+		t.Fatalf("Process failed: %v", err)
+	}
+	if stats.TotalEntries != 1 || stats.Starred != 1 {
+		t.Errorf("Expected only the new entry to be evaluated and starred, got %+v", stats)
+	}
+	if mockClient.lastFilter.AfterEntryID != 2 || state.LastEvaluatedID != 3 {
+		t.Errorf("Expected to fetch after entry 2 and record entry 3, got %d and %d", mockClient.lastFilter.AfterEntryID, state.LastEvaluatedID)
+	}
+
+	// A changed config evaluates the whole backlog again
+	processor.SetOptions(ProcessorOptions{SkipEvaluated: true, ConfigHash: "def"})
+	stats, err = processor.Process()
+	if err != nil {
+		t.Fatalf("Process failed: %v", err)
+	}
+	if stats.TotalEntries != 3 {
+		t.Errorf("Expected all 3 entries evaluated after a config change, got %d", stats.TotalEntries)
+	}
+}
+
+func TestLoadConfigSkipEvaluated(t *testing.T) {
+	tests := []struct {
+		content string
+		errMsg  string
+	}{
+		{"state_file: state.json\nskip_evaluated: true\nrules:\n  - {name: Ads, title: Ad, action: read}\n", ""},
+		{"skip_evaluated: true\n", "requires state_file or state_backend"},
+		{"state_file: state.json\nskip_evaluated: true\nrules:\n  - {name: Stale, title: x, action: read, older_than: 72h}\n", "cannot be used with skip_evaluated"},
+		{"state_file: state.json\nfetch_since: changed\n", ""},
+		{"state_file: state.json\nfetch_since: yesterday\n", "fetch_since must be"},
+		{"fetch_since: published\n", "requires state_file or state_backend"},
+	}
+
+	for _, tt := range tests {
+		configPath := filepath.Join(t.TempDir(), "rules.yaml")
+		content := "miniflux_url: https://miniflux.example.com\n" + tt.content
+		if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write config: %v", err)
+		}
+
+		_, err := LoadConfig(configPath)
+		if tt.errMsg == "" {
+			if err != nil {
+				t.Errorf("%q: unexpected error: %v", tt.content, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), tt.errMsg) {
+			t.Errorf("%q: expected error containing %q, got %v", tt.content, tt.errMsg, err)
+		}
+	}
+}
+
+func TestProcessorFetchSince(t *testing.T) {
+	mockClient := &MockClient{
+		entries: []*miniflux.Entry{{ID: 1, Title: "News", Status: miniflux.EntryStatusUnread}},
+	}
+
+	state, err := LoadState(filepath.Join(t.TempDir(), "state.json"))
+	if err != nil {
+		t.Fatalf("Failed to load state: %v", err)
+	}
+	matcher, err := NewMatcher([]Rule{
+		{Name: "Star releases", Title: "Release", Action: "star"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create matcher: %v", err)
+	}
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	processor := NewProcessor(mockClient, matcher, logger, false)
+	processor.SetState(state)
+	processor.SetOptions(ProcessorOptions{FetchSince: fetchSinceChanged, ConfigHash: "abc"})
+
+	if _, err := processor.Process(); err != nil {
+		t.Fatalf("Process failed: %v", err)
+	}
+	if mockClient.lastFilter.ChangedAfter != 0 {
+		t.Errorf("Expected the first run to fetch everything, got changed_after %d", mockClient.lastFilter.ChangedAfter)
+	}
+	started := state.LastEvaluatedAt
+	if started.IsZero() {
+		t.Fatal("Expected the run start to be recorded")
+	}
+
+	if _, err := processor.Process(); err != nil {
+		t.Fatalf("Process failed: %v", err)
+	}
+	if expected := started.Add(-fetchSinceOverlap).Unix(); mockClient.lastFilter.ChangedAfter != expected {
+		t.Errorf("Expected changed_after %d, got %d", expected, mockClient.lastFilter.ChangedAfter)
+	}
+	if mockClient.lastFilter.AfterEntryID != 0 || mockClient.lastFilter.PublishedAfter != 0 {
+		t.Errorf("Expected only changed_after to be set, got %+v", mockClient.lastFilter)
+	}
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"flag"
+	"io"
+	"maps"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+	miniflux "miniflux.app/v2/client"
+)
+
+// canonicalURL normalizes an entry URL so tracking parameters and fragments do not defeat exceptions
+func canonicalURL(raw string) string {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil || u.Host == "" {
+		return raw
+	}
+
+	if scheme := strings.ToLower(u.Scheme); scheme == "http" || scheme == "https" {
+		u.Scheme = "https"
+	}
+	u.Host = strings.TrimPrefix(strings.ToLower(u.Host), "www.")
+	u.Fragment = ""
+	u.Path = strings.TrimSuffix(u.Path, "/")
+
+	query := u.Query()
+	for key := range query {
+		if strings.HasPrefix(strings.ToLower(key), "utm_") {
+			query.Del(key)
+		}
+	}
+	u.RawQuery = query.Encode()
+
+	return u.String()
+}
+
+// detectOverride turns a manual reversal of a rule's action into an exception for that rule
+// An entry is overridden when the user restored it after a read or remove, or starred it
+func (p *Processor) detectOverride(entry *miniflux.Entry) {
+	if p.state == nil || p.dryRun {
+		return
+	}
+
+	last, ok := p.state.LastAction(entry.ID)
+	if !ok || (last.Action != "read" && last.Action != "remove") {
+		return
+	}
+	restored := entry.Status == miniflux.EntryStatusUnread
+	if !restored && !entry.Starred {
+		return
+	}
+
+	p.logger.Info("Entry was manually overridden, adding exception", "rule", last.Rule, "entry_id", entry.ID, "action", last.Action)
+	p.state.AddException(last.Rule, RuleException{
+		URL:     canonicalURL(entry.URL),
+		Author:  entry.Author,
+		EntryID: entry.ID,
+		Added:   p.now(),
+	})
+	p.state.ForgetActions(entry.ID, "", time.Time{})
+}
+
+// excepted reports whether the rule is configured or has learned to skip the entry
+func (m *Matcher) excepted(entry *miniflux.Entry, rule *Rule) bool {
+	if len(rule.ExceptURLs) == 0 && len(rule.ExceptAuthors) == 0 && m.state == nil {
+		return false
+	}
+
+	entryURL := canonicalURL(entry.URL)
+	for _, except := range rule.ExceptURLs {
+		if canonicalURL(except) == entryURL {
+			return true
+		}
+	}
+	if entry.Author != "" && slices.ContainsFunc(rule.ExceptAuthors, func(author string) bool {
+		return strings.EqualFold(author, entry.Author)
+	}) {
+		return true
+	}
+
+	return m.state != nil && m.state.IsException(rule.Name, entryURL, entry.Author)
+}
+
+// exportedExceptions is the YAML shape of learned exceptions, ready to merge into rules
+type exportedExceptions struct {
+	Name          string          `yaml:"name"`
+	ExceptURLs    []string        `yaml:"except_urls,omitempty"`
+	ExceptAuthors []string        `yaml:"except_authors,omitempty"`
+	Learned       []RuleException `yaml:"learned"`
+}
+
+// writeExceptions exports the learned exceptions as YAML rule fragments
+func writeExceptions(w io.Writer, state *State) error {
+	var rules []exportedExceptions
+	for _, name := range slices.Sorted(maps.Keys(state.Exceptions)) {
+		exported := exportedExceptions{Name: name, Learned: state.Exceptions[name]}
+		for _, exception := range exported.Learned {
+			if exception.URL != "" && !slices.Contains(exported.ExceptURLs, exception.URL) {
+				exported.ExceptURLs = append(exported.ExceptURLs, exception.URL)
+			}
+			if exception.Author != "" && !slices.Contains(exported.ExceptAuthors, exception.Author) {
+				exported.ExceptAuthors = append(exported.ExceptAuthors, exception.Author)
+			}
+		}
+		rules = append(rules, exported)
+	}
+
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(map[string][]exportedExceptions{"rules": rules}); err != nil {
+		return err
+	}
+	return encoder.Close()
+}
+
+// exceptionsCommand runs the exceptions subcommand, printing learned exceptions as YAML
+func exceptionsCommand(args []string) {
+	flags := flag.NewFlagSet("exceptions", flag.ExitOnError)
+	configPath := flags.String("config", defaultConfigPath(), "Path to the rules configuration file")
+	flags.Parse(args)
+
+	logger := commandLogger(os.Stderr)
+
+	config, err := LoadConfig(*configPath)
+	if err != nil {
+		fatal(logger, "Failed to load config", "error", err)
+	}
+	if !config.HasState() {
+		fatal(logger, "Exceptions require state_file or state_backend to be set")
+	}
+
+	store, err := OpenStateStore(config)
+	if err != nil {
+		fatal(logger, "Failed to open state store", "error", err)
+	}
+	defer store.Close()
+	state, err := LoadStateFrom(store)
+	if err != nil {
+		fatal(logger, "Failed to load state", "error", err)
+	}
+
+	if err := writeExceptions(os.Stdout, state); err != nil {
+		fatal(logger, "Failed to export exceptions", "error", err)
+	}
+}
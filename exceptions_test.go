@@ -0,0 +1,129 @@
+package main
+
+import (
+	"bytes"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	miniflux "miniflux.app/v2/client"
+)
+
+func TestProcessorLearnsExceptionFromOverride(t *testing.T) {
+	mockClient := &MockClient{
+		entries: []*miniflux.Entry{
+			{ID: 1, Title: "Sponsored: a tool I use", URL: "https://example.com/tool", Status: miniflux.EntryStatusUnread},
+		},
+	}
+
+	state, err := LoadState(filepath.Join(t.TempDir(), "state.json"))
+	if err != nil {
+		t.Fatalf("Failed to load state: %v", err)
+	}
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+
+	matcher, err := NewMatcher([]Rule{
+		{Name: "Remove sponsored", Title: "Sponsored", Action: "remove"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create matcher: %v", err)
+	}
+	processor := NewProcessor(mockClient, matcher, logger, false)
+	processor.SetState(state)
+	if _, err := processor.Process(); err != nil {
+		t.Fatalf("Process failed: %v", err)
+	}
+	if status := mockClient.entries[0].Status; status != miniflux.EntryStatusRemoved {
+		t.Fatalf("Expected entry 1 to be removed, got %s", status)
+	}
+
+	// The user restores the entry, and the same post shows up again with tracking parameters
+	mockClient.entries[0].Status = miniflux.EntryStatusUnread
+	mockClient.entries = append(mockClient.entries, &miniflux.Entry{
+		ID: 2, Title: "Sponsored: a tool I use", URL: "https://www.example.com/tool/?utm_source=rss", Status: miniflux.EntryStatusUnread,
+	})
+	mockClient.updatedIDs = nil
+
+	stats, err := processor.Process()
+	if err != nil {
+		t.Fatalf("Process failed: %v", err)
+	}
+	if stats.Removed != 0 {
+		t.Errorf("Expected no entries removed after the override, got %d", stats.Removed)
+	}
+	if !state.IsException("Remove sponsored", "https://example.com/tool", "") {
+		t.Errorf("Expected an exception for the overridden URL, got %+v", state.Exceptions)
+	}
+	if _, ok := state.LastAction(1); ok {
+		t.Error("Expected the overridden action to be forgotten")
+	}
+}
+
+func TestMatcherConfiguredExceptions(t *testing.T) {
+	matcher, err := NewMatcher([]Rule{
+		{
+			Name:          "Remove sponsored",
+			Title:         "Sponsored",
+			Action:        "remove",
+			ExceptURLs:    StringList{"https://example.com/keep"},
+			ExceptAuthors: StringList{"Jane Doe"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create matcher: %v", err)
+	}
+
+	tests := []struct {
+		entry    *miniflux.Entry
+		expected bool
+	}{
+		{&miniflux.Entry{Title: "Sponsored", URL: "https://example.com/other"}, true},
+		{&miniflux.Entry{Title: "Sponsored", URL: "http://www.example.com/keep#top"}, false},
+		{&miniflux.Entry{Title: "Sponsored", URL: "https://example.com/other", Author: "jane doe"}, false},
+	}
+	for _, tt := range tests {
+		if got := matcher.Match(tt.entry).Matched; got != tt.expected {
+			t.Errorf("Match(%s, %q) = %v, expected %v", tt.entry.URL, tt.entry.Author, got, tt.expected)
+		}
+	}
+}
+
+func TestCanonicalURL(t *testing.T) {
+	tests := map[string]string{
+		"https://www.Example.com/post/?utm_source=rss&id=3#comments": "https://example.com/post?id=3",
+		"https://example.com/post":                                   "https://example.com/post",
+		"not a url":                                                  "not a url",
+	}
+	for raw, expected := range tests {
+		if got := canonicalURL(raw); got != expected {
+			t.Errorf("canonicalURL(%q) = %q, expected %q", raw, got, expected)
+		}
+	}
+}
+
+func TestWriteExceptions(t *testing.T) {
+	state := &State{}
+	state.init()
+	state.AddException("Remove sponsored", RuleException{URL: "https://example.com/tool", Author: "Jane", EntryID: 1})
+	state.AddException("Remove sponsored", RuleException{URL: "https://example.com/other", Author: "Jane", EntryID: 2})
+
+	var buf bytes.Buffer
+	if err := writeExceptions(&buf, state); err != nil {
+		t.Fatalf("writeExceptions failed: %v", err)
+	}
+
+	output := buf.String()
+	for _, expected := range []string{
+		"- name: Remove sponsored",
+		"except_urls:",
+		"- https://example.com/tool",
+		"- https://example.com/other",
+		"except_authors:\n      - Jane\n",
+	} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("Expected output to contain %q, got:\n%s", expected, output)
+		}
+	}
+}
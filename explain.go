@@ -0,0 +1,263 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	miniflux "miniflux.app/v2/client"
+)
+
+// ConditionResult is the outcome of one rule condition for an entry
+type ConditionResult struct {
+	Condition string // config key of the condition, e.g. title
+	Passed    bool
+}
+
+// RuleExplanation reports how an entry fared against one rule
+type RuleExplanation struct {
+	Rule       *Rule
+	Matched    bool
+	Disabled   bool // turned off after repeated failures
+	Fallback   bool // category default, tried only when no rule matched
+	Conditions []ConditionResult
+}
+
+// Explain checks every condition of every rule against an entry, including the conditions
+// after one that failed, for showing why a rule does or does not match
+func (m *Matcher) Explain(entry *miniflux.Entry) []RuleExplanation {
+	explanations := make([]RuleExplanation, 0, len(m.compiledRules))
+	for i := range m.compiledRules {
+		cr := &m.compiledRules[i]
+		explanation := RuleExplanation{Rule: &cr.rule, Disabled: m.disabled[cr.rule.Name], Fallback: cr.fallback}
+		explanation.Matched = m.evalRule(entry, cr, func(condition string, passed bool) {
+			explanation.Conditions = append(explanation.Conditions, ConditionResult{condition, passed})
+		}, true)
+		explanations = append(explanations, explanation)
+	}
+	return explanations
+}
+
+// matchExcerptContext is the number of bytes of text shown around a highlighted match
+const matchExcerptContext = 40
+
+// MatchDetails describes which pattern of a matched rule caught which part of the entry,
+// one line per pattern condition with the matched text highlighted in [[ ]]
+func (m *Matcher) MatchDetails(entry *miniflux.Entry, rule *Rule) []string {
+	var cr *compiledRule
+	for i := range m.compiledRules {
+		if &m.compiledRules[i].rule == rule {
+			cr = &m.compiledRules[i]
+		}
+	}
+	if cr == nil {
+		return nil
+	}
+
+	feedTitle, categoryTitle := "", ""
+	if entry.Feed != nil {
+		feedTitle = entry.Feed.Title
+		if entry.Feed.Category != nil {
+			categoryTitle = entry.Feed.Category.Title
+		}
+	}
+	content := entry.Content
+	if cr.content != nil && cr.rule.FetchFullContent && m.fullContent != nil {
+		content = m.fullContent.Content(entry)
+	}
+
+	var details []string
+	for _, condition := range []struct {
+		field   string
+		pattern *regexp.Regexp
+		text    string
+	}{
+		{"feed", cr.feed, feedTitle},
+		{"category", cr.category, categoryTitle},
+		{"author", cr.author, entry.Author},
+		{"title", cr.title, entry.Title},
+		{"content", cr.content, content},
+		{"comments_url", cr.commentsURL, entry.CommentsURL},
+	} {
+		if condition.pattern == nil {
+			continue
+		}
+		text := condition.text
+		if !condition.pattern.MatchString(text) && cr.rule.Transliterate {
+			text = transliterate(text)
+		}
+		details = append(details, fmt.Sprintf("%s %s matched %q", condition.field, condition.pattern, highlightMatch(condition.pattern, text)))
+	}
+	return details
+}
+
+// highlightMatch returns the part of text around the pattern's first match, with the match in [[ ]]
+func highlightMatch(pattern *regexp.Regexp, text string) string {
+	loc := pattern.FindStringIndex(text)
+	if loc == nil {
+		return ""
+	}
+	start, end := max(loc[0]-matchExcerptContext, 0), min(loc[1]+matchExcerptContext, len(text))
+	for start > 0 && !utf8.RuneStart(text[start]) {
+		start--
+	}
+	for end < len(text) && !utf8.RuneStart(text[end]) {
+		end++
+	}
+
+	excerpt := text[start:loc[0]] + "[[" + text[loc[0]:loc[1]] + "]]" + text[loc[1]:end]
+	excerpt = strings.Join(strings.Fields(excerpt), " ")
+	if start > 0 {
+		excerpt = "..." + excerpt
+	}
+	if end < len(text) {
+		excerpt += "..."
+	}
+	return excerpt
+}
+
+// writeExplanation prints how each rule fares against the entry and the actions a run would take
+func writeExplanation(w io.Writer, matcher *Matcher, config *Config, entry *miniflux.Entry) error {
+	fmt.Fprintf(w, "Entry %d: %s\n", entry.ID, entry.Title)
+	for _, explanation := range matcher.Explain(entry) {
+		kind := "Rule"
+		if explanation.Fallback {
+			kind = "Category default"
+		}
+		result := "no match"
+		switch {
+		case explanation.Disabled:
+			result = "disabled"
+		case explanation.Matched:
+			result = "match"
+		}
+		fmt.Fprintf(w, "  %s '%s': %s\n", kind, explanation.Rule.Name, result)
+		for _, condition := range explanation.Conditions {
+			status := "fail"
+			if condition.Passed {
+				status = "pass"
+			}
+			fmt.Fprintf(w, "    %s  %s\n", status, condition.Condition)
+		}
+	}
+
+	results := matcher.MatchAll(entry)
+	if len(results) == 0 {
+		fmt.Fprintln(w, "  Action: none, no rule matches")
+		return nil
+	}
+	for _, result := range results {
+		steps, err := expandRule(result.Rule, config.Macros)
+		if err != nil {
+			return err
+		}
+		note := ""
+		if config.SkipStarred && entry.Starred && !result.Rule.IncludeStarred {
+			note = " (skipped: the entry is starred and skip_starred is set)"
+		}
+		fmt.Fprintf(w, "  Action: %s by rule '%s'%s\n", strings.Join(steps, ", "), result.Rule.Name, note)
+	}
+	return nil
+}
+
+// readEntries decodes one entry or a list of entries in the JSON format of the Miniflux API
+func readEntries(r io.Reader) ([]*miniflux.Entry, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	data = bytes.TrimSpace(data)
+	if bytes.HasPrefix(data, []byte("[")) {
+		var entries []*miniflux.Entry
+		if err := json.Unmarshal(data, &entries); err != nil {
+			return nil, err
+		}
+		return entries, nil
+	}
+	var entry miniflux.Entry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, err
+	}
+	return []*miniflux.Entry{&entry}, nil
+}
+
+// testCommand runs the test subcommand, showing how the rules fare against sample entries:
+// miniflux-jobs test --entry entry.json
+// miniflux-jobs test --entry-id 1234
+func testCommand(args []string) {
+	flags := flag.NewFlagSet("test", flag.ExitOnError)
+	configPath := flags.String("config", defaultConfigPath(), "Path to the rules configuration file")
+	entryFile := flags.String("entry", "-", "JSON file of an entry or a list of entries as returned by the Miniflux API, - for stdin")
+	entryID := flags.Int64("entry-id", 0, "ID of an entry fetched from Miniflux instead")
+	flags.Parse(args)
+
+	logger := commandLogger(os.Stderr)
+
+	config, err := LoadConfig(*configPath)
+	if err != nil {
+		fatal(logger, "Failed to load config", "error", err)
+	}
+
+	// Entries read from a file are matched offline unless a rule needs the API
+	var client MinifluxClient
+	apiKey, err := GetAPIKey()
+	switch {
+	case err == nil:
+		client = NewClientWrapper(config.MinifluxURL, apiKey, config.MaxResponseSize)
+	case *entryID != 0:
+		fatal(logger, "Failed to get API key", "error", err)
+	case usesFullContent(config.Rules):
+		fatal(logger, "Failed to get API key, needed by fetch_full_content rules", "error", err)
+	}
+
+	matcher, err := buildMatcher(config, client, logger)
+	if err != nil {
+		fatal(logger, "Failed to compile rules", "error", err)
+	}
+	// The state is read for seen and duplicate conditions, but never saved
+	if config.HasState() {
+		store, err := OpenStateStore(config)
+		if err != nil {
+			fatal(logger, "Failed to open state store", "error", err)
+		}
+		defer store.Close()
+		state, err := LoadStateFrom(store)
+		if err != nil {
+			fatal(logger, "Failed to load state", "error", err)
+		}
+		matcher.SetState(state)
+	}
+
+	var entries []*miniflux.Entry
+	switch {
+	case *entryID != 0:
+		entry, err := client.Entry(*entryID)
+		if err != nil {
+			fatal(logger, "Failed to fetch entry", "entry_id", *entryID, "error", err)
+		}
+		entries = []*miniflux.Entry{entry}
+	case *entryFile == "-":
+		entries, err = readEntries(os.Stdin)
+	default:
+		var file *os.File
+		if file, err = os.Open(*entryFile); err == nil {
+			entries, err = readEntries(file)
+			file.Close()
+		}
+	}
+	if err != nil {
+		fatal(logger, "Failed to read entries", "error", err)
+	}
+
+	for _, entry := range entries {
+		if err := writeExplanation(os.Stdout, matcher, config, entry); err != nil {
+			fatal(logger, "Failed to explain entry", "entry_id", entry.ID, "error", err)
+		}
+	}
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	miniflux "miniflux.app/v2/client"
+)
+
+func TestMatcherExplain(t *testing.T) {
+	matcher, err := NewMatcher([]Rule{
+		{Name: "Sponsored", Feed: "^Tech", Title: "(?i)sponsored", Author: "Ads", Action: "read"},
+		{Name: "Phones", Title: "phone", Action: "star", Continue: true},
+		{Name: "Clickbait", Title: "phone", TitleAllCaps: true, Action: "remove"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create matcher: %v", err)
+	}
+	entry := &miniflux.Entry{ID: 1, Title: "Sponsored: a phone", Author: "Jane", Feed: &miniflux.Feed{Title: "Tech Daily"}}
+
+	explanations := matcher.Explain(entry)
+	if len(explanations) != 3 {
+		t.Fatalf("Expected 3 explanations, got %d", len(explanations))
+	}
+	// Conditions after the failing author are still checked
+	expected := []ConditionResult{{"feed", true}, {"author", false}, {"title", true}}
+	sponsored := explanations[0]
+	if sponsored.Matched || len(sponsored.Conditions) != len(expected) {
+		t.Fatalf("Expected %v, got %+v", expected, sponsored)
+	}
+	for i, condition := range expected {
+		if sponsored.Conditions[i] != condition {
+			t.Errorf("Condition %d: expected %+v, got %+v", i, condition, sponsored.Conditions[i])
+		}
+	}
+	if !explanations[1].Matched || explanations[2].Matched {
+		t.Errorf("Expected only 'Phones' to match, got %+v", explanations)
+	}
+}
+
+func TestWriteExplanation(t *testing.T) {
+	config := &Config{
+		SkipStarred: true,
+		Macros:      map[string][]string{"later": {"read", "save"}},
+		Rules: []Rule{
+			{Name: "Phones", Title: "phone", Action: "later", Continue: true},
+			{Name: "Sponsored", Title: "(?i)sponsored", Action: "read"},
+		},
+	}
+	matcher, err := NewMatcher(config.Rules)
+	if err != nil {
+		t.Fatalf("Failed to create matcher: %v", err)
+	}
+
+	entries, err := readEntries(strings.NewReader(`[
+		{"id": 1, "title": "Sponsored: a phone", "starred": true},
+		{"id": 2, "title": "A review"}
+	]`))
+	if err != nil || len(entries) != 2 {
+		t.Fatalf("Failed to read entries: %v", err)
+	}
+
+	var out strings.Builder
+	for _, entry := range entries {
+		if err := writeExplanation(&out, matcher, config, entry); err != nil {
+			t.Fatalf("Failed to explain entry: %v", err)
+		}
+	}
+	printed := out.String()
+	for _, want := range []string{
+		"Entry 1: Sponsored: a phone\n  Rule 'Phones': match\n    pass  title\n",
+		"  Action: read, save by rule 'Phones' (skipped: the entry is starred and skip_starred is set)\n",
+		"  Action: read by rule 'Sponsored' (skipped",
+		"Entry 2: A review\n  Rule 'Phones': no match\n    fail  title\n",
+		"  Action: none, no rule matches\n",
+	} {
+		if !strings.Contains(printed, want) {
+			t.Errorf("Expected %q in the output:\n%s", want, printed)
+		}
+	}
+
+	entries, err = readEntries(strings.NewReader(`{"id": 3, "title": "Single"}`))
+	if err != nil || len(entries) != 1 || entries[0].ID != 3 {
+		t.Errorf("Expected a single entry to be read, got %v and %v", entries, err)
+	}
+}
+
+func TestProcessorExplainsDryRunMatches(t *testing.T) {
+	mockClient := &MockClient{entries: []*miniflux.Entry{
+		{ID: 1, Title: "Sponsored: a phone", Status: miniflux.EntryStatusUnread, Feed: &miniflux.Feed{Title: "Tech Daily"},
+			Content: "<p>" + strings.Repeat("Long introduction. ", 10) + "Use the promo code SAVE20 at checkout.</p>"},
+	}}
+	matcher, err := NewMatcher([]Rule{
+		{Name: "Promo codes", Feed: "Tech", Title: "(?i)sponsored", Content: "promo code \\w+", Action: "read"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create matcher: %v", err)
+	}
+
+	var logs bytes.Buffer
+	processor := NewProcessor(mockClient, matcher, slog.New(slog.NewJSONHandler(&logs, nil)), true)
+	processor.SetExplain(true)
+	if _, err := processor.Process(); err != nil {
+		t.Fatalf("Process failed: %v", err)
+	}
+
+	var details []string
+	decoder := json.NewDecoder(&logs)
+	for decoder.More() {
+		var line struct {
+			Msg    string `json:"msg"`
+			Rule   string `json:"rule"`
+			Detail string `json:"detail"`
+		}
+		if err := decoder.Decode(&line); err != nil {
+			t.Fatalf("Failed to decode log line: %v", err)
+		}
+		if line.Msg == "Match detail" && line.Rule == "Promo codes" {
+			details = append(details, line.Detail)
+		}
+	}
+	expected := []string{
+		`feed Tech matched "[[Tech]] Daily"`,
+		`title (?i)sponsored matched "[[Sponsored]]: a phone"`,
+		`content promo code \w+ matched "...ntroduction. Long introduction. Use the [[promo code SAVE20]] at checkout.</p>"`,
+	}
+	if !slices.Equal(details, expected) {
+		t.Errorf("Expected match details %q, got %q", expected, details)
+	}
+}
+
+func TestHighlightMatch(t *testing.T) {
+	pattern := regexp.MustCompile("Ärger")
+	text := strings.Repeat("ü", 30) + " Ärger " + strings.Repeat("é", 30)
+	got := highlightMatch(pattern, text)
+	if !strings.HasPrefix(got, "...") || !strings.HasSuffix(got, "...") || !strings.Contains(got, " [[Ärger]] ") {
+		t.Errorf("Expected an excerpt around the match, got %q", got)
+	}
+	if !utf8.ValidString(got) {
+		t.Errorf("Expected the excerpt to keep whole characters, got %q", got)
+	}
+}
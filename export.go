@@ -0,0 +1,129 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	miniflux "miniflux.app/v2/client"
+)
+
+// Export file formats
+const (
+	exportJSONL = "jsonl"
+	exportCSV   = "csv"
+)
+
+// exportColumns is the CSV header, in the order of exportRecord.row
+var exportColumns = []string{"exported_at", "rule", "action", "entry_id", "feed_id", "feed_title", "title", "url", "author", "published_at", "dry_run"}
+
+// exportRecord is a matched entry appended to the export file
+type exportRecord struct {
+	ExportedAt  time.Time `json:"exported_at"`
+	Rule        string    `json:"rule"`
+	Action      string    `json:"action"`
+	EntryID     int64     `json:"entry_id"`
+	FeedID      int64     `json:"feed_id"`
+	FeedTitle   string    `json:"feed_title"`
+	Title       string    `json:"title"`
+	URL         string    `json:"url"`
+	Author      string    `json:"author,omitempty"`
+	PublishedAt time.Time `json:"published_at"`
+	DryRun      bool      `json:"dry_run,omitempty"`
+}
+
+// row returns the record as CSV fields
+func (r exportRecord) row() []string {
+	return []string{
+		r.ExportedAt.Format(time.RFC3339),
+		r.Rule,
+		r.Action,
+		strconv.FormatInt(r.EntryID, 10),
+		strconv.FormatInt(r.FeedID, 10),
+		r.FeedTitle,
+		r.Title,
+		r.URL,
+		r.Author,
+		r.PublishedAt.Format(time.RFC3339),
+		strconv.FormatBool(r.DryRun),
+	}
+}
+
+// exportFormat returns the configured format, or the one implied by the file extension
+func exportFormat(path, format string) string {
+	if format != "" {
+		return strings.ToLower(format)
+	}
+	if strings.EqualFold(filepath.Ext(path), ".csv") {
+		return exportCSV
+	}
+	return exportJSONL
+}
+
+// appendExport appends the record to the export file, writing a CSV header to new files
+func appendExport(path, format string, record exportRecord) error {
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open export file: %w", err)
+	}
+	defer file.Close()
+
+	switch format {
+	case exportCSV:
+		info, err := file.Stat()
+		if err != nil {
+			return fmt.Errorf("failed to open export file: %w", err)
+		}
+		w := csv.NewWriter(file)
+		if info.Size() == 0 {
+			w.Write(exportColumns)
+		}
+		w.Write(record.row())
+		w.Flush()
+		err = w.Error()
+	default:
+		err = json.NewEncoder(file).Encode(record)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write export file: %w", err)
+	}
+	return nil
+}
+
+// applyExport appends an entry matched by rule to the export file, once per entry and rule
+// Exports only record matches, so they are written in dry runs too
+func (p *Processor) applyExport(entry *miniflux.Entry, rule *Rule, stats *ProcessStats) bool {
+	if !p.shouldNotify("export", entry, rule.Name) {
+		return true
+	}
+
+	record := exportRecord{
+		ExportedAt:  p.now(),
+		Rule:        rule.Name,
+		Action:      ruleActionLabel(rule),
+		EntryID:     entry.ID,
+		FeedID:      entryFeedID(entry),
+		Title:       entry.Title,
+		URL:         entry.URL,
+		Author:      entry.Author,
+		PublishedAt: entry.Date,
+		DryRun:      p.dryRun,
+	}
+	if entry.Feed != nil {
+		record.FeedTitle = entry.Feed.Title
+	}
+
+	path := p.options.ExportPath
+	if err := appendExport(path, exportFormat(path, p.options.ExportFormat), record); err != nil {
+		p.entryLogger(entry, rule).Error("Failed to export entry", "action", "export", "error", err)
+		stats.Errors++
+		return false
+	}
+	p.audit(entry, "export", rule.Name)
+	return true
+}
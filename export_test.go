@@ -0,0 +1,98 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	miniflux "miniflux.app/v2/client"
+)
+
+func TestProcessorExportAction(t *testing.T) {
+	for _, format := range []string{"jsonl", "csv"} {
+		dir := t.TempDir()
+		exportPath := filepath.Join(dir, "matches."+format)
+
+		mockClient := &MockClient{
+			entries: []*miniflux.Entry{
+				{ID: 1, FeedID: 3, Title: "Sponsored: gadgets", URL: "https://example.com/1", Feed: &miniflux.Feed{Title: "Tech"}, Status: miniflux.EntryStatusUnread},
+				{ID: 2, FeedID: 3, Title: "Sponsored: phones", URL: "https://example.com/2", Feed: &miniflux.Feed{Title: "Tech"}, Status: miniflux.EntryStatusUnread},
+			},
+		}
+
+		state, err := LoadState(filepath.Join(dir, "state.json"))
+		if err != nil {
+			t.Fatalf("Failed to load state: %v", err)
+		}
+		matcher, err := NewMatcher([]Rule{
+			{Name: "Sponsored", Title: "Sponsored", Actions: []ActionStep{{Action: "export"}, {Action: "read"}}},
+		})
+		if err != nil {
+			t.Fatalf("Failed to create matcher: %v", err)
+		}
+
+		logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+		processor := NewProcessor(mockClient, matcher, logger, false)
+		processor.SetState(state)
+		processor.SetOptions(ProcessorOptions{ExportPath: exportPath})
+
+		// The second run must not export the same entries again
+		for range 2 {
+			if _, err := processor.Process(); err != nil {
+				t.Fatalf("Process failed: %v", err)
+			}
+			for _, entry := range mockClient.entries {
+				entry.Status = miniflux.EntryStatusUnread
+			}
+		}
+
+		data, err := os.ReadFile(exportPath)
+		if err != nil {
+			t.Fatalf("Failed to read export: %v", err)
+		}
+
+		switch format {
+		case "jsonl":
+			lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+			if len(lines) != 2 {
+				t.Fatalf("Expected 2 JSONL records, got %d:\n%s", len(lines), data)
+			}
+			var record exportRecord
+			if err := json.Unmarshal([]byte(lines[0]), &record); err != nil {
+				t.Fatalf("Invalid JSONL record: %v", err)
+			}
+			if record.Rule != "Sponsored" || record.Action != "export, read" || record.EntryID != 1 || record.FeedTitle != "Tech" {
+				t.Errorf("Unexpected record: %+v", record)
+			}
+		case "csv":
+			rows, err := csv.NewReader(strings.NewReader(string(data))).ReadAll()
+			if err != nil {
+				t.Fatalf("Invalid CSV: %v", err)
+			}
+			if len(rows) != 3 || rows[0][1] != "rule" || rows[2][3] != "2" || rows[2][6] != "Sponsored: phones" {
+				t.Errorf("Unexpected CSV rows: %v", rows)
+			}
+		}
+	}
+}
+
+func TestExportFormat(t *testing.T) {
+	tests := []struct {
+		path, format, expected string
+	}{
+		{"matches.csv", "", "csv"},
+		{"matches.CSV", "", "csv"},
+		{"matches.jsonl", "", "jsonl"},
+		{"matches.log", "", "jsonl"},
+		{"matches.log", "CSV", "csv"},
+	}
+	for _, tt := range tests {
+		if got := exportFormat(tt.path, tt.format); got != tt.expected {
+			t.Errorf("exportFormat(%q, %q) = %q, expected %q", tt.path, tt.format, got, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	miniflux "miniflux.app/v2/client"
+)
+
+// FeedAction is a feed disabled or refreshed by a rule during a run
+type FeedAction struct {
+	Rule      string
+	Action    string // "disable_feed" or "refresh_feed"
+	FeedID    int64
+	FeedTitle string
+}
+
+// applyFeedAction disables or refreshes the feed of a matched entry
+// Each feed is acted on once per run, however many of its entries match
+func (p *Processor) applyFeedAction(entry *miniflux.Entry, step string, rule *Rule, stats *ProcessStats) bool {
+	feedID := entryFeedID(entry)
+	if feedID == 0 {
+		p.entryLogger(entry, rule).Warn("Cannot act on the feed of the entry, feed unknown", "action", step)
+		stats.Errors++
+		return false
+	}
+	for _, done := range stats.FeedActions {
+		if done.FeedID == feedID && done.Action == step {
+			return true
+		}
+	}
+
+	feedTitle := ""
+	if entry.Feed != nil {
+		feedTitle = entry.Feed.Title
+	}
+
+	if p.dryRun {
+		p.entryLogger(entry, rule).Info("Dry run: would apply feed action", "action", step, "feed_id", feedID)
+	} else {
+		var err error
+		switch step {
+		case "disable_feed":
+			err = p.client.DisableFeed(feedID)
+		case "refresh_feed":
+			err = p.client.RefreshFeed(feedID)
+		}
+		if err != nil {
+			p.entryLogger(entry, rule).Error("Failed to apply feed action", "action", step, "feed_id", feedID, "error", err)
+			stats.Errors++
+			return false
+		}
+		if step == "disable_feed" && entry.Feed != nil {
+			entry.Feed.Disabled = true
+		}
+		p.entryLogger(entry, rule).Info("Applied feed action", "action", step, "feed_id", feedID)
+	}
+	p.audit(entry, step, rule.Name)
+
+	stats.FeedActions = append(stats.FeedActions, FeedAction{
+		Rule:      rule.Name,
+		Action:    step,
+		FeedID:    feedID,
+		FeedTitle: feedTitle,
+	})
+	return true
+}
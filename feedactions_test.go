@@ -0,0 +1,107 @@
+package main
+
+import (
+	"errors"
+	"log/slog"
+	"os"
+	"slices"
+	"testing"
+
+	miniflux "miniflux.app/v2/client"
+)
+
+func TestProcessorFeedActions(t *testing.T) {
+	spam := &miniflux.Feed{ID: 10, Title: "Hijacked blog"}
+	mockClient := &MockClient{
+		entries: []*miniflux.Entry{
+			{ID: 1, FeedID: 10, Title: "Cheap pills", Feed: spam, Status: miniflux.EntryStatusUnread},
+			{ID: 2, FeedID: 10, Title: "Cheap watches", Feed: spam, Status: miniflux.EntryStatusUnread},
+			{ID: 3, FeedID: 20, Title: "Stale digest", Status: miniflux.EntryStatusUnread},
+		},
+	}
+
+	matcher, err := NewMatcher([]Rule{
+		{Name: "Spam feed", Title: "Cheap", Actions: []ActionStep{{Action: "remove"}, {Action: "disable_feed"}}},
+		{Name: "Stale", Title: "Stale", Action: "refresh_feed"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create matcher: %v", err)
+	}
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	processor := NewProcessor(mockClient, matcher, logger, false)
+	stats, err := processor.Process()
+	if err != nil {
+		t.Fatalf("Process failed: %v", err)
+	}
+
+	if !slices.Equal(mockClient.disabledFeeds, []int64{10}) {
+		t.Errorf("Expected feed 10 to be disabled once, got %v", mockClient.disabledFeeds)
+	}
+	if !slices.Equal(mockClient.refreshed, []int64{20}) {
+		t.Errorf("Expected feed 20 to be refreshed, got %v", mockClient.refreshed)
+	}
+	if stats.Removed != 2 || len(stats.FeedActions) != 2 {
+		t.Errorf("Expected 2 removals and 2 feed actions, got %+v", stats)
+	}
+	if !spam.Disabled {
+		t.Error("Expected the entry's feed to be marked disabled")
+	}
+}
+
+func TestProcessorFeedActionErrors(t *testing.T) {
+	mockClient := &MockClient{
+		entries: []*miniflux.Entry{
+			{ID: 1, Title: "Orphan entry", Status: miniflux.EntryStatusUnread},
+			{ID: 2, FeedID: 10, Title: "Orphan feed", Status: miniflux.EntryStatusUnread},
+		},
+	}
+
+	matcher, err := NewMatcher([]Rule{
+		{Name: "Orphans", Title: "Orphan", Action: "disable_feed"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create matcher: %v", err)
+	}
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	processor := NewProcessor(mockClient, matcher, logger, false)
+
+	// Entry 1 has no feed; entry 2's feed cannot be updated
+	mockClient.updateErr = errors.New("feed is gone")
+	stats, err := processor.Process()
+	if err != nil {
+		t.Fatalf("Process failed: %v", err)
+	}
+	if stats.Errors != 2 || len(stats.FeedActions) != 0 {
+		t.Errorf("Expected 2 errors and no feed actions, got %+v", stats)
+	}
+}
+
+func TestProcessorFeedActionDryRun(t *testing.T) {
+	mockClient := &MockClient{
+		entries: []*miniflux.Entry{
+			{ID: 1, FeedID: 10, Title: "Cheap pills", Status: miniflux.EntryStatusUnread},
+		},
+	}
+
+	matcher, err := NewMatcher([]Rule{
+		{Name: "Spam feed", Title: "Cheap", Action: "disable_feed"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create matcher: %v", err)
+	}
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	processor := NewProcessor(mockClient, matcher, logger, true)
+	stats, err := processor.Process()
+	if err != nil {
+		t.Fatalf("Process failed: %v", err)
+	}
+	if len(mockClient.disabledFeeds) != 0 {
+		t.Errorf("Expected no feeds disabled in dry run, got %v", mockClient.disabledFeeds)
+	}
+	if len(stats.FeedActions) != 1 {
+		t.Errorf("Expected the proposed feed action to be reported, got %+v", stats.FeedActions)
+	}
+}
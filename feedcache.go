@@ -0,0 +1,61 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	miniflux "miniflux.app/v2/client"
+)
+
+// feeds returns the subscribed feeds, fetched at most once per run
+// With feed_cache_ttl the list is also reused by later runs until it expires
+func (p *Processor) feeds() (miniflux.Feeds, error) {
+	if p.feedList != nil {
+		fresh := !p.feedsFetched.Before(p.runStarted)
+		if fresh || time.Since(p.feedsFetched) < p.options.FeedCacheTTL {
+			return p.feedList, nil
+		}
+	}
+
+	feeds, err := p.client.Feeds()
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch feeds: %w", err)
+	}
+	p.feedList = feeds
+	p.feedsByID = make(map[int64]*miniflux.Feed, len(feeds))
+	for _, feed := range feeds {
+		p.feedsByID[feed.ID] = feed
+	}
+	p.feedsFetched = p.now()
+	return feeds, nil
+}
+
+// enrichEntries completes the feed of entries whose API response omitted it or some of its
+// fields, so feed and category conditions see the title, category and site URL
+func (p *Processor) enrichEntries(page []*miniflux.Entry) {
+	if _, err := p.feeds(); err != nil {
+		p.logger.Warn("Cannot complete feed details of entries", "error", err)
+		return
+	}
+
+	for _, entry := range page {
+		id := entryFeedID(entry)
+		feed, ok := p.feedsByID[id]
+		if id == 0 || !ok {
+			continue
+		}
+		if entry.Feed == nil {
+			entry.Feed = feed
+			continue
+		}
+		if entry.Feed.Title == "" {
+			entry.Feed.Title = feed.Title
+		}
+		if entry.Feed.Category == nil {
+			entry.Feed.Category = feed.Category
+		}
+		if entry.Feed.SiteURL == "" {
+			entry.Feed.SiteURL = feed.SiteURL
+		}
+	}
+}
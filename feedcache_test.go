@@ -0,0 +1,69 @@
+package main
+
+import (
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	miniflux "miniflux.app/v2/client"
+)
+
+// feedCountingClient counts Feeds calls
+type feedCountingClient struct {
+	*MockClient
+	feedCalls int
+}
+
+func (c *feedCountingClient) Feeds() (miniflux.Feeds, error) {
+	c.feedCalls++
+	return c.MockClient.Feeds()
+}
+
+func TestProcessorEnrichesFeeds(t *testing.T) {
+	news := &miniflux.Category{ID: 4, Title: "News"}
+	client := &feedCountingClient{MockClient: &MockClient{
+		entries: []*miniflux.Entry{
+			{ID: 1, FeedID: 7, Title: "Sponsored: a phone"},
+			{ID: 2, FeedID: 7, Title: "Sponsored: a laptop", Feed: &miniflux.Feed{ID: 7}},
+			{ID: 3, FeedID: 8, Title: "Sponsored: a tablet"},
+		},
+		feeds: miniflux.Feeds{
+			{ID: 7, Title: "Tech Daily", SiteURL: "https://tech.example.com", Category: news},
+			{ID: 8, Title: "Gadgets", Category: &miniflux.Category{ID: 5, Title: "Shopping"}},
+		},
+	}}
+
+	matcher, err := NewMatcher([]Rule{
+		{Name: "Sponsored news", Feed: "^Tech", Category: "News", Title: "Sponsored", Action: "read"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create matcher: %v", err)
+	}
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	processor := NewProcessor(client, matcher, logger, true)
+	stats, err := processor.Process()
+	if err != nil {
+		t.Fatalf("Process failed: %v", err)
+	}
+
+	if stats.MatchedEntries != 2 {
+		t.Errorf("Expected the two entries of feed 7 to match once completed, got %d", stats.MatchedEntries)
+	}
+	if feed := client.entries[1].Feed; feed.SiteURL != "https://tech.example.com" || feed.Category != news {
+		t.Errorf("Expected the partial feed to be completed, got %+v", feed)
+	}
+
+	// The feed list is fetched again on the next run, unless feed_cache_ttl keeps it
+	if _, err := processor.Process(); err != nil {
+		t.Fatalf("Process failed: %v", err)
+	}
+	processor.SetOptions(ProcessorOptions{FeedCacheTTL: time.Hour})
+	if _, err := processor.Process(); err != nil {
+		t.Fatalf("Process failed: %v", err)
+	}
+	if client.feedCalls != 2 {
+		t.Errorf("Expected 2 feed list fetches, got %d", client.feedCalls)
+	}
+}
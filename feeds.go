@@ -0,0 +1,86 @@
+package main
+
+import (
+	"cmp"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"text/tabwriter"
+
+	miniflux "miniflux.app/v2/client"
+)
+
+// writeFeeds prints the feeds with their categories and unread counts, the IDs to use in
+// feed_id and category_id conditions, sorted by category and then feed title
+func writeFeeds(w io.Writer, feeds miniflux.Feeds, counters *miniflux.FeedCounters) error {
+	sorted := slices.Clone(feeds)
+	slices.SortStableFunc(sorted, func(a, b *miniflux.Feed) int {
+		return cmp.Or(
+			cmp.Compare(feedCategoryTitle(a), feedCategoryTitle(b)),
+			cmp.Compare(a.Title, b.Title),
+			cmp.Compare(a.ID, b.ID),
+		)
+	})
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "FEED ID\tFEED\tCATEGORY ID\tCATEGORY\tUNREAD")
+	for _, feed := range sorted {
+		categoryID := ""
+		if feed.Category != nil {
+			categoryID = fmt.Sprint(feed.Category.ID)
+		}
+		title := feed.Title
+		if feed.Disabled {
+			title += " (disabled)"
+		}
+		unread := 0
+		if counters != nil {
+			unread = counters.UnreadCounters[feed.ID]
+		}
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%d\n", feed.ID, title, categoryID, feedCategoryTitle(feed), unread)
+	}
+	return tw.Flush()
+}
+
+// feedCategoryTitle returns the title of a feed's category, empty when it has none
+func feedCategoryTitle(feed *miniflux.Feed) string {
+	if feed.Category == nil {
+		return ""
+	}
+	return feed.Category.Title
+}
+
+// feedsCommand runs the feeds subcommand, listing the feeds rules can refer to:
+// miniflux-jobs feeds
+func feedsCommand(args []string) {
+	flags := flag.NewFlagSet("feeds", flag.ExitOnError)
+	configPath := flags.String("config", defaultConfigPath(), "Path to the rules configuration file, read for the Miniflux URL")
+	flags.Parse(args)
+
+	logger := commandLogger(os.Stderr)
+
+	config, err := LoadConfig(*configPath)
+	if err != nil {
+		fatal(logger, "Failed to load config", "error", err)
+	}
+
+	apiKey, err := GetAPIKey()
+	if err != nil {
+		fatal(logger, "Failed to get API key", "error", err)
+	}
+	client := NewClientWrapper(config.MinifluxURL, apiKey, config.MaxResponseSize)
+
+	feeds, err := client.Feeds()
+	if err != nil {
+		fatal(logger, "Failed to fetch feeds", "error", err)
+	}
+	counters, err := client.FeedCounters()
+	if err != nil {
+		fatal(logger, "Failed to fetch unread counts", "error", err)
+	}
+	if err := writeFeeds(os.Stdout, feeds, counters); err != nil {
+		fatal(logger, "Failed to list feeds", "error", err)
+	}
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	miniflux "miniflux.app/v2/client"
+)
+
+func TestWriteFeeds(t *testing.T) {
+	news := &miniflux.Category{ID: 4, Title: "News"}
+	tech := &miniflux.Category{ID: 2, Title: "Tech"}
+	mockClient := &MockClient{
+		feeds: []*miniflux.Feed{
+			{ID: 1, Title: "Tech Blog", Category: tech},
+			{ID: 2, Title: "World", Category: news},
+			{ID: 3, Title: "Local", Category: news, Disabled: true},
+		},
+		entries: []*miniflux.Entry{
+			{ID: 10, FeedID: 1, Status: miniflux.EntryStatusUnread},
+			{ID: 11, FeedID: 1, Status: miniflux.EntryStatusUnread},
+			{ID: 12, FeedID: 2, Status: miniflux.EntryStatusRead},
+		},
+	}
+
+	counters, err := mockClient.FeedCounters()
+	if err != nil {
+		t.Fatalf("FeedCounters failed: %v", err)
+	}
+	var out strings.Builder
+	if err := writeFeeds(&out, mockClient.feeds, counters); err != nil {
+		t.Fatalf("writeFeeds failed: %v", err)
+	}
+
+	expected := []string{
+		"FEED ID  FEED              CATEGORY ID  CATEGORY  UNREAD",
+		"3        Local (disabled)  4            News      0",
+		"2        World             4            News      0",
+		"1        Tech Blog         2            Tech      2",
+	}
+	if got := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n"); strings.Join(got, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Expected:\n%s\ngot:\n%s", strings.Join(expected, "\n"), out.String())
+	}
+}
@@ -0,0 +1,287 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	miniflux "miniflux.app/v2/client"
+)
+
+// Entry fetching strategies
+const (
+	fetchSequential           = "sequential"
+	fetchRoundRobinFeeds      = "round_robin_feeds"
+	fetchRoundRobinCategories = "round_robin_categories"
+)
+
+// Entry orders accepted by the Miniflux API, and fetch directions
+var fetchOrders = []string{"id", "status", "published_at", "category_title", "category_id", "title", "author"}
+
+const (
+	fetchAscending  = "asc"
+	fetchDescending = "desc"
+)
+
+// PartialFetchError reports a run that completed after skipping pages it could not fetch
+// The entries on those pages were neither matched nor changed
+type PartialFetchError struct {
+	Pages int
+}
+
+// Error describes how much of the run was skipped
+func (e *PartialFetchError) Error() string {
+	return fmt.Sprintf("%d pages of entries could not be fetched and were skipped", e.Pages)
+}
+
+// fetchEntries pages through entries matching filter using the configured strategy
+func (p *Processor) fetchEntries(filter *miniflux.Filter, handle func(*miniflux.Entry)) error {
+	return p.fetchPages(filter, func(page []*miniflux.Entry) bool {
+		for _, entry := range page {
+			handle(entry)
+		}
+		return true
+	})
+}
+
+// fetchPages is fetchEntries handing over whole pages, in order, until handle returns false
+// When the fetch scope or the rules restrict processing to particular feeds, only those are fetched
+func (p *Processor) fetchPages(filter *miniflux.Filter, handle func([]*miniflux.Entry) bool) error {
+	feedIDs, scoped, err := p.scopedFeeds()
+	if err != nil {
+		return err
+	}
+	if scoped {
+		return p.fetchFeeds(filter, feedIDs, handle)
+	}
+
+	switch strategy := strings.ToLower(p.options.FetchStrategy); strategy {
+	case fetchRoundRobinFeeds, fetchRoundRobinCategories:
+		groups, err := p.fetchGroups()
+		if err != nil {
+			return err
+		}
+		return p.fetchRoundRobin(filter, groups, strategy == fetchRoundRobinCategories, handle)
+	default:
+		return p.fetchSequential(filter, handle)
+	}
+}
+
+// fetchFeeds fetches the entries of the given feeds, one feed after another
+// or interleaved by page with a round-robin strategy
+func (p *Processor) fetchFeeds(filter *miniflux.Filter, feedIDs []int64, handle func([]*miniflux.Entry) bool) error {
+	switch strings.ToLower(p.options.FetchStrategy) {
+	case fetchRoundRobinFeeds, fetchRoundRobinCategories:
+		groups := make([]fetchGroup, 0, len(feedIDs))
+		for _, id := range feedIDs {
+			groups = append(groups, fetchGroup{id: id})
+		}
+		return p.fetchRoundRobin(filter, groups, false, handle)
+	}
+
+	stopped := false
+	for _, id := range feedIDs {
+		feedFilter := *filter
+		feedFilter.FeedID = id
+		err := p.fetchSequential(&feedFilter, func(page []*miniflux.Entry) bool {
+			stopped = !handle(page)
+			return !stopped
+		})
+		if err != nil || stopped {
+			return err
+		}
+	}
+	return nil
+}
+
+// fetchSequential pages through all entries in the server's default order
+// With concurrency above 1, the pages after the first are fetched that many at a time
+func (p *Processor) fetchSequential(filter *miniflux.Filter, handle func([]*miniflux.Entry) bool) error {
+	offset, total := 0, 0
+	for {
+		filter.Offset = offset
+		result, err := p.fetchPage(filter)
+		if err != nil {
+			// Without the first page the number of entries is unknown, so the run cannot go on
+			if offset == 0 || !p.skipPage(offset, filter.Limit, err) {
+				return err
+			}
+			offset += filter.Limit
+			if offset >= total {
+				break
+			}
+			continue
+		}
+
+		if len(result.Entries) == 0 {
+			break
+		}
+
+		if !handle(result.Entries) {
+			break
+		}
+
+		offset += len(result.Entries)
+		total = result.Total
+
+		// Check if we've processed all entries
+		if offset >= total {
+			break
+		}
+		if p.concurrency() > 1 && filter.Limit > 0 {
+			return p.fetchConcurrent(filter, offset, result.Total, handle)
+		}
+	}
+	return nil
+}
+
+// fetchConcurrent fetches the pages from offset up to total in windows of concurrent requests
+// Pages are handled in offset order once their window has been fetched
+func (p *Processor) fetchConcurrent(filter *miniflux.Filter, offset, total int, handle func([]*miniflux.Entry) bool) error {
+	for offset < total {
+		var offsets []int
+		for len(offsets) < p.concurrency() && offset < total {
+			offsets = append(offsets, offset)
+			offset += filter.Limit
+		}
+
+		pages := make([][]*miniflux.Entry, len(offsets))
+		errs := make([]error, len(offsets))
+		var wg sync.WaitGroup
+		for i, pageOffset := range offsets {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				pageFilter := *filter
+				pageFilter.Offset = pageOffset
+				result, err := p.fetchPage(&pageFilter)
+				if err != nil {
+					errs[i] = err
+					return
+				}
+				pages[i] = result.Entries
+			}()
+		}
+		wg.Wait()
+
+		for i, page := range pages {
+			if errs[i] != nil {
+				if !p.skipPage(offsets[i], filter.Limit, errs[i]) {
+					return errs[i]
+				}
+				continue
+			}
+			if len(page) == 0 || !handle(page) {
+				return nil
+			}
+		}
+	}
+	return nil
+}
+
+// fetchPage fetches one page of entries, retrying a failure up to page_retries times
+func (p *Processor) fetchPage(filter *miniflux.Filter) (*miniflux.EntryResultSet, error) {
+	for attempt := 1; ; attempt++ {
+		result, err := p.client.Entries(filter)
+		if err == nil {
+			return result, nil
+		}
+		if attempt > p.options.PageRetries {
+			return nil, fmt.Errorf("failed to fetch entries: %w", err)
+		}
+		p.logger.Warn("Failed to fetch entries, retrying", "offset", filter.Offset, "attempt", attempt, "retries", p.options.PageRetries, "error", err)
+		time.Sleep(p.retryDelay * time.Duration(attempt))
+	}
+}
+
+// skipPage records a page that still failed after retries and reports whether
+// skip_failed_pages lets the run continue past it
+func (p *Processor) skipPage(offset, limit int, err error) bool {
+	if !p.options.SkipFailedPages || limit == 0 {
+		return false
+	}
+	p.logger.Warn("Skipping entries that could not be fetched", "offset", offset, "limit", limit, "error", err)
+	p.skippedPages++
+	return true
+}
+
+// fetchGroup tracks pagination through the entries of one feed or category
+type fetchGroup struct {
+	id     int64
+	offset int
+	total  int // known once a page of the group has been fetched
+}
+
+// fetchRoundRobin fetches one page per feed or category in turn, so a single
+// large feed cannot monopolize the start of a long run
+func (p *Processor) fetchRoundRobin(filter *miniflux.Filter, groups []fetchGroup, byCategory bool, handle func([]*miniflux.Entry) bool) error {
+	for len(groups) > 0 {
+		active := groups[:0]
+		for _, group := range groups {
+			pageFilter := *filter
+			pageFilter.Offset = group.offset
+			if byCategory {
+				pageFilter.CategoryID = group.id
+			} else {
+				pageFilter.FeedID = group.id
+			}
+
+			result, err := p.fetchPage(&pageFilter)
+			if err != nil {
+				if !p.skipPage(group.offset, pageFilter.Limit, err) {
+					return err
+				}
+				// A group whose size is still unknown is given up on entirely
+				group.offset += pageFilter.Limit
+				if group.offset < group.total {
+					active = append(active, group)
+				}
+				continue
+			}
+
+			if len(result.Entries) > 0 && !handle(result.Entries) {
+				return nil
+			}
+
+			group.offset += len(result.Entries)
+			group.total = result.Total
+			if len(result.Entries) > 0 && group.offset < result.Total {
+				active = append(active, group)
+			}
+		}
+		groups = active
+	}
+	return nil
+}
+
+// fetchGroups returns the feeds or categories to interleave, in ID order
+func (p *Processor) fetchGroups() ([]fetchGroup, error) {
+	feeds, err := p.feeds()
+	if err != nil {
+		return nil, err
+	}
+
+	byCategory := strings.EqualFold(p.options.FetchStrategy, fetchRoundRobinCategories)
+	var ids []int64
+	for _, feed := range feeds {
+		id := feed.ID
+		if byCategory {
+			if feed.Category == nil {
+				continue
+			}
+			id = feed.Category.ID
+		}
+		if !slices.Contains(ids, id) {
+			ids = append(ids, id)
+		}
+	}
+	slices.Sort(ids)
+
+	groups := make([]fetchGroup, 0, len(ids))
+	for _, id := range ids {
+		groups = append(groups, fetchGroup{id: id})
+	}
+	return groups, nil
+}
@@ -0,0 +1,211 @@
+package main
+
+import (
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	miniflux "miniflux.app/v2/client"
+)
+
+func TestProcessorRoundRobinFeeds(t *testing.T) {
+	var entries []*miniflux.Entry
+	// Feed 1 floods the backlog with 250 entries before feed 2's single entry
+	for i := 1; i <= 250; i++ {
+		entries = append(entries, &miniflux.Entry{ID: int64(i), Title: "Noise", FeedID: 1})
+	}
+	entries = append(entries, &miniflux.Entry{ID: 251, Title: "Signal", FeedID: 2})
+
+	mockClient := &MockClient{
+		entries: entries,
+		feeds:   miniflux.Feeds{{ID: 2}, {ID: 1}},
+	}
+
+	matcher, err := NewMatcher([]Rule{
+		{Name: "Any", Title: ".", Action: "read"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create matcher: %v", err)
+	}
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	processor := NewProcessor(mockClient, matcher, logger, true)
+	processor.SetOptions(ProcessorOptions{FetchStrategy: fetchRoundRobinFeeds})
+
+	var order []int64
+	err = processor.fetchEntries(&miniflux.Filter{Limit: 100}, func(entry *miniflux.Entry) {
+		order = append(order, entry.ID)
+	})
+	if err != nil {
+		t.Fatalf("fetchEntries failed: %v", err)
+	}
+
+	if len(order) != 251 {
+		t.Fatalf("Expected all 251 entries, got %d", len(order))
+	}
+	if order[100] != 251 {
+		t.Errorf("Expected feed 2 to be reached after the first page of feed 1, got entry %d", order[100])
+	}
+
+	stats, err := processor.Process()
+	if err != nil {
+		t.Fatalf("Process failed: %v", err)
+	}
+	if stats.TotalEntries != 251 {
+		t.Errorf("Expected 251 entries processed, got %d", stats.TotalEntries)
+	}
+}
+
+func TestProcessorConcurrency(t *testing.T) {
+	var entries []*miniflux.Entry
+	for i := 1; i <= 450; i++ {
+		title := "Keep"
+		if i%3 == 0 {
+			title = "Noise"
+		}
+		entries = append(entries, &miniflux.Entry{ID: int64(i), Title: title, Status: miniflux.EntryStatusUnread})
+	}
+	mockClient := &MockClient{entries: entries}
+
+	matcher, err := NewMatcher([]Rule{
+		{Name: "Noise", Title: "Noise", Action: "read"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create matcher: %v", err)
+	}
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	processor := NewProcessor(mockClient, matcher, logger, false)
+	processor.SetOptions(ProcessorOptions{Concurrency: 4})
+
+	stats, err := processor.Process()
+	if err != nil {
+		t.Fatalf("Process failed: %v", err)
+	}
+
+	if stats.TotalEntries != 450 || stats.MatchedEntries != 150 || stats.MarkedRead != 150 {
+		t.Errorf("Expected 450 entries checked and 150 marked read, got %+v", stats)
+	}
+	if len(mockClient.updatedIDs) != 150 {
+		t.Fatalf("Expected 150 updated entries, got %d", len(mockClient.updatedIDs))
+	}
+	for i, id := range mockClient.updatedIDs {
+		if id != int64(i+1)*3 {
+			t.Fatalf("Expected entries to be applied in fetch order, got %d at position %d", id, i)
+		}
+	}
+}
+
+// flakyClient fails page fetches at the given offsets a number of times
+type flakyClient struct {
+	*MockClient
+	failures map[int]int
+}
+
+func (c *flakyClient) Entries(filter *miniflux.Filter) (*miniflux.EntryResultSet, error) {
+	if c.failures[filter.Offset] > 0 {
+		c.failures[filter.Offset]--
+		return nil, errors.New("502 bad gateway")
+	}
+	return c.MockClient.Entries(filter)
+}
+
+func TestProcessorFailedPages(t *testing.T) {
+	var entries []*miniflux.Entry
+	for i := 1; i <= 250; i++ {
+		entries = append(entries, &miniflux.Entry{ID: int64(i), Title: "Post"})
+	}
+
+	tests := []struct {
+		name     string
+		options  ProcessorOptions
+		failures int
+		checked  int
+		skipped  int
+		partial  bool
+		aborted  bool
+	}{
+		{"abort by default", ProcessorOptions{}, 1, 100, 0, false, true},
+		{"retried page", ProcessorOptions{PageRetries: 2}, 2, 250, 0, false, false},
+		{"skipped page", ProcessorOptions{PageRetries: 1, SkipFailedPages: true}, 5, 150, 1, true, false},
+	}
+
+	for _, tt := range tests {
+		client := &flakyClient{MockClient: &MockClient{entries: entries}, failures: map[int]int{100: tt.failures}}
+		matcher, err := NewMatcher([]Rule{{Name: "Any", Title: ".", Action: "read"}})
+		if err != nil {
+			t.Fatalf("Failed to create matcher: %v", err)
+		}
+
+		logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+		processor := NewProcessor(client, matcher, logger, true)
+		processor.retryDelay = 0
+		processor.SetOptions(tt.options)
+
+		stats, err := processor.Process()
+		var partial *PartialFetchError
+		if got := errors.As(err, &partial); got != tt.partial {
+			t.Errorf("%s: expected partial failure %v, got error %v", tt.name, tt.partial, err)
+		}
+		if tt.aborted != (err != nil && partial == nil) {
+			t.Errorf("%s: expected aborted %v, got error %v", tt.name, tt.aborted, err)
+		}
+		if stats.TotalEntries != tt.checked || stats.SkippedPages != tt.skipped {
+			t.Errorf("%s: expected %d entries checked and %d pages skipped, got %d and %d",
+				tt.name, tt.checked, tt.skipped, stats.TotalEntries, stats.SkippedPages)
+		}
+	}
+}
+
+func TestProcessorFetchOrder(t *testing.T) {
+	mockClient := &MockClient{entries: []*miniflux.Entry{{ID: 1, Title: "Post"}}}
+	matcher, err := NewMatcher([]Rule{{Name: "Any", Title: ".", Action: "read"}})
+	if err != nil {
+		t.Fatalf("Failed to create matcher: %v", err)
+	}
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	processor := NewProcessor(mockClient, matcher, logger, true)
+	processor.SetOptions(ProcessorOptions{FetchOrder: "Published_At", FetchDirection: "DESC"})
+	if _, err := processor.Process(); err != nil {
+		t.Fatalf("Process failed: %v", err)
+	}
+
+	if mockClient.lastFilter.Order != "published_at" || mockClient.lastFilter.Direction != "desc" {
+		t.Errorf("Expected newest entries first by publication date, got order %q direction %q",
+			mockClient.lastFilter.Order, mockClient.lastFilter.Direction)
+	}
+}
+
+func TestLoadConfigFetchOrder(t *testing.T) {
+	tests := []struct {
+		settings string
+		errMsg   string
+	}{
+		{"fetch_order: published_at\nfetch_direction: desc\n", ""},
+		{"fetch_order: date\n", "fetch_order must be one of"},
+		{"fetch_direction: newest\n", "fetch_direction must be"},
+	}
+
+	for _, tt := range tests {
+		configPath := filepath.Join(t.TempDir(), "rules.yaml")
+		content := "miniflux_url: https://miniflux.example.com\n" + tt.settings
+		if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write config: %v", err)
+		}
+
+		_, err := LoadConfig(configPath)
+		if tt.errMsg == "" {
+			if err != nil {
+				t.Errorf("%q: unexpected error: %v", tt.settings, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), tt.errMsg) {
+			t.Errorf("%q: expected error containing %q, got %v", tt.settings, tt.errMsg, err)
+		}
+	}
+}
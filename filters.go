@@ -0,0 +1,211 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+
+	miniflux "miniflux.app/v2/client"
+)
+
+// Lines delimiting the block filter rules managed by sync-filters
+// Miniflux ignores lines without "=", so the markers never act as rules
+const (
+	filterSectionBegin = "# miniflux-jobs begin"
+	filterSectionEnd   = "# miniflux-jobs end"
+)
+
+// filterFields maps rule fields to the Miniflux filter rule types checking the same entry field
+var filterFields = map[string]string{
+	"title":        "EntryTitle",
+	"content":      "EntryContent",
+	"author":       "EntryAuthor",
+	"authors":      "EntryAuthor",
+	"comments_url": "EntryCommentsURL",
+}
+
+// filterScopeFields are rule fields that select feeds rather than entries
+var filterScopeFields = []string{"feed", "feed_id", "category", "category_id"}
+
+// filterIgnoredFields do not affect which entries a rule matches
+var filterIgnoredFields = []string{"name", "description", "labels", "action", "actions", "continue"}
+
+// filterModifierFields change how the other fields match rather than matching on their own
+var filterModifierFields = []string{"status", "case_insensitive", "match_mode"}
+
+// serverFilter is a rule translated into a Miniflux block filter line
+type serverFilter struct {
+	rule *compiledRule
+	line string
+}
+
+// translateRule returns the block filter line equivalent to a rule
+// Only read and remove rules with a single title, content, author or comments URL
+// condition translate: Miniflux ORs filter lines, and blocked entries are never stored,
+// so a read rule becomes stricter on the server
+func translateRule(rule *Rule, macros map[string][]string) (string, bool) {
+	steps, err := expandRule(rule, macros)
+	if err != nil || len(steps) != 1 || (steps[0] != "read" && steps[0] != "remove") {
+		return "", false
+	}
+	if len(rule.Status) > 0 && !slices.Equal(rule.Statuses(), []string{miniflux.EntryStatusUnread}) {
+		return "", false
+	}
+
+	var line string
+	value := reflect.ValueOf(*rule)
+	for i := range value.NumField() {
+		name, _, _ := strings.Cut(value.Type().Field(i).Tag.Get("yaml"), ",")
+		if value.Field(i).IsZero() || slices.Contains(filterModifierFields, name) || slices.Contains(filterScopeFields, name) || slices.Contains(filterIgnoredFields, name) {
+			continue
+		}
+
+		filterType, ok := filterFields[name]
+		if !ok || line != "" {
+			return "", false
+		}
+		pattern := value.Field(i).String()
+		if name == "authors" {
+			quoted := make([]string, 0, len(rule.Authors))
+			for _, author := range rule.Authors {
+				quoted = append(quoted, regexp.QuoteMeta(author))
+			}
+			pattern = "(?i)^(" + strings.Join(quoted, "|") + ")$"
+		} else {
+			// Rules referencing named patterns never get here: *_pattern fields have no filter type
+			pattern = rule.effectivePattern(pattern, "")
+		}
+		line = filterType + "=" + pattern
+	}
+	return line, line != ""
+}
+
+// feedInScope reports whether the rule's feed and category conditions select the feed
+func feedInScope(cr *compiledRule, feed *miniflux.Feed) bool {
+	if len(cr.rule.FeedID) > 0 && !slices.Contains(cr.rule.FeedID, feed.ID) {
+		return false
+	}
+	if cr.feed != nil && !cr.feed.MatchString(feed.Title) {
+		return false
+	}
+
+	categoryID, categoryTitle := int64(0), ""
+	if feed.Category != nil {
+		categoryID, categoryTitle = feed.Category.ID, feed.Category.Title
+	}
+	if len(cr.rule.CategoryID) > 0 && !slices.Contains(cr.rule.CategoryID, categoryID) {
+		return false
+	}
+	if cr.category != nil && !cr.category.MatchString(categoryTitle) {
+		return false
+	}
+	return true
+}
+
+// replaceFilterSection swaps the managed section of a feed's block rules, keeping other lines
+func replaceFilterSection(existing string, lines []string) string {
+	var kept []string
+	managed := false
+	for _, line := range strings.Split(strings.ReplaceAll(existing, "\r\n", "\n"), "\n") {
+		switch {
+		case strings.TrimSpace(line) == filterSectionBegin:
+			managed = true
+		case strings.TrimSpace(line) == filterSectionEnd:
+			managed = false
+		case !managed && strings.TrimSpace(line) != "":
+			kept = append(kept, line)
+		}
+	}
+
+	if len(lines) > 0 {
+		kept = append(kept, filterSectionBegin)
+		kept = append(kept, lines...)
+		kept = append(kept, filterSectionEnd)
+	}
+	return strings.Join(kept, "\n")
+}
+
+// syncFilters writes the translatable rules into each feed's block filter rules
+// It returns the number of feeds updated and the names of rules that were not translatable
+func syncFilters(client MinifluxClient, rules []Rule, macros map[string][]string, dryRun bool, logger *slog.Logger) (int, []string, error) {
+	var filters []serverFilter
+	var skipped []string
+	for _, rule := range rules {
+		line, ok := translateRule(&rule, macros)
+		if !ok {
+			skipped = append(skipped, rule.Name)
+			continue
+		}
+		cr, err := compileRule(rule)
+		if err != nil {
+			return 0, nil, err
+		}
+		filters = append(filters, serverFilter{rule: &cr, line: line})
+	}
+
+	feeds, err := client.Feeds()
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to fetch feeds: %w", err)
+	}
+
+	updated := 0
+	for _, feed := range feeds {
+		var lines []string
+		for _, filter := range filters {
+			if feedInScope(filter.rule, feed) && !slices.Contains(lines, filter.line) {
+				lines = append(lines, filter.line)
+			}
+		}
+
+		blockRules := replaceFilterSection(feed.BlockFilterEntryRules, lines)
+		if blockRules == feed.BlockFilterEntryRules {
+			continue
+		}
+		updated++
+
+		if dryRun {
+			logger.Info("Dry run: would set block rules", "feed_id", feed.ID, "feed", feed.Title, "rules", len(lines))
+			continue
+		}
+		if err := client.SetBlockFilterRules(feed.ID, blockRules); err != nil {
+			return updated - 1, skipped, fmt.Errorf("failed to update feed %d: %w", feed.ID, err)
+		}
+		logger.Info("Set block rules", "feed_id", feed.ID, "feed", feed.Title, "rules", len(lines))
+	}
+	return updated, skipped, nil
+}
+
+// syncFiltersCommand runs the sync-filters subcommand
+func syncFiltersCommand(args []string) {
+	flags := flag.NewFlagSet("sync-filters", flag.ExitOnError)
+	configPath := flags.String("config", defaultConfigPath(), "Path to the rules configuration file")
+	dryRun := flags.Bool("dry-run", false, "Show the feeds that would change without updating them")
+	flags.Parse(args)
+
+	logger := commandLogger(os.Stdout)
+
+	config, err := LoadConfig(*configPath)
+	if err != nil {
+		fatal(logger, "Failed to load config", "error", err)
+	}
+
+	apiKey, err := GetAPIKey()
+	if err != nil {
+		fatal(logger, "Failed to get API key", "error", err)
+	}
+	client := NewClientWrapper(config.MinifluxURL, apiKey, config.MaxResponseSize)
+
+	updated, skipped, err := syncFilters(client, config.Rules, config.Macros, *dryRun, logger)
+	if err != nil {
+		fatal(logger, "Failed to sync filters", "error", err)
+	}
+	if len(skipped) > 0 {
+		logger.Info("Rules left to miniflux-jobs", "rules", strings.Join(skipped, ", "))
+	}
+	logger.Info("Synced rules to feeds", "synced", len(config.Rules)-len(skipped), "rules", len(config.Rules), "feeds", updated)
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"log/slog"
+	"os"
+	"slices"
+	"testing"
+
+	miniflux "miniflux.app/v2/client"
+)
+
+func TestTranslateRule(t *testing.T) {
+	tests := []struct {
+		name     string
+		rule     Rule
+		expected string
+	}{
+		{"title", Rule{Title: "(?i)sponsored", Action: "remove"}, "EntryTitle=(?i)sponsored"},
+		{"scoped content", Rule{Feed: "Blog", Content: "giveaway", Action: "read"}, "EntryContent=giveaway"},
+		{"authors", Rule{Authors: []string{"Jane Doe", "A.N. Other"}, Action: "remove"}, `EntryAuthor=(?i)^(Jane Doe|A\.N\. Other)$`},
+		{"star action", Rule{Title: "Release", Action: "star"}, ""},
+		{"two conditions", Rule{Title: "Deal", Content: "coupon", Action: "remove"}, ""},
+		{"feed only", Rule{Feed: "Blog", Action: "read"}, ""},
+		{"client-side condition", Rule{Title: "Deal", MinWords: 50, Action: "remove"}, ""},
+		{"read entries", Rule{Title: "Deal", Status: StringList{"read"}, Action: "remove"}, ""},
+	}
+
+	for _, tt := range tests {
+		line, ok := translateRule(&tt.rule, nil)
+		if ok != (tt.expected != "") || line != tt.expected {
+			t.Errorf("%s: expected %q, got %q (ok=%v)", tt.name, tt.expected, line, ok)
+		}
+	}
+}
+
+func TestReplaceFilterSection(t *testing.T) {
+	existing := "EntryURL=example\\.com/ads\n# miniflux-jobs begin\nEntryTitle=old\n# miniflux-jobs end"
+
+	got := replaceFilterSection(existing, []string{"EntryTitle=new"})
+	expected := "EntryURL=example\\.com/ads\n# miniflux-jobs begin\nEntryTitle=new\n# miniflux-jobs end"
+	if got != expected {
+		t.Errorf("Expected %q, got %q", expected, got)
+	}
+
+	if got := replaceFilterSection(existing, nil); got != "EntryURL=example\\.com/ads" {
+		t.Errorf("Expected the managed section to be dropped, got %q", got)
+	}
+}
+
+func TestSyncFilters(t *testing.T) {
+	mockClient := &MockClient{
+		feeds: []*miniflux.Feed{
+			{ID: 1, Title: "Tech Blog", Category: &miniflux.Category{ID: 2, Title: "Tech"}},
+			{ID: 2, Title: "Deals", Category: &miniflux.Category{ID: 3, Title: "Shopping"}, BlockFilterEntryRules: "EntryURL=/ads/"},
+			{ID: 3, Title: "News", BlockFilterEntryRules: "EntryTitle=(?i)sponsored"},
+		},
+	}
+	rules := []Rule{
+		{Name: "Sponsored", Title: "(?i)sponsored", Action: "remove"},
+		{Name: "Coupons", Category: "Shopping", Content: "coupon", Action: "read"},
+		{Name: "Star releases", Title: "Release", Action: "star"},
+	}
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+
+	updated, skipped, err := syncFilters(mockClient, rules, nil, false, logger)
+	if err != nil {
+		t.Fatalf("syncFilters failed: %v", err)
+	}
+	if !slices.Equal(skipped, []string{"Star releases"}) {
+		t.Errorf("Expected only 'Star releases' to be skipped, got %v", skipped)
+	}
+	if updated != 3 {
+		t.Errorf("Expected 3 feeds updated, got %d", updated)
+	}
+
+	expected := map[int64]string{
+		1: "# miniflux-jobs begin\nEntryTitle=(?i)sponsored\n# miniflux-jobs end",
+		2: "EntryURL=/ads/\n# miniflux-jobs begin\nEntryTitle=(?i)sponsored\nEntryContent=coupon\n# miniflux-jobs end",
+		3: "EntryTitle=(?i)sponsored\n# miniflux-jobs begin\nEntryTitle=(?i)sponsored\n# miniflux-jobs end",
+	}
+	for feedID, rules := range expected {
+		if got := mockClient.blockRules[feedID]; got != rules {
+			t.Errorf("Feed %d: expected block rules %q, got %q", feedID, rules, got)
+		}
+	}
+
+	// A second sync against the updated feeds changes nothing
+	for _, feed := range mockClient.feeds {
+		feed.BlockFilterEntryRules = mockClient.blockRules[feed.ID]
+	}
+	mockClient.blockRules = nil
+	if updated, _, _ := syncFilters(mockClient, rules, nil, false, logger); updated != 0 || mockClient.blockRules != nil {
+		t.Errorf("Expected no updates on the second sync, got %d: %v", updated, mockClient.blockRules)
+	}
+}
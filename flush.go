@@ -0,0 +1,103 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	miniflux "miniflux.app/v2/client"
+)
+
+// defaultFlushHistoryInterval applies when flush_history omits an interval
+const defaultFlushHistoryInterval = 24 * time.Hour
+
+// flushBatchSize is how many entries are removed per API request
+const flushBatchSize = 100
+
+// FlushHistoryConfig schedules whole-history housekeeping of read entries
+type FlushHistoryConfig struct {
+	Enabled   bool          `yaml:"enabled"`
+	Interval  time.Duration `yaml:"interval"`   // time between flushes (default 24h)
+	OlderThan time.Duration `yaml:"older_than"` // remove only read entries published longer ago, e.g. 720h (0 = flush all read entries)
+}
+
+// interval returns the flush interval, applying the default
+func (c FlushHistoryConfig) interval() time.Duration {
+	if c.Interval == 0 {
+		return defaultFlushHistoryInterval
+	}
+	return c.Interval
+}
+
+// flushHistory removes read entries once per configured interval
+// Starred entries are kept, as Miniflux does when flushing history
+func (p *Processor) flushHistory() {
+	flush := p.options.FlushHistory
+	if p.state == nil || !flush.Enabled {
+		return
+	}
+
+	now := p.now()
+	if !p.state.LastFlushHistory.IsZero() && now.Sub(p.state.LastFlushHistory) < flush.interval() {
+		return
+	}
+
+	if flush.OlderThan == 0 {
+		if p.dryRun {
+			p.logger.Info("Dry run: would flush read entries from history")
+			return
+		}
+		if err := p.client.FlushHistory(); err != nil {
+			p.logger.Error("Failed to flush history", "error", err)
+			return
+		}
+		p.logger.Info("Flushed read entries from history")
+	} else {
+		removed, err := p.removeReadBefore(now.Add(-flush.OlderThan))
+		if err != nil {
+			p.logger.Error("Failed to flush history", "error", err)
+			return
+		}
+		if p.dryRun {
+			p.logger.Info("Dry run: would remove read entries from history", "entries", removed, "older_than", flush.OlderThan)
+			return
+		}
+		p.logger.Info("Removed read entries from history", "entries", removed, "older_than", flush.OlderThan)
+	}
+
+	p.state.LastFlushHistory = now
+}
+
+// removeReadBefore removes unstarred read entries published before the cutoff
+// All IDs are collected first, since removing entries shifts later pages
+func (p *Processor) removeReadBefore(cutoff time.Time) (int, error) {
+	filter := &miniflux.Filter{
+		Limit:           flushBatchSize,
+		Status:          miniflux.EntryStatusRead,
+		Starred:         miniflux.FilterNotStarred,
+		PublishedBefore: cutoff.Unix(),
+	}
+
+	var ids []int64
+	err := p.fetchSequential(filter, func(page []*miniflux.Entry) bool {
+		for _, entry := range page {
+			if entry.Status == miniflux.EntryStatusRead && !entry.Starred && entry.Date.Before(cutoff) {
+				ids = append(ids, entry.ID)
+			}
+		}
+		return true
+	})
+	if err != nil {
+		return 0, err
+	}
+	if p.dryRun {
+		return len(ids), nil
+	}
+
+	for start := 0; start < len(ids); start += flushBatchSize {
+		batch := ids[start:min(start+flushBatchSize, len(ids))]
+		if err := p.client.UpdateEntries(batch, miniflux.EntryStatusRemoved); err != nil {
+			return start, fmt.Errorf("failed to remove entries: %w", err)
+		}
+	}
+	return len(ids), nil
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	miniflux "miniflux.app/v2/client"
+)
+
+func TestProcessorFlushHistory(t *testing.T) {
+	mockClient := &MockClient{}
+
+	state, err := LoadState(filepath.Join(t.TempDir(), "state.json"))
+	if err != nil {
+		t.Fatalf("Failed to load state: %v", err)
+	}
+	matcher, err := NewMatcher(nil)
+	if err != nil {
+		t.Fatalf("Failed to create matcher: %v", err)
+	}
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	processor := NewProcessor(mockClient, matcher, logger, false)
+	processor.SetState(state)
+	processor.SetOptions(ProcessorOptions{FlushHistory: FlushHistoryConfig{Enabled: true}})
+
+	// The second run falls within the interval and does not flush again
+	for range 2 {
+		if _, err := processor.Process(); err != nil {
+			t.Fatalf("Process failed: %v", err)
+		}
+	}
+	if mockClient.flushed != 1 {
+		t.Errorf("Expected history to be flushed once, got %d", mockClient.flushed)
+	}
+	if state.LastFlushHistory.IsZero() {
+		t.Error("Expected the flush time to be recorded")
+	}
+}
+
+func TestProcessorFlushHistoryOlderThan(t *testing.T) {
+	old := time.Now().Add(-60 * 24 * time.Hour)
+	mockClient := &MockClient{
+		entries: []*miniflux.Entry{
+			{ID: 1, Status: miniflux.EntryStatusRead, Date: old},
+			{ID: 2, Status: miniflux.EntryStatusRead, Date: time.Now()},
+			{ID: 3, Status: miniflux.EntryStatusRead, Date: old, Starred: true},
+			{ID: 4, Status: miniflux.EntryStatusUnread, Date: old},
+		},
+	}
+
+	state, err := LoadState(filepath.Join(t.TempDir(), "state.json"))
+	if err != nil {
+		t.Fatalf("Failed to load state: %v", err)
+	}
+	matcher, err := NewMatcher(nil)
+	if err != nil {
+		t.Fatalf("Failed to create matcher: %v", err)
+	}
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	processor := NewProcessor(mockClient, matcher, logger, false)
+	processor.SetState(state)
+	processor.SetOptions(ProcessorOptions{
+		FlushHistory: FlushHistoryConfig{Enabled: true, OlderThan: 30 * 24 * time.Hour},
+	})
+
+	if _, err := processor.Process(); err != nil {
+		t.Fatalf("Process failed: %v", err)
+	}
+	if mockClient.flushed != 0 {
+		t.Error("Expected the flush endpoint not to be used with older_than")
+	}
+	if !slices.Equal(mockClient.updatedIDs, []int64{1}) || mockClient.updatedStatus != miniflux.EntryStatusRemoved {
+		t.Errorf("Expected only entry 1 to be removed, got %v (%s)", mockClient.updatedIDs, mockClient.updatedStatus)
+	}
+}
+
+func TestProcessorFlushHistoryDryRun(t *testing.T) {
+	mockClient := &MockClient{}
+
+	state, err := LoadState(filepath.Join(t.TempDir(), "state.json"))
+	if err != nil {
+		t.Fatalf("Failed to load state: %v", err)
+	}
+	matcher, err := NewMatcher(nil)
+	if err != nil {
+		t.Fatalf("Failed to create matcher: %v", err)
+	}
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	processor := NewProcessor(mockClient, matcher, logger, true)
+	processor.SetState(state)
+	processor.SetOptions(ProcessorOptions{FlushHistory: FlushHistoryConfig{Enabled: true}})
+
+	if _, err := processor.Process(); err != nil {
+		t.Fatalf("Process failed: %v", err)
+	}
+	if mockClient.flushed != 0 || !state.LastFlushHistory.IsZero() {
+		t.Errorf("Expected no flush in dry run, got %d flushes", mockClient.flushed)
+	}
+}
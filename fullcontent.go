@@ -0,0 +1,60 @@
+package main
+
+import (
+	"log/slog"
+	"sync"
+
+	miniflux "miniflux.app/v2/client"
+)
+
+// maxFullContentCache bounds how many original articles are kept in memory
+const maxFullContentCache = 1000
+
+// contentFetcher retrieves an entry's original article through the Miniflux scraper
+type contentFetcher interface {
+	FetchEntryOriginalContent(entryID int64) (string, error)
+}
+
+// FullContent provides the original article of entries whose feed only carries a summary
+// Articles are fetched by Miniflux, which applies the feed's scraper rules, and cached by entry
+type FullContent struct {
+	client contentFetcher
+	logger *slog.Logger
+
+	mu    sync.Mutex
+	cache map[int64]string
+}
+
+// NewFullContent creates a FullContent fetching through the given client
+func NewFullContent(client contentFetcher, logger *slog.Logger) *FullContent {
+	return &FullContent{
+		client: client,
+		logger: logger,
+		cache:  make(map[int64]string),
+	}
+}
+
+// Content returns the entry's original article, or its feed content if the article cannot be fetched
+func (f *FullContent) Content(entry *miniflux.Entry) string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if content, ok := f.cache[entry.ID]; ok {
+		return content
+	}
+
+	content, err := f.client.FetchEntryOriginalContent(entry.ID)
+	if err != nil || content == "" {
+		if err != nil {
+			f.logger.Warn("Failed to fetch original content", "entry_id", entry.ID, "error", err)
+		}
+		return entry.Content
+	}
+
+	// The cache is dropped rather than evicted piecemeal; it only saves repeated fetches
+	if len(f.cache) >= maxFullContentCache {
+		clear(f.cache)
+	}
+	f.cache[entry.ID] = content
+	return content
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	miniflux "miniflux.app/v2/client"
+)
+
+func TestMatcherFetchFullContent(t *testing.T) {
+	mockClient := &MockClient{
+		original: map[int64]string{
+			1: "<p>Full article. This post is sponsored by Acme.</p>",
+			2: "<p>Full article with nothing to hide.</p>",
+		},
+	}
+
+	matcher, err := NewMatcher([]Rule{
+		{Name: "Sponsored", Title: "Review", Content: "(?i)sponsored by", FetchFullContent: true, Action: "read"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create matcher: %v", err)
+	}
+	matcher.SetFullContent(NewFullContent(mockClient, slog.New(slog.NewTextHandler(os.Stdout, nil))))
+
+	sponsored := &miniflux.Entry{ID: 1, Title: "Review: a phone", Content: "<p>A short summary.</p>"}
+	clean := &miniflux.Entry{ID: 2, Title: "Review: a laptop", Content: "<p>A short summary.</p>"}
+	other := &miniflux.Entry{ID: 3, Title: "News", Content: "<p>A short summary.</p>"}
+
+	if !matcher.Match(sponsored).Matched {
+		t.Error("Expected the original article to match the content pattern")
+	}
+	if matcher.Match(clean).Matched {
+		t.Error("Expected the clean article not to match")
+	}
+	if matcher.Match(other).Matched {
+		t.Error("Expected the title condition to rule out entry 3")
+	}
+	matcher.Match(sponsored)
+
+	// Entry 3 fails the title before its content is needed, and entry 1 is cached
+	if mockClient.contentFetches != 2 {
+		t.Errorf("Expected 2 original content fetches, got %d", mockClient.contentFetches)
+	}
+}
+
+func TestFullContentFallsBackToFeedContent(t *testing.T) {
+	mockClient := &MockClient{entriesErr: errors.New("scraper failed")}
+	fullContent := NewFullContent(mockClient, slog.New(slog.NewTextHandler(os.Stdout, nil)))
+
+	entry := &miniflux.Entry{ID: 1, Content: "<p>A short summary.</p>"}
+	if got := fullContent.Content(entry); got != entry.Content {
+		t.Errorf("Expected the feed content on failure, got %q", got)
+	}
+}
+
+func TestLoadConfigFetchFullContent(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "rules.yaml")
+	content := "miniflux_url: https://miniflux.example.com\nrules:\n  - name: Summary only\n    title: Review\n    fetch_full_content: true\n    action: read\n"
+	if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	_, err := LoadConfig(configPath)
+	if err == nil || !strings.Contains(err.Error(), "fetch_full_content requires content or content_pattern") {
+		t.Errorf("Expected fetch_full_content without a content pattern to be rejected, got %v", err)
+	}
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// defaultHealthGrace is how late a scheduled run may be before /healthz fails, when health_grace is unset
+const defaultHealthGrace = 10 * time.Minute
+
+// readyTimeout bounds the Miniflux requests made by /readyz
+const readyTimeout = 5 * time.Second
+
+// healthCheck answers the liveness and readiness probes of the daemon
+// An instance is unhealthy once its next run is overdue by more than the grace period, which
+// catches a loop stuck in a run; it is ready when its Miniflux server accepts its API key.
+type healthCheck struct {
+	instances []*instance
+	schedule  loopSchedule // nil in single-run mode, where runs are never due
+	grace     time.Duration
+	started   time.Time
+	now       func() time.Time
+}
+
+// newHealthCheck creates the health checks of the daemon's instances
+func newHealthCheck(instances []*instance, schedule loopSchedule, grace time.Duration) *healthCheck {
+	if grace == 0 {
+		grace = defaultHealthGrace
+	}
+	return &healthCheck{
+		instances: instances,
+		schedule:  schedule,
+		grace:     grace,
+		started:   time.Now(),
+		now:       time.Now,
+	}
+}
+
+// register adds the probe routes to the server's routes
+func (h *healthCheck) register(mux *http.ServeMux) {
+	mux.HandleFunc("GET /healthz", h.handleHealth)
+	mux.HandleFunc("GET /readyz", h.handleReady)
+}
+
+// HealthResponse is returned by GET /healthz
+type HealthResponse struct {
+	Status    string           `json:"status"` // "ok" or "stale"
+	Instances []InstanceHealth `json:"instances"`
+}
+
+// InstanceHealth reports the runs of an instance
+type InstanceHealth struct {
+	Instance    string     `json:"instance,omitempty"` // empty for the account of miniflux_url
+	LastRun     *time.Time `json:"last_run,omitempty"`
+	LastSuccess *time.Time `json:"last_success,omitempty"`
+	NextRunDue  *time.Time `json:"next_run_due,omitempty"`
+	Stale       bool       `json:"stale"`
+}
+
+// handleHealth reports the last runs of each instance, failing when one of them is overdue
+func (h *healthCheck) handleHealth(w http.ResponseWriter, r *http.Request) {
+	now := h.now()
+	response := HealthResponse{Status: "ok", Instances: []InstanceHealth{}}
+	for _, inst := range h.instances {
+		health := InstanceHealth{Instance: inst.name}
+
+		// The next run is due a period after the last one finished, or after startup
+		since := h.started
+		if run := inst.runs.latest(); run != nil {
+			health.LastRun = &run.finished
+			since = run.finished
+		}
+		if succeeded := inst.runs.succeeded(); !succeeded.IsZero() {
+			health.LastSuccess = &succeeded
+		}
+		if h.schedule != nil {
+			due := h.schedule.Next(since)
+			health.NextRunDue = &due
+			health.Stale = now.After(due.Add(h.grace))
+		}
+
+		if health.Stale {
+			response.Status = "stale"
+		}
+		response.Instances = append(response.Instances, health)
+	}
+
+	status := http.StatusOK
+	if response.Status != "ok" {
+		status = http.StatusServiceUnavailable
+	}
+	writeJSONResponse(w, status, response)
+}
+
+// ReadyResponse is returned by GET /readyz
+type ReadyResponse struct {
+	Status    string          `json:"status"` // "ready" or "unavailable"
+	Instances []InstanceReady `json:"instances"`
+}
+
+// InstanceReady reports whether an instance can reach its Miniflux server
+type InstanceReady struct {
+	Instance string `json:"instance,omitempty"`
+	Ready    bool   `json:"ready"`
+	Error    string `json:"error,omitempty"`
+}
+
+// handleReady checks that each instance's Miniflux server is reachable and accepts its API key
+func (h *healthCheck) handleReady(w http.ResponseWriter, r *http.Request) {
+	response := ReadyResponse{Status: "ready", Instances: []InstanceReady{}}
+	for _, inst := range h.instances {
+		ready := InstanceReady{Instance: inst.name, Ready: true}
+		if err := checkMiniflux(inst.processor.client); err != nil {
+			ready.Ready, ready.Error = false, err.Error()
+			response.Status = "unavailable"
+		}
+		response.Instances = append(response.Instances, ready)
+	}
+
+	status := http.StatusOK
+	if response.Status != "ready" {
+		status = http.StatusServiceUnavailable
+	}
+	writeJSONResponse(w, status, response)
+}
+
+// checkMiniflux fetches the API key's user, giving up after readyTimeout
+func checkMiniflux(client MinifluxClient) error {
+	result := make(chan error, 1)
+	go func() {
+		_, err := client.Me()
+		result <- err
+	}()
+	select {
+	case err := <-result:
+		return err
+	case <-time.After(readyTimeout):
+		return fmt.Errorf("no response from Miniflux within %s", readyTimeout)
+	}
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestHealthCheck(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	matcher, err := NewMatcher(nil)
+	if err != nil {
+		t.Fatalf("Failed to create matcher: %v", err)
+	}
+	partnerClient := &MockClient{}
+	instances := []*instance{
+		{processor: NewProcessor(&MockClient{}, matcher, logger, false), logger: logger},
+		{name: "partner", processor: NewProcessor(partnerClient, matcher, logger, false), logger: logger},
+	}
+	schedule, err := newLoopSchedule(&Config{Interval: 300})
+	if err != nil {
+		t.Fatalf("Failed to create schedule: %v", err)
+	}
+
+	start := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	health := newHealthCheck(instances, schedule, 0)
+	health.started = start
+	server := NewServer(instances[0].processor, logger)
+	server.SetHealth(health)
+	handler := server.Handler()
+
+	get := func(path string, response any) int {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		if err := json.NewDecoder(rec.Body).Decode(response); err != nil {
+			t.Fatalf("Failed to decode %s response: %v", path, err)
+		}
+		return rec.Code
+	}
+
+	// Runs are due an interval after startup or the last run, plus the grace period
+	health.now = func() time.Time { return start.Add(14 * time.Minute) }
+	var response HealthResponse
+	if code := get("/healthz", &response); code != http.StatusOK || response.Status != "ok" {
+		t.Errorf("Expected healthy before the first run is overdue, got %d %+v", code, response)
+	}
+
+	finished := start.Add(10 * time.Minute)
+	instances[0].runs.record(&lastRun{started: finished.Add(-time.Second), finished: finished, stats: &ProcessStats{}})
+	instances[1].runs.record(&lastRun{started: finished.Add(-time.Second), finished: finished, stats: &ProcessStats{}, err: errors.New("unreachable")})
+	health.now = func() time.Time { return finished.Add(16 * time.Minute) }
+	if code := get("/healthz", &response); code != http.StatusServiceUnavailable || response.Status != "stale" {
+		t.Errorf("Expected an overdue run to fail the check, got %d %+v", code, response)
+	}
+	if len(response.Instances) != 2 || response.Instances[0].LastSuccess == nil || response.Instances[1].LastSuccess != nil {
+		t.Errorf("Expected the last success of the first instance only, got %+v", response.Instances)
+	}
+	if due := response.Instances[0].NextRunDue; due == nil || !due.Equal(finished.Add(5*time.Minute)) {
+		t.Errorf("Expected the next run due an interval after the last, got %v", due)
+	}
+
+	var ready ReadyResponse
+	if code := get("/readyz", &ready); code != http.StatusOK || ready.Status != "ready" {
+		t.Errorf("Expected ready, got %d %+v", code, ready)
+	}
+	partnerClient.meErr = errors.New("401 unauthorized")
+	if code := get("/readyz", &ready); code != http.StatusServiceUnavailable || ready.Instances[1].Ready || ready.Instances[1].Error == "" {
+		t.Errorf("Expected the partner instance to be unavailable, got %d %+v", code, ready)
+	}
+}
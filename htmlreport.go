@@ -0,0 +1,131 @@
+package main
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"time"
+)
+
+// MatchItem is an entry matched by a rule during a run
+type MatchItem struct {
+	Rule      string
+	Action    string
+	EntryID   int64
+	FeedTitle string
+	Title     string
+	URL       string
+}
+
+// htmlReport is the data rendered into the HTML run report
+type htmlReport struct {
+	Generated time.Time
+	DryRun    bool
+	Stats     *ProcessStats
+	Rules     []htmlReportRule
+	Warnings  []string
+}
+
+// htmlReportRule groups the entries matched by one rule
+type htmlReportRule struct {
+	Name    string
+	Action  string
+	Entries []MatchItem
+}
+
+// htmlReportTemplate renders a self-contained page without external assets
+var htmlReportTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>miniflux-jobs run report</title>
+<style>
+body { font-family: system-ui, sans-serif; max-width: 60rem; margin: 2rem auto; padding: 0 1rem; color: #222; }
+h1 { font-size: 1.5rem; }
+h2 { font-size: 1.15rem; margin-top: 2rem; border-bottom: 1px solid #ddd; padding-bottom: .25rem; }
+table { border-collapse: collapse; }
+td, th { padding: .2rem .8rem .2rem 0; text-align: left; }
+.dry-run { background: #fff3cd; padding: .5rem 1rem; border-radius: 4px; }
+.warnings li { color: #a94442; }
+.action { color: #666; font-weight: normal; }
+.feed { color: #666; }
+</style>
+</head>
+<body>
+<h1>miniflux-jobs run report</h1>
+<p>Generated {{.Generated.Format "2006-01-02 15:04:05 MST"}}{{with .Stats.ConfigHash}} with config hash <code>{{.}}</code>{{end}}</p>
+{{if .DryRun}}<p class="dry-run">Dry run: the actions below were proposed, not applied.</p>{{end}}
+{{if .Warnings}}<h2>Warnings</h2>
+<ul class="warnings">{{range .Warnings}}
+<li>{{.}}</li>{{end}}
+</ul>{{end}}
+<h2>Summary</h2>
+<table>
+<tr><th>Entries checked</th><td>{{.Stats.TotalEntries}}</td></tr>
+<tr><th>Matched</th><td>{{.Stats.MatchedEntries}}</td></tr>
+<tr><th>Marked read</th><td>{{.Stats.MarkedRead}}</td></tr>
+<tr><th>Marked unread</th><td>{{.Stats.MarkedUnread}}</td></tr>
+<tr><th>Removed</th><td>{{.Stats.Removed}}</td></tr>
+<tr><th>Quarantined</th><td>{{.Stats.Quarantined}}</td></tr>
+<tr><th>Starred</th><td>{{.Stats.Starred}}</td></tr>
+<tr><th>Unstarred</th><td>{{.Stats.Unstarred}}</td></tr>
+<tr><th>Saved</th><td>{{.Stats.Saved}}</td></tr>
+<tr><th>Notified</th><td>{{.Stats.Notified}}</td></tr>
+<tr><th>Retitled</th><td>{{.Stats.Retitled}}</td></tr>
+<tr><th>Errors</th><td>{{.Stats.Errors}}</td></tr>
+</table>
+{{range .Rules}}
+<h2>{{.Name}} <span class="action">{{.Action}} &middot; {{len .Entries}} entries</span></h2>
+<ul>{{range .Entries}}
+<li>{{if .URL}}<a href="{{.URL}}">{{.Title}}</a>{{else}}{{.Title}}{{end}} <span class="feed">{{.FeedTitle}}</span></li>{{end}}
+</ul>{{end}}
+</body>
+</html>
+`))
+
+// buildHTMLReport groups matches by rule and collects warnings about the run
+func buildHTMLReport(stats *ProcessStats, runErr error, dryRun bool, generated time.Time) htmlReport {
+	report := htmlReport{Generated: generated, DryRun: dryRun, Stats: stats}
+
+	if runErr != nil {
+		report.Warnings = append(report.Warnings, fmt.Sprintf("Run failed: %v", runErr))
+	}
+	if stats.SkippedPages > 0 {
+		report.Warnings = append(report.Warnings, fmt.Sprintf("%d pages of entries could not be fetched and were skipped", stats.SkippedPages))
+	}
+	if stats.Errors > 0 {
+		report.Warnings = append(report.Warnings, fmt.Sprintf("%d actions failed, see the log for details", stats.Errors))
+	}
+	for _, key := range sortedAPIErrorKeys(stats.APIErrors) {
+		report.Warnings = append(report.Warnings, fmt.Sprintf("%d API errors: %s", stats.APIErrors[key], key))
+	}
+	for _, action := range stats.FeedActions {
+		if action.Action == "disable_feed" {
+			report.Warnings = append(report.Warnings, fmt.Sprintf("Feed '%s' (%d) was disabled by rule '%s'", action.FeedTitle, action.FeedID, action.Rule))
+		}
+	}
+	for _, alert := range stats.FeedAlerts {
+		report.Warnings = append(report.Warnings, fmt.Sprintf(
+			"Feed '%s' (%d): %d of %d entries matched aggregate '%s' (%s)",
+			alert.FeedTitle, alert.FeedID, alert.Matched, alert.Total, alert.Aggregate, alert.Action,
+		))
+	}
+
+	index := make(map[string]int)
+	for _, match := range stats.Matches {
+		i, ok := index[match.Rule]
+		if !ok {
+			i = len(report.Rules)
+			index[match.Rule] = i
+			report.Rules = append(report.Rules, htmlReportRule{Name: match.Rule, Action: match.Action})
+		}
+		report.Rules[i].Entries = append(report.Rules[i].Entries, match)
+	}
+
+	return report
+}
+
+// writeHTMLReport renders the run report as a self-contained HTML page
+func writeHTMLReport(w io.Writer, stats *ProcessStats, runErr error, dryRun bool, generated time.Time) error {
+	return htmlReportTemplate.Execute(w, buildHTMLReport(stats, runErr, dryRun, generated))
+}
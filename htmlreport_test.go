@@ -0,0 +1,76 @@
+package main
+
+import (
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	miniflux "miniflux.app/v2/client"
+)
+
+func TestHTMLReport(t *testing.T) {
+	mockClient := &MockClient{
+		entries: []*miniflux.Entry{
+			{ID: 1, Title: "Sponsored <deal>", URL: "https://example.com/deal", Feed: &miniflux.Feed{Title: "Blog"}},
+			{ID: 2, Title: "Regular post"},
+		},
+	}
+
+	matcher, err := NewMatcher([]Rule{
+		{Name: "Sponsored", Title: "Sponsored", Action: "remove"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create matcher: %v", err)
+	}
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	processor := NewProcessor(mockClient, matcher, logger, true)
+
+	stats, err := processor.Process()
+	if err != nil {
+		t.Fatalf("Process failed: %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "report.html")
+	if err := saveReport(path, reportHTML, stats, errors.New("change budget exceeded"), true); err != nil {
+		t.Fatalf("Failed to save report: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read report: %v", err)
+	}
+	html := string(data)
+
+	for _, expected := range []string{
+		"Dry run: the actions below were proposed",
+		"Run failed: change budget exceeded",
+		`<h2>Sponsored <span class="action">remove &middot; 1 entries</span></h2>`,
+		`<a href="https://example.com/deal">Sponsored &lt;deal&gt;</a>`,
+	} {
+		if !strings.Contains(html, expected) {
+			t.Errorf("Expected report to contain %q", expected)
+		}
+	}
+}
+
+func TestBuildHTMLReportGroupsByRule(t *testing.T) {
+	stats := &ProcessStats{
+		Matches: []MatchItem{
+			{Rule: "A", Action: "read", EntryID: 1},
+			{Rule: "B", Action: "remove", EntryID: 2},
+			{Rule: "A", Action: "read", EntryID: 3},
+		},
+	}
+
+	report := buildHTMLReport(stats, nil, false, time.Now())
+	if len(report.Rules) != 2 || report.Rules[0].Name != "A" || len(report.Rules[0].Entries) != 2 {
+		t.Errorf("Unexpected grouping: %+v", report.Rules)
+	}
+	if len(report.Warnings) != 0 {
+		t.Errorf("Expected no warnings, got %v", report.Warnings)
+	}
+}
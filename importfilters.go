@@ -0,0 +1,155 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// simpleRule is the YAML shape of a rule with one pattern condition, as written by
+// import-filters and rule add
+type simpleRule struct {
+	Name        string  `yaml:"name"`
+	Description string  `yaml:"description,omitempty"`
+	FeedID      []int64 `yaml:"feed_id,flow,omitempty"`
+	Title       string  `yaml:"title,omitempty"`
+	Content     string  `yaml:"content,omitempty"`
+	Author      string  `yaml:"author,omitempty"`
+	CommentsURL string  `yaml:"comments_url,omitempty"`
+	Action      string  `yaml:"action"`
+}
+
+// importedFilters holds the rules converted from the feeds' filters and the lines left behind
+type importedFilters struct {
+	Rules   []simpleRule
+	Skipped []string // "feed N: line" descriptions of filter lines with no rule equivalent
+}
+
+// importFilters converts the block filter rules of each feed into rules removing the same entries
+// A pattern blocked on several feeds becomes a single rule listing them. Lines managed by
+// sync-filters are already rules and left out. Keep filters block entries matching none of
+// their lines, which no rule condition expresses, so they are reported as skipped like the
+// EntryURL, EntryTag and EntryDate lines rules have no condition for.
+func importFilters(client MinifluxClient) (*importedFilters, error) {
+	feeds, err := client.Feeds()
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch feeds: %w", err)
+	}
+
+	imported := &importedFilters{}
+	byLine := make(map[string]int) // filter line to its rule's index
+	for _, feed := range feeds {
+		managed := false
+		for _, line := range filterLines(feed.BlockFilterEntryRules) {
+			switch line {
+			case filterSectionBegin:
+				managed = true
+				continue
+			case filterSectionEnd:
+				managed = false
+				continue
+			}
+			// Miniflux ignores lines without "=", such as comments
+			filterType, pattern, ok := strings.Cut(line, "=")
+			if managed || !ok {
+				continue
+			}
+			filterType, pattern = strings.TrimSpace(filterType), strings.TrimSpace(pattern)
+			rule := simpleRule{Action: "remove"}
+			switch filterType {
+			case "EntryTitle":
+				rule.Title = pattern
+			case "EntryContent":
+				rule.Content = pattern
+			case "EntryAuthor":
+				rule.Author = pattern
+			case "EntryCommentsURL":
+				rule.CommentsURL = pattern
+			default:
+				ok = false
+			}
+			if !ok || pattern == "" {
+				imported.Skipped = append(imported.Skipped, fmt.Sprintf("feed %d: %s", feed.ID, line))
+				continue
+			}
+
+			key := filterType + "=" + pattern
+			if i, ok := byLine[key]; ok {
+				imported.Rules[i].FeedID = append(imported.Rules[i].FeedID, feed.ID)
+				imported.Rules[i].Description = fmt.Sprintf("Imported from the Miniflux block filter %s of %d feeds", key, len(imported.Rules[i].FeedID))
+				continue
+			}
+			byLine[key] = len(imported.Rules)
+			rule.Name = fmt.Sprintf("Imported filter %d", len(imported.Rules)+1)
+			rule.Description = fmt.Sprintf("Imported from the Miniflux block filter %s of feed %s", key, feed.Title)
+			rule.FeedID = []int64{feed.ID}
+			imported.Rules = append(imported.Rules, rule)
+		}
+
+		for _, line := range filterLines(feed.KeepFilterEntryRules) {
+			if !strings.Contains(line, "=") {
+				continue
+			}
+			imported.Skipped = append(imported.Skipped, fmt.Sprintf("feed %d: keep %s", feed.ID, line))
+		}
+	}
+	return imported, nil
+}
+
+// filterLines returns the non-empty lines of a feed's filter rules
+func filterLines(rules string) []string {
+	var lines []string
+	for _, line := range strings.Split(strings.ReplaceAll(rules, "\r\n", "\n"), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// writeImportedRules writes the imported rules as YAML, ready to include or merge into the config
+func writeImportedRules(w io.Writer, rules []simpleRule) error {
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(map[string][]simpleRule{"rules": rules}); err != nil {
+		return err
+	}
+	return encoder.Close()
+}
+
+// importFiltersCommand runs the import-filters subcommand, printing the feeds' block filters as rules:
+// miniflux-jobs import-filters > rules.d/imported.yaml
+func importFiltersCommand(args []string) {
+	flags := flag.NewFlagSet("import-filters", flag.ExitOnError)
+	configPath := flags.String("config", defaultConfigPath(), "Path to the rules configuration file")
+	flags.Parse(args)
+
+	logger := commandLogger(os.Stderr)
+
+	config, err := LoadConfig(*configPath)
+	if err != nil {
+		fatal(logger, "Failed to load config", "error", err)
+	}
+
+	apiKey, err := GetAPIKey()
+	if err != nil {
+		fatal(logger, "Failed to get API key", "error", err)
+	}
+	client := NewClientWrapper(config.MinifluxURL, apiKey, config.MaxResponseSize)
+
+	imported, err := importFilters(client)
+	if err != nil {
+		fatal(logger, "Failed to import filters", "error", err)
+	}
+	for _, skipped := range imported.Skipped {
+		logger.Warn("No rule equivalent, left out", "filter", skipped)
+	}
+	if err := writeImportedRules(os.Stdout, imported.Rules); err != nil {
+		fatal(logger, "Failed to write rules", "error", err)
+	}
+	logger.Info("Imported filters", "rules", len(imported.Rules), "skipped", len(imported.Skipped))
+}
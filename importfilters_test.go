@@ -0,0 +1,52 @@
+package main
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	miniflux "miniflux.app/v2/client"
+)
+
+func TestImportFilters(t *testing.T) {
+	mockClient := &MockClient{
+		feeds: []*miniflux.Feed{
+			{ID: 1, Title: "Tech Blog", BlockFilterEntryRules: "EntryTitle=(?i)sponsored\r\nEntryAuthor=Ads Team\n# promos\nEntryURL=/ads/"},
+			{ID: 2, Title: "News", BlockFilterEntryRules: "EntryTitle=(?i)sponsored\n# miniflux-jobs begin\nEntryContent=coupon\n# miniflux-jobs end"},
+			{ID: 3, Title: "Podcasts", KeepFilterEntryRules: "EntryTitle=Episode"},
+		},
+	}
+
+	imported, err := importFilters(mockClient)
+	if err != nil {
+		t.Fatalf("importFilters failed: %v", err)
+	}
+	if len(imported.Rules) != 2 {
+		t.Fatalf("Expected 2 rules, got %+v", imported.Rules)
+	}
+	if rule := imported.Rules[0]; rule.Title != "(?i)sponsored" || !slices.Equal(rule.FeedID, []int64{1, 2}) || rule.Action != "remove" {
+		t.Errorf("Expected the shared title filter as one rule for both feeds, got %+v", rule)
+	}
+	if rule := imported.Rules[1]; rule.Author != "Ads Team" || !slices.Equal(rule.FeedID, []int64{1}) {
+		t.Errorf("Expected the author filter of feed 1, got %+v", rule)
+	}
+	if expected := []string{"feed 1: EntryURL=/ads/", "feed 3: keep EntryTitle=Episode"}; !slices.Equal(imported.Skipped, expected) {
+		t.Errorf("Expected skipped lines %v, got %v", expected, imported.Skipped)
+	}
+
+	// The output is a valid rules file
+	var out strings.Builder
+	if err := writeImportedRules(&out, imported.Rules); err != nil {
+		t.Fatalf("Failed to write rules: %v", err)
+	}
+	var included ruleFile
+	if err := unmarshalConfig([]byte(out.String()), formatYAML, "", &included); err != nil {
+		t.Fatalf("Failed to parse the imported rules: %v\n%s", err, out.String())
+	}
+	if _, err := NewMatcher(included.Rules); err != nil {
+		t.Errorf("Failed to compile the imported rules: %v", err)
+	}
+	if !slices.Equal(included.Rules[0].FeedID, IDList{1, 2}) {
+		t.Errorf("Expected feed IDs 1 and 2, got %v", included.Rules[0].FeedID)
+	}
+}
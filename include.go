@@ -0,0 +1,101 @@
+package main
+
+import (
+	"fmt"
+	"hash"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ruleFile is a file of rules merged into the config by include or rules_dir
+type ruleFile struct {
+	Rules []Rule `yaml:"rules"`
+}
+
+// loadIncludes appends the rules of included files to the config's own rules
+// Files are merged in a fixed order: include entries as listed, the matches of each glob
+// sorted by name, then the rules_dir files by name. A rule name defined twice is an error.
+// Each file's content is added to the digest so the config hash covers all rules.
+func (c *Config) loadIncludes(configPath string, digest hash.Hash) error {
+	if isRemoteConfig(configPath) && (len(c.Include) > 0 || c.RulesDir != "") {
+		return fmt.Errorf("include and rules_dir cannot be used with a remote config")
+	}
+	files, err := c.ruleFiles(filepath.Dir(configPath))
+	if err != nil {
+		return err
+	}
+
+	origins := make(map[string]string, len(c.Rules))
+	add := func(rules []Rule, file string) error {
+		for _, rule := range rules {
+			if prev, ok := origins[rule.Name]; ok && rule.Name != "" {
+				return fmt.Errorf("rule '%s' in %s is already defined in %s", rule.Name, file, prev)
+			}
+			origins[rule.Name] = file
+		}
+		return nil
+	}
+	if err := add(c.Rules, configPath); err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return fmt.Errorf("failed to read rules file: %w", err)
+		}
+		format, err := detectConfigFormat(file, "")
+		if err != nil {
+			return fmt.Errorf("rules file %s: %w", file, err)
+		}
+		var included ruleFile
+		if err := unmarshalConfig(data, format, "", &included); err != nil {
+			return fmt.Errorf("failed to parse rules file %s: %w", file, err)
+		}
+		if err := add(included.Rules, file); err != nil {
+			return err
+		}
+
+		c.Rules = append(c.Rules, included.Rules...)
+		c.Sources = append(c.Sources, file)
+		digest.Write(data)
+	}
+	return nil
+}
+
+// ruleFiles lists the included rule files in merge order, resolving paths against dir
+func (c *Config) ruleFiles(dir string) ([]string, error) {
+	resolve := func(path string) string {
+		if filepath.IsAbs(path) {
+			return path
+		}
+		return filepath.Join(dir, path)
+	}
+
+	var files []string
+	for _, pattern := range c.Include {
+		matches, err := filepath.Glob(resolve(pattern))
+		if err != nil {
+			return nil, fmt.Errorf("include '%s': %w", pattern, err)
+		}
+		if len(matches) == 0 && !strings.ContainsAny(pattern, "*?[") {
+			return nil, fmt.Errorf("include '%s': file not found", pattern)
+		}
+		files = append(files, matches...)
+	}
+
+	if c.RulesDir != "" {
+		rulesDir := resolve(c.RulesDir)
+		entries, err := os.ReadDir(rulesDir)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read rules_dir: %w", err)
+		}
+		for _, entry := range entries {
+			if !entry.IsDir() && isRulesFile(entry.Name()) {
+				files = append(files, filepath.Join(rulesDir, entry.Name()))
+			}
+		}
+	}
+	return files, nil
+}
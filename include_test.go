@@ -0,0 +1,80 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadConfigIncludes(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"rules.yaml": "miniflux_url: https://miniflux.example.com\ninclude:\n  - topics/*.yaml\nrules_dir: rules.d\n" +
+			"patterns:\n  promo: (?i)promo\nrules:\n  - name: Main\n    title: Main\n    action: read\n",
+		"topics/b.yaml":   "rules:\n  - name: Topic B\n    title: B\n    action: read\n",
+		"topics/a.yaml":   "rules:\n  - name: Topic A\n    title_pattern: promo\n    action: remove\n",
+		"rules.d/20.yml":  "rules:\n  - name: Dir 20\n    title: Twenty\n    action: star\n",
+		"rules.d/10.yaml": "rules:\n  - name: Dir 10\n    title: Ten\n    action: read\n",
+		"rules.d/notes":   "not rules",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	config, err := LoadConfig(filepath.Join(dir, "rules.yaml"))
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	var names []string
+	for _, rule := range config.Rules {
+		names = append(names, rule.Name)
+	}
+	if got := strings.Join(names, ", "); got != "Main, Topic A, Topic B, Dir 10, Dir 20" {
+		t.Errorf("Expected rules merged in file order, got %s", got)
+	}
+	if config.Rules[1].Title != "(?i)promo" {
+		t.Errorf("Expected included rules to resolve shared patterns, got %q", config.Rules[1].Title)
+	}
+	if len(config.Sources) != 5 {
+		t.Errorf("Expected 5 source files, got %v", config.Sources)
+	}
+
+	// The hash covers the included rules
+	hash := config.Hash
+	if err := os.WriteFile(filepath.Join(dir, "rules.d/20.yml"), []byte("rules:\n  - name: Dir 20\n    title: Twenty\n    action: read\n"), 0644); err != nil {
+		t.Fatalf("Failed to write rules: %v", err)
+	}
+	if config, err = LoadConfig(filepath.Join(dir, "rules.yaml")); err != nil || config.Hash == hash {
+		t.Errorf("Expected a changed included file to change the hash (err %v)", err)
+	}
+
+	// A rule name defined twice is rejected
+	if err := os.WriteFile(filepath.Join(dir, "topics/c.yaml"), []byte("rules:\n  - name: Dir 10\n    title: C\n    action: read\n"), 0644); err != nil {
+		t.Fatalf("Failed to write rules: %v", err)
+	}
+	_, err = LoadConfig(filepath.Join(dir, "rules.yaml"))
+	if err == nil || !strings.Contains(err.Error(), "rule 'Dir 10' in "+filepath.Join(dir, "rules.d/10.yaml")+" is already defined in") {
+		t.Errorf("Expected the duplicate rule name to be rejected, got %v", err)
+	}
+}
+
+func TestLoadConfigMissingInclude(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "rules.yaml")
+	content := "miniflux_url: https://miniflux.example.com\ninclude:\n  - missing.yaml\n  - optional/*.yaml\n"
+	if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	_, err := LoadConfig(configPath)
+	if err == nil || !strings.Contains(err.Error(), "include 'missing.yaml': file not found") {
+		t.Errorf("Expected a missing include to be rejected, got %v", err)
+	}
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// InstanceConfig is a further Miniflux account maintained by the same daemon
+// It shares the rules and settings of the config, runs in the same process and keeps its own state
+type InstanceConfig struct {
+	Name       string     `yaml:"name"`
+	URL        string     `yaml:"url"`          // Miniflux URL of the account
+	APIKey     string     `yaml:"api_key"`      // API key, e.g. ${PARTNER_MINIFLUX_API_KEY}
+	APIKeyFile string     `yaml:"api_key_file"` // file holding the API key, alternative to api_key
+	Rules      StringList `yaml:"rules"`        // rule names or labels applied to the account (default: all rules)
+}
+
+// validateInstances checks the instances and that their names are unique
+func (c *Config) validateInstances() error {
+	names := make(map[string]bool, len(c.Instances))
+	for i, instance := range c.Instances {
+		if instance.Name == "" {
+			return fmt.Errorf("instance %d: name is required", i)
+		}
+		if names[instance.Name] {
+			return fmt.Errorf("instance %d (%s): name is already used", i, instance.Name)
+		}
+		names[instance.Name] = true
+
+		if instance.URL == "" {
+			return fmt.Errorf("instance %d (%s): url is required", i, instance.Name)
+		}
+		if (instance.APIKey == "") == (instance.APIKeyFile == "") {
+			return fmt.Errorf("instance %d (%s): exactly one of api_key or api_key_file is required", i, instance.Name)
+		}
+		if len(instance.Rules) > 0 && len(SelectRules(c.Rules, instance.Rules, nil)) == 0 {
+			return fmt.Errorf("instance %d (%s): rules select no rule", i, instance.Name)
+		}
+	}
+
+	if len(c.Instances) > 0 && c.LeaderElection.Enabled {
+		return fmt.Errorf("instances cannot be used with leader_election")
+	}
+	return nil
+}
+
+// ForInstance returns the config of an instance: its URL, the rules it selects and its own state,
+// kept next to the main state with the instance name added to the file name or key
+func (c *Config) ForInstance(instance InstanceConfig) *Config {
+	derived := *c
+	derived.MinifluxURL = instance.URL
+	derived.Instances = nil
+	if len(instance.Rules) > 0 {
+		derived.Rules = SelectRules(c.Rules, instance.Rules, nil)
+	}
+
+	derived.StateFile = instancePath(c.StateFile, instance.Name)
+	switch c.StateBackend.Type {
+	case "":
+	case backendFile:
+		derived.StateBackend.Path = instancePath(c.StateBackend.Path, instance.Name)
+	default:
+		key := c.StateBackend.Key
+		if key == "" {
+			key = defaultStateKey
+		}
+		derived.StateBackend.Key = key + ":" + instance.Name
+	}
+	return &derived
+}
+
+// instancePath adds the instance name to a file name, e.g. state-partner.json
+func instancePath(path, name string) string {
+	if path == "" || name == "" {
+		return path
+	}
+	ext := filepath.Ext(path)
+	return strings.TrimSuffix(path, ext) + "-" + name + ext
+}
+
+// apiKey returns the API key of the instance
+func (i InstanceConfig) apiKey() (string, error) {
+	if i.APIKey != "" {
+		return i.APIKey, nil
+	}
+	data, err := os.ReadFile(i.APIKeyFile)
+	if err != nil {
+		return "", fmt.Errorf("failed to read API key file: %w", err)
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// instance is a Miniflux account processed by the daemon
+type instance struct {
+	name      string // empty for the account of miniflux_url
+	processor *Processor
+	logger    *slog.Logger
+	store     StateStore
+	runs      runHistory // latest run, served by the admin API
+}
+
+// openInstance sets up the processor of an instance from its derived config,
+// logging with the instance name added to every line
+func openInstance(config *Config, spec InstanceConfig, dryRun bool, logger *slog.Logger) (*instance, error) {
+	logger = logger.With("instance", spec.Name)
+	derived := config.ForInstance(spec)
+
+	apiKey, err := spec.apiKey()
+	if err != nil {
+		return nil, err
+	}
+	client := NewClientWrapper(derived.MinifluxURL, apiKey, derived.MaxResponseSize)
+	client.LogRequests(logger)
+
+	matcher, err := buildMatcher(derived, client, logger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile rules: %w", err)
+	}
+	processor := NewProcessor(client, matcher, logger, dryRun)
+	processor.SetOptions(processorOptions(derived))
+
+	inst := &instance{name: spec.Name, processor: processor, logger: logger}
+	if derived.HasState() {
+		store, err := OpenStateStore(derived)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open state store: %w", err)
+		}
+		state, err := LoadStateFrom(store)
+		if err != nil {
+			store.Close()
+			return nil, fmt.Errorf("failed to load state: %w", err)
+		}
+		processor.SetState(state)
+		inst.store = store
+	}
+	logger.Info("Maintaining instance", "url", derived.MinifluxURL, "rules", len(derived.Rules))
+	return inst, nil
+}
+
+// reportPath returns the report file of the instance, with its name added for further instances
+func (i *instance) reportPath(report reportTarget) string {
+	return instancePath(report.Path, i.name)
+}
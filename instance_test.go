@@ -0,0 +1,111 @@
+package main
+
+import (
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	miniflux "miniflux.app/v2/client"
+)
+
+func TestConfigForInstance(t *testing.T) {
+	config := &Config{
+		MinifluxURL: "https://mine.example.com",
+		StateFile:   "/var/lib/miniflux-jobs/state.json",
+		Rules: []Rule{
+			{Name: "Promos", Labels: []string{"shared"}},
+			{Name: "Sports"},
+		},
+	}
+	spec := InstanceConfig{Name: "partner", URL: "https://partner.example.com", APIKey: "key", Rules: StringList{"shared"}}
+
+	derived := config.ForInstance(spec)
+	if derived.MinifluxURL != spec.URL || derived.StateFile != "/var/lib/miniflux-jobs/state-partner.json" {
+		t.Errorf("Expected the instance URL and its own state file, got %q and %q", derived.MinifluxURL, derived.StateFile)
+	}
+	if len(derived.Rules) != 1 || derived.Rules[0].Name != "Promos" {
+		t.Errorf("Expected only the shared rule, got %+v", derived.Rules)
+	}
+
+	config.StateBackend = StateBackendConfig{Type: backendRedis, URL: "redis://localhost:6379/0"}
+	if key := config.ForInstance(spec).StateBackend.Key; key != defaultStateKey+":partner" {
+		t.Errorf("Expected the instance state under its own key, got %q", key)
+	}
+	if len(config.Rules) != 2 || config.StateBackend.Key != "" {
+		t.Error("Expected the main config to be left unchanged")
+	}
+}
+
+func TestLoadConfigInstances(t *testing.T) {
+	tests := []struct {
+		instances string
+		errMsg    string
+	}{
+		{"  - name: partner\n    url: https://partner.example.com\n    api_key: key\n", ""},
+		{"  - url: https://partner.example.com\n    api_key: key\n", "instance 0: name is required"},
+		{"  - name: partner\n    api_key: key\n", "url is required"},
+		{"  - name: partner\n    url: https://partner.example.com\n", "exactly one of api_key or api_key_file"},
+		{"  - name: partner\n    url: https://partner.example.com\n    api_key: key\n    rules: missing\n", "rules select no rule"},
+		{"  - name: partner\n    url: https://a.example.com\n    api_key: key\n  - name: partner\n    url: https://b.example.com\n    api_key: key\n", "name is already used"},
+	}
+
+	for _, tt := range tests {
+		configPath := filepath.Join(t.TempDir(), "rules.yaml")
+		content := "miniflux_url: https://miniflux.example.com\nrules:\n  - name: Promos\n    title: Promo\n    action: read\ninstances:\n" + tt.instances
+		if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write config: %v", err)
+		}
+
+		_, err := LoadConfig(configPath)
+		if tt.errMsg == "" {
+			if err != nil {
+				t.Errorf("%q: unexpected error: %v", tt.instances, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), tt.errMsg) {
+			t.Errorf("%q: expected error containing %q, got %v", tt.instances, tt.errMsg, err)
+		}
+	}
+}
+
+func TestRunProcessingInstances(t *testing.T) {
+	matcher, err := NewMatcher([]Rule{{Name: "Promos", Title: "Promo", Action: "read"}})
+	if err != nil {
+		t.Fatalf("Failed to create matcher: %v", err)
+	}
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+
+	failing := &MockClient{entriesErr: errors.New("connection refused")}
+	partner := &MockClient{entries: []*miniflux.Entry{{ID: 1, Title: "Promo: 50% off", Status: miniflux.EntryStatusUnread}}}
+	instances := []*instance{
+		{processor: NewProcessor(failing, matcher, logger, false), logger: logger},
+		{name: "partner", processor: NewProcessor(partner, matcher, logger, false), logger: logger},
+	}
+
+	report := reportTarget{Path: filepath.Join(t.TempDir(), "report.json"), Format: reportJSON}
+	if err := runProcessing(instances, report); err != nil {
+		t.Errorf("Expected the failed fetch to be logged only, got %v", err)
+	}
+	if len(partner.updatedIDs) != 1 {
+		t.Errorf("Expected the partner's account to be processed despite the failure, got %v", partner.updatedIDs)
+	}
+	if _, err := os.Stat(strings.TrimSuffix(report.Path, ".json") + "-partner.json"); err != nil {
+		t.Errorf("Expected a report for the partner's account: %v", err)
+	}
+	// -once exits non-zero for the failed fetch
+	if failed := failedRuns(instances); failed != 1 {
+		t.Errorf("Expected 1 failed run, got %d", failed)
+	}
+
+	failing.entriesErr = nil
+	if err := runProcessing(instances, reportTarget{}); err != nil {
+		t.Fatalf("runProcessing failed: %v", err)
+	}
+	if failed := failedRuns(instances); failed != 0 {
+		t.Errorf("Expected no failed run after a successful one, got %d", failed)
+	}
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/zalando/go-keyring"
+	"golang.org/x/term"
+)
+
+// keyringService is the OS keyring service API keys are stored under, one per Miniflux URL
+const keyringService = "miniflux-jobs"
+
+// keyringAPIKey reads the API key of a Miniflux server from the OS keyring
+// (Keychain on macOS, Secret Service on Linux, Credential Manager on Windows)
+func keyringAPIKey(minifluxURL string) (string, error) {
+	apiKey, err := keyring.Get(keyringService, minifluxURL)
+	if errors.Is(err, keyring.ErrNotFound) {
+		return "", fmt.Errorf("no API key for %s in the keyring, store one with the login subcommand", minifluxURL)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read the keyring: %w", err)
+	}
+	return apiKey, nil
+}
+
+// loginCommand runs the login subcommand, storing the API key in the OS keyring for -keyring:
+// miniflux-jobs login
+// miniflux-jobs login --url https://miniflux.example.com < api-key.txt
+func loginCommand(args []string) {
+	flags := flag.NewFlagSet("login", flag.ExitOnError)
+	configPath := flags.String("config", defaultConfigPath(), "Path to the rules configuration file, read for the Miniflux URL")
+	minifluxURL := flags.String("url", "", "Miniflux URL the API key is stored for (default: miniflux_url of the config)")
+	logout := flags.Bool("logout", false, "Remove the stored API key instead")
+	flags.Parse(args)
+
+	logger := commandLogger(os.Stderr)
+
+	if *minifluxURL == "" {
+		config, err := LoadConfig(*configPath)
+		if err != nil {
+			fatal(logger, "Failed to load config", "error", err)
+		}
+		*minifluxURL = config.MinifluxURL
+	}
+
+	if *logout {
+		if err := keyring.Delete(keyringService, *minifluxURL); err != nil && !errors.Is(err, keyring.ErrNotFound) {
+			fatal(logger, "Failed to remove the API key", "error", err)
+		}
+		logger.Info("Removed the API key from the keyring", "url", *minifluxURL)
+		return
+	}
+
+	apiKey, err := readAPIKey(os.Stdin, *minifluxURL)
+	if err != nil {
+		fatal(logger, "Failed to read the API key", "error", err)
+	}
+	if err := keyring.Set(keyringService, *minifluxURL, apiKey); err != nil {
+		fatal(logger, "Failed to store the API key", "error", err)
+	}
+	logger.Info("Stored the API key in the keyring, run with -keyring to use it", "url", *minifluxURL)
+}
+
+// readAPIKey prompts for the API key without echoing it, or reads it from piped input
+func readAPIKey(input *os.File, minifluxURL string) (string, error) {
+	var apiKey string
+	if fd := int(input.Fd()); term.IsTerminal(fd) {
+		fmt.Fprintf(os.Stderr, "API key for %s: ", minifluxURL)
+		data, err := term.ReadPassword(fd)
+		fmt.Fprintln(os.Stderr)
+		if err != nil {
+			return "", err
+		}
+		apiKey = string(data)
+	} else {
+		line, err := bufio.NewReader(input).ReadString('\n')
+		if err != nil && err != io.EOF {
+			return "", err
+		}
+		apiKey = line
+	}
+
+	apiKey = strings.TrimSpace(apiKey)
+	if apiKey == "" {
+		return "", fmt.Errorf("the API key is empty")
+	}
+	return apiKey, nil
+}
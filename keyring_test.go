@@ -0,0 +1,49 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/zalando/go-keyring"
+)
+
+func TestKeyringAPIKey(t *testing.T) {
+	keyring.MockInit()
+
+	if _, err := keyringAPIKey("https://miniflux.example.com"); err == nil || !strings.Contains(err.Error(), "login subcommand") {
+		t.Errorf("Expected a missing key to point to login, got %v", err)
+	}
+
+	if err := keyring.Set(keyringService, "https://miniflux.example.com", "s3cret"); err != nil {
+		t.Fatalf("Failed to store key: %v", err)
+	}
+	apiKey, err := keyringAPIKey("https://miniflux.example.com")
+	if err != nil || apiKey != "s3cret" {
+		t.Errorf("Expected the stored key, got %q and %v", apiKey, err)
+	}
+	if _, err := keyringAPIKey("https://staging.example.com"); err == nil {
+		t.Error("Expected keys to be stored per Miniflux URL")
+	}
+}
+
+func TestReadAPIKey(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "key")
+	if err := os.WriteFile(path, []byte("  s3cret\n"), 0600); err != nil {
+		t.Fatalf("Failed to write key: %v", err)
+	}
+	input, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("Failed to open key: %v", err)
+	}
+	defer input.Close()
+
+	apiKey, err := readAPIKey(input, "https://miniflux.example.com")
+	if err != nil || apiKey != "s3cret" {
+		t.Errorf("Expected the piped key, got %q and %v", apiKey, err)
+	}
+	if _, err := readAPIKey(input, "https://miniflux.example.com"); err == nil {
+		t.Error("Expected empty input to be rejected")
+	}
+}
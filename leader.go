@@ -0,0 +1,113 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// minLeaderTTL is the shortest leader lease, so short intervals do not cause flapping
+const minLeaderTTL = 30 * time.Second
+
+// LeaderElectionConfig lets several replicas share one state backend
+// with only the current leader applying changes
+type LeaderElectionConfig struct {
+	Enabled bool          `yaml:"enabled"`
+	TTL     time.Duration `yaml:"ttl"` // how long a lease outlives its last run (default 3x interval, at least 30s)
+}
+
+// LeaderLock decides which replica may run
+type LeaderLock interface {
+	Acquire() (bool, error) // takes or renews the lease, reporting whether this replica leads
+	Release() error
+}
+
+// acquireScript renews the lease if we hold it, or takes it if nobody does
+var acquireScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+if redis.call("SET", KEYS[1], ARGV[1], "NX", "PX", ARGV[2]) then
+	return 1
+end
+return 0
+`)
+
+// releaseScript drops the lease only if we still hold it
+var releaseScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// RedisLock is a lease-based leader lock held in Redis
+type RedisLock struct {
+	client *redis.Client
+	key    string
+	token  string
+	ttl    time.Duration
+}
+
+// LeaderLock returns a lock stored next to the state key
+func (r *RedisStore) LeaderLock(ttl time.Duration) *RedisLock {
+	return &RedisLock{
+		client: r.client,
+		key:    r.key + ":leader",
+		token:  leaderToken(),
+		ttl:    ttl,
+	}
+}
+
+// Acquire takes or renews the lease
+func (l *RedisLock) Acquire() (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+	defer cancel()
+
+	result, err := acquireScript.Run(ctx, l.client, []string{l.key}, l.token, l.ttl.Milliseconds()).Int()
+	if err != nil {
+		return false, fmt.Errorf("failed to acquire leader lock: %w", err)
+	}
+	return result == 1, nil
+}
+
+// Release gives up the lease so another replica can take over immediately
+func (l *RedisLock) Release() error {
+	ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+	defer cancel()
+
+	if err := releaseScript.Run(ctx, l.client, []string{l.key}, l.token).Err(); err != nil {
+		return fmt.Errorf("failed to release leader lock: %w", err)
+	}
+	return nil
+}
+
+// leaderToken identifies this replica in the lock
+func leaderToken() string {
+	host, _ := os.Hostname()
+	suffix := make([]byte, 8)
+	rand.Read(suffix)
+	return fmt.Sprintf("%s-%d-%s", host, os.Getpid(), hex.EncodeToString(suffix))
+}
+
+// leaderTTL returns the configured lease duration or one derived from the interval
+func leaderTTL(config *Config) time.Duration {
+	if config.LeaderElection.TTL > 0 {
+		return config.LeaderElection.TTL
+	}
+	return max(3*time.Duration(config.Interval)*time.Second, minLeaderTTL)
+}
+
+// OpenLeaderLock creates the leader lock for the configured state backend
+func OpenLeaderLock(config *Config, store StateStore) (LeaderLock, error) {
+	redisStore, ok := store.(*RedisStore)
+	if !ok {
+		return nil, fmt.Errorf("leader_election requires the redis state_backend")
+	}
+	return redisStore.LeaderLock(leaderTTL(config)), nil
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	miniflux "miniflux.app/v2/client"
+)
+
+// fakeLock grants leadership according to a scripted sequence
+type fakeLock struct {
+	grants []bool
+}
+
+func (f *fakeLock) Acquire() (bool, error) {
+	grant := f.grants[0]
+	f.grants = f.grants[1:]
+	return grant, nil
+}
+
+func (f *fakeLock) Release() error {
+	return nil
+}
+
+func TestProcessorLeaderElection(t *testing.T) {
+	mockClient := &MockClient{
+		entries: []*miniflux.Entry{
+			{ID: 1, Title: "Spam offer"},
+		},
+	}
+
+	matcher, err := NewMatcher([]Rule{
+		{Name: "Spam", Title: "Spam", Action: "read"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create matcher: %v", err)
+	}
+
+	statePath := filepath.Join(t.TempDir(), "state.json")
+	state, err := LoadState(statePath)
+	if err != nil {
+		t.Fatalf("Failed to load state: %v", err)
+	}
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	processor := NewProcessor(mockClient, matcher, logger, false)
+	processor.SetState(state)
+	processor.SetLeaderLock(&fakeLock{grants: []bool{false, true}})
+
+	// A follower leaves entries alone
+	stats, err := processor.Process()
+	if err != nil {
+		t.Fatalf("Process failed: %v", err)
+	}
+	if stats.TotalEntries != 0 || len(mockClient.updatedIDs) != 0 {
+		t.Errorf("Expected follower to skip the run, got %d entries and updates %v", stats.TotalEntries, mockClient.updatedIDs)
+	}
+
+	// Meanwhile the leader saved state, which is picked up on takeover
+	other, err := LoadState(statePath)
+	if err != nil {
+		t.Fatalf("Failed to load state: %v", err)
+	}
+	other.MarkConsumed("Elsewhere", time.Now())
+	if err := other.Save(); err != nil {
+		t.Fatalf("Failed to save state: %v", err)
+	}
+
+	stats, err = processor.Process()
+	if err != nil {
+		t.Fatalf("Process failed: %v", err)
+	}
+	if stats.MarkedRead != 1 {
+		t.Errorf("Expected leader to mark 1 entry read, got %d", stats.MarkedRead)
+	}
+	if !state.IsConsumed("Elsewhere") {
+		t.Error("Expected state to be reloaded after acquiring the leader lock")
+	}
+}
+
+func TestLeaderTTL(t *testing.T) {
+	if ttl := leaderTTL(&Config{Interval: 300}); ttl != 15*time.Minute {
+		t.Errorf("Expected 15m lease, got %s", ttl)
+	}
+	if ttl := leaderTTL(&Config{Interval: 5}); ttl != minLeaderTTL {
+		t.Errorf("Expected minimum lease, got %s", ttl)
+	}
+	if ttl := leaderTTL(&Config{LeaderElection: LeaderElectionConfig{TTL: time.Minute}}); ttl != time.Minute {
+		t.Errorf("Expected configured lease, got %s", ttl)
+	}
+}
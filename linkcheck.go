@@ -0,0 +1,90 @@
+package main
+
+import (
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Default link checking behaviour when not configured
+const (
+	defaultLinkCheckRateLimit = time.Second
+	defaultLinkCheckCacheTTL  = 24 * time.Hour
+)
+
+// LinkChecker determines whether entry URLs are still reachable
+// Requests are rate-limited and results cached per URL
+type LinkChecker struct {
+	client    *http.Client
+	rateLimit time.Duration
+	cacheTTL  time.Duration
+
+	mu          sync.Mutex
+	lastRequest time.Time
+	cache       map[string]linkStatus
+}
+
+// linkStatus is a cached link check result
+type linkStatus struct {
+	dead      bool
+	checkedAt time.Time
+}
+
+// NewLinkChecker creates a LinkChecker waiting at least rateLimit between requests
+func NewLinkChecker(client *http.Client, rateLimit, cacheTTL time.Duration) *LinkChecker {
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &LinkChecker{
+		client:    client,
+		rateLimit: rateLimit,
+		cacheTTL:  cacheTTL,
+		cache:     make(map[string]linkStatus),
+	}
+}
+
+// IsDead reports whether the URL responds with 404 Not Found or 410 Gone
+// Network errors and other statuses are treated as alive
+func (c *LinkChecker) IsDead(url string) bool {
+	if url == "" {
+		return false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if status, ok := c.cache[url]; ok && time.Since(status.checkedAt) < c.cacheTTL {
+		return status.dead
+	}
+
+	if wait := c.rateLimit - time.Since(c.lastRequest); wait > 0 {
+		time.Sleep(wait)
+	}
+	c.lastRequest = time.Now()
+
+	code := c.statusCode(http.MethodHead, url)
+	if code == http.StatusMethodNotAllowed || code == http.StatusNotImplemented {
+		code = c.statusCode(http.MethodGet, url)
+	}
+
+	dead := code == http.StatusNotFound || code == http.StatusGone
+	c.cache[url] = linkStatus{dead: dead, checkedAt: time.Now()}
+
+	return dead
+}
+
+// statusCode performs a request and returns the response status, or 0 on error
+func (c *LinkChecker) statusCode(method, url string) int {
+	req, err := http.NewRequest(method, url, nil)
+	if err != nil {
+		return 0
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return 0
+	}
+	resp.Body.Close()
+
+	return resp.StatusCode
+}
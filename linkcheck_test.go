@@ -0,0 +1,73 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	miniflux "miniflux.app/v2/client"
+)
+
+func TestLinkCheckerIsDead(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		switch r.URL.Path {
+		case "/gone":
+			w.WriteHeader(http.StatusGone)
+		case "/missing":
+			w.WriteHeader(http.StatusNotFound)
+		default:
+			w.WriteHeader(http.StatusOK)
+		}
+	}))
+	defer server.Close()
+
+	checker := NewLinkChecker(server.Client(), 0, time.Hour)
+
+	testCases := []struct {
+		path     string
+		expected bool
+	}{
+		{"/gone", true},
+		{"/missing", true},
+		{"/ok", false},
+	}
+
+	for _, tc := range testCases {
+		if dead := checker.IsDead(server.URL + tc.path); dead != tc.expected {
+			t.Errorf("Path '%s': expected dead=%v, got %v", tc.path, tc.expected, dead)
+		}
+	}
+
+	// Repeated checks are served from the cache
+	checker.IsDead(server.URL + "/gone")
+	if requests != 3 {
+		t.Errorf("Expected 3 requests with caching, got %d", requests)
+	}
+}
+
+func TestMatcherDeadLinkCheck(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/gone" {
+			w.WriteHeader(http.StatusGone)
+		}
+	}))
+	defer server.Close()
+
+	matcher, err := NewMatcher([]Rule{
+		{Name: "Remove dead links", DeadLinkCheck: true, Action: "remove"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create matcher: %v", err)
+	}
+	matcher.SetLinkChecker(NewLinkChecker(server.Client(), 0, time.Hour))
+
+	if !matcher.Match(&miniflux.Entry{ID: 1, URL: server.URL + "/gone"}).Matched {
+		t.Error("Expected dead link to match")
+	}
+	if matcher.Match(&miniflux.Entry{ID: 2, URL: server.URL + "/alive"}).Matched {
+		t.Error("Expected live link not to match")
+	}
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	miniflux "miniflux.app/v2/client"
+)
+
+// Log formats accepted by -log-format
+const (
+	logFormatText = "text"
+	logFormatJSON = "json"
+)
+
+// levelTrace is the level of HTTP request logs, below debug and enabled by -vv
+const levelTrace = slog.LevelDebug - 4
+
+// summaryKey marks the context of log records that quiet mode still writes
+type summaryKey struct{}
+
+// summaryContext is the context of the statistics logged at the end of a run
+var summaryContext = context.WithValue(context.Background(), summaryKey{}, true)
+
+// newLogger creates a logger writing text or JSON lines at the given level and above
+// A quiet logger only writes errors and the statistics of each run.
+func newLogger(w io.Writer, format, level string, quiet bool) (*slog.Logger, error) {
+	var minLevel slog.Level
+	if strings.EqualFold(level, "trace") {
+		minLevel = levelTrace
+	} else if err := minLevel.UnmarshalText([]byte(level)); err != nil {
+		return nil, fmt.Errorf("unknown log level %q, expected trace, debug, info, warn or error", level)
+	}
+	options := &slog.HandlerOptions{Level: minLevel, ReplaceAttr: replaceLevelName}
+
+	var handler slog.Handler
+	switch format {
+	case logFormatText:
+		handler = slog.NewTextHandler(w, options)
+	case logFormatJSON:
+		handler = slog.NewJSONHandler(w, options)
+	default:
+		return nil, fmt.Errorf("unknown log format %q, expected %s or %s", format, logFormatText, logFormatJSON)
+	}
+	if quiet {
+		handler = quietHandler{handler}
+	}
+	return slog.New(handler), nil
+}
+
+// replaceLevelName names the trace level, which slog would write as DEBUG-4
+func replaceLevelName(groups []string, attr slog.Attr) slog.Attr {
+	if attr.Key == slog.LevelKey && len(groups) == 0 {
+		if level, ok := attr.Value.Any().(slog.Level); ok && level == levelTrace {
+			attr.Value = slog.StringValue("TRACE")
+		}
+	}
+	return attr
+}
+
+// quietHandler drops every record but errors and those logged with summaryContext
+type quietHandler struct {
+	slog.Handler
+}
+
+// Handle writes the record if quiet mode keeps it
+func (h quietHandler) Handle(ctx context.Context, record slog.Record) error {
+	if record.Level < slog.LevelError && ctx.Value(summaryKey{}) == nil {
+		return nil
+	}
+	return h.Handler.Handle(ctx, record)
+}
+
+// WithAttrs returns a quiet handler adding the attributes
+func (h quietHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return quietHandler{h.Handler.WithAttrs(attrs)}
+}
+
+// WithGroup returns a quiet handler nesting attributes in the group
+func (h quietHandler) WithGroup(name string) slog.Handler {
+	return quietHandler{h.Handler.WithGroup(name)}
+}
+
+// loggingTransport logs every HTTP request at trace level with its status and duration
+type loggingTransport struct {
+	base   http.RoundTripper
+	logger *slog.Logger
+}
+
+// RoundTrip performs the request and logs it
+// URLs are logged without their query, which may carry credentials
+func (t *loggingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !t.logger.Enabled(req.Context(), levelTrace) {
+		return t.base.RoundTrip(req)
+	}
+	start := time.Now()
+	resp, err := t.base.RoundTrip(req)
+	args := []any{"method", req.Method, "url", req.URL.Scheme + "://" + req.URL.Host + req.URL.Path, "duration", time.Since(start)}
+	if err != nil {
+		args = append(args, "error", err)
+	} else {
+		args = append(args, "status", resp.StatusCode)
+	}
+	t.logger.Log(req.Context(), levelTrace, "HTTP request", args...)
+	return resp, err
+}
+
+// logRequests makes the client log its requests at trace level
+func logRequests(client *http.Client, logger *slog.Logger) {
+	base := client.Transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	client.Transport = &loggingTransport{base: base, logger: logger}
+}
+
+// commandLogger returns the text logger of the subcommands
+func commandLogger(w io.Writer) *slog.Logger {
+	return slog.New(slog.NewTextHandler(w, nil))
+}
+
+// fatal logs an error and exits with status 1
+func fatal(logger *slog.Logger, msg string, args ...any) {
+	logger.Error(msg, args...)
+	os.Exit(1)
+}
+
+// entryLogger returns the processor's logger with the fields identifying an entry and the rule acting on it,
+// attached to every action log line
+func (p *Processor) entryLogger(entry *miniflux.Entry, rule *Rule) *slog.Logger {
+	feedTitle := ""
+	if entry.Feed != nil {
+		feedTitle = entry.Feed.Title
+	}
+	args := []any{"entry_id", entry.ID, "feed", feedTitle}
+	if rule != nil {
+		args = append([]any{"rule", rule.Name}, args...)
+	}
+	return p.logger.With(args...)
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	miniflux "miniflux.app/v2/client"
+)
+
+func TestNewLogger(t *testing.T) {
+	var out bytes.Buffer
+	logger, err := newLogger(&out, logFormatJSON, "warn", false)
+	if err != nil {
+		t.Fatalf("newLogger failed: %v", err)
+	}
+	logger.Info("hidden")
+	logger.Warn("shown", "rule", "Promos")
+
+	var line map[string]any
+	if err := json.Unmarshal(out.Bytes(), &line); err != nil {
+		t.Fatalf("Expected one JSON line, got %q: %v", out.String(), err)
+	}
+	if line["msg"] != "shown" || line["level"] != "WARN" || line["rule"] != "Promos" {
+		t.Errorf("Unexpected log line %v", line)
+	}
+
+	if _, err := newLogger(&out, "xml", "info", false); err == nil {
+		t.Error("Expected an error for an unknown format")
+	}
+	if _, err := newLogger(&out, logFormatText, "verbose", false); err == nil {
+		t.Error("Expected an error for an unknown level")
+	}
+}
+
+func TestActionLogFields(t *testing.T) {
+	mockClient := &MockClient{entries: []*miniflux.Entry{
+		{ID: 7, Title: "Sponsored post", Status: miniflux.EntryStatusUnread, Feed: &miniflux.Feed{ID: 3, Title: "Tech Daily"}},
+	}}
+	matcher, err := NewMatcher([]Rule{{Name: "Promos", Title: "(?i)sponsored", Action: "read"}})
+	if err != nil {
+		t.Fatalf("Failed to create matcher: %v", err)
+	}
+
+	var out bytes.Buffer
+	logger, _ := newLogger(&out, logFormatJSON, "info", false)
+	processor := NewProcessor(mockClient, matcher, logger, false)
+	if _, err := processor.Process(); err != nil {
+		t.Fatalf("Process failed: %v", err)
+	}
+
+	decoder := json.NewDecoder(&out)
+	found := false
+	for decoder.More() {
+		var line map[string]any
+		if err := decoder.Decode(&line); err != nil {
+			t.Fatalf("Failed to decode log line: %v", err)
+		}
+		if line["msg"] != "Applied action" {
+			continue
+		}
+		found = true
+		if line["rule"] != "Promos" || line["entry_id"] != float64(7) || line["feed"] != "Tech Daily" || line["action"] != "read" {
+			t.Errorf("Expected rule, entry_id, feed and action fields, got %v", line)
+		}
+	}
+	if !found {
+		t.Errorf("Expected an applied action line in the logs:\n%s", out.String())
+	}
+}
+
+func TestQuietLogger(t *testing.T) {
+	var out bytes.Buffer
+	logger, err := newLogger(&out, logFormatText, "info", true)
+	if err != nil {
+		t.Fatalf("newLogger failed: %v", err)
+	}
+	logger.With("instance", "partner").Info("Applied action")
+	logger.Warn("Cannot watch the config file")
+	logger.Error("Failed to update entry")
+	logStats(logger, &ProcessStats{TotalEntries: 3, SkippedPages: 1})
+
+	logs := out.String()
+	for _, hidden := range []string{"Applied action", "Cannot watch"} {
+		if strings.Contains(logs, hidden) {
+			t.Errorf("Expected %q to be dropped in quiet mode:\n%s", hidden, logs)
+		}
+	}
+	for _, shown := range []string{"Failed to update entry", "Processing complete", "Skipped pages"} {
+		if !strings.Contains(logs, shown) {
+			t.Errorf("Expected %q in quiet mode:\n%s", shown, logs)
+		}
+	}
+}
+
+func TestVerboseTraces(t *testing.T) {
+	var out bytes.Buffer
+	logger, err := newLogger(&out, logFormatText, "trace", false)
+	if err != nil {
+		t.Fatalf("newLogger failed: %v", err)
+	}
+
+	// Rule evaluations stop at the first failed condition, as without tracing
+	matcher, err := NewMatcher([]Rule{{Name: "Promos", Feed: "News", Title: "(?i)sponsored", Action: "read"}})
+	if err != nil {
+		t.Fatalf("Failed to create matcher: %v", err)
+	}
+	matcher.SetLogger(logger)
+	matcher.Match(&miniflux.Entry{ID: 7, Title: "Sponsored", Feed: &miniflux.Feed{Title: "Tech"}})
+	if want := `msg="Evaluated rule" rule=Promos entry_id=7 matched=false conditions="feed=fail"`; !strings.Contains(out.String(), want) {
+		t.Errorf("Expected %q in the logs:\n%s", want, out.String())
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	}))
+	defer server.Close()
+	client := &http.Client{}
+	logRequests(client, logger)
+	resp, err := client.Get(server.URL + "/v1/entries?api_key=secret")
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	resp.Body.Close()
+	logs := out.String()
+	if !strings.Contains(logs, `level=TRACE msg="HTTP request" method=GET url=`+server.URL+`/v1/entries`) || !strings.Contains(logs, "status=418") {
+		t.Errorf("Expected the request at trace level in the logs:\n%s", logs)
+	}
+	if strings.Contains(logs, "secret") {
+		t.Errorf("Expected the query to be left out of the logs:\n%s", logs)
+	}
+}
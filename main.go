@@ -1,121 +1,690 @@
 package main
 
 import (
+	"errors"
 	"flag"
-	"log"
+	"fmt"
+	"log/slog"
+	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 )
 
 func main() {
-	// Parse command line flags
-	defaultConfigPath := os.Getenv("MINIFLUX_RULES_FILE")
-	if defaultConfigPath == "" {
-		defaultConfigPath = "rules.yaml"
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "reprocess":
+			reprocessCommand(os.Args[2:])
+			return
+		case "undo":
+			undoCommand(os.Args[2:])
+			return
+		case "purge":
+			purgeCommand(os.Args[2:])
+			return
+		case "exceptions":
+			exceptionsCommand(os.Args[2:])
+			return
+		case "config":
+			configCommand(os.Args[2:])
+			return
+		case "schema":
+			schemaCommand(os.Args[2:])
+			return
+		case "docs":
+			docsCommand(os.Args[2:])
+			return
+		case "sync-filters":
+			syncFiltersCommand(os.Args[2:])
+			return
+		case "login":
+			loginCommand(os.Args[2:])
+			return
+		case "test":
+			testCommand(os.Args[2:])
+			return
+		case "rule":
+			ruleCommand(os.Args[2:])
+			return
+		case "feeds":
+			feedsCommand(os.Args[2:])
+			return
+		case "import-filters":
+			importFiltersCommand(os.Args[2:])
+			return
+		case "self-update":
+			selfUpdateCommand(os.Args[2:])
+			return
+		}
 	}
-	configPath := flag.String("config", defaultConfigPath, "Path to the rules configuration file")
+
+	// Parse command line flags
+	configPath := flag.String("config", defaultConfigPath(), "Path or http(s) URL of the rules configuration file")
+	configFormat := flag.String("config-format", "", "Format of the configuration file: \"yaml\", \"toml\" or \"json\" (default: from the file extension)")
+	profile := flag.String("profile", "", "Name of the config profile whose settings are merged over the others, e.g. staging")
 	dryRun := flag.Bool("dry-run", false, "Run without making changes")
+	once := flag.Bool("once", false, "Run once and exit, ignoring interval, schedule, listen and metrics_listen, exiting non-zero if the run fails")
+	explain := flag.Bool("explain", false, "With -dry-run, log which pattern of each matching rule caught which text")
+	onlyRules := flag.String("only-rules", "", "Comma-separated rule names or labels to run exclusively")
+	skipRules := flag.String("skip-rules", "", "Comma-separated rule names or labels to skip")
+	feedScope := flag.String("feed", "", "Only process the feed with this ID or whose title matches this regex")
+	categoryScope := flag.String("category", "", "Only process the feeds of the category with this ID or whose title matches this regex")
+	reportFormat := flag.String("report", "", "Write a report of each run: \"html\", \"json\" or \"markdown\"")
+	reportFile := flag.String("report-file", "", "Path of the report written by -report (default: miniflux-jobs-report with the format's extension)")
+	useKeyring := flag.Bool("keyring", false, "Read the API key from the OS keyring, stored there with the login subcommand")
+	expectConfigHash := flag.String("expect-config-hash", "", "Refuse to start unless the config file SHA-256 starts with this value")
+	showVersion := flag.Bool("version", false, "Print the version, commit and build date and exit")
+	logFormat := flag.String("log-format", logFormatText, "Log line format: \"text\" or \"json\"")
+	logLevel := flag.String("log-level", "info", "Lowest level logged: \"trace\", \"debug\", \"info\", \"warn\" or \"error\"")
+	quiet := flag.Bool("q", false, "Only log errors and the statistics of each run")
+	verbose := flag.Bool("v", false, "Log how each rule evaluates each entry (same as -log-level debug)")
+	veryVerbose := flag.Bool("vv", false, "Also log every Miniflux API request (same as -log-level trace)")
 	flag.Parse()
 
+	if *showVersion {
+		fmt.Printf("miniflux-jobs %s\n", currentBuildInfo())
+		return
+	}
+
 	// Setup logger
-	logger := log.New(os.Stdout, "[miniflux-jobs] ", log.LstdFlags)
+	switch {
+	case *quiet && (*verbose || *veryVerbose):
+		fatal(commandLogger(os.Stderr), "-q cannot be combined with -v or -vv")
+	case *veryVerbose:
+		*logLevel = "trace"
+	case *verbose:
+		*logLevel = "debug"
+	}
+	logger, err := newLogger(os.Stdout, *logFormat, *logLevel, *quiet)
+	if err != nil {
+		fatal(commandLogger(os.Stderr), "Invalid logging flags", "error", err)
+	}
+	slog.SetDefault(logger)
 
-	if *dryRun {
-		logger.Println("Dry-run mode enabled: no changes will be applied")
+	var report reportTarget
+	switch *reportFormat {
+	case "":
+	case reportHTML, reportJSON, reportMarkdown:
+		report = reportTarget{Path: *reportFile, Format: *reportFormat}
+		if report.Path == "" {
+			report.Path = defaultReportFile(report.Format)
+		}
+	default:
+		fatal(logger, "Unknown report format", "format", *reportFormat)
 	}
 
+	logger.Info("Starting miniflux-jobs", "build", currentBuildInfo())
+
 	// Load configuration
-	logger.Printf("Loading configuration from %s", *configPath)
-	config, err := LoadConfig(*configPath)
+	logger.Info("Loading configuration", "path", *configPath)
+	config, err := LoadConfigProfile(*configPath, *configFormat, *profile)
 	if err != nil {
-		logger.Fatalf("Failed to load config: %v", err)
+		fatal(logger, "Failed to load config", "error", err)
+	}
+	if *profile != "" {
+		logger.Info("Using profile", "profile", *profile)
+	}
+	logger.Info("Loaded rules", "rules", len(config.Rules), "config_hash", config.Hash)
+	if *expectConfigHash != "" && !strings.HasPrefix(config.Hash, strings.ToLower(*expectConfigHash)) {
+		fatal(logger, "Config hash does not match the expected hash", "config_hash", config.Hash, "expected", *expectConfigHash)
+	}
+	if config.Timezone != "" {
+		logger.Info("Using timezone", "timezone", config.Timezone)
+	}
+	if *once {
+		// An ad hoc run beside the daemon must not take over its listen addresses
+		config.Interval, config.Schedule = 0, ""
+		config.Listen, config.MetricsListen = "", ""
+	}
+	if config.DryRun {
+		*dryRun = true
+	}
+	if *dryRun {
+		logger.Info("Dry-run mode enabled: no changes will be applied")
+	} else if *explain {
+		logger.Warn("-explain only applies to dry runs")
+	}
+
+	if *onlyRules != "" || *skipRules != "" {
+		if len(config.CategoryDefaults) > 0 {
+			logger.Info("Skipping category_defaults while rules are selected")
+		}
+		config.SelectRules(splitList(*onlyRules), splitList(*skipRules))
+		logger.Info("Selected rules", "rules", len(config.Rules))
+	}
+	if *feedScope != "" {
+		if config.FeedScope, err = parseFeedSelector(*feedScope); err != nil {
+			fatal(logger, "Invalid -feed", "error", err)
+		}
+		logger.Info("Only processing the feed", "feed", *feedScope)
+	}
+	if *categoryScope != "" {
+		if config.CategoryScope, err = parseFeedSelector(*categoryScope); err != nil {
+			fatal(logger, "Invalid -category", "error", err)
+		}
+		logger.Info("Only processing the category", "category", *categoryScope)
+	}
+	if (config.FeedScope != nil || config.CategoryScope != nil) && config.MaxEntriesPerRun > 0 {
+		fatal(logger, "-feed and -category cannot be used with max_entries_per_run")
 	}
-	logger.Printf("Loaded %d rules", len(config.Rules))
 
 	// Get API key
-	apiKey, err := GetAPIKey()
+	var apiKey string
+	if *useKeyring {
+		apiKey, err = keyringAPIKey(config.MinifluxURL)
+	} else {
+		apiKey, err = GetAPIKey()
+	}
 	if err != nil {
-		logger.Fatalf("Failed to get API key: %v", err)
+		fatal(logger, "Failed to get API key", "error", err)
 	}
-	logger.Println("API key loaded successfully")
+	logger.Info("API key loaded successfully")
 
 	// Create Miniflux client
-	client := NewClientWrapper(config.MinifluxURL, apiKey)
+	client := NewClientWrapper(config.MinifluxURL, apiKey, config.MaxResponseSize)
+	client.LogRequests(logger)
 
 	// Create matcher with compiled rules
-	matcher, err := NewMatcher(config.Rules)
+	matcher, err := buildMatcher(config, client, logger)
 	if err != nil {
-		logger.Fatalf("Failed to compile rules: %v", err)
+		fatal(logger, "Failed to compile rules", "error", err)
+	}
+
+	// Load persistent state
+	var state *State
+	var leader LeaderLock
+	if config.HasState() {
+		store, err := OpenStateStore(config)
+		if err != nil {
+			fatal(logger, "Failed to open state store", "error", err)
+		}
+		defer store.Close()
+		if config.LeaderElection.Enabled {
+			leader, err = OpenLeaderLock(config, store)
+			if err != nil {
+				fatal(logger, "Failed to set up leader election", "error", err)
+			}
+			logger.Info("Leader election enabled", "lease", leaderTTL(config))
+		}
+		state, err = LoadStateFrom(store)
+		if err != nil {
+			fatal(logger, "Failed to load state", "error", err)
+		}
+		if enterSafeMode(state, config.SafeMode, newSafeModeAlerter(config), logger) {
+			*dryRun = true
+		}
 	}
 
 	// Create processor
 	processor := NewProcessor(client, matcher, logger, *dryRun)
+	processor.SetOptions(processorOptions(config))
+	if state != nil {
+		processor.SetState(state)
+	}
+	if leader != nil {
+		processor.SetLeaderLock(leader)
+	}
+	processor.SetExplain(*explain)
+
+	// Open the further accounts maintained with the same rules
+	instances := []*instance{{processor: processor, logger: logger}}
+	for _, spec := range config.Instances {
+		inst, err := openInstance(config, spec, *dryRun, logger)
+		if err != nil {
+			fatal(logger, "Failed to set up instance", "instance", spec.Name, "error", err)
+		}
+		if inst.store != nil {
+			defer inst.store.Close()
+		}
+		inst.processor.SetExplain(*explain)
+		instances = append(instances, inst)
+	}
 
 	// Setup signal handling for graceful shutdown
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
 
+	// Reload the rules on SIGHUP or when the config file changes
+	var reloader *configReloader
+	if config.Interval > 0 || config.Schedule != "" || config.Listen != "" {
+		reloader = &configReloader{
+			path:          *configPath,
+			format:        *configFormat,
+			profile:       *profile,
+			onlyRules:     splitList(*onlyRules),
+			skipRules:     splitList(*skipRules),
+			feedScope:     config.FeedScope,
+			categoryScope: config.CategoryScope,
+			expectHash:    *expectConfigHash,
+			instances:     instances,
+			logger:        logger,
+			hash:          config.Hash,
+		}
+		hupChan := make(chan os.Signal, 1)
+		signal.Notify(hupChan, syscall.SIGHUP)
+		var changes <-chan struct{}
+		if isRemoteConfig(*configPath) {
+			changes = pollRemoteConfig()
+		} else if changes, err = watchConfig(config.Sources, logger); err != nil {
+			logger.Warn("Cannot watch the config file, reload it with SIGHUP instead", "error", err)
+		}
+		go reloader.run(hupChan, changes)
+	}
+
+	var schedule loopSchedule
+	if config.Interval > 0 || config.Schedule != "" {
+		if schedule, err = newLoopSchedule(config); err != nil {
+			fatal(logger, "Invalid schedule", "error", err)
+		}
+	}
+
+	// Start serve mode
+	server := NewServer(processor, logger)
+	server.SetInstances(instances)
+	var admin *adminAPI
+	if config.Listen != "" {
+		server.SetHealth(newHealthCheck(instances, schedule, config.HealthGrace))
+		if config.AdminToken != "" {
+			admin = newAdminAPI(config.AdminToken, reloader, instances)
+			server.SetAdmin(admin)
+			logger.Info("Serving the admin API", "path", "/admin/")
+		}
+		if config.WebhookSecret != "" {
+			server.SetWebhookSecret(config.WebhookSecret)
+			logger.Info("Accepting Miniflux webhooks for new entries", "path", "/miniflux/webhook")
+		}
+		go func() {
+			logger.Info("Serving HTTP", "listen", config.Listen)
+			if err := http.ListenAndServe(config.Listen, server.Handler()); err != nil {
+				fatal(logger, "HTTP server failed", "error", err)
+			}
+		}()
+	}
+	if config.MetricsListen != "" {
+		go func() {
+			logger.Info("Serving metrics", "listen", config.MetricsListen)
+			if err := http.ListenAndServe(config.MetricsListen, server.MetricsHandler()); err != nil {
+				fatal(logger, "Metrics server failed", "error", err)
+			}
+		}()
+	}
+
 	// Run processing loop
-	if config.Interval == 0 {
-		// Run once and exit
-		logger.Println("Running in single-run mode")
-		runOnce(processor, logger)
+	var runErr error
+	if config.Interval == 0 && config.Schedule == "" {
+		// Run once, then exit unless serving HTTP
+		logger.Info("Running in single-run mode")
+		runErr = runOnce(instances, report)
+		if (config.Listen != "" || config.MetricsListen != "") && !fatalRunError(runErr) {
+			sig := <-sigChan
+			logger.Info("Received signal, shutting down", "signal", sig.String())
+		}
 	} else {
 		// Run in loop mode
-		logger.Printf("Running in loop mode with %d second interval", config.Interval)
-		runLoop(processor, logger, config.Interval, sigChan)
+		if config.Schedule != "" {
+			logger.Info("Running in loop mode on a schedule", "schedule", config.Schedule)
+		} else {
+			logger.Info("Running in loop mode", "interval_seconds", config.Interval)
+		}
+		var runs <-chan struct{}
+		if admin != nil {
+			runs = admin.runs
+		}
+		runErr = runLoop(instances, logger, schedule, sigChan, runs, report)
+	}
+
+	if leader != nil {
+		if err := leader.Release(); err != nil {
+			logger.Error("Failed to release leader lock", "error", err)
+		}
+	}
+	var partial *PartialFetchError
+	if runErr != nil && !errors.As(runErr, &partial) {
+		fatal(logger, "Aborted", "error", runErr)
+	}
+	markCleanExit(state, logger)
+	if *once && failedRuns(instances) > 0 {
+		os.Exit(exitRunFailure)
+	}
+	if partial != nil {
+		os.Exit(exitPartialFailure)
 	}
 }
 
-// runOnce executes a single processing run
-func runOnce(processor *Processor, logger *log.Logger) {
-	stats, err := processor.Process()
-	if err != nil {
-		logger.Printf("Processing error: %v", err)
+// Exit codes of runs that did not fully succeed
+const (
+	exitRunFailure     = 1 // a -once run failed or some of its actions did
+	exitPartialFailure = 2 // a run skipped pages it could not fetch
+)
+
+// failedRuns counts the instances whose latest run failed or had actions fail
+// Runs that only skipped pages are left to exitPartialFailure.
+func failedRuns(instances []*instance) int {
+	failed := 0
+	for _, inst := range instances {
+		run := inst.runs.latest()
+		if run == nil {
+			continue
+		}
+		var partial *PartialFetchError
+		if run.err != nil && !errors.As(run.err, &partial) || run.stats != nil && run.stats.Errors > 0 {
+			failed++
+		}
 	}
-	logStats(logger, stats)
+	return failed
 }
 
-// runLoop executes processing in a loop with the given interval
-func runLoop(processor *Processor, logger *log.Logger, interval int, sigChan chan os.Signal) {
-	ticker := time.NewTicker(time.Duration(interval) * time.Second)
-	defer ticker.Stop()
+// defaultConfigPath returns the config path used when -config is not given
+func defaultConfigPath() string {
+	if path := os.Getenv("MINIFLUX_RULES_FILE"); path != "" {
+		return path
+	}
+	return "rules.yaml"
+}
 
-	// Run immediately on start
-	logger.Println("Starting initial processing run")
-	stats, err := processor.Process()
+// buildMatcher compiles the rules and attaches the helpers they need
+func buildMatcher(config *Config, client MinifluxClient, logger *slog.Logger) (*Matcher, error) {
+	matcher, err := NewMatcher(config.Rules)
 	if err != nil {
-		logger.Printf("Processing error: %v", err)
+		return nil, err
+	}
+	matcher.SetLogger(logger)
+	if err := matcher.AddCategoryDefaults(config.CategoryDefaults); err != nil {
+		return nil, err
+	}
+	if usesDeadLinkCheck(config.Rules) {
+		rateLimit := config.LinkCheck.RateLimit
+		if rateLimit == 0 {
+			rateLimit = defaultLinkCheckRateLimit
+		}
+		cacheTTL := config.LinkCheck.CacheTTL
+		if cacheTTL == 0 {
+			cacheTTL = defaultLinkCheckCacheTTL
+		}
+		matcher.SetLinkChecker(NewLinkChecker(nil, rateLimit, cacheTTL))
+	}
+	if config.Embeddings.Endpoint != "" {
+		embedder, err := NewEmbedder(config.Embeddings, logger)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create embedder: %w", err)
+		}
+		matcher.SetEmbedder(embedder)
+	}
+
+	if usesVideoDurations(config.Rules) {
+		matcher.SetVideoDurations(NewVideoDurations(nil, logger))
+	}
+
+	if usesEnclosureDurations(config.Rules) {
+		matcher.SetEnclosureDurations(NewEnclosureDurations(nil, logger))
+	}
+
+	if usesFullContent(config.Rules) {
+		matcher.SetFullContent(NewFullContent(client, logger))
+	}
+
+	if usesTopics(config.Rules) {
+		model, err := LoadTopicModel(config.TopicModel)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load topic model: %w", err)
+		}
+		if err := model.CheckRules(config.Rules); err != nil {
+			return nil, fmt.Errorf("invalid topic rules: %w", err)
+		}
+		matcher.SetTopicModel(model)
+	}
+	return matcher, nil
+}
+
+// processorOptions collects the processor settings from the config
+func processorOptions(config *Config) ProcessorOptions {
+	return ProcessorOptions{
+		SkipStarred: config.SkipStarred,
+		Aggregates:  config.Aggregates,
+		Location:    config.Location(),
+		ReadReport:  config.ReadReport,
+		ConfigHash:  config.Hash,
+		Macros:      config.Macros,
+		Webhooks:    config.Webhooks,
+		Notify:      config.Notify,
+		Notifiers:   config.Notifiers,
+		Email:       config.Email,
+		Wallabag:    config.Wallabag,
+		Readwise:    config.Readwise,
+		Bookmarks:   config.Bookmarks,
+
+		FlushHistory: config.FlushHistory,
+		ExportPath:   config.ExportPath,
+		ExportFormat: config.ExportFormat,
+		AuditLog:     config.AuditLog,
+
+		ActionOrder:    config.ActionOrder,
+		MaxChangeRatio: config.MaxChangeRatio,
+		FetchStrategy:  config.FetchStrategy,
+		FetchOrder:     config.FetchOrder,
+		FetchDirection: config.FetchDirection,
+
+		MaxActionsPerRun:  config.MaxActionsPerRun,
+		MaxRemovalsPerRun: config.MaxRemovalsPerRun,
+		MaxEntriesPerRun:  config.MaxEntriesPerRun,
+		Quarantine:        config.Quarantine,
+
+		Statuses:     config.Statuses,
+		Categories:   config.Categories,
+		ExcludeFeeds: config.ExcludeFeeds,
+
+		FeedScope:     config.FeedScope,
+		CategoryScope: config.CategoryScope,
+
+		Concurrency:     config.Concurrency,
+		FeedCacheTTL:    config.FeedCacheTTL,
+		PageRetries:     config.PageRetries,
+		SkipFailedPages: config.SkipFailedPages,
+		SkipEvaluated:   config.SkipEvaluated,
+		FetchSince:      config.FetchSince,
+	}
+}
+
+// usesDeadLinkCheck reports whether any rule needs the link checker
+func usesDeadLinkCheck(rules []Rule) bool {
+	for _, rule := range rules {
+		if rule.DeadLinkCheck {
+			return true
+		}
+	}
+	return false
+}
+
+// usesVideoDurations reports whether any rule needs YouTube video durations
+func usesVideoDurations(rules []Rule) bool {
+	for _, rule := range rules {
+		if rule.VideoDurationLT > 0 || rule.VideoDurationGT > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// usesEnclosureDurations reports whether any rule needs podcast episode durations
+func usesEnclosureDurations(rules []Rule) bool {
+	for _, rule := range rules {
+		if rule.EnclosureDurationLT > 0 || rule.EnclosureDurationGT > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// usesFullContent reports whether any rule matches against original articles
+func usesFullContent(rules []Rule) bool {
+	for _, rule := range rules {
+		if rule.FetchFullContent {
+			return true
+		}
+	}
+	return false
+}
+
+// usesTopics reports whether any rule needs the topic classifier
+func usesTopics(rules []Rule) bool {
+	for _, rule := range rules {
+		if len(rule.Topics()) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// splitList splits a comma-separated flag value, dropping empty items
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// runOnce executes a single processing run
+func runOnce(instances []*instance, report reportTarget) error {
+	return runProcessing(instances, report)
+}
+
+// runLoop executes processing in a loop on the given schedule, and in between whenever
+// a run is requested through runs
+// It returns the error of a run aborted by a change limit, which stops the loop;
+// runs that skipped pages are retried at the next scheduled time
+func runLoop(instances []*instance, logger *slog.Logger, schedule loopSchedule, sigChan chan os.Signal, runs <-chan struct{}, report reportTarget) error {
+	// An interval loop runs immediately on start, a cron schedule waits for its first time
+	_, interval := schedule.(intervalSchedule)
+
+	due := time.Now()
+	if interval {
+		logger.Info("Starting initial processing run")
+		if err := runProcessing(instances, report); fatalRunError(err) {
+			return err
+		}
 	}
-	logStats(logger, stats)
 
+	due = schedule.Next(due)
 	for {
+		// A run that overran the next due time is followed a full period later, not right away
+		if now := time.Now(); due.Before(now) {
+			due = schedule.Next(now)
+		}
+		if !interval {
+			logger.Info("Next processing run", "at", due)
+		}
+
+		timer := time.NewTimer(time.Until(due))
 		select {
-		case <-ticker.C:
-			logger.Println("Starting scheduled processing run")
-			stats, err := processor.Process()
-			if err != nil {
-				logger.Printf("Processing error: %v", err)
+		case <-timer.C:
+			logger.Info("Starting scheduled processing run")
+			if err := runProcessing(instances, report); fatalRunError(err) {
+				return err
+			}
+			due = schedule.Next(due)
+
+		case <-runs:
+			// A requested run leaves the next scheduled run where it was
+			timer.Stop()
+			logger.Info("Starting requested processing run")
+			if err := runProcessing(instances, report); fatalRunError(err) {
+				return err
 			}
-			logStats(logger, stats)
 
 		case sig := <-sigChan:
-			logger.Printf("Received signal %v, shutting down", sig)
-			return
+			timer.Stop()
+			logger.Info("Received signal, shutting down", "signal", sig.String())
+			return nil
+		}
+	}
+}
+
+// fatalRunError reports whether a run's error stops the program rather than waiting for the next run
+func fatalRunError(err error) bool {
+	var partial *PartialFetchError
+	return err != nil && !errors.As(err, &partial)
+}
+
+// runProcessing performs one run of each instance, logs its stats and writes the report if requested
+// It returns the error of a run aborted by a change limit or completed with skipped pages,
+// preferring an aborted run; other errors are only logged. A failed instance does not hold up the others.
+func runProcessing(instances []*instance, report reportTarget) error {
+	var runErr error
+	for _, inst := range instances {
+		err := runInstance(inst, report)
+		var budgetErr *ChangeBudgetError
+		if err != nil && (runErr == nil || errors.As(err, &budgetErr)) {
+			runErr = err
+		}
+	}
+	return runErr
+}
+
+// runInstance performs one run of an instance and returns the error that runProcessing reports
+func runInstance(inst *instance, report reportTarget) error {
+	logger := inst.logger
+	started := time.Now()
+	stats, err := inst.processor.Process()
+	finished := time.Now()
+	inst.runs.record(&lastRun{started: started, finished: finished, stats: stats, err: err})
+	inst.processor.metrics.recordRun(stats, err, finished.Sub(started), finished)
+	if err != nil {
+		logger.Error("Processing error", "error", err)
+	}
+	logStats(logger, stats)
+
+	if report.Path != "" {
+		path := inst.reportPath(report)
+		if err := saveReport(path, report.Format, stats, err, inst.processor.dryRun); err != nil {
+			logger.Error("Failed to write report", "error", err)
+		} else {
+			logger.Info("Wrote report", "format", report.Format, "path", path)
 		}
 	}
+
+	var budgetErr *ChangeBudgetError
+	var partial *PartialFetchError
+	if errors.As(err, &budgetErr) || errors.As(err, &partial) {
+		return err
+	}
+	return nil
 }
 
 // logStats logs the processing statistics
-func logStats(logger *log.Logger, stats *ProcessStats) {
-	logger.Printf(
-		"Processing complete: %d entries checked, %d matched, %d marked read, %d removed, %d errors",
-		stats.TotalEntries,
-		stats.MatchedEntries,
-		stats.MarkedRead,
-		stats.Removed,
-		stats.Errors,
+func logStats(logger *slog.Logger, stats *ProcessStats) {
+	logger.InfoContext(summaryContext,
+		"Processing complete",
+		"checked", stats.TotalEntries,
+		"matched", stats.MatchedEntries,
+		"marked_read", stats.MarkedRead,
+		"marked_unread", stats.MarkedUnread,
+		"removed", stats.Removed,
+		"starred", stats.Starred,
+		"unstarred", stats.Unstarred,
+		"saved", stats.Saved,
+		"notified", stats.Notified,
+		"retitled", stats.Retitled,
+		"errors", stats.Errors,
+		"config_hash", stats.ConfigHash,
 	)
+
+	if stats.Quarantined > 0 {
+		logger.InfoContext(summaryContext, "Quarantined entries, removed by the purge subcommand", "entries", stats.Quarantined)
+	}
+	if stats.SkippedPages > 0 {
+		logger.WarnContext(summaryContext, "Skipped pages that could not be fetched", "pages", stats.SkippedPages)
+	}
+	if len(stats.APIErrors) > 0 {
+		var counts []any
+		for _, key := range sortedAPIErrorKeys(stats.APIErrors) {
+			counts = append(counts, slog.Int(key.String(), stats.APIErrors[key]))
+		}
+		logger.WarnContext(summaryContext, "API errors", slog.Group("api_errors", counts...))
+	}
 }
@@ -1,8 +1,12 @@
 package main
 
 import (
+	"context"
+	"log/slog"
 	"regexp"
+	"slices"
 	"strings"
+	"time"
 
 	miniflux "miniflux.app/v2/client"
 )
@@ -10,15 +14,30 @@ import (
 // Matcher handles rule matching against entries
 type Matcher struct {
 	compiledRules []compiledRule
+	disabled      map[string]bool
+	linkChecker   *LinkChecker
+	state         *State
+	embedder      *Embedder
+	topicModel    *TopicModel
+	videos        *VideoDurations
+	enclosures    *EnclosureDurations
+	fullContent   *FullContent
+	macros        map[string][]string // user macros, telling which rules default to read entries
+	logger        *slog.Logger        // optional, traces rule evaluations at debug level
 }
 
 // compiledRule holds pre-compiled regex patterns for a rule
 type compiledRule struct {
-	rule    Rule
-	feed    *regexp.Regexp
-	author  *regexp.Regexp
-	title   *regexp.Regexp
-	content *regexp.Regexp
+	rule     Rule
+	feed     *regexp.Regexp
+	category *regexp.Regexp
+	author   *regexp.Regexp
+	title    *regexp.Regexp
+	content  *regexp.Regexp
+
+	commentsURL *regexp.Regexp
+
+	fallback bool // category default, evaluated only when no other rule matched
 }
 
 // NewMatcher creates a new Matcher with pre-compiled regex patterns
@@ -26,41 +45,141 @@ func NewMatcher(rules []Rule) (*Matcher, error) {
 	compiled := make([]compiledRule, 0, len(rules))
 
 	for _, rule := range rules {
-		cr := compiledRule{rule: rule}
-		var err error
+		cr, err := compileRule(rule)
+		if err != nil {
+			return nil, err
+		}
+		compiled = append(compiled, cr)
+	}
+
+	return &Matcher{compiledRules: compiled, disabled: make(map[string]bool)}, nil
+}
+
+// compileRule pre-compiles the regex patterns of a rule
+func compileRule(rule Rule) (compiledRule, error) {
+	cr := compiledRule{rule: rule}
+	var err error
+
+	if rule.Feed != "" {
+		cr.feed, err = regexp.Compile(rule.effectivePattern(rule.Feed, rule.FeedPattern))
+		if err != nil {
+			return cr, &RegexError{Field: "feed", Rule: rule.Name, Err: err}
+		}
+	}
 
-		if rule.Feed != "" {
-			cr.feed, err = regexp.Compile(rule.Feed)
-			if err != nil {
-				return nil, &RegexError{Field: "feed", Rule: rule.Name, Err: err}
-			}
+	if rule.Category != "" {
+		cr.category, err = regexp.Compile(rule.effectivePattern(rule.Category, ""))
+		if err != nil {
+			return cr, &RegexError{Field: "category", Rule: rule.Name, Err: err}
 		}
+	}
 
-		if rule.Author != "" {
-			cr.author, err = regexp.Compile(rule.Author)
-			if err != nil {
-				return nil, &RegexError{Field: "author", Rule: rule.Name, Err: err}
-			}
+	if rule.Author != "" {
+		cr.author, err = regexp.Compile(rule.effectivePattern(rule.Author, rule.AuthorPattern))
+		if err != nil {
+			return cr, &RegexError{Field: "author", Rule: rule.Name, Err: err}
 		}
+	}
 
-		if rule.Title != "" {
-			cr.title, err = regexp.Compile(rule.Title)
-			if err != nil {
-				return nil, &RegexError{Field: "title", Rule: rule.Name, Err: err}
-			}
+	if rule.Title != "" {
+		cr.title, err = regexp.Compile(rule.effectivePattern(rule.Title, rule.TitlePattern))
+		if err != nil {
+			return cr, &RegexError{Field: "title", Rule: rule.Name, Err: err}
 		}
+	}
 
-		if rule.Content != "" {
-			cr.content, err = regexp.Compile(rule.Content)
-			if err != nil {
-				return nil, &RegexError{Field: "content", Rule: rule.Name, Err: err}
-			}
+	if rule.Content != "" {
+		cr.content, err = regexp.Compile(rule.effectivePattern(rule.Content, rule.ContentPattern))
+		if err != nil {
+			return cr, &RegexError{Field: "content", Rule: rule.Name, Err: err}
 		}
+	}
 
-		compiled = append(compiled, cr)
+	if rule.CommentsURL != "" {
+		cr.commentsURL, err = regexp.Compile(rule.effectivePattern(rule.CommentsURL, rule.CommentsURLPattern))
+		if err != nil {
+			return cr, &RegexError{Field: "comments_url", Rule: rule.Name, Err: err}
+		}
+	}
+
+	return cr, nil
+}
+
+// Rules returns the rules known to the matcher, in evaluation order
+func (m *Matcher) Rules() []Rule {
+	rules := make([]Rule, 0, len(m.compiledRules))
+	for _, cr := range m.compiledRules {
+		rules = append(rules, cr.rule)
+	}
+	return rules
+}
+
+// SetLinkChecker enables dead_link_check conditions
+func (m *Matcher) SetLinkChecker(checker *LinkChecker) {
+	m.linkChecker = checker
+}
+
+// SetEmbedder enables similar_to conditions
+func (m *Matcher) SetEmbedder(embedder *Embedder) {
+	m.embedder = embedder
+}
+
+// SetTopicModel enables topic conditions
+func (m *Matcher) SetTopicModel(model *TopicModel) {
+	m.topicModel = model
+}
+
+// SetVideoDurations enables video_duration conditions
+func (m *Matcher) SetVideoDurations(videos *VideoDurations) {
+	m.videos = videos
+}
+
+// SetEnclosureDurations enables enclosure_duration conditions
+func (m *Matcher) SetEnclosureDurations(enclosures *EnclosureDurations) {
+	m.enclosures = enclosures
+}
+
+// SetFullContent enables fetch_full_content
+func (m *Matcher) SetFullContent(fullContent *FullContent) {
+	m.fullContent = fullContent
+}
+
+// SetLogger enables tracing each rule evaluation, logged when the logger is at debug level
+func (m *Matcher) SetLogger(logger *slog.Logger) {
+	m.logger = logger
+}
+
+// SetMacros sets the user macros rule actions expand with
+func (m *Matcher) SetMacros(macros map[string][]string) {
+	m.macros = macros
+}
+
+// SaveCaches persists caches built up while matching
+func (m *Matcher) SaveCaches() error {
+	if m.embedder != nil {
+		return m.embedder.SaveCache()
+	}
+	return nil
+}
+
+// SetState enables conditions backed by persistent state
+func (m *Matcher) SetState(state *State) {
+	m.state = state
+}
+
+// UsesSeenCache reports whether any rule relies on the seen-cache
+func (m *Matcher) UsesSeenCache() bool {
+	for _, cr := range m.compiledRules {
+		if cr.rule.ChangedSinceLastSeen || cr.rule.SeenTitleBefore || cr.rule.DuplicateContent {
+			return true
+		}
 	}
+	return false
+}
 
-	return &Matcher{compiledRules: compiled}, nil
+// DisableRule excludes the named rule from subsequent matching
+func (m *Matcher) DisableRule(name string) {
+	m.disabled[name] = true
 }
 
 // RegexError represents an error in compiling a regex pattern
@@ -83,52 +202,300 @@ type MatchResult struct {
 
 // Match checks if an entry matches any rule and returns the first matching rule
 func (m *Matcher) Match(entry *miniflux.Entry) MatchResult {
-	for _, cr := range m.compiledRules {
-		if m.matchRule(entry, &cr) {
-			return MatchResult{
-				Matched: true,
-				Rule:    &cr.rule,
-				Action:  strings.ToLower(cr.rule.Action),
-			}
+	results := m.MatchAll(entry)
+	if len(results) == 0 {
+		return MatchResult{Matched: false}
+	}
+	return results[0]
+}
+
+// MatchAll returns every matching rule in order, stopping after the first
+// matching rule that does not set continue
+// Category defaults are tried last, and only if no rule matched
+func (m *Matcher) MatchAll(entry *miniflux.Entry) []MatchResult {
+	var results []MatchResult
+	for i := range m.compiledRules {
+		cr := &m.compiledRules[i]
+		if m.disabled[cr.rule.Name] {
+			continue
+		}
+		if cr.fallback && len(results) > 0 {
+			break
+		}
+		if !m.matchRule(entry, cr) {
+			continue
+		}
+		results = append(results, MatchResult{
+			Matched: true,
+			Rule:    &cr.rule,
+			Action:  strings.ToLower(cr.rule.Action),
+		})
+		if !cr.rule.Continue || cr.fallback {
+			break
 		}
 	}
-	return MatchResult{Matched: false}
+	return results
 }
 
 // matchRule checks if an entry matches a single compiled rule
 // All non-empty patterns must match (AND logic)
 func (m *Matcher) matchRule(entry *miniflux.Entry, cr *compiledRule) bool {
+	if m.logger == nil || !m.logger.Enabled(context.Background(), slog.LevelDebug) {
+		return m.evalRule(entry, cr, nil, false)
+	}
+
+	var conditions []string
+	matched := m.evalRule(entry, cr, func(condition string, passed bool) {
+		result := "fail"
+		if passed {
+			result = "pass"
+		}
+		conditions = append(conditions, condition+"="+result)
+	}, false)
+	m.logger.Debug("Evaluated rule",
+		"rule", cr.rule.Name,
+		"entry_id", entry.ID,
+		"matched", matched,
+		"conditions", strings.Join(conditions, " "),
+	)
+	return matched
+}
+
+// evalRule checks the conditions of a rule against an entry, stopping at the first that fails
+// With a trace, the conditions checked are reported by their config key; with checkAll too,
+// every condition the rule sets is checked, including those after one that failed.
+func (m *Matcher) evalRule(entry *miniflux.Entry, cr *compiledRule, trace func(condition string, passed bool), checkAll bool) bool {
+	matched := true
+	// check records a condition, reporting whether evaluation goes on
+	check := func(condition string, passed bool) bool {
+		if trace != nil {
+			trace(condition, passed)
+		}
+		matched = matched && passed
+		return passed || checkAll
+	}
+
+	// Check the entry status against the statuses the rule is fetched for, which other
+	// rules may widen; entries without a status, such as evaluated samples, are not filtered
+	if entry.Status != "" && !check("status", slices.Contains(ruleStatuses(&cr.rule, m.macros), entry.Status)) {
+		return false
+	}
+
+	// Skip entries excepted in the config or learned from manual overrides
+	if m.excepted(entry, &cr.rule) && !check("exceptions", false) {
+		return false
+	}
+
+	// Check feed and category IDs
+	if len(cr.rule.FeedID) > 0 && !check("feed_id", slices.Contains(cr.rule.FeedID, entryFeedID(entry))) {
+		return false
+	}
+	if len(cr.rule.CategoryID) > 0 && !check("category_id", slices.Contains(cr.rule.CategoryID, entryCategoryID(entry))) {
+		return false
+	}
+
+	// Check starred flag
+	if cr.rule.Starred != nil && !check("starred", entry.Starred == *cr.rule.Starred) {
+		return false
+	}
+
+	// Check entry age from its publication date
+	age := time.Since(entry.Date)
+	if cr.rule.OlderThan > 0 && !check("older_than", !entry.Date.IsZero() && age > cr.rule.OlderThan) {
+		return false
+	}
+	if cr.rule.NewerThan > 0 && !check("newer_than", !entry.Date.IsZero() && age < cr.rule.NewerThan) {
+		return false
+	}
+
 	// Check feed title
 	if cr.feed != nil {
 		feedTitle := ""
 		if entry.Feed != nil {
 			feedTitle = entry.Feed.Title
 		}
-		if !cr.feed.MatchString(feedTitle) {
+		if !check("feed", matchText(cr.feed, feedTitle, cr.rule.Transliterate)) {
 			return false
 		}
 	}
 
-	// Check author
-	if cr.author != nil {
-		if !cr.author.MatchString(entry.Author) {
+	// Check category title
+	if cr.category != nil {
+		categoryTitle := ""
+		if entry.Feed != nil && entry.Feed.Category != nil {
+			categoryTitle = entry.Feed.Category.Title
+		}
+		if !check("category", cr.category.MatchString(categoryTitle)) {
 			return false
 		}
 	}
 
+	// Check author
+	if cr.author != nil && !check("author", matchText(cr.author, entry.Author, cr.rule.Transliterate)) {
+		return false
+	}
+
+	// Check exact author names
+	if len(cr.rule.Authors) > 0 && !check("authors", containsFold(cr.rule.Authors, entry.Author)) {
+		return false
+	}
+
 	// Check entry title
-	if cr.title != nil {
-		if !cr.title.MatchString(entry.Title) {
+	if cr.title != nil && !check("title", matchText(cr.title, entry.Title, cr.rule.Transliterate)) {
+		return false
+	}
+
+	// Check content, against the original article when the feed only carries a summary
+	if cr.content != nil {
+		content := entry.Content
+		if cr.rule.FetchFullContent && m.fullContent != nil {
+			content = m.fullContent.Content(entry)
+		}
+		if !check("content", matchText(cr.content, content, cr.rule.Transliterate)) {
 			return false
 		}
 	}
 
-	// Check content
-	if cr.content != nil {
-		if !cr.content.MatchString(entry.Content) {
+	// Check clickbait heuristics on the title
+	if cr.rule.TitleAllCaps && !check("title_all_caps", isAllCaps(entry.Title)) {
+		return false
+	}
+	if n := cr.rule.TitleEmojiCountGT; n != nil && !check("title_emoji_count_gt", emojiCount(entry.Title) > *n) {
+		return false
+	}
+	if n := cr.rule.TitleExclamationsGT; n != nil && !check("title_exclamations_gt", strings.Count(entry.Title, "!") > *n) {
+		return false
+	}
+
+	// Check comments URL
+	if cr.commentsURL != nil && !check("comments_url", cr.commentsURL.MatchString(entry.CommentsURL)) {
+		return false
+	}
+
+	// Check word count of the plain text content
+	if cr.rule.MinWords > 0 || cr.rule.MaxWords > 0 {
+		words := wordCount(entry.Content)
+		if cr.rule.MinWords > 0 && !check("min_words", words >= cr.rule.MinWords) {
+			return false
+		}
+		if cr.rule.MaxWords > 0 && !check("max_words", words <= cr.rule.MaxWords) {
+			return false
+		}
+	}
+
+	// Check content change against the seen-cache
+	if cr.rule.ChangedSinceLastSeen && !check("changed_since_last_seen",
+		m.state != nil && m.state.ContentChanged(entry.ID, contentHash(entry.Content))) {
+		return false
+	}
+
+	// Check for reposts of previously processed titles or URLs
+	if cr.rule.SeenTitleBefore && !check("seen_title_before", m.state != nil && m.state.SeenBefore(entry.ID, entry.Title, entry.URL)) {
+		return false
+	}
+
+	// Check topic assigned by the local classifier
+	if topics := cr.rule.Topics(); len(topics) > 0 {
+		topic := ""
+		if m.topicModel != nil {
+			topic = m.topicModel.Classify(entry.Title + "\n" + stripHTML(entry.Content))
+		}
+		if !check("topic", m.topicModel != nil && slices.Contains(topics, topic)) {
+			return false
+		}
+	}
+
+	// Check for verbatim copies of previously processed content
+	if cr.rule.DuplicateContent {
+		hash := normalizedContentHash(entry.Content)
+		if !check("duplicate_content", m.state != nil && hash != "" && m.state.DuplicateContent(entry.ID, hash)) {
+			return false
+		}
+	}
+
+	// Check semantic similarity to example texts
+	if sim := cr.rule.SimilarTo; sim != nil {
+		threshold := sim.Threshold
+		if threshold == 0 {
+			threshold = defaultSimilarityThreshold
+		}
+		text := entry.Title + "\n" + stripHTML(entry.Content)
+		if !check("similar_to", m.embedder != nil && m.embedder.Similar(text, sim.Examples, threshold)) {
+			return false
+		}
+	}
+
+	// Check YouTube video duration, fetched from the watch page on first use
+	if cr.rule.VideoDurationLT > 0 || cr.rule.VideoDurationGT > 0 {
+		var duration time.Duration
+		ok := m.videos != nil
+		if ok {
+			duration, ok = m.videos.Duration(entry.URL)
+		}
+		if cr.rule.VideoDurationLT > 0 && !check("video_duration_lt", ok && duration < cr.rule.VideoDurationLT) {
+			return false
+		}
+		if cr.rule.VideoDurationGT > 0 && !check("video_duration_gt", ok && duration > cr.rule.VideoDurationGT) {
+			return false
+		}
+	}
+
+	// Check podcast episode duration from the feed's itunes metadata
+	if cr.rule.EnclosureDurationLT > 0 || cr.rule.EnclosureDurationGT > 0 {
+		var duration time.Duration
+		ok := m.enclosures != nil
+		if ok {
+			duration, ok = m.enclosures.Duration(entry)
+		}
+		if cr.rule.EnclosureDurationLT > 0 && !check("enclosure_duration_lt", ok && duration < cr.rule.EnclosureDurationLT) {
 			return false
 		}
+		if cr.rule.EnclosureDurationGT > 0 && !check("enclosure_duration_gt", ok && duration > cr.rule.EnclosureDurationGT) {
+			return false
+		}
+	}
+
+	// Check link availability last since it performs a network request
+	if cr.rule.DeadLinkCheck && !check("dead_link_check", m.linkChecker != nil && m.linkChecker.IsDead(entry.URL)) {
+		return false
+	}
+
+	return matched
+}
+
+// matchText matches text, or with transliterate also its Latin transliteration
+func matchText(re *regexp.Regexp, text string, transliterated bool) bool {
+	if re.MatchString(text) {
+		return true
+	}
+	return transliterated && re.MatchString(transliterate(text))
+}
+
+// containsFold reports whether list contains s, ignoring case
+func containsFold(list []string, s string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, s) {
+			return true
+		}
 	}
+	return false
+}
 
-	return true
+// entryFeedID returns the entry's feed ID, falling back to the embedded feed
+func entryFeedID(entry *miniflux.Entry) int64 {
+	if entry.FeedID != 0 {
+		return entry.FeedID
+	}
+	if entry.Feed != nil {
+		return entry.Feed.ID
+	}
+	return 0
+}
+
+// entryCategoryID returns the entry's category ID, or 0 if unknown
+func entryCategoryID(entry *miniflux.Entry) int64 {
+	if entry.Feed != nil && entry.Feed.Category != nil {
+		return entry.Feed.Category.ID
+	}
+	return 0
 }
@@ -304,3 +304,231 @@ func TestMatcherEmptyRules(t *testing.T) {
 		t.Error("Expected no match with empty rules")
 	}
 }
+
+func TestMatcherFeedAndCategoryID(t *testing.T) {
+	rules := []Rule{
+		{
+			Name:       "Match feed 7 in category 3",
+			FeedID:     IDList{7, 8},
+			CategoryID: IDList{3},
+			Action:     "read",
+		},
+	}
+
+	matcher, err := NewMatcher(rules)
+	if err != nil {
+		t.Fatalf("Failed to create matcher: %v", err)
+	}
+
+	testCases := []struct {
+		name     string
+		entry    *miniflux.Entry
+		expected bool
+	}{
+		{"feed and category", &miniflux.Entry{FeedID: 7, Feed: &miniflux.Feed{ID: 7, Category: &miniflux.Category{ID: 3}}}, true},
+		{"renamed feed", &miniflux.Entry{FeedID: 8, Feed: &miniflux.Feed{ID: 8, Title: "New Name", Category: &miniflux.Category{ID: 3}}}, true},
+		{"wrong category", &miniflux.Entry{FeedID: 7, Feed: &miniflux.Feed{ID: 7, Category: &miniflux.Category{ID: 4}}}, false},
+		{"wrong feed", &miniflux.Entry{FeedID: 9, Feed: &miniflux.Feed{ID: 9, Category: &miniflux.Category{ID: 3}}}, false},
+		{"nil feed", &miniflux.Entry{FeedID: 7}, false},
+	}
+
+	for _, tc := range testCases {
+		result := matcher.Match(tc.entry)
+		if result.Matched != tc.expected {
+			t.Errorf("%s: expected matched=%v, got matched=%v", tc.name, tc.expected, result.Matched)
+		}
+	}
+}
+
+func TestMatcherAuthorsList(t *testing.T) {
+	rules := []Rule{
+		{
+			Name:    "Match listed authors",
+			Authors: []string{"J.R. Smith (Guest)", "Alice"},
+			Action:  "read",
+		},
+	}
+
+	matcher, err := NewMatcher(rules)
+	if err != nil {
+		t.Fatalf("Failed to create matcher: %v", err)
+	}
+
+	testCases := []struct {
+		author   string
+		expected bool
+	}{
+		{"J.R. Smith (Guest)", true},
+		{"j.r. smith (guest)", true},
+		{"ALICE", true},
+		{"JxR. Smith (Guest)", false},
+		{"Alice Cooper", false},
+		{"", false},
+	}
+
+	for _, tc := range testCases {
+		result := matcher.Match(&miniflux.Entry{ID: 1, Author: tc.author})
+		if result.Matched != tc.expected {
+			t.Errorf("Author '%s': expected matched=%v, got matched=%v", tc.author, tc.expected, result.Matched)
+		}
+	}
+}
+
+func TestMatcherCommentsURL(t *testing.T) {
+	rules := []Rule{
+		{
+			Name:        "Lobsters discussions",
+			CommentsURL: `^https://lobste\.rs/s/`,
+			Action:      "read",
+		},
+	}
+
+	matcher, err := NewMatcher(rules)
+	if err != nil {
+		t.Fatalf("Failed to create matcher: %v", err)
+	}
+
+	testCases := []struct {
+		commentsURL string
+		expected    bool
+	}{
+		{"https://lobste.rs/s/abc123/some_post", true},
+		{"https://news.ycombinator.com/item?id=1", false},
+		{"", false},
+	}
+
+	for _, tc := range testCases {
+		result := matcher.Match(&miniflux.Entry{ID: 1, CommentsURL: tc.commentsURL})
+		if result.Matched != tc.expected {
+			t.Errorf("Comments URL '%s': expected matched=%v, got matched=%v", tc.commentsURL, tc.expected, result.Matched)
+		}
+	}
+}
+
+func TestMatcherMatchAllContinue(t *testing.T) {
+	matcher, err := NewMatcher([]Rule{
+		{Name: "Flag sponsored", Title: "(?i)sponsored", Action: "read", Continue: true},
+		{Name: "Remove promos", Title: "(?i)promo", Action: "remove"},
+		{Name: "Never reached", Title: "(?i)sponsored", Action: "read"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create matcher: %v", err)
+	}
+
+	results := matcher.MatchAll(&miniflux.Entry{ID: 1, Title: "Sponsored promo"})
+	if len(results) != 2 {
+		t.Fatalf("Expected 2 results, got %d", len(results))
+	}
+	if results[0].Rule.Name != "Flag sponsored" || results[1].Rule.Name != "Remove promos" {
+		t.Errorf("Unexpected rules matched: %s, %s", results[0].Rule.Name, results[1].Rule.Name)
+	}
+
+	// Without a stopping rule, matching continues to the end
+	results = matcher.MatchAll(&miniflux.Entry{ID: 2, Title: "Sponsored post"})
+	if len(results) != 2 || results[1].Rule.Name != "Never reached" {
+		t.Errorf("Expected continue to reach the last rule, got %d results", len(results))
+	}
+}
+
+func TestMatcherWordCount(t *testing.T) {
+	matcher, err := NewMatcher([]Rule{
+		{Name: "Short posts", MinWords: 2, MaxWords: 4, Action: "read"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create matcher: %v", err)
+	}
+
+	testCases := []struct {
+		content  string
+		expected bool
+	}{
+		{`<p>One</p>`, false},
+		{`<p>Two <a href="https://example.com/a/very/long/url">words</a></p>`, true},
+		{`<p>Exactly four words here</p>`, true},
+		{`<p>This one has five words</p>`, false},
+	}
+
+	for _, tc := range testCases {
+		result := matcher.Match(&miniflux.Entry{ID: 1, Content: tc.content})
+		if result.Matched != tc.expected {
+			t.Errorf("Content %q: expected matched=%v, got %v", tc.content, tc.expected, result.Matched)
+		}
+	}
+}
+
+func TestMatcherClickbaitHeuristics(t *testing.T) {
+	zero, two := 0, 2
+	matcher, err := NewMatcher([]Rule{
+		{Name: "Shouting", TitleAllCaps: true, Action: "read"},
+		{Name: "Emoji", TitleEmojiCountGT: &zero, Action: "read"},
+		{Name: "Exclamations", TitleExclamationsGT: &two, Action: "read"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create matcher: %v", err)
+	}
+
+	testCases := []struct {
+		title    string
+		expected string
+	}{
+		{"YOU WON'T BELIEVE THIS", "Shouting"},
+		{"AI news", ""},
+		{"New release 🚀", "Emoji"},
+		{"Wow!!! Amazing", "Exclamations"},
+		{"Wow!! Amazing", ""},
+	}
+
+	for _, tc := range testCases {
+		result := matcher.Match(&miniflux.Entry{ID: 1, Title: tc.title})
+		name := ""
+		if result.Matched {
+			name = result.Rule.Name
+		}
+		if name != tc.expected {
+			t.Errorf("Title %q: expected rule %q, got %q", tc.title, tc.expected, name)
+		}
+	}
+}
+
+func TestMatcherTransliterate(t *testing.T) {
+	matcher, err := NewMatcher([]Rule{
+		{Name: "Casino", Title: "(?i)kazino|kasino", Transliterate: true, Action: "remove"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create matcher: %v", err)
+	}
+
+	testCases := []struct {
+		title    string
+		expected string
+	}{
+		{"Лучшее КАЗИНО онлайн", "Casino"},
+		{"Καζίνο μπόνους", "Casino"},
+		{"Kazino bonus", "Casino"},
+		{"Новости недели", ""},
+	}
+
+	for _, tc := range testCases {
+		result := matcher.Match(&miniflux.Entry{ID: 1, Title: tc.title})
+		name := ""
+		if result.Matched {
+			name = result.Rule.Name
+		}
+		if name != tc.expected {
+			t.Errorf("Title %q: expected rule %q, got %q", tc.title, tc.expected, name)
+		}
+	}
+
+	// Without transliterate, only the original script is matched
+	strict, err := NewMatcher([]Rule{{Name: "Casino", Title: "(?i)kazino", Action: "remove"}})
+	if err != nil {
+		t.Fatalf("Failed to create matcher: %v", err)
+	}
+	if strict.Match(&miniflux.Entry{ID: 1, Title: "Лучшее КАЗИНО онлайн"}).Matched {
+		t.Error("Expected no match without transliterate")
+	}
+
+	if got := transliterate("Щука и Ёж"); got != "Shchuka i Ezh" {
+		t.Errorf("Expected %q, got %q", "Shchuka i Ezh", got)
+	}
+}
@@ -0,0 +1,188 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Results of a run, as counted by the runs metric
+const (
+	runResultSuccess = "success"
+	runResultPartial = "partial" // completed with skipped pages
+	runResultFailure = "failure"
+)
+
+// processMetrics accumulates what a processor did across runs and webhook batches, for Prometheus
+type processMetrics struct {
+	mu sync.Mutex
+
+	entries int            // entries checked
+	matches map[string]int // matches by rule name
+	actions map[string]int // applied actions by type
+	runs    map[string]int // runs by result
+
+	lastDuration time.Duration // duration of the latest run
+	lastSuccess  time.Time     // end of the latest successful run
+}
+
+// newProcessMetrics creates empty metrics
+func newProcessMetrics() *processMetrics {
+	return &processMetrics{
+		matches: make(map[string]int),
+		actions: make(map[string]int),
+		runs:    make(map[string]int),
+	}
+}
+
+// recordEntries adds the entries, matches and actions of processed entries
+func (m *processMetrics) recordEntries(stats *ProcessStats) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.entries += stats.TotalEntries
+	for _, match := range stats.Matches {
+		m.matches[match.Rule]++
+	}
+	for action, count := range map[string]int{
+		"read":       stats.MarkedRead,
+		"unread":     stats.MarkedUnread,
+		"remove":     stats.Removed,
+		"quarantine": stats.Quarantined,
+		"star":       stats.Starred,
+		"unstar":     stats.Unstarred,
+		"save":       stats.Saved,
+		"notify":     stats.Notified,
+		"retitle":    stats.Retitled,
+	} {
+		m.actions[action] += count
+	}
+}
+
+// recordRun adds a run's entries and outcome
+func (m *processMetrics) recordRun(stats *ProcessStats, err error, duration time.Duration, finished time.Time) {
+	m.recordEntries(stats)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var partial *PartialFetchError
+	switch {
+	case err == nil:
+		m.runs[runResultSuccess]++
+		m.lastSuccess = finished
+	case errors.As(err, &partial):
+		m.runs[runResultPartial]++
+	default:
+		m.runs[runResultFailure]++
+	}
+	m.lastDuration = duration
+}
+
+// metricsSnapshot is a copy of processMetrics taken under its lock
+type metricsSnapshot struct {
+	entries      int
+	matches      map[string]int
+	actions      map[string]int
+	runs         map[string]int
+	lastDuration time.Duration
+	lastSuccess  time.Time
+}
+
+// snapshot copies the metrics
+func (m *processMetrics) snapshot() metricsSnapshot {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return metricsSnapshot{
+		entries:      m.entries,
+		matches:      maps.Clone(m.matches),
+		actions:      maps.Clone(m.actions),
+		runs:         maps.Clone(m.runs),
+		lastDuration: m.lastDuration,
+		lastSuccess:  m.lastSuccess,
+	}
+}
+
+// promLabels formats label pairs as {name="value",...}, leaving out empty values
+// so the account of miniflux_url has no instance label
+func promLabels(pairs ...string) string {
+	var labels []string
+	for i := 0; i+1 < len(pairs); i += 2 {
+		if pairs[i+1] != "" {
+			labels = append(labels, fmt.Sprintf("%s=%q", pairs[i], pairs[i+1]))
+		}
+	}
+	if len(labels) == 0 {
+		return ""
+	}
+	return "{" + strings.Join(labels, ",") + "}"
+}
+
+// writeMetricHeader writes the HELP and TYPE lines of a metric
+func writeMetricHeader(w io.Writer, name, kind, help string) {
+	fmt.Fprintf(w, "# HELP %s %s\n", name, help)
+	fmt.Fprintf(w, "# TYPE %s %s\n", name, kind)
+}
+
+// writeMetrics writes the metrics of every instance in the Prometheus text exposition format
+func writeMetrics(w io.Writer, instances []*instance) {
+	snapshots := make([]metricsSnapshot, len(instances))
+	for i, inst := range instances {
+		snapshots[i] = inst.processor.metrics.snapshot()
+	}
+
+	writeMetricHeader(w, "miniflux_jobs_entries_processed_total", "counter", "Entries checked against the rules.")
+	for i, inst := range instances {
+		fmt.Fprintf(w, "miniflux_jobs_entries_processed_total%s %d\n", promLabels("instance", inst.name), snapshots[i].entries)
+	}
+
+	writeMetricHeader(w, "miniflux_jobs_rule_matches_total", "counter", "Entries matched by each rule.")
+	for i, inst := range instances {
+		matches := snapshots[i].matches
+		for _, rule := range slices.Sorted(maps.Keys(matches)) {
+			fmt.Fprintf(w, "miniflux_jobs_rule_matches_total%s %d\n", promLabels("instance", inst.name, "rule", rule), matches[rule])
+		}
+	}
+
+	writeMetricHeader(w, "miniflux_jobs_actions_total", "counter", "Actions applied to entries by type.")
+	for i, inst := range instances {
+		actions := snapshots[i].actions
+		for _, action := range slices.Sorted(maps.Keys(actions)) {
+			fmt.Fprintf(w, "miniflux_jobs_actions_total%s %d\n", promLabels("instance", inst.name, "action", action), actions[action])
+		}
+	}
+
+	writeMetricHeader(w, "miniflux_jobs_runs_total", "counter", "Processing runs by result: success, partial or failure.")
+	for i, inst := range instances {
+		for _, result := range []string{runResultSuccess, runResultPartial, runResultFailure} {
+			fmt.Fprintf(w, "miniflux_jobs_runs_total%s %d\n", promLabels("instance", inst.name, "result", result), snapshots[i].runs[result])
+		}
+	}
+
+	writeMetricHeader(w, "miniflux_jobs_last_run_duration_seconds", "gauge", "Duration of the latest processing run.")
+	for i, inst := range instances {
+		fmt.Fprintf(w, "miniflux_jobs_last_run_duration_seconds%s %g\n", promLabels("instance", inst.name), snapshots[i].lastDuration.Seconds())
+	}
+
+	// Zero until the first successful run, so alerts on its age fire for a daemon that never succeeded
+	writeMetricHeader(w, "miniflux_jobs_last_success_timestamp_seconds", "gauge", "Unix time the latest successful run finished.")
+	for i, inst := range instances {
+		var timestamp int64
+		if !snapshots[i].lastSuccess.IsZero() {
+			timestamp = snapshots[i].lastSuccess.Unix()
+		}
+		fmt.Fprintf(w, "miniflux_jobs_last_success_timestamp_seconds%s %d\n", promLabels("instance", inst.name), timestamp)
+	}
+
+	writeAPIErrorsHeader(w)
+	for _, inst := range instances {
+		if apiErrors := inst.processor.apiErrors(); apiErrors != nil {
+			apiErrors.writeSamples(w, inst.name)
+		}
+	}
+}
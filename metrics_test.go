@@ -0,0 +1,69 @@
+package main
+
+import (
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	miniflux "miniflux.app/v2/client"
+)
+
+func TestServerMetrics(t *testing.T) {
+	mockClient := &MockClient{entries: []*miniflux.Entry{
+		{ID: 1, Title: "Sponsored: buy now", Status: "unread", Feed: &miniflux.Feed{ID: 1, Title: "News"}},
+		{ID: 2, Title: "Election results", Status: "unread", Feed: &miniflux.Feed{ID: 1, Title: "News"}},
+	}}
+	matcher, err := NewMatcher([]Rule{{Name: "Sponsored", Title: "(?i)sponsored", Action: "read"}})
+	if err != nil {
+		t.Fatalf("Failed to create matcher: %v", err)
+	}
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	processor := NewProcessor(mockClient, matcher, logger, false)
+
+	partnerMatcher, err := NewMatcher(nil)
+	if err != nil {
+		t.Fatalf("Failed to create matcher: %v", err)
+	}
+	partner := &instance{name: "partner", processor: NewProcessor(&MockClient{}, partnerMatcher, logger, false), logger: logger}
+	instances := []*instance{{processor: processor, logger: logger}, partner}
+
+	finished := time.Unix(1760000000, 0)
+	processor.metrics.recordRun(&ProcessStats{}, &PartialFetchError{Pages: 1}, time.Second, finished.Add(-time.Hour))
+	stats, err := processor.Process()
+	if err != nil {
+		t.Fatalf("Process failed: %v", err)
+	}
+	processor.metrics.recordRun(stats, nil, 1500*time.Millisecond, finished)
+
+	server := NewServer(processor, logger)
+	server.SetInstances(instances)
+	for _, handler := range []http.Handler{server.Handler(), server.MetricsHandler()} {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+		metrics := rec.Body.String()
+		for _, want := range []string{
+			"# TYPE miniflux_jobs_entries_processed_total counter\nminiflux_jobs_entries_processed_total 2\n",
+			`miniflux_jobs_entries_processed_total{instance="partner"} 0`,
+			`miniflux_jobs_rule_matches_total{rule="Sponsored"} 1`,
+			`miniflux_jobs_actions_total{action="read"} 1`,
+			`miniflux_jobs_runs_total{result="success"} 1`,
+			`miniflux_jobs_runs_total{result="partial"} 1`,
+			`miniflux_jobs_runs_total{instance="partner",result="success"} 0`,
+			"miniflux_jobs_last_run_duration_seconds 1.5\n",
+			"miniflux_jobs_last_success_timestamp_seconds 1760000000\n",
+			`miniflux_jobs_last_success_timestamp_seconds{instance="partner"} 0`,
+			"# TYPE miniflux_jobs_api_errors_total counter",
+		} {
+			if !strings.Contains(metrics, want) {
+				t.Errorf("Expected %q in the metrics:\n%s", want, metrics)
+			}
+		}
+		if strings.Count(metrics, "# TYPE miniflux_jobs_runs_total") != 1 {
+			t.Errorf("Expected each metric declared once:\n%s", metrics)
+		}
+	}
+}
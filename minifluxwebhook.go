@@ -0,0 +1,152 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"slices"
+
+	miniflux "miniflux.app/v2/client"
+)
+
+// maxMinifluxWebhookBodySize limits the size of the entry batches Miniflux posts
+const maxMinifluxWebhookBodySize = 32 << 20
+
+// Headers and event type of the webhooks Miniflux sends for new entries
+const (
+	minifluxSignatureHeader = "X-Miniflux-Signature"
+	minifluxEventHeader     = "X-Miniflux-Event-Type"
+	minifluxEventNewEntries = "new_entries"
+)
+
+// minifluxWebhookEvent is the body of a new_entries webhook: the refreshed feed and its new entries
+type minifluxWebhookEvent struct {
+	EventType string            `json:"event_type"`
+	Feed      *miniflux.Feed    `json:"feed"`
+	Entries   []*miniflux.Entry `json:"entries"`
+}
+
+// validMinifluxSignature checks the hex HMAC-SHA256 of the body Miniflux signs with the webhook secret
+func validMinifluxSignature(body []byte, signature, secret string) bool {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	expected, err := hex.DecodeString(signature)
+	return err == nil && hmac.Equal(expected, mac.Sum(nil))
+}
+
+// handleMinifluxWebhook processes the entries of a Miniflux new_entries webhook right away,
+// instead of waiting for the next run to fetch them
+// Other events, such as save_entry, are acknowledged and ignored.
+func (s *Server) handleMinifluxWebhook(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxMinifluxWebhookBodySize))
+	if err != nil {
+		http.Error(w, "failed to read body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if !validMinifluxSignature(body, r.Header.Get(minifluxSignatureHeader), s.webhookSecret) {
+		s.logger.Warn("Rejected Miniflux webhook with an invalid signature", "remote", r.RemoteAddr)
+		http.Error(w, "invalid signature", http.StatusUnauthorized)
+		return
+	}
+	if event := r.Header.Get(minifluxEventHeader); event != minifluxEventNewEntries {
+		s.logger.Debug("Ignoring Miniflux webhook event", "event", event)
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	var event minifluxWebhookEvent
+	if err := json.Unmarshal(body, &event); err != nil {
+		http.Error(w, "invalid webhook JSON: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	for _, entry := range event.Entries {
+		if entry.Feed == nil && event.Feed != nil && entry.FeedID == event.Feed.ID {
+			feed := *event.Feed
+			entry.Feed = &feed
+		}
+	}
+
+	s.logger.Info("Received new entries from Miniflux", "entries", len(event.Entries))
+	stats, err := s.processor.ProcessEntries(event.Entries)
+	if stats != nil {
+		logStats(s.logger, stats)
+	}
+	var budget *ChangeBudgetError
+	switch {
+	case errors.As(err, &budget):
+		// Retrying would exceed the budget again
+		s.logger.Error("Webhook entries left unprocessed", "error", err)
+	case err != nil:
+		s.logger.Error("Failed to process webhook entries", "error", err)
+		http.Error(w, "processing failed", http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ProcessEntries applies the rules to entries pushed by Miniflux, as a run limited to them
+// Entries outside the fetch scope or with a status the rules are not fetched for are left
+// alone. Run-wide work such as digests, aggregates, read reports and consuming one-off rules
+// is left to the regular runs.
+func (p *Processor) ProcessEntries(entries []*miniflux.Entry) (*ProcessStats, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	stats := &ProcessStats{ConfigHash: p.options.ConfigHash}
+
+	if apiErrors := p.apiErrors(); apiErrors != nil {
+		before := apiErrors.Snapshot()
+		defer func() { stats.APIErrors = apiErrorsSince(before, apiErrors.Snapshot()) }()
+	}
+
+	leading, err := p.acquireLeadership()
+	if err != nil {
+		return stats, err
+	}
+	if !leading {
+		p.logger.Info("Another replica holds the leader lock, skipping webhook entries")
+		return stats, nil
+	}
+
+	p.disableConsumedRules()
+	p.runStarted = p.now()
+	p.enrichEntries(entries)
+
+	statuses := p.fetchStatuses()
+	inScope := make([]*miniflux.Entry, 0, len(entries))
+	for _, entry := range entries {
+		if entry.Feed != nil && !p.inFetchScope(entry.Feed) || !slices.Contains(statuses, entry.Status) {
+			continue
+		}
+		inScope = append(inScope, entry)
+	}
+	stats.TotalEntries = len(inScope)
+
+	var pending []pendingEntry
+	p.matchPage(inScope, func(entry *miniflux.Entry, results []MatchResult) {
+		pending = append(pending, pendingEntry{entry: entry, results: results})
+	})
+	if p.budgeted() {
+		if err := p.checkChangeBudget(pending, stats.TotalEntries); err != nil {
+			return stats, err
+		}
+	}
+	for _, pe := range pending {
+		p.processEntry(pe.entry, pe.results, stats)
+		p.recordSeen(pe.entry)
+	}
+
+	if err := p.matcher.SaveCaches(); err != nil {
+		p.logger.Error("Failed to save caches", "error", err)
+	}
+	p.metrics.recordEntries(stats)
+
+	if err := p.saveState(); err != nil {
+		return stats, err
+	}
+	return stats, nil
+}
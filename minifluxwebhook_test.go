@@ -0,0 +1,90 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	miniflux "miniflux.app/v2/client"
+)
+
+func signMinifluxWebhook(body, secret string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(body))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestServerMinifluxWebhook(t *testing.T) {
+	mockClient := &MockClient{feeds: miniflux.Feeds{
+		{ID: 1, Title: "News", Category: &miniflux.Category{ID: 10, Title: "General"}},
+		{ID: 2, Title: "Deals", Category: &miniflux.Category{ID: 20, Title: "Shopping"}},
+	}}
+	matcher, err := NewMatcher([]Rule{{Name: "Sponsored", Title: "(?i)sponsored", Action: "read"}})
+	if err != nil {
+		t.Fatalf("Failed to create matcher: %v", err)
+	}
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	processor := NewProcessor(mockClient, matcher, logger, false)
+	processor.SetOptions(ProcessorOptions{ExcludeFeeds: []string{"Deals"}})
+	server := NewServer(processor, logger)
+	server.SetWebhookSecret("s3cret")
+	handler := server.Handler()
+
+	post := func(event, body, signature string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/miniflux/webhook", strings.NewReader(body))
+		req.Header.Set(minifluxEventHeader, event)
+		req.Header.Set(minifluxSignatureHeader, signature)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	body := `{"event_type": "new_entries", "feed": {"id": 1, "title": "News"}, "entries": [
+		{"id": 1, "feed_id": 1, "status": "unread", "title": "Sponsored: buy now"},
+		{"id": 2, "feed_id": 1, "status": "unread", "title": "Election results"},
+		{"id": 3, "feed_id": 2, "status": "unread", "title": "Sponsored deal"}
+	]}`
+
+	if rec := post(minifluxEventNewEntries, body, signMinifluxWebhook(body, "wrong")); rec.Code != http.StatusUnauthorized {
+		t.Errorf("Expected status 401 for a bad signature, got %d", rec.Code)
+	}
+	if len(mockClient.updatedIDs) != 0 {
+		t.Fatalf("Expected no entries processed for a bad signature, got %v", mockClient.updatedIDs)
+	}
+
+	saved := `{"event_type": "save_entry", "entry": {"id": 1}}`
+	if rec := post("save_entry", saved, signMinifluxWebhook(saved, "s3cret")); rec.Code != http.StatusNoContent {
+		t.Errorf("Expected status 204 for an ignored event, got %d", rec.Code)
+	}
+
+	if rec := post(minifluxEventNewEntries, body, signMinifluxWebhook(body, "s3cret")); rec.Code != http.StatusNoContent {
+		t.Fatalf("Expected status 204, got %d: %s", rec.Code, rec.Body.String())
+	}
+	// The entry of the excluded feed is left alone
+	if len(mockClient.updatedIDs) != 1 || mockClient.updatedIDs[0] != 1 {
+		t.Errorf("Expected only entry 1 marked read, got %v", mockClient.updatedIDs)
+	}
+}
+
+func TestServerMinifluxWebhookDisabled(t *testing.T) {
+	matcher, err := NewMatcher(nil)
+	if err != nil {
+		t.Fatalf("Failed to create matcher: %v", err)
+	}
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	handler := NewServer(NewProcessor(&MockClient{}, matcher, logger, false), logger).Handler()
+
+	req := httptest.NewRequest(http.MethodPost, "/miniflux/webhook", strings.NewReader("{}"))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404 without a webhook secret, got %d", rec.Code)
+	}
+}
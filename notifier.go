@@ -0,0 +1,213 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	miniflux "miniflux.app/v2/client"
+)
+
+// notifierStepPrefix marks action steps that push to a configured notifier, e.g. "notify:phone"
+const notifierStepPrefix = "notify:"
+
+// Supported notifier types
+const (
+	notifierNtfy     = "ntfy"
+	notifierPushover = "pushover"
+	notifierTelegram = "telegram"
+)
+
+// Default service endpoints, overridable for self-hosted servers and tests
+const (
+	defaultNtfyServer  = "https://ntfy.sh"
+	defaultPushoverURL = "https://api.pushover.net/1/messages.json"
+	defaultTelegramURL = "https://api.telegram.org"
+)
+
+// telegramMessageSize is the longest text Telegram accepts in one message
+const telegramMessageSize = 4096
+
+// NotifierConfig defines a push notification target
+type NotifierConfig struct {
+	Type     string `yaml:"type"`     // "ntfy", "pushover" or "telegram"
+	Server   string `yaml:"server"`   // service base URL (default: the public service)
+	Topic    string `yaml:"topic"`    // ntfy topic
+	Token    string `yaml:"token"`    // ntfy access token, Pushover application token or Telegram bot token
+	User     string `yaml:"user"`     // Pushover user key
+	ChatID   string `yaml:"chat_id"`  // Telegram chat ID
+	Priority string `yaml:"priority"` // ntfy or Pushover priority, e.g. "high" or "1"
+}
+
+// notification is the message pushed about an entry
+type notification struct {
+	Title   string
+	Message string
+	URL     string
+}
+
+// notifierName returns the notifier referenced by an action step, if any
+func notifierName(step string) (string, bool) {
+	return strings.CutPrefix(step, notifierStepPrefix)
+}
+
+// findNotifier looks up a notifier by case-insensitive name
+func findNotifier(notifiers map[string]NotifierConfig, name string) (NotifierConfig, bool) {
+	for key, notifier := range notifiers {
+		if strings.EqualFold(key, name) {
+			return notifier, true
+		}
+	}
+	return NotifierConfig{}, false
+}
+
+// validate checks that the notifier has the settings its type needs
+func (n NotifierConfig) validate() error {
+	switch strings.ToLower(n.Type) {
+	case notifierNtfy:
+		if n.Topic == "" {
+			return fmt.Errorf("ntfy requires topic")
+		}
+	case notifierPushover:
+		if n.Token == "" || n.User == "" {
+			return fmt.Errorf("pushover requires token and user")
+		}
+	case notifierTelegram:
+		if n.Token == "" || n.ChatID == "" {
+			return fmt.Errorf("telegram requires token and chat_id")
+		}
+	default:
+		return fmt.Errorf("type must be '%s', '%s' or '%s', got '%s'", notifierNtfy, notifierPushover, notifierTelegram, n.Type)
+	}
+	return nil
+}
+
+// buildNotification renders an entry matched by rule into a push message
+func buildNotification(entry *miniflux.Entry, rule string) notification {
+	message := entry.Title
+	if entry.Feed != nil && entry.Feed.Title != "" {
+		message = fmt.Sprintf("[%s] %s", entry.Feed.Title, entry.Title)
+	}
+	return notification{
+		Title:   rule,
+		Message: message,
+		URL:     entry.URL,
+	}
+}
+
+// sendNotification pushes a message through the notifier's service
+func sendNotification(client *http.Client, cfg NotifierConfig, msg notification) error {
+	var req *http.Request
+	var err error
+
+	switch strings.ToLower(cfg.Type) {
+	case notifierNtfy:
+		server := cfg.Server
+		if server == "" {
+			server = defaultNtfyServer
+		}
+		req, err = http.NewRequest(http.MethodPost, strings.TrimSuffix(server, "/")+"/"+url.PathEscape(cfg.Topic), strings.NewReader(msg.Message))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Title", msg.Title)
+		if msg.URL != "" {
+			req.Header.Set("Click", msg.URL)
+		}
+		if cfg.Priority != "" {
+			req.Header.Set("Priority", cfg.Priority)
+		}
+		if cfg.Token != "" {
+			req.Header.Set("Authorization", "Bearer "+cfg.Token)
+		}
+
+	case notifierPushover:
+		endpoint := cfg.Server
+		if endpoint == "" {
+			endpoint = defaultPushoverURL
+		}
+		form := url.Values{
+			"token":   {cfg.Token},
+			"user":    {cfg.User},
+			"title":   {msg.Title},
+			"message": {msg.Message},
+		}
+		if msg.URL != "" {
+			form.Set("url", msg.URL)
+		}
+		if cfg.Priority != "" {
+			form.Set("priority", cfg.Priority)
+		}
+		req, err = http.NewRequest(http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	case notifierTelegram:
+		server := cfg.Server
+		if server == "" {
+			server = defaultTelegramURL
+		}
+		text := msg.Title + "\n" + msg.Message
+		if msg.URL != "" {
+			text += "\n" + msg.URL
+		}
+		form := url.Values{
+			"chat_id": {cfg.ChatID},
+			"text":    {truncate(text, telegramMessageSize)},
+		}
+		endpoint := fmt.Sprintf("%s/bot%s/sendMessage", strings.TrimSuffix(server, "/"), cfg.Token)
+		req, err = http.NewRequest(http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	default:
+		return fmt.Errorf("unknown notifier type '%s'", cfg.Type)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s request failed: %w", cfg.Type, err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("%s request failed: status %d", cfg.Type, resp.StatusCode)
+	}
+	return nil
+}
+
+// applyNotifier pushes an entry through the named notifier, once per entry and rule
+func (p *Processor) applyNotifier(entry *miniflux.Entry, name string, rule *Rule, stats *ProcessStats) bool {
+	cfg, ok := findNotifier(p.options.Notifiers, name)
+	if !ok {
+		p.entryLogger(entry, rule).Warn("Unknown notifier", "action", notifierStepPrefix+name)
+		stats.Errors++
+		return false
+	}
+
+	if !p.shouldNotify(notifierStepPrefix+name, entry, rule.Name) {
+		return true
+	}
+
+	if p.dryRun {
+		p.entryLogger(entry, rule).Info("Dry run: would push entry to notifier", "action", notifierStepPrefix+name)
+		p.audit(entry, notifierStepPrefix+name, rule.Name)
+		return true
+	}
+
+	if err := sendNotification(p.httpClient, cfg, buildNotification(entry, rule.Name)); err != nil {
+		p.entryLogger(entry, rule).Error("Failed to push entry to notifier", "action", notifierStepPrefix+name, "error", err)
+		stats.Errors++
+		return false
+	}
+
+	stats.Notified++
+	p.entryLogger(entry, rule).Info("Pushed entry to notifier", "action", notifierStepPrefix+name)
+	p.audit(entry, notifierStepPrefix+name, rule.Name)
+	return true
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	miniflux "miniflux.app/v2/client"
+)
+
+func TestSendNotification(t *testing.T) {
+	msg := notification{Title: "Breaking", Message: "[News] Launch", URL: "https://example.com/launch"}
+
+	tests := []struct {
+		cfg   NotifierConfig
+		check func(r *http.Request) string
+	}{
+		{
+			cfg: NotifierConfig{Type: "ntfy", Topic: "news", Priority: "high"},
+			check: func(r *http.Request) string {
+				if r.URL.Path != "/news" || r.Header.Get("Click") != msg.URL || r.Header.Get("Priority") != "high" {
+					return "unexpected ntfy request"
+				}
+				return ""
+			},
+		},
+		{
+			cfg: NotifierConfig{Type: "pushover", Token: "app", User: "me"},
+			check: func(r *http.Request) string {
+				if r.FormValue("token") != "app" || r.FormValue("user") != "me" || r.FormValue("message") != msg.Message {
+					return "unexpected pushover form"
+				}
+				return ""
+			},
+		},
+		{
+			cfg: NotifierConfig{Type: "telegram", Token: "123:abc", ChatID: "42"},
+			check: func(r *http.Request) string {
+				if r.URL.Path != "/bot123:abc/sendMessage" || r.FormValue("chat_id") != "42" {
+					return "unexpected telegram request"
+				}
+				return ""
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		var problem string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			problem = tt.check(r)
+		}))
+		tt.cfg.Server = server.URL
+
+		if err := sendNotification(server.Client(), tt.cfg, msg); err != nil {
+			t.Errorf("%s: send failed: %v", tt.cfg.Type, err)
+		}
+		if problem != "" {
+			t.Errorf("%s: %s", tt.cfg.Type, problem)
+		}
+		server.Close()
+	}
+}
+
+func TestProcessorNotifierAction(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+	}))
+	defer server.Close()
+
+	mockClient := &MockClient{
+		entries: []*miniflux.Entry{
+			{ID: 1, Title: "Breaking: launch"},
+			{ID: 2, Title: "Weekly recap"},
+		},
+	}
+
+	matcher, err := NewMatcher([]Rule{
+		{Name: "Breaking", Title: "^Breaking", Action: "notify:phone"},
+		{Name: "Recaps", Title: "recap", Action: "read"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create matcher: %v", err)
+	}
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	processor := NewProcessor(mockClient, matcher, logger, false)
+	processor.SetOptions(ProcessorOptions{
+		Notifiers: map[string]NotifierConfig{
+			"phone": {Type: "ntfy", Server: server.URL, Topic: "news"},
+		},
+	})
+
+	stats, err := processor.Process()
+	if err != nil {
+		t.Fatalf("Process failed: %v", err)
+	}
+	if calls != 1 || stats.Notified != 1 {
+		t.Errorf("Expected only the breaking entry to be pushed, got %d calls", calls)
+	}
+}
+
+func TestNotifierValidate(t *testing.T) {
+	if err := (NotifierConfig{Type: "ntfy"}).validate(); err == nil {
+		t.Error("Expected error for ntfy without topic")
+	}
+	if err := (NotifierConfig{Type: "telegram", Token: "t"}).validate(); err == nil {
+		t.Error("Expected error for telegram without chat_id")
+	}
+	if err := (NotifierConfig{Type: "sms"}).validate(); err == nil {
+		t.Error("Expected error for unknown type")
+	}
+	if err := (NotifierConfig{Type: "Pushover", Token: "t", User: "u"}).validate(); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"text/template"
+	"time"
+
+	miniflux "miniflux.app/v2/client"
+)
+
+// defaultNotifyTemplate renders a JSON body when notify omits a template
+const defaultNotifyTemplate = `{"rule": {{json .Rule}}, "title": {{json .Title}}, "url": {{json .URL}}, "feed": {{json .Feed}}}`
+
+// defaultNotifyContentType applies when notify omits a content type
+const defaultNotifyContentType = "application/json"
+
+// NotifyConfig defines where notify actions post and how the body is rendered
+// The top-level notify block provides defaults that a rule's notify block overrides
+type NotifyConfig struct {
+	URL         string `yaml:"url"`
+	Template    string `yaml:"template"`     // Go template for the request body (default: JSON with rule, title, url and feed)
+	ContentType string `yaml:"content_type"` // request content type (default application/json)
+}
+
+// NotifyData is the data available to notify templates
+type NotifyData struct {
+	Rule        string
+	EntryID     int64
+	Title       string
+	URL         string
+	Feed        string
+	Author      string
+	PublishedAt time.Time
+}
+
+// notifyFuncs are the helpers available to notify templates
+var notifyFuncs = template.FuncMap{
+	"json": func(v any) (string, error) {
+		data, err := json.Marshal(v)
+		return string(data), err
+	},
+}
+
+// merge returns the config with unset fields taken from defaults
+func (n NotifyConfig) merge(defaults NotifyConfig) NotifyConfig {
+	if n.URL == "" {
+		n.URL = defaults.URL
+	}
+	if n.Template == "" {
+		n.Template = defaults.Template
+	}
+	if n.ContentType == "" {
+		n.ContentType = defaults.ContentType
+	}
+	if n.Template == "" {
+		n.Template = defaultNotifyTemplate
+	}
+	if n.ContentType == "" {
+		n.ContentType = defaultNotifyContentType
+	}
+	return n
+}
+
+// parseTemplate compiles the body template
+func (n NotifyConfig) parseTemplate() (*template.Template, error) {
+	return template.New("notify").Funcs(notifyFuncs).Option("missingkey=error").Parse(n.Template)
+}
+
+// ruleNotify returns the effective notify settings for a rule
+func ruleNotify(rule *Rule, defaults NotifyConfig) NotifyConfig {
+	if rule.Notify == nil {
+		return NotifyConfig{}.merge(defaults)
+	}
+	return rule.Notify.merge(defaults)
+}
+
+// renderNotify executes the template for an entry matched by rule
+func renderNotify(cfg NotifyConfig, entry *miniflux.Entry, rule string) ([]byte, error) {
+	tmpl, err := cfg.parseTemplate()
+	if err != nil {
+		return nil, fmt.Errorf("invalid notify template: %w", err)
+	}
+
+	data := NotifyData{
+		Rule:        rule,
+		EntryID:     entry.ID,
+		Title:       entry.Title,
+		URL:         entry.URL,
+		Author:      entry.Author,
+		PublishedAt: entry.Date,
+	}
+	if entry.Feed != nil {
+		data.Feed = entry.Feed.Title
+	}
+
+	var body bytes.Buffer
+	if err := tmpl.Execute(&body, data); err != nil {
+		return nil, fmt.Errorf("failed to render notify template: %w", err)
+	}
+	return body.Bytes(), nil
+}
+
+// sendNotify posts a rendered body to the notify URL
+func sendNotify(client *http.Client, cfg NotifyConfig, body []byte) error {
+	resp, err := client.Post(cfg.URL, cfg.ContentType, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("notify request failed: %w", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("notify request failed: status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// applyNotify posts a templated notification about an entry, once per entry and target
+func (p *Processor) applyNotify(entry *miniflux.Entry, rule *Rule, stats *ProcessStats) bool {
+	cfg := ruleNotify(rule, p.options.Notify)
+
+	body, err := renderNotify(cfg, entry, rule.Name)
+	if err != nil {
+		p.entryLogger(entry, rule).Error("Failed to render notification", "action", "notify", "error", err)
+		stats.Errors++
+		return false
+	}
+
+	if !p.shouldNotify("notify", entry, cfg.URL+"\x00"+string(body)) {
+		return true
+	}
+
+	if p.dryRun {
+		p.entryLogger(entry, rule).Info("Dry run: would send notification", "action", "notify", "url", cfg.URL)
+		p.audit(entry, "notify", rule.Name)
+		return true
+	}
+
+	if err := sendNotify(p.httpClient, cfg, body); err != nil {
+		p.entryLogger(entry, rule).Error("Failed to send notification", "action", "notify", "error", err)
+		stats.Errors++
+		return false
+	}
+
+	stats.Notified++
+	p.entryLogger(entry, rule).Info("Sent notification", "action", "notify", "url", cfg.URL)
+	p.audit(entry, "notify", rule.Name)
+	return true
+}
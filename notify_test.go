@@ -0,0 +1,112 @@
+package main
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	miniflux "miniflux.app/v2/client"
+)
+
+func TestRenderNotify(t *testing.T) {
+	entry := &miniflux.Entry{
+		ID:    1,
+		Title: `Say "hello"`,
+		URL:   "https://example.com/hello",
+		Feed:  &miniflux.Feed{Title: "Blog"},
+	}
+
+	body, err := renderNotify(NotifyConfig{}.merge(NotifyConfig{}), entry, "Greetings")
+	if err != nil {
+		t.Fatalf("Failed to render: %v", err)
+	}
+	expected := `{"rule": "Greetings", "title": "Say \"hello\"", "url": "https://example.com/hello", "feed": "Blog"}`
+	if string(body) != expected {
+		t.Errorf("Expected %s, got %s", expected, body)
+	}
+
+	cfg := NotifyConfig{Template: "{{.Feed}}: {{.Title}}"}.merge(NotifyConfig{})
+	body, err = renderNotify(cfg, entry, "Greetings")
+	if err != nil {
+		t.Fatalf("Failed to render: %v", err)
+	}
+	if string(body) != `Blog: Say "hello"` {
+		t.Errorf("Unexpected body: %s", body)
+	}
+}
+
+func TestProcessorNotifyAction(t *testing.T) {
+	var bodies []string
+	var contentType string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+		contentType = r.Header.Get("Content-Type")
+	}))
+	defer server.Close()
+
+	mockClient := &MockClient{
+		entries: []*miniflux.Entry{
+			{ID: 1, Title: "Breaking: launch", URL: "https://example.com/launch"},
+		},
+	}
+
+	matcher, err := NewMatcher([]Rule{
+		{
+			Name:   "Breaking",
+			Title:  "^Breaking",
+			Action: "notify",
+			Notify: &NotifyConfig{Template: "{{.Rule}} {{.URL}}", ContentType: "text/plain"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create matcher: %v", err)
+	}
+
+	state, err := LoadState(filepath.Join(t.TempDir(), "state.json"))
+	if err != nil {
+		t.Fatalf("Failed to load state: %v", err)
+	}
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	processor := NewProcessor(mockClient, matcher, logger, false)
+	processor.SetState(state)
+	processor.SetOptions(ProcessorOptions{Notify: NotifyConfig{URL: server.URL}})
+
+	for range 2 {
+		if _, err := processor.Process(); err != nil {
+			t.Fatalf("Process failed: %v", err)
+		}
+	}
+
+	if len(bodies) != 1 {
+		t.Fatalf("Expected 1 notification, got %d", len(bodies))
+	}
+	if bodies[0] != "Breaking https://example.com/launch" || contentType != "text/plain" {
+		t.Errorf("Unexpected notification %q (%s)", bodies[0], contentType)
+	}
+}
+
+func TestValidateNotifyRequiresURL(t *testing.T) {
+	config := &Config{
+		MinifluxURL: "https://miniflux.example.com",
+		Rules:       []Rule{{Name: "Breaking", Action: "notify"}},
+	}
+	if err := config.Validate(); err == nil {
+		t.Error("Expected error for notify action without url")
+	}
+
+	config.Notify.URL = "https://hooks.example.com"
+	if err := config.Validate(); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	config.Rules[0].Notify = &NotifyConfig{Template: "{{.Title"}
+	if err := config.Validate(); err == nil {
+		t.Error("Expected error for invalid template")
+	}
+}
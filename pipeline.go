@@ -0,0 +1,197 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	miniflux "miniflux.app/v2/client"
+)
+
+// Error handling of a failing step
+const (
+	onErrorStop     = "stop"     // skip the rule's remaining steps (default)
+	onErrorContinue = "continue" // run the rule's remaining steps anyway
+)
+
+// ActionStep is one entry of a rule's actions list
+type ActionStep struct {
+	Action   string     `yaml:"action"`   // action, macro or webhook:<name>
+	Requires StringList `yaml:"requires"` // actions that must have succeeded on the entry first, e.g. save
+	OnError  string     `yaml:"on_error"` // "stop" (default) or "continue"
+}
+
+// Steps returns the rule's actions, treating a single action as a one-step list
+func (r *Rule) Steps() []ActionStep {
+	if len(r.Actions) > 0 {
+		return r.Actions
+	}
+	return []ActionStep{{Action: r.Action}}
+}
+
+// ruleActionLabel describes the rule's actions for reports, e.g. "save, remove"
+func ruleActionLabel(rule *Rule) string {
+	var actions []string
+	for _, step := range rule.Steps() {
+		actions = append(actions, strings.ToLower(step.Action))
+	}
+	return strings.Join(actions, ", ")
+}
+
+// expandRule resolves all of a rule's actions into primitive steps
+func expandRule(rule *Rule, macros map[string][]string) ([]string, error) {
+	var expanded []string
+	for _, step := range rule.Steps() {
+		steps, err := expandAction(step.Action, macros)
+		if err != nil {
+			return nil, err
+		}
+		expanded = append(expanded, steps...)
+	}
+	return expanded, nil
+}
+
+// validateSteps checks a rule's actions list
+func validateSteps(rule *Rule, macros map[string][]string) error {
+	if rule.Action != "" && len(rule.Actions) > 0 {
+		return fmt.Errorf("action and actions are mutually exclusive")
+	}
+	for _, step := range rule.Steps() {
+		if _, err := expandAction(step.Action, macros); err != nil {
+			return err
+		}
+		switch strings.ToLower(step.OnError) {
+		case "", onErrorStop, onErrorContinue:
+		default:
+			return fmt.Errorf("on_error must be '%s' or '%s', got '%s'", onErrorStop, onErrorContinue, step.OnError)
+		}
+		for _, required := range step.Requires {
+			if !isPrimitiveStep(required) {
+				return fmt.Errorf("requires must name %s, webhook:<name>, notify:<name> or bookmark:<name>, got '%s'", strings.Join(actionSteps, ", "), required)
+			}
+		}
+	}
+	return nil
+}
+
+// pipelineStep is a primitive action scheduled for an entry
+type pipelineStep struct {
+	action   string
+	rule     *Rule
+	requires []string
+	onError  string
+}
+
+// buildPipeline collects the steps of every matching rule, in rule order,
+// then reorders them by action_order
+func (p *Processor) buildPipeline(entry *miniflux.Entry, results []MatchResult, stats *ProcessStats) []pipelineStep {
+	feedTitle := ""
+	if entry.Feed != nil {
+		feedTitle = entry.Feed.Title
+	}
+
+	var pipeline []pipelineStep
+	for _, result := range results {
+		logger := p.entryLogger(entry, result.Rule)
+		logger.Info("Rule matched entry", "title", entry.Title)
+		if p.dryRun && p.explain {
+			for _, detail := range p.matcher.MatchDetails(entry, result.Rule) {
+				logger.Info("Match detail", "detail", detail)
+			}
+		}
+		stats.Matches = append(stats.Matches, MatchItem{
+			Rule:      result.Rule.Name,
+			Action:    ruleActionLabel(result.Rule),
+			EntryID:   entry.ID,
+			FeedTitle: feedTitle,
+			Title:     entry.Title,
+			URL:       entry.URL,
+		})
+
+		// Rules explicitly targeting starred entries are exempt from skip_starred
+		if entry.Starred && p.options.SkipStarred && !result.Rule.IncludeStarred && !result.Rule.OnlyStarred() {
+			logger.Info("Skipping starred entry")
+			continue
+		}
+
+		if !p.delayElapsed(entry, result.Rule) {
+			continue
+		}
+
+		for _, step := range result.Rule.Steps() {
+			actions, err := expandAction(step.Action, p.options.Macros)
+			if err != nil {
+				logger.Warn("Unknown action", "action", step.Action)
+				stats.Errors++
+				p.failedRules[result.Rule.Name] = true
+				continue
+			}
+			for _, action := range actions {
+				pipeline = append(pipeline, pipelineStep{
+					action:   action,
+					rule:     result.Rule,
+					requires: step.Requires,
+					onError:  strings.ToLower(step.OnError),
+				})
+			}
+		}
+	}
+
+	if len(p.options.ActionOrder) > 0 {
+		slices.SortStableFunc(pipeline, func(a, b pipelineStep) int {
+			return p.actionRank(a.action) - p.actionRank(b.action)
+		})
+	}
+	return pipeline
+}
+
+// actionRank orders steps by action_order, with unlisted actions last
+func (p *Processor) actionRank(action string) int {
+	for i, ordered := range p.options.ActionOrder {
+		if strings.EqualFold(ordered, action) {
+			return i
+		}
+	}
+	return len(p.options.ActionOrder)
+}
+
+// runPipeline applies the steps to an entry
+// A step runs only if the actions it requires succeeded earlier; a failing step
+// skips the rest of its rule's steps unless it sets on_error: continue
+func (p *Processor) runPipeline(entry *miniflux.Entry, pipeline []pipelineStep, stats *ProcessStats) {
+	succeeded := make(map[string]bool)
+	stopped := make(map[*Rule]bool)
+
+	for _, step := range pipeline {
+		if stopped[step.rule] {
+			continue
+		}
+
+		if missing := firstMissing(step.requires, succeeded); missing != "" {
+			p.entryLogger(entry, step.rule).Info("Skipping action, a required action did not succeed", "action", step.action, "requires", missing)
+			if step.onError != onErrorContinue {
+				stopped[step.rule] = true
+			}
+			continue
+		}
+
+		if p.applyStep(entry, step.action, step.rule, stats) {
+			succeeded[step.action] = true
+			continue
+		}
+		p.failedRules[step.rule.Name] = true
+		if step.onError != onErrorContinue {
+			stopped[step.rule] = true
+		}
+	}
+}
+
+// firstMissing returns the first required action that has not succeeded
+func firstMissing(requires []string, succeeded map[string]bool) string {
+	for _, required := range requires {
+		if !succeeded[strings.ToLower(required)] {
+			return required
+		}
+	}
+	return ""
+}
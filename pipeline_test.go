@@ -0,0 +1,105 @@
+package main
+
+import (
+	"errors"
+	"log/slog"
+	"os"
+	"slices"
+	"testing"
+
+	miniflux "miniflux.app/v2/client"
+)
+
+func TestProcessorActionRequires(t *testing.T) {
+	rules := []Rule{
+		{
+			Name:  "Archive",
+			Title: "Long read",
+			Actions: []ActionStep{
+				{Action: "save", OnError: "continue"},
+				{Action: "star"},
+				{Action: "remove", Requires: StringList{"save"}},
+			},
+		},
+	}
+	matcher, err := NewMatcher(rules)
+	if err != nil {
+		t.Fatalf("Failed to create matcher: %v", err)
+	}
+
+	mockClient := &MockClient{
+		entries: []*miniflux.Entry{{ID: 1, Title: "Long read"}},
+		saveErr: errors.New("integration unavailable"),
+	}
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	processor := NewProcessor(mockClient, matcher, logger, false)
+
+	stats, err := processor.Process()
+	if err != nil {
+		t.Fatalf("Process failed: %v", err)
+	}
+
+	// The failed save does not stop the star, but the remove depending on it is skipped
+	if stats.Errors != 1 || stats.Starred != 1 || stats.Removed != 0 {
+		t.Errorf("Unexpected stats: %+v", stats)
+	}
+	if len(mockClient.updatedIDs) != 0 {
+		t.Errorf("Expected no status updates, got %v", mockClient.updatedIDs)
+	}
+
+	mockClient = &MockClient{entries: []*miniflux.Entry{{ID: 1, Title: "Long read"}}}
+	processor = NewProcessor(mockClient, matcher, logger, false)
+	if stats, err = processor.Process(); err != nil {
+		t.Fatalf("Process failed: %v", err)
+	}
+	if stats.Saved != 1 || stats.Removed != 1 {
+		t.Errorf("Expected save and remove to succeed, got %+v", stats)
+	}
+}
+
+func TestBuildPipelineActionOrder(t *testing.T) {
+	rules := []Rule{
+		{Name: "Drop", Title: "Promo", Action: "remove", Continue: true},
+		{Name: "Keep a copy", Title: "Promo", Action: "save"},
+	}
+	matcher, err := NewMatcher(rules)
+	if err != nil {
+		t.Fatalf("Failed to create matcher: %v", err)
+	}
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	processor := NewProcessor(&MockClient{}, matcher, logger, false)
+	processor.SetOptions(ProcessorOptions{ActionOrder: []string{"save", "notify", "remove"}})
+
+	entry := &miniflux.Entry{ID: 1, Title: "Promo"}
+	pipeline := processor.buildPipeline(entry, matcher.MatchAll(entry), &ProcessStats{})
+
+	var actions []string
+	for _, step := range pipeline {
+		actions = append(actions, step.action)
+	}
+	if !slices.Equal(actions, []string{"save", "remove"}) {
+		t.Errorf("Expected save before remove, got %v", actions)
+	}
+}
+
+func TestValidateSteps(t *testing.T) {
+	tests := []struct {
+		name  string
+		rule  Rule
+		valid bool
+	}{
+		{"single action", Rule{Action: "read"}, true},
+		{"steps", Rule{Actions: []ActionStep{{Action: "save"}, {Action: "remove", Requires: StringList{"save"}}}}, true},
+		{"both", Rule{Action: "read", Actions: []ActionStep{{Action: "save"}}}, false},
+		{"bad on_error", Rule{Actions: []ActionStep{{Action: "save", OnError: "retry"}}}, false},
+		{"bad requires", Rule{Actions: []ActionStep{{Action: "remove", Requires: StringList{"archive"}}}}, false},
+	}
+
+	for _, tt := range tests {
+		err := validateSteps(&tt.rule, nil)
+		if (err == nil) != tt.valid {
+			t.Errorf("%s: expected valid=%v, got error %v", tt.name, tt.valid, err)
+		}
+	}
+}
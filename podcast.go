@@ -0,0 +1,147 @@
+package main
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	miniflux "miniflux.app/v2/client"
+)
+
+const (
+	// enclosureDurationTTL is how long a parsed podcast feed is reused
+	enclosureDurationTTL = time.Hour
+
+	// maxPodcastFeedSize limits how much of a podcast feed is read
+	maxPodcastFeedSize = 16 << 20
+)
+
+// EnclosureDurations looks up itunes:duration values for podcast episodes
+// The Miniflux API does not expose them, so the feed itself is fetched and parsed
+type EnclosureDurations struct {
+	client *http.Client
+	logger *slog.Logger
+
+	mu    sync.Mutex
+	feeds map[string]podcastFeed
+}
+
+// podcastFeed holds episode durations keyed by enclosure URL and item link
+type podcastFeed struct {
+	durations map[string]time.Duration
+	fetchedAt time.Time
+}
+
+// podcastRSS is the subset of an RSS document needed to read episode durations
+type podcastRSS struct {
+	Items []struct {
+		Link      string `xml:"link"`
+		Duration  string `xml:"http://www.itunes.com/dtds/podcast-1.0.dtd duration"`
+		Enclosure struct {
+			URL string `xml:"url,attr"`
+		} `xml:"enclosure"`
+	} `xml:"channel>item"`
+}
+
+// NewEnclosureDurations creates an EnclosureDurations using the given client, or a default one if nil
+func NewEnclosureDurations(client *http.Client, logger *slog.Logger) *EnclosureDurations {
+	if client == nil {
+		client = &http.Client{Timeout: 30 * time.Second}
+	}
+	return &EnclosureDurations{
+		client: client,
+		logger: logger,
+		feeds:  make(map[string]podcastFeed),
+	}
+}
+
+// Duration returns the itunes duration of the entry's episode
+// ok is false when the entry has no feed URL or its feed carries no duration for it
+func (e *EnclosureDurations) Duration(entry *miniflux.Entry) (duration time.Duration, ok bool) {
+	if entry.Feed == nil || entry.Feed.FeedURL == "" {
+		return 0, false
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	feed, cached := e.feeds[entry.Feed.FeedURL]
+	if !cached || time.Since(feed.fetchedAt) >= enclosureDurationTTL {
+		durations, err := e.fetch(entry.Feed.FeedURL)
+		if err != nil {
+			e.logger.Warn("Failed to fetch podcast feed", "url", entry.Feed.FeedURL, "error", err)
+		}
+		// Failures are cached too so a broken feed is not refetched for every entry
+		feed = podcastFeed{durations: durations, fetchedAt: time.Now()}
+		e.feeds[entry.Feed.FeedURL] = feed
+	}
+
+	for _, enclosure := range entry.Enclosures {
+		if duration, ok := feed.durations[enclosure.URL]; ok {
+			return duration, true
+		}
+	}
+	duration, ok = feed.durations[entry.URL]
+	return duration, ok
+}
+
+// fetch downloads a podcast feed and parses its episode durations
+func (e *EnclosureDurations) fetch(feedURL string) (map[string]time.Duration, error) {
+	resp, err := e.client.Get(feedURL)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("status %d", resp.StatusCode)
+	}
+
+	var rss podcastRSS
+	decoder := xml.NewDecoder(io.LimitReader(resp.Body, maxPodcastFeedSize))
+	decoder.Strict = false
+	if err := decoder.Decode(&rss); err != nil {
+		return nil, fmt.Errorf("failed to parse feed: %w", err)
+	}
+
+	durations := make(map[string]time.Duration)
+	for _, item := range rss.Items {
+		duration, ok := parseItunesDuration(item.Duration)
+		if !ok {
+			continue
+		}
+		if item.Enclosure.URL != "" {
+			durations[item.Enclosure.URL] = duration
+		}
+		if item.Link != "" {
+			durations[item.Link] = duration
+		}
+	}
+
+	return durations, nil
+}
+
+// parseItunesDuration parses "HH:MM:SS", "MM:SS" or plain seconds
+func parseItunesDuration(value string) (time.Duration, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, false
+	}
+
+	var seconds int
+	for _, part := range strings.Split(value, ":") {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return 0, false
+		}
+		seconds = seconds*60 + n
+	}
+
+	return time.Duration(seconds) * time.Second, true
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	miniflux "miniflux.app/v2/client"
+)
+
+func TestParseItunesDuration(t *testing.T) {
+	testCases := []struct {
+		value    string
+		expected time.Duration
+		ok       bool
+	}{
+		{"1:02:03", time.Hour + 2*time.Minute + 3*time.Second, true},
+		{"04:30", 4*time.Minute + 30*time.Second, true},
+		{"300", 5 * time.Minute, true},
+		{"", 0, false},
+		{"about an hour", 0, false},
+	}
+
+	for _, tc := range testCases {
+		duration, ok := parseItunesDuration(tc.value)
+		if duration != tc.expected || ok != tc.ok {
+			t.Errorf("Value '%s': expected %v/%v, got %v/%v", tc.value, tc.expected, tc.ok, duration, ok)
+		}
+	}
+}
+
+func TestMatcherEnclosureDuration(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		fmt.Fprint(w, `<?xml version="1.0"?>
+<rss xmlns:itunes="http://www.itunes.com/dtds/podcast-1.0.dtd"><channel>
+<item><link>https://example.com/trailer</link><enclosure url="https://cdn.example.com/trailer.mp3"/><itunes:duration>3:12</itunes:duration></item>
+<item><link>https://example.com/ep1</link><enclosure url="https://cdn.example.com/ep1.mp3"/><itunes:duration>01:05:00</itunes:duration></item>
+</channel></rss>`)
+	}))
+	defer server.Close()
+
+	matcher, err := NewMatcher([]Rule{
+		{Name: "Trailers", EnclosureDurationLT: 5 * time.Minute, Action: "read"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create matcher: %v", err)
+	}
+	matcher.SetEnclosureDurations(NewEnclosureDurations(server.Client(), slog.New(slog.NewTextHandler(os.Stdout, nil))))
+
+	feed := &miniflux.Feed{FeedURL: server.URL}
+	trailer := &miniflux.Entry{
+		ID:         1,
+		Feed:       feed,
+		Enclosures: miniflux.Enclosures{{URL: "https://cdn.example.com/trailer.mp3"}},
+	}
+	episode := &miniflux.Entry{ID: 2, Feed: feed, URL: "https://example.com/ep1"}
+	unknown := &miniflux.Entry{ID: 3, Feed: feed, URL: "https://example.com/other"}
+
+	if !matcher.Match(trailer).Matched {
+		t.Error("Expected trailer to match")
+	}
+	if matcher.Match(episode).Matched {
+		t.Error("Expected full episode not to match")
+	}
+	if matcher.Match(unknown).Matched {
+		t.Error("Expected episode without duration not to match")
+	}
+	if requests != 1 {
+		t.Errorf("Expected the feed to be fetched once, got %d requests", requests)
+	}
+}
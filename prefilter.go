@@ -0,0 +1,129 @@
+package main
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	miniflux "miniflux.app/v2/client"
+)
+
+// pinned reports whether the rule only matches entries of particular feeds or categories
+func (cr *compiledRule) pinned() bool {
+	return cr.feed != nil || cr.category != nil || len(cr.rule.FeedID) > 0 || len(cr.rule.CategoryID) > 0
+}
+
+// pinned reports whether every enabled rule is restricted to particular feeds or categories
+func (m *Matcher) pinned() bool {
+	enabled := 0
+	for i := range m.compiledRules {
+		cr := &m.compiledRules[i]
+		if m.disabled[cr.rule.Name] {
+			continue
+		}
+		if !cr.pinned() {
+			return false
+		}
+		enabled++
+	}
+	return enabled > 0
+}
+
+// matchesFeed reports whether an enabled rule can match entries of the feed
+func (m *Matcher) matchesFeed(feed *miniflux.Feed) bool {
+	for i := range m.compiledRules {
+		cr := &m.compiledRules[i]
+		if !m.disabled[cr.rule.Name] && feedInScope(cr, feed) {
+			return true
+		}
+	}
+	return false
+}
+
+// namedBy reports whether a value in names is the ID or, case-insensitively, the title
+func namedBy(names []string, id int64, title string) bool {
+	for _, name := range names {
+		if name == strconv.FormatInt(id, 10) || strings.EqualFold(name, title) {
+			return true
+		}
+	}
+	return false
+}
+
+// feedSelector picks a feed or category by ID or by a regex of its title, for -feed and -category
+type feedSelector struct {
+	id    int64
+	title *regexp.Regexp
+}
+
+// parseFeedSelector reads an ID or, for anything else, a title regex
+func parseFeedSelector(value string) (*feedSelector, error) {
+	if id, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return &feedSelector{id: id}, nil
+	}
+	title, err := regexp.Compile(value)
+	if err != nil {
+		return nil, err
+	}
+	return &feedSelector{title: title}, nil
+}
+
+// matches reports whether the selector picks the feed or category with this ID and title
+func (s *feedSelector) matches(id int64, title string) bool {
+	if s.title == nil {
+		return id == s.id
+	}
+	return s.title.MatchString(title)
+}
+
+// adHocScoped reports whether -feed or -category restrict the run
+func (p *Processor) adHocScoped() bool {
+	return p.options.FeedScope != nil || p.options.CategoryScope != nil
+}
+
+// inFetchScope reports whether the feed passes the categories and exclude_feeds settings
+func (p *Processor) inFetchScope(feed *miniflux.Feed) bool {
+	if namedBy(p.options.ExcludeFeeds, feed.ID, feed.Title) {
+		return false
+	}
+	if p.options.FeedScope != nil && !p.options.FeedScope.matches(feed.ID, feed.Title) {
+		return false
+	}
+	if p.options.CategoryScope != nil && (feed.Category == nil || !p.options.CategoryScope.matches(feed.Category.ID, feed.Category.Title)) {
+		return false
+	}
+	if len(p.options.Categories) == 0 {
+		return true
+	}
+	return feed.Category != nil && namedBy(p.options.Categories, feed.Category.ID, feed.Category.Title)
+}
+
+// scopedFeeds resolves the feeds whose entries are fetched, or returns false to fetch every entry
+// Feeds are limited by the categories and exclude_feeds settings, and to those the rules are
+// pinned to when every rule names a feed or category. Aggregates and read reports need the
+// outcome of every entry, and max_entries_per_run resumes in ID order across all feeds, so
+// they disable the pinning. Feeds are resolved on every run to follow subscriptions added or
+// removed in loop mode, unless feed_cache_ttl keeps them longer.
+func (p *Processor) scopedFeeds() ([]int64, bool, error) {
+	scoped := len(p.options.Categories) > 0 || len(p.options.ExcludeFeeds) > 0 || p.adHocScoped()
+	pinned := !p.tracksFeedActivity() && !p.chunked() && p.matcher.pinned()
+	if !scoped && !pinned {
+		return nil, false, nil
+	}
+
+	feeds, err := p.feeds()
+	if err != nil {
+		return nil, false, err
+	}
+
+	feedIDs := []int64{}
+	for _, feed := range feeds {
+		if p.inFetchScope(feed) && (!pinned || p.matcher.matchesFeed(feed)) {
+			feedIDs = append(feedIDs, feed.ID)
+		}
+	}
+	if p.adHocScoped() && len(feedIDs) == 0 {
+		p.logger.Warn("No feed matches -feed and -category")
+	}
+	return feedIDs, true, nil
+}
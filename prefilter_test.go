@@ -0,0 +1,191 @@
+package main
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	miniflux "miniflux.app/v2/client"
+)
+
+func TestProcessorPinnedFeeds(t *testing.T) {
+	tech := &miniflux.Feed{ID: 1, Title: "Tech Blog", Category: &miniflux.Category{ID: 2, Title: "Tech"}}
+	deals := &miniflux.Feed{ID: 2, Title: "Deals", Category: &miniflux.Category{ID: 3, Title: "Shopping"}}
+	other := &miniflux.Feed{ID: 3, Title: "Other", Category: &miniflux.Category{ID: 4, Title: "Misc"}}
+
+	var entries []*miniflux.Entry
+	for i := 1; i <= 30; i++ {
+		feed := []*miniflux.Feed{tech, deals, other}[i%3]
+		entries = append(entries, &miniflux.Entry{ID: int64(i), FeedID: feed.ID, Feed: feed, Title: "Post", Status: miniflux.EntryStatusUnread})
+	}
+
+	tests := []struct {
+		name     string
+		rules    []Rule
+		expected int
+	}{
+		{"pinned by feed and category", []Rule{
+			{Name: "Deals", Feed: "^Deals$", Action: "read"},
+			{Name: "Tech", CategoryID: IDList{2}, Title: "Sponsored", Action: "remove"},
+		}, 20},
+		{"no feed pinned", []Rule{
+			{Name: "Deals", Feed: "^Deals$", Action: "read"},
+			{Name: "Anywhere", Title: "Sponsored", Action: "remove"},
+		}, 30},
+		{"pinned feed missing", []Rule{
+			{Name: "Gone", FeedID: IDList{99}, Action: "read"},
+		}, 0},
+	}
+
+	for _, tt := range tests {
+		mockClient := &MockClient{entries: entries, feeds: miniflux.Feeds{tech, deals, other}}
+		matcher, err := NewMatcher(tt.rules)
+		if err != nil {
+			t.Fatalf("Failed to create matcher: %v", err)
+		}
+
+		logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+		processor := NewProcessor(mockClient, matcher, logger, true)
+		stats, err := processor.Process()
+		if err != nil {
+			t.Fatalf("%s: Process failed: %v", tt.name, err)
+		}
+		if stats.TotalEntries != tt.expected {
+			t.Errorf("%s: expected %d entries fetched, got %d", tt.name, tt.expected, stats.TotalEntries)
+		}
+	}
+}
+
+func TestMatcherPinnedSkipsDisabledRules(t *testing.T) {
+	matcher, err := NewMatcher([]Rule{
+		{Name: "Deals", Feed: "Deals", Action: "read"},
+		{Name: "Cleanup", Title: "Old", Action: "remove", Once: true},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create matcher: %v", err)
+	}
+
+	if matcher.pinned() {
+		t.Error("Expected an unrestricted rule to require all feeds")
+	}
+
+	matcher.DisableRule("Cleanup")
+	if !matcher.pinned() {
+		t.Error("Expected the rules to be pinned once the unrestricted rule is disabled")
+	}
+	if matcher.matchesFeed(&miniflux.Feed{ID: 2, Title: "News"}) {
+		t.Error("Expected no rule to match the News feed")
+	}
+}
+
+func TestProcessorFetchScope(t *testing.T) {
+	tech := &miniflux.Feed{ID: 1, Title: "Tech Blog", Category: &miniflux.Category{ID: 2, Title: "Tech"}}
+	noisy := &miniflux.Feed{ID: 2, Title: "Noisy", Category: &miniflux.Category{ID: 2, Title: "Tech"}}
+	deals := &miniflux.Feed{ID: 3, Title: "Deals", Category: &miniflux.Category{ID: 3, Title: "Shopping"}}
+	mockClient := &MockClient{
+		entries: []*miniflux.Entry{
+			{ID: 1, FeedID: 1, Feed: tech, Title: "Post"},
+			{ID: 2, FeedID: 2, Feed: noisy, Title: "Post"},
+			{ID: 3, FeedID: 3, Feed: deals, Title: "Post"},
+		},
+		feeds: miniflux.Feeds{tech, noisy, deals},
+	}
+
+	matcher, err := NewMatcher([]Rule{
+		{Name: "Read all", Title: ".", Action: "read"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create matcher: %v", err)
+	}
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	processor := NewProcessor(mockClient, matcher, logger, false)
+	processor.SetOptions(ProcessorOptions{
+		Statuses:     []string{"read", "unread"},
+		Categories:   []string{"tech"},
+		ExcludeFeeds: []string{"2"},
+	})
+
+	if _, err := processor.Process(); err != nil {
+		t.Fatalf("Process failed: %v", err)
+	}
+	if !slices.Equal(mockClient.updatedIDs, []int64{1}) {
+		t.Errorf("Expected only entry 1 to be processed, got %v", mockClient.updatedIDs)
+	}
+	if !slices.Equal(mockClient.lastFilter.Statuses, []string{"read", "unread"}) {
+		t.Errorf("Expected the configured statuses to be fetched, got %v", mockClient.lastFilter.Statuses)
+	}
+}
+
+func TestProcessorAdHocScope(t *testing.T) {
+	tech := &miniflux.Feed{ID: 1, Title: "Tech Blog", Category: &miniflux.Category{ID: 2, Title: "Tech"}}
+	noisy := &miniflux.Feed{ID: 2, Title: "Noisy", Category: &miniflux.Category{ID: 2, Title: "Tech"}}
+	deals := &miniflux.Feed{ID: 3, Title: "Deals", Category: &miniflux.Category{ID: 3, Title: "Shopping"}}
+
+	tests := []struct {
+		name     string
+		feed     string
+		category string
+		want     []int64
+	}{
+		{"feed title regex", "^Tech", "", []int64{1}},
+		{"feed ID", "2", "", []int64{2}},
+		{"category ID", "", "2", []int64{1, 2}},
+		{"category title regex", "", "(?i)shop", []int64{3}},
+		{"feed outside the category", "Deals", "Tech", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := &MockClient{
+				entries: []*miniflux.Entry{
+					{ID: 1, FeedID: 1, Feed: tech, Title: "Post", Status: "unread"},
+					{ID: 2, FeedID: 2, Feed: noisy, Title: "Post", Status: "unread"},
+					{ID: 3, FeedID: 3, Feed: deals, Title: "Post", Status: "unread"},
+				},
+				feeds: miniflux.Feeds{tech, noisy, deals},
+			}
+			matcher, err := NewMatcher([]Rule{{Name: "Read all", Title: ".", Action: "read"}})
+			if err != nil {
+				t.Fatalf("Failed to create matcher: %v", err)
+			}
+			state, err := LoadState(filepath.Join(t.TempDir(), "state.json"))
+			if err != nil {
+				t.Fatalf("Failed to load state: %v", err)
+			}
+
+			options := ProcessorOptions{SkipEvaluated: true}
+			if tt.feed != "" {
+				if options.FeedScope, err = parseFeedSelector(tt.feed); err != nil {
+					t.Fatalf("parseFeedSelector failed: %v", err)
+				}
+			}
+			if tt.category != "" {
+				if options.CategoryScope, err = parseFeedSelector(tt.category); err != nil {
+					t.Fatalf("parseFeedSelector failed: %v", err)
+				}
+			}
+			logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+			processor := NewProcessor(mockClient, matcher, logger, false)
+			processor.SetOptions(options)
+			processor.SetState(state)
+
+			if _, err := processor.Process(); err != nil {
+				t.Fatalf("Process failed: %v", err)
+			}
+			slices.Sort(mockClient.updatedIDs)
+			if !slices.Equal(mockClient.updatedIDs, tt.want) {
+				t.Errorf("Expected entries %v to be processed, got %v", tt.want, mockClient.updatedIDs)
+			}
+			// The other feeds were not evaluated, so later runs must still fetch them
+			if state.LastEvaluatedID != 0 {
+				t.Errorf("Expected a scoped run to record no evaluated entries, got up to %d", state.LastEvaluatedID)
+			}
+		})
+	}
+
+	if _, err := parseFeedSelector("(unclosed"); err == nil {
+		t.Error("Expected an invalid regex to be rejected")
+	}
+}
@@ -1,8 +1,15 @@
 package main
 
 import (
+	"cmp"
 	"fmt"
-	"log"
+	"log/slog"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
 
 	miniflux "miniflux.app/v2/client"
 )
@@ -11,15 +18,100 @@ import (
 type Processor struct {
 	client  MinifluxClient
 	matcher *Matcher
-	logger  *log.Logger
+	logger  *slog.Logger
 	dryRun  bool
+	explain bool // log the patterns behind each match in dry runs
+	state   *State
+	options ProcessorOptions
+
+	httpClient *http.Client  // used by webhook and notification actions
+	mailer     mailer        // used by email actions and digests
+	retryDelay time.Duration // wait before the first retry of a failed page, growing with each attempt
+
+	wallabag *wallabagClient // created on the first wallabag action
+
+	// mu serializes runs with evaluations served over HTTP
+	mu sync.Mutex
+
+	feedTitles map[int64]string // feed titles seen during processing, for aggregate alerts
+	runStarted time.Time        // start of the current run, recorded with applied actions
+
+	skippedPages int             // pages given up on during the current run, with skip_failed_pages
+	failedRules  map[string]bool // rules with an action that failed during the current run
+
+	feedList     miniflux.Feeds           // subscribed feeds, for enrichment and fetch scoping
+	feedsByID    map[int64]*miniflux.Feed // feedList by ID
+	feedsFetched time.Time                // when feedList was fetched
+
+	leader    LeaderLock // optional, only the lock holder runs
+	following bool       // the last run was skipped because another replica led
+
+	metrics *processMetrics // totals served on /metrics
+}
+
+// ProcessorOptions holds global settings that tune processing behaviour
+type ProcessorOptions struct {
+	SkipStarred bool            // leave starred entries alone unless a rule opts in
+	Aggregates  []AggregateRule // feed-level rules evaluated after each run
+	Location    *time.Location  // timezone for time-based features (default: local)
+	ReadReport  ReadReportConfig
+	ConfigHash  string // hash of the loaded config, recorded in run stats
+
+	Macros   map[string][]string      // user-defined action macros
+	Webhooks map[string]WebhookConfig // webhook targets for webhook:<name> actions
+	Notify   NotifyConfig             // default target and template for notify actions
+
+	Notifiers map[string]NotifierConfig // push targets for notify:<name> actions
+	Email     EmailConfig               // SMTP settings for email actions and run digests
+	Wallabag  WallabagConfig            // server and credentials for wallabag actions
+	Readwise  ReadwiseConfig            // Readwise Reader account for readwise actions
+	Bookmarks map[string]BookmarkConfig // linkding and Shaarli targets for bookmark:<name> actions
+
+	ActionOrder []string // order of an entry's steps across matching rules, e.g. save before remove
+
+	FlushHistory FlushHistoryConfig // scheduled removal of read entries
+
+	ExportPath   string // file that export actions append matched entries to
+	ExportFormat string // "jsonl" or "csv" (default: from the file extension)
+
+	AuditLog string // JSONL file every action, applied or dry run, is appended to
+
+	MaxChangeRatio float64 // abort the run if a larger share of entries would change (0 = no limit)
+	FetchStrategy  string  // "sequential" (default), "round_robin_feeds" or "round_robin_categories"
+
+	FetchOrder     string // entry field entries are fetched by (default: Miniflux's)
+	FetchDirection string // "asc" or "desc" (default: Miniflux's)
+
+	MaxActionsPerRun  int // abort the run if more entries would change (0 = no limit)
+	MaxRemovalsPerRun int // abort the run if more entries would be removed (0 = no limit)
+
+	MaxEntriesPerRun int // process at most this many entries per run, resuming after the last one (0 = no limit)
+
+	Concurrency int // pages fetched and entries matched at once (default 1)
+
+	FeedCacheTTL time.Duration // reuse the feed list across runs for this long (default: fetched every run)
+
+	Quarantine bool // remove actions mark entries read and record them for a later purge
+
+	PageRetries     int  // retries of a failed page fetch
+	SkipFailedPages bool // skip pages that still fail after retries instead of aborting the run
+
+	Statuses     []string // entry statuses to fetch (default: those targeted by the rules)
+	Categories   []string // category titles or IDs to fetch (default: all)
+	ExcludeFeeds []string // feed titles or IDs never fetched
+
+	FeedScope     *feedSelector // only the feed picked by -feed
+	CategoryScope *feedSelector // only the feeds of the category picked by -category
+
+	SkipEvaluated bool   // fetch only entries newer than those evaluated by previous runs
+	FetchSince    string // "published" or "changed": fetch only entries published or changed since the last run
 }
 
 // NewProcessor creates a new Processor
 func NewProcessor(
 	client MinifluxClient,
 	matcher *Matcher,
-	logger *log.Logger,
+	logger *slog.Logger,
 	dryRun bool,
 ) *Processor {
 	return &Processor{
@@ -27,110 +119,515 @@ func NewProcessor(
 		matcher: matcher,
 		logger:  logger,
 		dryRun:  dryRun,
+
+		httpClient:  &http.Client{Timeout: 30 * time.Second},
+		mailer:      sendSMTP,
+		retryDelay:  2 * time.Second,
+		feedTitles:  make(map[int64]string),
+		failedRules: make(map[string]bool),
+		metrics:     newProcessMetrics(),
+	}
+}
+
+// SetState attaches persistent state to the processor and its matcher
+func (p *Processor) SetState(state *State) {
+	p.state = state
+	p.matcher.SetState(state)
+}
+
+// SetLeaderLock restricts runs to the replica holding the leader lock
+func (p *Processor) SetLeaderLock(leader LeaderLock) {
+	p.leader = leader
+}
+
+// SetExplain logs, in dry runs, which pattern of each matching rule caught which text
+func (p *Processor) SetExplain(explain bool) {
+	p.explain = explain
+}
+
+// SetOptions applies global processing settings
+func (p *Processor) SetOptions(options ProcessorOptions) {
+	p.options = options
+	p.matcher.SetMacros(options.Macros)
+}
+
+// Reload swaps in recompiled rules and settings, waiting for a run in progress to finish
+func (p *Processor) Reload(matcher *Matcher, options ProcessorOptions) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	matcher.SetState(p.state)
+	matcher.SetMacros(options.Macros)
+	p.matcher = matcher
+	p.options = options
+}
+
+// now returns the current time in the configured timezone
+func (p *Processor) now() time.Time {
+	if p.options.Location == nil {
+		return time.Now()
 	}
+	return time.Now().In(p.options.Location)
 }
 
 // ProcessStats holds statistics about a processing run
 type ProcessStats struct {
+	ConfigHash     string
 	TotalEntries   int
 	MatchedEntries int
 	MarkedRead     int
+	MarkedUnread   int
 	Removed        int
+	Quarantined    int
+	Starred        int
+	Unstarred      int
+	Saved          int
+	Notified       int
+	Retitled       int
 	Errors         int
+	FeedAlerts     []FeedAlert
+	ReadReport     []FeedReadStats // set on runs that produced a read report
+	Digest         []DigestItem    // entries collected by the digest action
+	Changes        []ChangeItem    // entries changed by actions, for the email digest
+	Matches        []MatchItem     // entries matched per rule, for the HTML report
+	FeedActions    []FeedAction    // feeds disabled or refreshed by actions
+
+	APIErrors map[APIErrorKey]int // failed Miniflux API requests during the run
+
+	SkippedPages int // pages that could not be fetched, with skip_failed_pages
 }
 
-// Process fetches unread entries and applies matching rules
+// apiErrorSource is implemented by clients that classify failed requests
+type apiErrorSource interface {
+	APIErrors() *APIErrors
+}
+
+// apiErrors returns the client's error counters, or nil if it does not track them
+func (p *Processor) apiErrors() *APIErrors {
+	if source, ok := p.client.(apiErrorSource); ok {
+		return source.APIErrors()
+	}
+	return nil
+}
+
+// Process fetches entries in scope of the rules and applies matching rules
 func (p *Processor) Process() (*ProcessStats, error) {
-	stats := &ProcessStats{}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	stats := &ProcessStats{ConfigHash: p.options.ConfigHash}
+
+	if apiErrors := p.apiErrors(); apiErrors != nil {
+		before := apiErrors.Snapshot()
+		defer func() { stats.APIErrors = apiErrorsSince(before, apiErrors.Snapshot()) }()
+	}
 
-	// Fetch entries (unread by default, all in dry-run)
+	leading, err := p.acquireLeadership()
+	if err != nil {
+		return stats, err
+	}
+	if !leading {
+		p.logger.Info("Another replica holds the leader lock, skipping run")
+		return stats, nil
+	}
+
+	p.disableConsumedRules()
+
+	// Fetch entries with any status targeted by a rule (unread by default)
 	filter := &miniflux.Filter{
-		Limit: 100, // Process in batches
+		Limit:    100, // Process in batches
+		Statuses: p.fetchStatuses(),
+		Starred:  p.fetchStarred(),
+
+		Order:     strings.ToLower(p.options.FetchOrder),
+		Direction: strings.ToLower(p.options.FetchDirection),
 	}
-	if !p.dryRun {
-		filter.Status = miniflux.EntryStatusUnread
+	p.restrictToUnevaluated(filter)
+	p.resumeChunk(filter)
+	p.runStarted = p.now()
+	p.skippedPages = 0
+	clear(p.failedRules)
+
+	// With a change budget, matches are held back until the whole run is known
+	var pending []pendingEntry
+	budgeted := p.budgeted()
+
+	handle := func(entry *miniflux.Entry, results []MatchResult) {
+		if budgeted {
+			pending = append(pending, pendingEntry{entry: entry, results: results})
+			return
+		}
+		p.processEntry(entry, results, stats)
+		p.recordSeen(entry)
 	}
 
-	offset := 0
-	for {
-		filter.Offset = offset
-		result, err := p.client.Entries(filter)
-		if err != nil {
-			return stats, fmt.Errorf("failed to fetch entries: %w", err)
+	var newest int64
+	truncated := false
+	err = p.fetchPages(filter, func(page []*miniflux.Entry) bool {
+		page, truncated = p.truncatePage(page, stats.TotalEntries)
+		stats.TotalEntries += len(page)
+		newest = max(newest, newestEntryID(page))
+		p.enrichEntries(page)
+		p.matchPage(page, handle)
+		return !truncated
+	})
+	stats.SkippedPages = p.skippedPages
+	if err != nil {
+		return stats, err
+	}
+
+	if budgeted {
+		if err := p.checkChangeBudget(pending, stats.TotalEntries); err != nil {
+			return stats, err
+		}
+		for _, pe := range pending {
+			p.processEntry(pe.entry, pe.results, stats)
+			p.recordSeen(pe.entry)
 		}
+	}
+
+	// Entries on skipped pages or beyond max_entries_per_run were never evaluated,
+	// so the next run must fetch them again
+	p.recordChunk(newest, truncated)
+	if stats.SkippedPages == 0 && !truncated {
+		p.recordEvaluated(newest, p.runStarted)
+	}
+	p.logDigest(stats)
+	p.sendEmailDigest(stats)
+	// A one-off rule has not seen every entry until the run covers them all
+	if stats.SkippedPages == 0 && !truncated {
+		p.consumeOnceRules()
+	}
+	p.evaluateAggregates(stats)
+	p.generateReadReport(stats)
+	p.flushHistory()
+	p.pruneFeedActivity()
+
+	if err := p.matcher.SaveCaches(); err != nil {
+		p.logger.Error("Failed to save caches", "error", err)
+	}
+
+	if err := p.saveState(); err != nil {
+		return stats, err
+	}
 
-		if len(result.Entries) == 0 {
-			break
+	if stats.SkippedPages > 0 {
+		return stats, &PartialFetchError{Pages: stats.SkippedPages}
+	}
+	return stats, nil
+}
+
+// concurrency returns how many pages may be fetched and entries matched at once
+func (p *Processor) concurrency() int {
+	return max(p.options.Concurrency, 1)
+}
+
+// matchPage matches a page of entries and hands each to handle in page order
+// With concurrency above 1, a pool of workers matches the whole page before any
+// entry is handled, so actions, stats and state are only ever touched by the caller.
+// Seen-cache rules compare entries with those handled before them, so they keep
+// matching one entry at a time.
+func (p *Processor) matchPage(page []*miniflux.Entry, handle func(*miniflux.Entry, []MatchResult)) {
+	if p.concurrency() == 1 || p.matcher.UsesSeenCache() {
+		for _, entry := range page {
+			p.detectOverride(entry)
+			handle(entry, p.matcher.MatchAll(entry))
 		}
+		return
+	}
+
+	for _, entry := range page {
+		p.detectOverride(entry)
+	}
 
-		for _, entry := range result.Entries {
-			stats.TotalEntries++
-			p.processEntry(entry, stats)
+	results := make([][]MatchResult, len(page))
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for range min(p.concurrency(), len(page)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				results[i] = p.matcher.MatchAll(page[i])
+			}
+		}()
+	}
+	for i := range page {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	for i, entry := range page {
+		handle(entry, results[i])
+	}
+}
+
+// acquireLeadership reports whether this replica may run
+// A replica taking over from another reloads the shared state first
+func (p *Processor) acquireLeadership() (bool, error) {
+	if p.leader == nil {
+		return true, nil
+	}
+
+	leading, err := p.leader.Acquire()
+	if err != nil {
+		return false, err
+	}
+	if !leading {
+		p.following = true
+		return false, nil
+	}
+
+	if p.following && p.state != nil {
+		p.logger.Info("Acquired leader lock, reloading state")
+		if err := p.state.Reload(); err != nil {
+			return false, err
 		}
+	}
+	p.following = false
+	return true, nil
+}
+
+// recordSeen updates the seen-cache with the entry's current content
+// Dry runs leave the cache untouched so change detection still fires later
+func (p *Processor) recordSeen(entry *miniflux.Entry) {
+	if p.state == nil || p.dryRun || !p.matcher.UsesSeenCache() {
+		return
+	}
+	now := p.now()
+	p.state.RecordSeen(entry.ID, contentHash(entry.Content), now)
+	p.state.RecordSeenKeys(entry.ID, entry.Title, entry.URL, now)
+	if hash := normalizedContentHash(entry.Content); hash != "" {
+		p.state.RecordContentKey(entry.ID, hash, now)
+	}
+}
 
-		offset += len(result.Entries)
+// saveState prunes stale seen-cache records and persists the state
+func (p *Processor) saveState() error {
+	if p.state == nil || p.dryRun {
+		return nil
+	}
 
-		// Check if we've processed all entries
-		if offset >= result.Total {
-			break
+	p.state.PruneSeen(p.now().Add(-seenRetention))
+	p.state.PruneNotifications(p.now().Add(-seenRetention))
+	p.state.PruneActions(p.now().Add(-seenRetention))
+	p.state.PrunePending(p.now().Add(-seenRetention))
+
+	if err := p.state.Save(); err != nil {
+		return fmt.Errorf("failed to save state: %w", err)
+	}
+	return nil
+}
+
+// fetchStatuses returns the configured statuses, or the union of entry statuses targeted by the rules
+// Dry runs fetch the same statuses, so they preview exactly what a real run would change
+func (p *Processor) fetchStatuses() []string {
+	var statuses []string
+	if len(p.options.Statuses) > 0 {
+		for _, status := range p.options.Statuses {
+			statuses = append(statuses, strings.ToLower(status))
 		}
+		return statuses
 	}
 
-	return stats, nil
+	for _, rule := range p.matcher.Rules() {
+		for _, status := range ruleStatuses(&rule, p.options.Macros) {
+			if !slices.Contains(statuses, status) {
+				statuses = append(statuses, status)
+			}
+		}
+	}
+	if len(statuses) == 0 {
+		statuses = []string{miniflux.EntryStatusUnread}
+	}
+	return statuses
+}
+
+// ruleStatuses returns the entry statuses a rule is fetched for, and the only ones it matches
+// Rules marking entries unread default to read entries, the only ones they can change
+func ruleStatuses(rule *Rule, macros map[string][]string) []string {
+	if len(rule.Status) == 0 {
+		steps, _ := expandRule(rule, macros)
+		if slices.Contains(steps, "unread") {
+			return []string{miniflux.EntryStatusRead}
+		}
+	}
+	return rule.Statuses()
 }
 
-// processEntry processes a single entry against all rules
-func (p *Processor) processEntry(entry *miniflux.Entry, stats *ProcessStats) {
-	result := p.matcher.Match(entry)
-	if !result.Matched {
+// fetchStarred restricts fetching to starred entries when every rule targets them
+func (p *Processor) fetchStarred() string {
+	rules := p.matcher.Rules()
+	if len(rules) == 0 {
+		return ""
+	}
+	for _, rule := range rules {
+		if !rule.OnlyStarred() {
+			return ""
+		}
+	}
+	return miniflux.FilterOnlyStarred
+}
+
+// disableConsumedRules turns off one-off rules that already ran in a previous run
+func (p *Processor) disableConsumedRules() {
+	if p.state == nil {
 		return
 	}
+	for _, rule := range p.matcher.Rules() {
+		if rule.Once && p.state.IsConsumed(rule.Name) {
+			p.matcher.DisableRule(rule.Name)
+		}
+	}
+}
 
-	stats.MatchedEntries++
+// consumeOnceRules records one-off rules as consumed after a completed run
+// Dry runs never consume rules so the real run still applies them, and rules with a
+// failed action stay active so the next run retries the entries
+func (p *Processor) consumeOnceRules() {
+	if p.state == nil || p.dryRun {
+		return
+	}
 
-	feedTitle := ""
-	if entry.Feed != nil {
-		feedTitle = entry.Feed.Title
+	for _, rule := range p.matcher.Rules() {
+		if !rule.Once || p.state.IsConsumed(rule.Name) {
+			continue
+		}
+		if p.failedRules[rule.Name] {
+			p.logger.Warn("One-off rule had failed actions, keeping it for the next run", "rule", rule.Name)
+			continue
+		}
+		p.state.MarkConsumed(rule.Name, p.now())
+		p.matcher.DisableRule(rule.Name)
+		p.logger.Info("One-off rule applied and marked as consumed", "rule", rule.Name)
 	}
+}
+
+// Evaluation is a rule matching an evaluated entry, with the primitive actions it would perform
+type Evaluation struct {
+	Result MatchResult
+	Steps  []string
+}
 
-	p.logger.Printf("Rule '%s' matched entry: [%s] %s", result.Rule.Name, feedTitle, entry.Title)
+// Evaluate matches an entry against the rules without applying or recording anything
+// Steps are expanded under the lock, with the macros of the same config as the rules
+func (p *Processor) Evaluate(entry *miniflux.Entry) []Evaluation {
+	p.mu.Lock()
+	defer p.mu.Unlock()
 
-	var status string
-	switch result.Action {
-	case "read":
-		status = miniflux.EntryStatusRead
-		stats.MarkedRead++
-	case "remove":
-		status = miniflux.EntryStatusRemoved
-		stats.Removed++
-	default:
-		p.logger.Printf("Unknown action '%s' for rule '%s'", result.Action, result.Rule.Name)
-		stats.Errors++
-		return
+	var evaluations []Evaluation
+	for _, result := range p.matcher.MatchAll(entry) {
+		steps, _ := expandRule(result.Rule, p.options.Macros)
+		evaluations = append(evaluations, Evaluation{Result: result, Steps: steps})
+	}
+	return evaluations
+}
+
+// shouldNotify reports whether a notification about an entry has not been sent yet
+// and records it as sent, so reprocessing never repeats the same notification
+func (p *Processor) shouldNotify(kind string, entry *miniflux.Entry, payload string) bool {
+	if p.notificationSent(kind, entry, payload) {
+		return false
+	}
+	if !p.dryRun {
+		p.recordNotification(kind, entry, payload)
+	}
+	return true
+}
+
+// notificationSent reports whether a notification about an entry was already sent
+func (p *Processor) notificationSent(kind string, entry *miniflux.Entry, payload string) bool {
+	if p.state == nil || !p.state.NotificationSent(notificationHash(kind, entry.ID, payload)) {
+		return false
 	}
+	p.logger.Info("Suppressing duplicate notification", "entry_id", entry.ID, "action", kind)
+	return true
+}
+
+// recordNotification records a notification about an entry as sent
+func (p *Processor) recordNotification(kind string, entry *miniflux.Entry, payload string) {
+	if p.state != nil {
+		p.state.RecordNotification(notificationHash(kind, entry.ID, payload), p.now())
+	}
+}
 
-	if p.dryRun {
-		actionVerb := result.Action
-		if result.Action == "read" {
-			actionVerb = "mark read"
-		} else if result.Action == "remove" {
-			actionVerb = "remove"
+// pendingEntry is an entry whose matches await the change budget check
+type pendingEntry struct {
+	entry   *miniflux.Entry
+	results []MatchResult
+}
+
+// ChangeBudgetError aborts a run whose changes exceed a safety limit, before any is applied
+type ChangeBudgetError struct {
+	Reason string
+}
+
+// Error describes the exceeded limit
+func (e *ChangeBudgetError) Error() string {
+	return "change budget exceeded: " + e.Reason + "; no changes applied"
+}
+
+// budgeted reports whether matches are held back until the change limits are checked
+func (p *Processor) budgeted() bool {
+	return p.options.MaxChangeRatio > 0 || p.options.MaxActionsPerRun > 0 || p.options.MaxRemovalsPerRun > 0
+}
+
+// checkChangeBudget fails when the matched entries exceed max_removals_per_run,
+// max_actions_per_run or max_change_ratio, logging how many entries each rule matched
+func (p *Processor) checkChangeBudget(pending []pendingEntry, total int) error {
+	changes, removals := 0, 0
+	matches := make(map[string]int)
+	for _, pe := range pending {
+		if len(pe.results) == 0 {
+			continue
+		}
+		changes++
+
+		removes := false
+		for _, result := range pe.results {
+			matches[result.Rule.Name]++
+			steps, _ := expandRule(result.Rule, p.options.Macros)
+			removes = removes || slices.Contains(steps, "remove")
 		}
-		p.logger.Printf(
-			"Dry run: would %s entry %d [%s] %s",
-			actionVerb,
-			entry.ID,
-			feedTitle,
-			entry.Title,
+		if removes {
+			removals++
+		}
+	}
+
+	var reason string
+	switch {
+	case p.options.MaxRemovalsPerRun > 0 && removals > p.options.MaxRemovalsPerRun:
+		reason = fmt.Sprintf("%d entries would be removed, max_removals_per_run is %d", removals, p.options.MaxRemovalsPerRun)
+	case p.options.MaxActionsPerRun > 0 && changes > p.options.MaxActionsPerRun:
+		reason = fmt.Sprintf("%d entries would be modified, max_actions_per_run is %d", changes, p.options.MaxActionsPerRun)
+	case p.options.MaxChangeRatio > 0 && total > 0 && float64(changes)/float64(total) > p.options.MaxChangeRatio:
+		reason = fmt.Sprintf(
+			"%d of %d entries (%.0f%%) would be modified, max_change_ratio is %.0f%%",
+			changes, total, float64(changes)/float64(total)*100, p.options.MaxChangeRatio*100,
 		)
-		return
+	default:
+		return nil
 	}
 
-	if err := p.client.UpdateEntries([]int64{entry.ID}, status); err != nil {
-		p.logger.Printf("Failed to update entry %d: %v", entry.ID, err)
-		stats.Errors++
+	names := slices.SortedFunc(maps.Keys(matches), func(a, b string) int {
+		return cmp.Or(matches[b]-matches[a], strings.Compare(a, b))
+	})
+	for _, name := range names {
+		p.logger.Warn("Rule would change entries", "rule", name, "entries", matches[name])
+	}
+	return &ChangeBudgetError{Reason: reason}
+}
+
+// processEntry records and applies the matching rules of a single entry
+func (p *Processor) processEntry(entry *miniflux.Entry, results []MatchResult, stats *ProcessStats) {
+	if len(results) == 0 {
+		p.recordFeedActivity(entry, MatchResult{Matched: false})
 		return
 	}
+	p.recordFeedActivity(entry, results[0])
+
+	stats.MatchedEntries++
 
-	p.logger.Printf("Applied action '%s' to entry %d", result.Action, entry.ID)
+	p.runPipeline(entry, p.buildPipeline(entry, results, stats), stats)
 }
@@ -1,8 +1,11 @@
 package main
 
 import (
-	"log"
+	"errors"
+	"log/slog"
 	"os"
+	"path/filepath"
+	"sync"
 	"testing"
 
 	miniflux "miniflux.app/v2/client"
@@ -10,40 +13,83 @@ import (
 
 // MockClient implements MinifluxClient for testing
 type MockClient struct {
-	entries       []*miniflux.Entry
-	updatedIDs    []int64
-	updatedStatus string
-	feeds         miniflux.Feeds
-	entriesErr    error
-	updateErr     error
-	feedsErr      error
+	entries        []*miniflux.Entry
+	updatedIDs     []int64
+	updatedStatus  string
+	starredIDs     []int64
+	savedIDs       []int64
+	feeds          miniflux.Feeds
+	disabledFeeds  []int64
+	refreshed      []int64
+	flushed        int
+	blockRules     map[int64]string
+	titles         map[int64]string
+	original       map[int64]string // original articles returned by FetchEntryOriginalContent
+	contentFetches int
+	entriesErr     error
+	updateErr      error
+	saveErr        error
+	feedsErr       error
+	meErr          error
+	lastFilter     *miniflux.Filter
+	mu             sync.Mutex // guards lastFilter against concurrent page fetches
 }
 
 func (m *MockClient) Entries(filter *miniflux.Filter) (*miniflux.EntryResultSet, error) {
+	m.mu.Lock()
+	m.lastFilter = filter
+	m.mu.Unlock()
 	if m.entriesErr != nil {
 		return nil, m.entriesErr
 	}
 
+	// Apply feed, category and entry ID filters
+	entries := m.entries
+	if filter.FeedID != 0 || filter.CategoryID != 0 || filter.AfterEntryID != 0 {
+		entries = nil
+		for _, entry := range m.entries {
+			if filter.FeedID != 0 && entryFeedID(entry) != filter.FeedID {
+				continue
+			}
+			if entry.ID <= filter.AfterEntryID {
+				continue
+			}
+			if filter.CategoryID != 0 && entryCategoryID(entry) != filter.CategoryID {
+				continue
+			}
+			entries = append(entries, entry)
+		}
+	}
+
 	// Apply offset and limit
 	start := filter.Offset
-	if start >= len(m.entries) {
+	if start >= len(entries) {
 		return &miniflux.EntryResultSet{
-			Total:   len(m.entries),
+			Total:   len(entries),
 			Entries: []*miniflux.Entry{},
 		}, nil
 	}
 
 	end := start + filter.Limit
-	if end > len(m.entries) || filter.Limit == 0 {
-		end = len(m.entries)
+	if end > len(entries) || filter.Limit == 0 {
+		end = len(entries)
 	}
 
 	return &miniflux.EntryResultSet{
-		Total:   len(m.entries),
-		Entries: m.entries[start:end],
+		Total:   len(entries),
+		Entries: entries[start:end],
 	}, nil
 }
 
+func (m *MockClient) Entry(entryID int64) (*miniflux.Entry, error) {
+	for _, entry := range m.entries {
+		if entry.ID == entryID {
+			return entry, nil
+		}
+	}
+	return nil, miniflux.ErrNotFound
+}
+
 func (m *MockClient) UpdateEntries(entryIDs []int64, status string) error {
 	if m.updateErr != nil {
 		return m.updateErr
@@ -53,11 +99,126 @@ func (m *MockClient) UpdateEntries(entryIDs []int64, status string) error {
 	return nil
 }
 
+func (m *MockClient) ToggleStarred(entryID int64) error {
+	if m.updateErr != nil {
+		return m.updateErr
+	}
+	m.starredIDs = append(m.starredIDs, entryID)
+	return nil
+}
+
+func (m *MockClient) FetchEntryOriginalContent(entryID int64) (string, error) {
+	m.contentFetches++
+	if m.entriesErr != nil {
+		return "", m.entriesErr
+	}
+	return m.original[entryID], nil
+}
+
+func (m *MockClient) SaveEntry(entryID int64) error {
+	if m.updateErr != nil {
+		return m.updateErr
+	}
+	if m.saveErr != nil {
+		return m.saveErr
+	}
+	m.savedIDs = append(m.savedIDs, entryID)
+	return nil
+}
+
 func (m *MockClient) Feeds() (miniflux.Feeds, error) {
 	if m.feedsErr != nil {
 		return nil, m.feedsErr
 	}
-	return m.feeds, nil
+	if m.feeds != nil {
+		return m.feeds, nil
+	}
+
+	// Like a real server, list the feeds of the entries when none are set
+	var feeds miniflux.Feeds
+	seen := make(map[int64]bool)
+	for _, entry := range m.entries {
+		id := entryFeedID(entry)
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		if entry.Feed != nil {
+			feeds = append(feeds, entry.Feed)
+		} else {
+			feeds = append(feeds, &miniflux.Feed{ID: id})
+		}
+	}
+	return feeds, nil
+}
+
+func (m *MockClient) FeedCounters() (*miniflux.FeedCounters, error) {
+	if m.feedsErr != nil {
+		return nil, m.feedsErr
+	}
+	counters := &miniflux.FeedCounters{ReadCounters: map[int64]int{}, UnreadCounters: map[int64]int{}}
+	for _, entry := range m.entries {
+		switch entry.Status {
+		case miniflux.EntryStatusUnread:
+			counters.UnreadCounters[entryFeedID(entry)]++
+		case miniflux.EntryStatusRead:
+			counters.ReadCounters[entryFeedID(entry)]++
+		}
+	}
+	return counters, nil
+}
+
+func (m *MockClient) Me() (*miniflux.User, error) {
+	if m.meErr != nil {
+		return nil, m.meErr
+	}
+	return &miniflux.User{ID: 1, Username: "admin"}, nil
+}
+
+func (m *MockClient) DisableFeed(feedID int64) error {
+	if m.updateErr != nil {
+		return m.updateErr
+	}
+	m.disabledFeeds = append(m.disabledFeeds, feedID)
+	return nil
+}
+
+func (m *MockClient) RefreshFeed(feedID int64) error {
+	if m.updateErr != nil {
+		return m.updateErr
+	}
+	m.refreshed = append(m.refreshed, feedID)
+	return nil
+}
+
+func (m *MockClient) FlushHistory() error {
+	if m.updateErr != nil {
+		return m.updateErr
+	}
+	m.flushed++
+	return nil
+}
+
+func (m *MockClient) UpdateEntryTitle(entryID int64, title string) error {
+	if m.updateErr != nil {
+		return m.updateErr
+	}
+	if m.titles == nil {
+		m.titles = make(map[int64]string)
+	}
+	m.titles[entryID] = title
+	return nil
+}
+
+func (m *MockClient) SetBlockFilterRules(feedID int64, rules string) error {
+	if m.updateErr != nil {
+		return m.updateErr
+	}
+	if m.blockRules == nil {
+		m.blockRules = make(map[int64]string)
+	}
+	m.blockRules[feedID] = rules
+	return nil
 }
 
 func TestProcessorMarkRead(t *testing.T) {
@@ -93,7 +254,7 @@ func TestProcessorMarkRead(t *testing.T) {
 		t.Fatalf("Failed to create matcher: %v", err)
 	}
 
-	logger := log.New(os.Stdout, "[test] ", 0)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
 	processor := NewProcessor(mockClient, matcher, logger, false)
 
 	stats, err := processor.Process()
@@ -149,7 +310,7 @@ func TestProcessorRemove(t *testing.T) {
 		t.Fatalf("Failed to create matcher: %v", err)
 	}
 
-	logger := log.New(os.Stdout, "[test] ", 0)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
 	processor := NewProcessor(mockClient, matcher, logger, false)
 
 	stats, err := processor.Process()
@@ -192,7 +353,7 @@ func TestProcessorDryRun(t *testing.T) {
 		t.Fatalf("Failed to create matcher: %v", err)
 	}
 
-	logger := log.New(os.Stdout, "[test] ", 0)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
 	processor := NewProcessor(mockClient, matcher, logger, true)
 
 	stats, err := processor.Process()
@@ -237,7 +398,7 @@ func TestProcessorNoMatches(t *testing.T) {
 		t.Fatalf("Failed to create matcher: %v", err)
 	}
 
-	logger := log.New(os.Stdout, "[test] ", 0)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
 	processor := NewProcessor(mockClient, matcher, logger, false)
 
 	stats, err := processor.Process()
@@ -274,7 +435,7 @@ func TestProcessorEmptyEntries(t *testing.T) {
 		t.Fatalf("Failed to create matcher: %v", err)
 	}
 
-	logger := log.New(os.Stdout, "[test] ", 0)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
 	processor := NewProcessor(mockClient, matcher, logger, false)
 
 	stats, err := processor.Process()
@@ -332,7 +493,7 @@ func TestProcessorMultipleRules(t *testing.T) {
 		t.Fatalf("Failed to create matcher: %v", err)
 	}
 
-	logger := log.New(os.Stdout, "[test] ", 0)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
 	processor := NewProcessor(mockClient, matcher, logger, false)
 
 	stats, err := processor.Process()
@@ -383,7 +544,7 @@ func TestProcessorPagination(t *testing.T) {
 		t.Fatalf("Failed to create matcher: %v", err)
 	}
 
-	logger := log.New(os.Stdout, "[test] ", 0)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
 	processor := NewProcessor(mockClient, matcher, logger, false)
 
 	stats, err := processor.Process()
@@ -398,3 +559,409 @@ func TestProcessorPagination(t *testing.T) {
 		t.Errorf("Expected 150 matched entries, got %d", stats.MatchedEntries)
 	}
 }
+
+func TestProcessorOnceRule(t *testing.T) {
+	mockClient := &MockClient{
+		entries: []*miniflux.Entry{
+			{ID: 1, Title: "Old Post", Feed: &miniflux.Feed{Title: "Gone Feed"}},
+		},
+	}
+
+	rules := []Rule{
+		{
+			Name:   "Purge gone feed",
+			Feed:   "Gone Feed",
+			Action: "remove",
+			Once:   true,
+		},
+	}
+
+	matcher, err := NewMatcher(rules)
+	if err != nil {
+		t.Fatalf("Failed to create matcher: %v", err)
+	}
+
+	state, err := LoadState(filepath.Join(t.TempDir(), "state.json"))
+	if err != nil {
+		t.Fatalf("Failed to load state: %v", err)
+	}
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	processor := NewProcessor(mockClient, matcher, logger, false)
+	processor.SetState(state)
+
+	stats, err := processor.Process()
+	if err != nil {
+		t.Fatalf("Process failed: %v", err)
+	}
+	if stats.Removed != 1 {
+		t.Errorf("Expected 1 removed on first run, got %d", stats.Removed)
+	}
+	if !state.IsConsumed("Purge gone feed") {
+		t.Error("Expected rule to be marked consumed")
+	}
+
+	stats, err = processor.Process()
+	if err != nil {
+		t.Fatalf("Process failed: %v", err)
+	}
+	if stats.MatchedEntries != 0 {
+		t.Errorf("Expected consumed rule not to match again, got %d matches", stats.MatchedEntries)
+	}
+}
+
+func TestProcessorOnceRuleFailedAction(t *testing.T) {
+	mockClient := &MockClient{
+		entries: []*miniflux.Entry{
+			{ID: 1, Title: "Old Post", Feed: &miniflux.Feed{Title: "Gone Feed"}},
+		},
+		updateErr: errors.New("server unavailable"),
+	}
+
+	matcher, err := NewMatcher([]Rule{{Name: "Purge gone feed", Feed: "Gone Feed", Action: "remove", Once: true}})
+	if err != nil {
+		t.Fatalf("Failed to create matcher: %v", err)
+	}
+
+	state, err := LoadState(filepath.Join(t.TempDir(), "state.json"))
+	if err != nil {
+		t.Fatalf("Failed to load state: %v", err)
+	}
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	processor := NewProcessor(mockClient, matcher, logger, false)
+	processor.SetState(state)
+
+	stats, err := processor.Process()
+	if err != nil {
+		t.Fatalf("Process failed: %v", err)
+	}
+	if stats.Errors != 1 {
+		t.Errorf("Expected 1 error, got %d", stats.Errors)
+	}
+	if state.IsConsumed("Purge gone feed") {
+		t.Error("Expected rule with a failed action not to be consumed")
+	}
+
+	// The next run retries the entry and consumes the rule once it succeeds
+	mockClient.updateErr = nil
+	stats, err = processor.Process()
+	if err != nil {
+		t.Fatalf("Process failed: %v", err)
+	}
+	if stats.Removed != 1 || !state.IsConsumed("Purge gone feed") {
+		t.Errorf("Expected the retried removal to consume the rule, got %d removed", stats.Removed)
+	}
+}
+
+func TestProcessorSkipStarred(t *testing.T) {
+	mockClient := &MockClient{
+		entries: []*miniflux.Entry{
+			{ID: 1, Title: "Sponsored Post", Starred: true},
+			{ID: 2, Title: "Sponsored Post"},
+		},
+	}
+
+	rules := []Rule{
+		{
+			Name:   "Remove sponsored",
+			Title:  "(?i)sponsored",
+			Action: "remove",
+		},
+	}
+
+	matcher, err := NewMatcher(rules)
+	if err != nil {
+		t.Fatalf("Failed to create matcher: %v", err)
+	}
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	processor := NewProcessor(mockClient, matcher, logger, false)
+	processor.SetOptions(ProcessorOptions{SkipStarred: true})
+
+	stats, err := processor.Process()
+	if err != nil {
+		t.Fatalf("Process failed: %v", err)
+	}
+	if stats.Removed != 1 {
+		t.Errorf("Expected 1 removed, got %d", stats.Removed)
+	}
+	if len(mockClient.updatedIDs) != 1 || mockClient.updatedIDs[0] != 2 {
+		t.Errorf("Expected only entry 2 to be updated, got %v", mockClient.updatedIDs)
+	}
+
+	// Rules can opt back in to starred entries
+	mockClient.updatedIDs = nil
+	mockClient.entries = []*miniflux.Entry{
+		{ID: 1, Title: "Sponsored Post", Starred: true},
+		{ID: 2, Title: "Sponsored Post"},
+	}
+	rules[0].IncludeStarred = true
+	matcher, err = NewMatcher(rules)
+	if err != nil {
+		t.Fatalf("Failed to create matcher: %v", err)
+	}
+	processor = NewProcessor(mockClient, matcher, logger, false)
+	processor.SetOptions(ProcessorOptions{SkipStarred: true})
+
+	if _, err := processor.Process(); err != nil {
+		t.Fatalf("Process failed: %v", err)
+	}
+	if len(mockClient.updatedIDs) != 2 {
+		t.Errorf("Expected both entries to be updated, got %v", mockClient.updatedIDs)
+	}
+}
+
+func TestProcessorFetchStatuses(t *testing.T) {
+	mockClient := &MockClient{
+		entries: []*miniflux.Entry{
+			{ID: 1, Title: "Junk", Status: miniflux.EntryStatusRead},
+			{ID: 2, Title: "Junk", Status: miniflux.EntryStatusUnread},
+		},
+	}
+
+	rules := []Rule{
+		{
+			Name:   "Remove read junk",
+			Title:  "Junk",
+			Action: "remove",
+			Status: StringList{"read"},
+		},
+	}
+
+	matcher, err := NewMatcher(rules)
+	if err != nil {
+		t.Fatalf("Failed to create matcher: %v", err)
+	}
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	processor := NewProcessor(mockClient, matcher, logger, false)
+
+	stats, err := processor.Process()
+	if err != nil {
+		t.Fatalf("Process failed: %v", err)
+	}
+
+	if len(mockClient.lastFilter.Statuses) != 1 || mockClient.lastFilter.Statuses[0] != miniflux.EntryStatusRead {
+		t.Errorf("Expected fetch of read entries only, got %v", mockClient.lastFilter.Statuses)
+	}
+	if stats.Removed != 1 || mockClient.updatedIDs[0] != 1 {
+		t.Errorf("Expected only read entry 1 to be removed, got %v", mockClient.updatedIDs)
+	}
+}
+
+func TestProcessorStatusAllLeavesOtherRulesUnread(t *testing.T) {
+	mockClient := &MockClient{
+		entries: []*miniflux.Entry{
+			{ID: 1, Title: "Junk", Status: miniflux.EntryStatusRead},
+			{ID: 2, Title: "Junk", Status: miniflux.EntryStatusUnread},
+			{ID: 3, Title: "Release notes", Status: miniflux.EntryStatusRead},
+		},
+	}
+
+	rules := []Rule{
+		{
+			Name:   "Star releases",
+			Title:  "Release",
+			Action: "star",
+			Status: StringList{"all"},
+		},
+		{
+			Name:   "Remove junk",
+			Title:  "Junk",
+			Action: "remove",
+		},
+	}
+
+	matcher, err := NewMatcher(rules)
+	if err != nil {
+		t.Fatalf("Failed to create matcher: %v", err)
+	}
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	processor := NewProcessor(mockClient, matcher, logger, false)
+
+	stats, err := processor.Process()
+	if err != nil {
+		t.Fatalf("Process failed: %v", err)
+	}
+
+	if len(mockClient.lastFilter.Statuses) != 2 {
+		t.Errorf("Expected fetch of unread and read entries, got %v", mockClient.lastFilter.Statuses)
+	}
+	if stats.Removed != 1 || len(mockClient.updatedIDs) != 1 || mockClient.updatedIDs[0] != 2 {
+		t.Errorf("Expected only unread entry 2 to be removed, got %v", mockClient.updatedIDs)
+	}
+	if len(mockClient.starredIDs) != 1 || mockClient.starredIDs[0] != 3 {
+		t.Errorf("Expected read entry 3 to be starred, got %v", mockClient.starredIDs)
+	}
+}
+
+func TestProcessorChangedSinceLastSeen(t *testing.T) {
+	mockClient := &MockClient{
+		entries: []*miniflux.Entry{
+			{ID: 1, Title: "Story", Content: "Original text"},
+			{ID: 2, Title: "Other", Content: "Stable text"},
+		},
+	}
+
+	rules := []Rule{
+		{
+			Name:                 "Flag stealth edits",
+			ChangedSinceLastSeen: true,
+			Action:               "read",
+		},
+	}
+
+	matcher, err := NewMatcher(rules)
+	if err != nil {
+		t.Fatalf("Failed to create matcher: %v", err)
+	}
+
+	state, err := LoadState(filepath.Join(t.TempDir(), "state.json"))
+	if err != nil {
+		t.Fatalf("Failed to load state: %v", err)
+	}
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	processor := NewProcessor(mockClient, matcher, logger, false)
+	processor.SetState(state)
+
+	stats, err := processor.Process()
+	if err != nil {
+		t.Fatalf("Process failed: %v", err)
+	}
+	if stats.MatchedEntries != 0 {
+		t.Errorf("Expected no matches on first sight, got %d", stats.MatchedEntries)
+	}
+
+	mockClient.entries[0].Content = "Corrected text"
+
+	stats, err = processor.Process()
+	if err != nil {
+		t.Fatalf("Process failed: %v", err)
+	}
+	if stats.MatchedEntries != 1 {
+		t.Errorf("Expected 1 match after edit, got %d", stats.MatchedEntries)
+	}
+	if len(mockClient.updatedIDs) != 1 || mockClient.updatedIDs[0] != 1 {
+		t.Errorf("Expected edited entry 1 to be updated, got %v", mockClient.updatedIDs)
+	}
+}
+
+func TestProcessorContinueRule(t *testing.T) {
+	mockClient := &MockClient{
+		entries: []*miniflux.Entry{
+			{ID: 1, Title: "Sponsored promo"},
+		},
+	}
+
+	rules := []Rule{
+		{Name: "Read sponsored", Title: "(?i)sponsored", Action: "read", Continue: true},
+		{Name: "Remove promos", Title: "(?i)promo", Action: "remove"},
+	}
+
+	matcher, err := NewMatcher(rules)
+	if err != nil {
+		t.Fatalf("Failed to create matcher: %v", err)
+	}
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	processor := NewProcessor(mockClient, matcher, logger, false)
+
+	stats, err := processor.Process()
+	if err != nil {
+		t.Fatalf("Process failed: %v", err)
+	}
+
+	if stats.MatchedEntries != 1 {
+		t.Errorf("Expected 1 matched entry, got %d", stats.MatchedEntries)
+	}
+	if stats.MarkedRead != 1 || stats.Removed != 1 {
+		t.Errorf("Expected both actions to apply, got %d read and %d removed", stats.MarkedRead, stats.Removed)
+	}
+	if mockClient.updatedStatus != miniflux.EntryStatusRemoved {
+		t.Errorf("Expected last action to remove the entry, got '%s'", mockClient.updatedStatus)
+	}
+}
+
+func TestProcessorMaxChangeRatio(t *testing.T) {
+	mockClient := &MockClient{
+		entries: []*miniflux.Entry{
+			{ID: 1, Title: "Junk"},
+			{ID: 2, Title: "Junk"},
+			{ID: 3, Title: "News"},
+			{ID: 4, Title: "News"},
+		},
+	}
+
+	matcher, err := NewMatcher([]Rule{
+		{Name: "Junk", Title: "Junk", Action: "remove"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create matcher: %v", err)
+	}
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	processor := NewProcessor(mockClient, matcher, logger, false)
+	processor.SetOptions(ProcessorOptions{MaxChangeRatio: 0.3})
+
+	if _, err := processor.Process(); err == nil {
+		t.Error("Expected change budget error")
+	}
+	if len(mockClient.updatedIDs) != 0 {
+		t.Errorf("Expected no updates when over budget, got %v", mockClient.updatedIDs)
+	}
+
+	processor.SetOptions(ProcessorOptions{MaxChangeRatio: 0.5})
+	stats, err := processor.Process()
+	if err != nil {
+		t.Fatalf("Process failed: %v", err)
+	}
+	if stats.Removed != 2 {
+		t.Errorf("Expected 2 removed within budget, got %d", stats.Removed)
+	}
+}
+
+func TestProcessorMaxActionsPerRun(t *testing.T) {
+	mockClient := &MockClient{
+		entries: []*miniflux.Entry{
+			{ID: 1, Title: "Junk"},
+			{ID: 2, Title: "Junk"},
+			{ID: 3, Title: "Release"},
+		},
+	}
+
+	matcher, err := NewMatcher([]Rule{
+		{Name: "Junk", Title: "Junk", Action: "remove"},
+		{Name: "Releases", Title: "Release", Action: "star"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create matcher: %v", err)
+	}
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	processor := NewProcessor(mockClient, matcher, logger, false)
+
+	tests := []struct {
+		options  ProcessorOptions
+		exceeded bool
+	}{
+		{ProcessorOptions{MaxRemovalsPerRun: 1}, true},
+		{ProcessorOptions{MaxActionsPerRun: 2}, true},
+		{ProcessorOptions{MaxRemovalsPerRun: 2, MaxActionsPerRun: 3}, false},
+	}
+	for _, tt := range tests {
+		mockClient.updatedIDs = nil
+		processor.SetOptions(tt.options)
+
+		_, err := processor.Process()
+		var budgetErr *ChangeBudgetError
+		if errors.As(err, &budgetErr) != tt.exceeded {
+			t.Errorf("%+v: expected exceeded=%v, got %v", tt.options, tt.exceeded, err)
+		}
+		if tt.exceeded && len(mockClient.updatedIDs) != 0 {
+			t.Errorf("%+v: expected no updates when over budget, got %v", tt.options, mockClient.updatedIDs)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// applyProfile merges the named profile over the rest of a parsed config document and drops
+// the profiles, so the same rules can run against e.g. a staging server with dry_run set.
+// Settings of the profile replace those of the same name; nested settings such as webhooks
+// or notify are merged key by key, while lists such as rules are replaced as a whole.
+func applyProfile(doc *yaml.Node, profile string) error {
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		if profile != "" {
+			return fmt.Errorf("unknown profile '%s'", profile)
+		}
+		return nil
+	}
+	root := doc.Content[0]
+
+	var profiles *yaml.Node
+	for i := 0; i < len(root.Content); i += 2 {
+		if root.Content[i].Value == "profiles" {
+			profiles = root.Content[i+1]
+			root.Content = slices.Delete(root.Content, i, i+2)
+			break
+		}
+	}
+	if profile == "" {
+		return nil
+	}
+
+	var names []string
+	if profiles != nil && profiles.Kind == yaml.MappingNode {
+		for i := 0; i < len(profiles.Content); i += 2 {
+			if profiles.Content[i].Value != profile {
+				names = append(names, profiles.Content[i].Value)
+				continue
+			}
+			overlay := profiles.Content[i+1]
+			if overlay.Kind != yaml.MappingNode {
+				return fmt.Errorf("profile '%s' must be a mapping of settings", profile)
+			}
+			if mappingValue(overlay, "profiles") != nil {
+				return fmt.Errorf("profile '%s' cannot define profiles", profile)
+			}
+			mergeMapping(root, overlay)
+			return nil
+		}
+	}
+	if len(names) == 0 {
+		return fmt.Errorf("unknown profile '%s', the config defines no profiles", profile)
+	}
+	return fmt.Errorf("unknown profile '%s', expected one of %s", profile, strings.Join(names, ", "))
+}
+
+// mergeMapping sets the keys of overlay in base, merging mappings found in both
+func mergeMapping(base, overlay *yaml.Node) {
+	for i := 0; i < len(overlay.Content); i += 2 {
+		key, value := overlay.Content[i], overlay.Content[i+1]
+		existing := mappingValue(base, key.Value)
+		switch {
+		case existing == nil:
+			base.Content = append(base.Content, key, value)
+		case existing.Kind == yaml.MappingNode && value.Kind == yaml.MappingNode:
+			mergeMapping(existing, value)
+		default:
+			*existing = *value
+		}
+	}
+}
+
+// mappingValue returns the value of a key in a mapping node, or nil if it is not set
+func mappingValue(mapping *yaml.Node, key string) *yaml.Node {
+	for i := 0; i < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return mapping.Content[i+1]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadConfigProfile(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "rules.yaml")
+	content := `miniflux_url: https://miniflux.example.com
+interval: 300
+webhooks:
+  chat:
+    url: https://hooks.example.com/prod
+    strip_html: true
+rules:
+  - name: Promos
+    title: Sponsored
+    action: remove
+profiles:
+  staging:
+    miniflux_url: https://staging.example.com
+    dry_run: true
+    webhooks:
+      chat:
+        url: https://hooks.example.com/staging
+`
+	if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	config, err := LoadConfig(configPath)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if config.MinifluxURL != "https://miniflux.example.com" || config.DryRun {
+		t.Errorf("Expected the profiles to be ignored without -profile, got %q and dry_run %v", config.MinifluxURL, config.DryRun)
+	}
+
+	config, err = LoadConfigProfile(configPath, "", "staging")
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if config.MinifluxURL != "https://staging.example.com" || !config.DryRun || config.Interval != 300 {
+		t.Errorf("Expected the staging settings over the others, got %q, dry_run %v and interval %d",
+			config.MinifluxURL, config.DryRun, config.Interval)
+	}
+	chat := config.Webhooks["chat"]
+	if chat.URL != "https://hooks.example.com/staging" || !chat.StripHTML {
+		t.Errorf("Expected the webhook to be merged key by key, got %+v", chat)
+	}
+	if len(config.Rules) != 1 || config.Profiles != nil {
+		t.Errorf("Expected the rules to be kept and the profiles dropped, got %d rules and %v", len(config.Rules), config.Profiles)
+	}
+
+	if _, err := LoadConfigProfile(configPath, "", "prod"); err == nil || !strings.Contains(err.Error(), "unknown profile 'prod', expected one of staging") {
+		t.Errorf("Expected an unknown profile to be rejected, got %v", err)
+	}
+}
+
+func TestLoadConfigProfileUnknownKey(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "rules.yaml")
+	content := "miniflux_url: https://miniflux.example.com\nprofiles:\n  staging:\n    dryrun: true\n"
+	if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	_, err := LoadConfig(configPath)
+	if err == nil || !strings.Contains(err.Error(), "line 4: unknown key 'dryrun' (did you mean 'dry_run'?)") {
+		t.Errorf("Expected the misspelt profile key to be reported, got %v", err)
+	}
+}
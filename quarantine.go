@@ -0,0 +1,141 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	miniflux "miniflux.app/v2/client"
+)
+
+// defaultPurgeAge is how long entries stay quarantined when purge is run without -older-than
+const defaultPurgeAge = "30d"
+
+// quarantines reports whether remove actions only mark entries read until they are purged
+func (p *Processor) quarantines() bool {
+	return p.options.Quarantine && p.state != nil
+}
+
+// purgeCommand runs the purge subcommand, removing entries quarantined longer than -older-than:
+// miniflux-jobs purge --older-than 14d
+func purgeCommand(args []string) {
+	flags := flag.NewFlagSet("purge", flag.ExitOnError)
+	configPath := flags.String("config", defaultConfigPath(), "Path to the rules configuration file")
+	olderThan := flags.String("older-than", defaultPurgeAge, "Remove entries quarantined this long ago, e.g. 14d or 72h")
+	dryRun := flags.Bool("dry-run", false, "Show what would be removed without changing anything")
+	flags.Parse(args)
+
+	logger := commandLogger(os.Stdout)
+
+	age, err := parseSince(*olderThan)
+	if err != nil {
+		fatal(logger, "Invalid -older-than", "error", err)
+	}
+
+	config, err := LoadConfig(*configPath)
+	if err != nil {
+		fatal(logger, "Failed to load config", "error", err)
+	}
+	if !config.HasState() {
+		fatal(logger, "Purge requires state_file or state_backend to be set")
+	}
+
+	apiKey, err := GetAPIKey()
+	if err != nil {
+		fatal(logger, "Failed to get API key", "error", err)
+	}
+	client := NewClientWrapper(config.MinifluxURL, apiKey, config.MaxResponseSize)
+
+	store, err := OpenStateStore(config)
+	if err != nil {
+		fatal(logger, "Failed to open state store", "error", err)
+	}
+	defer store.Close()
+	state, err := LoadStateFrom(store)
+	if err != nil {
+		fatal(logger, "Failed to load state", "error", err)
+	}
+
+	matcher, err := NewMatcher(nil)
+	if err != nil {
+		fatal(logger, "Failed to create matcher", "error", err)
+	}
+	processor := NewProcessor(client, matcher, logger, *dryRun)
+	processor.SetOptions(processorOptions(config))
+	processor.SetState(state)
+
+	stats, err := processor.Purge(time.Now().Add(-age))
+	if err != nil {
+		logger.Error("Purge error", "error", err)
+	}
+	logger.Info("Removed quarantined entries", "removed", stats.Removed, "errors", stats.Errors)
+}
+
+// Purge removes the entries quarantined before the cutoff
+// Entries the user marked unread or starred in the meantime are kept and released
+func (p *Processor) Purge(cutoff time.Time) (*ProcessStats, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	stats := &ProcessStats{ConfigHash: p.options.ConfigHash}
+	if p.state == nil {
+		return stats, fmt.Errorf("purge requires state")
+	}
+
+	ids := p.state.QuarantinedBefore(cutoff)
+	p.logger.Info("Purging quarantined entries", "entries", len(ids))
+
+	for _, id := range ids {
+		quarantined := p.state.Quarantined[id]
+		logger := p.logger.With("rule", quarantined.Rule, "entry_id", id)
+		entry, err := p.client.Entry(id)
+		if errors.Is(err, miniflux.ErrNotFound) {
+			logger.Info("Entry no longer exists")
+			p.release(id)
+			continue
+		}
+		if err != nil {
+			logger.Error("Failed to fetch entry", "error", err)
+			stats.Errors++
+			continue
+		}
+		stats.TotalEntries++
+
+		if entry.Status != miniflux.EntryStatusRead || entry.Starred {
+			logger.Info("Keeping entry restored after the rule quarantined it")
+			p.release(id)
+			continue
+		}
+
+		if p.dryRun {
+			logger.Info("Dry run: would remove quarantined entry", "action", "purge", "title", entry.Title)
+			p.audit(entry, "purge", quarantined.Rule)
+			stats.Removed++
+			continue
+		}
+		if err := p.client.UpdateEntries([]int64{id}, miniflux.EntryStatusRemoved); err != nil {
+			logger.Error("Failed to remove entry", "action", "purge", "error", err)
+			stats.Errors++
+			continue
+		}
+		entry.Status = miniflux.EntryStatusRemoved
+		p.state.Release(id)
+		p.audit(entry, "purge", quarantined.Rule)
+		stats.Removed++
+		logger.Info("Removed quarantined entry", "action", "purge", "title", entry.Title)
+	}
+
+	if err := p.saveState(); err != nil {
+		return stats, err
+	}
+	return stats, nil
+}
+
+// release forgets a quarantined entry outside of dry runs
+func (p *Processor) release(entryID int64) {
+	if !p.dryRun {
+		p.state.Release(entryID)
+	}
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	miniflux "miniflux.app/v2/client"
+)
+
+func TestProcessorQuarantineAndPurge(t *testing.T) {
+	mockClient := &MockClient{
+		entries: []*miniflux.Entry{
+			{ID: 1, Title: "Promo: 50% off", Status: miniflux.EntryStatusUnread},
+			{ID: 2, Title: "Promo: free shipping", Status: miniflux.EntryStatusUnread},
+		},
+	}
+
+	state, err := LoadState(filepath.Join(t.TempDir(), "state.json"))
+	if err != nil {
+		t.Fatalf("Failed to load state: %v", err)
+	}
+	matcher, err := NewMatcher([]Rule{
+		{Name: "Remove promos", Title: "Promo", Action: "remove", Status: StringList{"unread"}},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create matcher: %v", err)
+	}
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	processor := NewProcessor(mockClient, matcher, logger, false)
+	processor.SetState(state)
+	processor.SetOptions(ProcessorOptions{Quarantine: true})
+
+	stats, err := processor.Process()
+	if err != nil {
+		t.Fatalf("Process failed: %v", err)
+	}
+	if stats.Quarantined != 2 || stats.Removed != 0 || mockClient.updatedStatus != miniflux.EntryStatusRead {
+		t.Errorf("Expected both entries to be marked read instead of removed, got %d quarantined, %d removed, status %q",
+			stats.Quarantined, stats.Removed, mockClient.updatedStatus)
+	}
+	if len(state.Quarantined) != 2 || state.Quarantined[1].Rule != "Remove promos" {
+		t.Errorf("Expected both entries to be recorded as quarantined, got %+v", state.Quarantined)
+	}
+
+	// Nothing is purged within the recovery window
+	if stats, err := processor.Purge(time.Now().Add(-time.Hour)); err != nil || stats.Removed != 0 {
+		t.Errorf("Expected nothing to be purged yet, got %d removed (err %v)", stats.Removed, err)
+	}
+
+	// Entry 2 was restored by the user in the meantime and is kept
+	mockClient.entries[1].Status = miniflux.EntryStatusUnread
+	mockClient.updatedIDs = nil
+	stats, err = processor.Purge(time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatalf("Purge failed: %v", err)
+	}
+	if stats.Removed != 1 || len(mockClient.updatedIDs) != 1 || mockClient.updatedIDs[0] != 1 {
+		t.Errorf("Expected only entry 1 to be removed, got %d removed, updated %v", stats.Removed, mockClient.updatedIDs)
+	}
+	if mockClient.updatedStatus != miniflux.EntryStatusRemoved {
+		t.Errorf("Expected entry 1 to be removed, got status %q", mockClient.updatedStatus)
+	}
+	if len(state.Quarantined) != 0 {
+		t.Errorf("Expected the quarantine to be empty, got %+v", state.Quarantined)
+	}
+}
+
+func TestLoadConfigQuarantine(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "rules.yaml")
+	content := "miniflux_url: https://miniflux.example.com\nquarantine: true\n"
+	if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	_, err := LoadConfig(configPath)
+	if err == nil || !strings.Contains(err.Error(), "quarantine requires state_file or state_backend") {
+		t.Errorf("Expected quarantine without state to be rejected, got %v", err)
+	}
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	miniflux "miniflux.app/v2/client"
+)
+
+// defaultReadwiseURL is the Readwise Reader endpoint that saves documents
+const defaultReadwiseURL = "https://readwise.io/api/v3/save/"
+
+// ReadwiseConfig configures the Readwise Reader account used by readwise actions
+type ReadwiseConfig struct {
+	Token    string     `yaml:"token"`    // access token from readwise.io/access_token
+	Location string     `yaml:"location"` // "new", "later", "archive" or "feed" (default: Reader's default)
+	Tags     StringList `yaml:"tags"`     // tags added to every saved document
+	URL      string     `yaml:"url"`      // API endpoint override, for tests and proxies
+}
+
+// readwiseDocument is the JSON body of a save request
+type readwiseDocument struct {
+	URL      string   `json:"url"`
+	Title    string   `json:"title,omitempty"`
+	Author   string   `json:"author,omitempty"`
+	Location string   `json:"location,omitempty"`
+	Tags     []string `json:"tags,omitempty"`
+	Saved    string   `json:"saved_using"`
+}
+
+// validate checks the token and location
+func (r ReadwiseConfig) validate() error {
+	if r.Token == "" {
+		return fmt.Errorf("readwise requires token")
+	}
+	switch r.Location {
+	case "", "new", "later", "archive", "feed":
+	default:
+		return fmt.Errorf("readwise location must be 'new', 'later', 'archive' or 'feed', got '%s'", r.Location)
+	}
+	return nil
+}
+
+// saveToReadwise adds the entry to Readwise Reader
+func saveToReadwise(client *http.Client, cfg ReadwiseConfig, entry *miniflux.Entry) error {
+	endpoint := cfg.URL
+	if endpoint == "" {
+		endpoint = defaultReadwiseURL
+	}
+
+	data, err := json.Marshal(readwiseDocument{
+		URL:      entry.URL,
+		Title:    entry.Title,
+		Author:   entry.Author,
+		Location: cfg.Location,
+		Tags:     cfg.Tags,
+		Saved:    "miniflux-jobs",
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, endpoint, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Token "+cfg.Token)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("readwise request failed: %w", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("readwise request failed: status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// applyReadwise saves an entry to Readwise Reader, once per entry
+func (p *Processor) applyReadwise(entry *miniflux.Entry, rule *Rule, stats *ProcessStats) bool {
+	if entry.URL == "" {
+		p.entryLogger(entry, rule).Warn("Cannot save entry without a URL to Readwise", "action", "readwise")
+		stats.Errors++
+		return false
+	}
+
+	if !p.shouldNotify("readwise", entry, "") {
+		return true
+	}
+
+	if p.dryRun {
+		p.entryLogger(entry, rule).Info("Dry run: would save entry to Readwise", "action", "readwise")
+		p.audit(entry, "readwise", rule.Name)
+		return true
+	}
+
+	if err := saveToReadwise(p.httpClient, p.options.Readwise, entry); err != nil {
+		p.entryLogger(entry, rule).Error("Failed to save entry to Readwise", "action", "readwise", "error", err)
+		stats.Errors++
+		return false
+	}
+
+	stats.Saved++
+	p.entryLogger(entry, rule).Info("Saved entry to Readwise", "action", "readwise")
+	p.audit(entry, "readwise", rule.Name)
+	return true
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	miniflux "miniflux.app/v2/client"
+)
+
+func TestProcessorReadwiseAction(t *testing.T) {
+	var docs []readwiseDocument
+	var auth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		var doc readwiseDocument
+		json.NewDecoder(r.Body).Decode(&doc)
+		docs = append(docs, doc)
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	mockClient := &MockClient{
+		entries: []*miniflux.Entry{
+			{ID: 1, Title: "On reading", Author: "Alice", URL: "https://example.com/reading", Status: miniflux.EntryStatusUnread},
+		},
+	}
+
+	state, err := LoadState(filepath.Join(t.TempDir(), "state.json"))
+	if err != nil {
+		t.Fatalf("Failed to load state: %v", err)
+	}
+	matcher, err := NewMatcher([]Rule{
+		{Name: "Curated", Authors: []string{"Alice"}, Action: "readwise"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create matcher: %v", err)
+	}
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	processor := NewProcessor(mockClient, matcher, logger, false)
+	processor.SetState(state)
+	processor.SetOptions(ProcessorOptions{Readwise: ReadwiseConfig{
+		Token:    "abc",
+		Location: "later",
+		Tags:     StringList{"miniflux"},
+		URL:      server.URL,
+	}})
+
+	// The second run must not save the same entry again
+	for range 2 {
+		stats, err := processor.Process()
+		if err != nil {
+			t.Fatalf("Process failed: %v", err)
+		}
+		if stats.Errors != 0 {
+			t.Fatalf("Expected no errors, got %d", stats.Errors)
+		}
+	}
+
+	if len(docs) != 1 {
+		t.Fatalf("Expected one saved document, got %d", len(docs))
+	}
+	doc := docs[0]
+	if auth != "Token abc" || doc.URL != "https://example.com/reading" || doc.Author != "Alice" || doc.Location != "later" || len(doc.Tags) != 1 {
+		t.Errorf("Unexpected request: auth %q, document %+v", auth, doc)
+	}
+}
+
+func TestReadwiseConfigValidate(t *testing.T) {
+	if err := (ReadwiseConfig{Token: "abc", Location: "later"}).validate(); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if err := (ReadwiseConfig{Location: "later"}).validate(); err == nil {
+		t.Error("Expected an error without token")
+	}
+	if err := (ReadwiseConfig{Token: "abc", Location: "inbox"}).validate(); err == nil {
+		t.Error("Expected an error for an unknown location")
+	}
+}
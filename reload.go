@@ -0,0 +1,170 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// configReloadDelay lets a burst of file events from one save settle before reloading
+const configReloadDelay = 500 * time.Millisecond
+
+// configReloader recompiles the rules when the config file changes or on SIGHUP
+// An invalid config is rejected and the previous rules stay active; settings read at
+// startup, such as interval, listen, dry_run, the state store and the instances, still need a restart
+type configReloader struct {
+	path      string
+	format    string
+	profile   string
+	onlyRules []string
+	skipRules []string
+
+	feedScope     *feedSelector // -feed, kept across reloads
+	categoryScope *feedSelector // -category, kept across reloads
+	expectHash    string
+
+	instances []*instance
+	logger    *slog.Logger
+
+	mu   sync.Mutex // serializes reloads on signals, file changes and admin requests
+	hash string     // hash of the active config
+}
+
+// activeHash returns the hash of the active config
+func (r *configReloader) activeHash() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.hash
+}
+
+// reload loads the config and swaps in its rules, reporting whether it changed
+func (r *configReloader) reload() (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	config, err := LoadConfigProfile(r.path, r.format, r.profile)
+	if err != nil {
+		return false, err
+	}
+	if config.Hash == r.hash {
+		return false, nil
+	}
+	if r.expectHash != "" && !strings.HasPrefix(config.Hash, strings.ToLower(r.expectHash)) {
+		return false, fmt.Errorf("config hash %s does not match expected %s", config.Hash, r.expectHash)
+	}
+
+	if len(r.onlyRules) > 0 || len(r.skipRules) > 0 {
+		config.SelectRules(r.onlyRules, r.skipRules)
+	}
+	config.FeedScope, config.CategoryScope = r.feedScope, r.categoryScope
+
+	// Every instance's rules are compiled before any is swapped in, so an error changes nothing
+	type update struct {
+		processor *Processor
+		matcher   *Matcher
+		options   ProcessorOptions
+	}
+	var updates []update
+	for _, inst := range r.instances {
+		derived := config
+		if inst.name != "" {
+			i := slices.IndexFunc(config.Instances, func(spec InstanceConfig) bool { return spec.Name == inst.name })
+			if i < 0 {
+				r.logger.Warn("Instance is no longer configured, keeping its rules until a restart", "instance", inst.name)
+				continue
+			}
+			derived = config.ForInstance(config.Instances[i])
+		}
+		matcher, err := buildMatcher(derived, inst.processor.client, inst.logger)
+		if err != nil {
+			return false, fmt.Errorf("failed to compile rules: %w", err)
+		}
+		updates = append(updates, update{inst.processor, matcher, processorOptions(derived)})
+	}
+
+	for _, u := range updates {
+		u.processor.Reload(u.matcher, u.options)
+	}
+	r.hash = config.Hash
+	r.logger.Info("Reloaded rules", "rules", len(config.Rules), "config_hash", config.Hash)
+	return true, nil
+}
+
+// run reloads the config on each signal or file change until the program exits
+func (r *configReloader) run(signals <-chan os.Signal, changes <-chan struct{}) {
+	for {
+		select {
+		case sig := <-signals:
+			r.logger.Info("Received signal, reloading config", "signal", sig.String())
+			changed, err := r.reload()
+			if err != nil {
+				r.logger.Error("Failed to reload config, keeping the previous rules", "error", err)
+			} else if !changed {
+				r.logger.Info("Config unchanged")
+			}
+		case <-changes:
+			if _, err := r.reload(); err != nil {
+				r.logger.Error("Failed to reload config, keeping the previous rules", "error", err)
+			}
+		}
+	}
+}
+
+// watchConfig signals changes to the config file and the rule files it includes
+// Their directories are watched since editors often replace a file rather than write to it;
+// files added to rules_dir later are picked up on SIGHUP
+func watchConfig(paths []string, logger *slog.Logger) (<-chan struct{}, error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	targets := make(map[string]bool, len(paths))
+	for _, path := range paths {
+		targets[filepath.Clean(path)] = true
+		if err := watcher.Add(filepath.Dir(path)); err != nil {
+			watcher.Close()
+			return nil, err
+		}
+	}
+
+	changes := make(chan struct{}, 1)
+	notify := func() {
+		select {
+		case changes <- struct{}{}:
+		default:
+		}
+	}
+
+	go func() {
+		var timer *time.Timer
+		for {
+			select {
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if !targets[filepath.Clean(event.Name)] || !event.Op.Has(fsnotify.Write) && !event.Op.Has(fsnotify.Create) {
+					continue
+				}
+				if timer == nil {
+					timer = time.AfterFunc(configReloadDelay, notify)
+				} else {
+					timer.Reset(configReloadDelay)
+				}
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				logger.Warn("Config watcher error", "error", err)
+			}
+		}
+	}()
+	return changes, nil
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestConfigReloaderKeepsRulesOnError(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "rules.yaml")
+	writeConfig := func(rules string) {
+		content := "miniflux_url: https://miniflux.example.com\nrules:\n" + rules
+		if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write config: %v", err)
+		}
+	}
+	writeConfig("  - name: Promos\n    title: Promo\n    action: read\n")
+
+	config, err := LoadConfig(configPath)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	mockClient := &MockClient{}
+	matcher, err := buildMatcher(config, mockClient, logger)
+	if err != nil {
+		t.Fatalf("Failed to create matcher: %v", err)
+	}
+	processor := NewProcessor(mockClient, matcher, logger, true)
+	reloader := &configReloader{
+		path:      configPath,
+		instances: []*instance{{processor: processor, logger: logger}},
+		logger:    logger,
+		hash:      config.Hash,
+	}
+
+	if changed, err := reloader.reload(); changed || err != nil {
+		t.Errorf("Expected an unchanged config to be left alone, got %v (err %v)", changed, err)
+	}
+
+	// An invalid pattern is rejected and the previous rules stay active
+	writeConfig("  - name: Broken\n    title: \"(\"\n    action: read\n")
+	if _, err := reloader.reload(); err == nil {
+		t.Error("Expected the invalid config to be rejected")
+	}
+	if rules := processor.matcher.Rules(); len(rules) != 1 || rules[0].Name != "Promos" {
+		t.Errorf("Expected the previous rules to stay active, got %+v", rules)
+	}
+
+	writeConfig("  - name: Promos\n    title: Promo\n    action: read\n  - name: Ads\n    title: Ad\n    action: remove\n")
+	if changed, err := reloader.reload(); !changed || err != nil {
+		t.Fatalf("Expected the new config to be loaded, got %v (err %v)", changed, err)
+	}
+	if rules := processor.matcher.Rules(); len(rules) != 2 {
+		t.Errorf("Expected 2 rules after the reload, got %d", len(rules))
+	}
+}
+
+func TestWatchConfig(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "rules.yaml")
+	if err := os.WriteFile(configPath, []byte("rules: []\n"), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	changes, err := watchConfig([]string{configPath}, slog.New(slog.NewTextHandler(os.Stdout, nil)))
+	if err != nil {
+		t.Fatalf("Failed to watch config: %v", err)
+	}
+
+	// Other files in the directory are ignored
+	if err := os.WriteFile(filepath.Join(filepath.Dir(configPath), "state.json"), []byte("{}"), 0644); err != nil {
+		t.Fatalf("Failed to write state: %v", err)
+	}
+	select {
+	case <-changes:
+		t.Fatal("Expected changes to other files to be ignored")
+	case <-time.After(2 * configReloadDelay):
+	}
+
+	if err := os.WriteFile(configPath, []byte("rules: []\ninterval: 60\n"), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	select {
+	case <-changes:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected the config change to be signalled")
+	}
+}
@@ -0,0 +1,186 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// maxRemoteConfigSize bounds the size of a config downloaded over HTTP(S)
+const maxRemoteConfigSize = 10 << 20
+
+// remoteConfigPollInterval is how often a remote config is checked for changes
+const remoteConfigPollInterval = time.Minute
+
+// remoteConfigClient fetches remote configs
+var remoteConfigClient = &http.Client{Timeout: 30 * time.Second}
+
+// isRemoteConfig reports whether the config path is an http(s) URL
+func isRemoteConfig(path string) bool {
+	return strings.HasPrefix(path, "http://") || strings.HasPrefix(path, "https://")
+}
+
+// configFileName returns the file name part of a config path or URL, which carries its extension
+func configFileName(path string) string {
+	if !isRemoteConfig(path) {
+		return path
+	}
+	u, err := url.Parse(path)
+	if err != nil {
+		return path
+	}
+	return u.Path
+}
+
+// readConfig reads a local config file or downloads a remote one
+func readConfig(path string) ([]byte, error) {
+	if isRemoteConfig(path) {
+		return fetchRemoteConfig(path)
+	}
+	return os.ReadFile(path)
+}
+
+// remoteConfigCache is the last downloaded copy of a remote config, kept to revalidate it
+// with If-None-Match and If-Modified-Since instead of downloading it again
+type remoteConfigCache struct {
+	URL          string `json:"url"`
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"last_modified,omitempty"`
+	Body         []byte `json:"body"`
+}
+
+// fetchRemoteConfig downloads a config over HTTP(S), reusing the cached copy while it is unchanged
+func fetchRemoteConfig(configURL string) ([]byte, error) {
+	req, err := http.NewRequest(http.MethodGet, configURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	auth, err := remoteConfigAuth()
+	if err != nil {
+		return nil, err
+	}
+	if auth != "" {
+		req.Header.Set("Authorization", auth)
+	}
+
+	cachePath := remoteConfigCachePath(configURL)
+	cached := loadRemoteConfigCache(cachePath, configURL)
+	if cached != nil {
+		if cached.ETag != "" {
+			req.Header.Set("If-None-Match", cached.ETag)
+		}
+		if cached.LastModified != "" {
+			req.Header.Set("If-Modified-Since", cached.LastModified)
+		}
+	}
+
+	resp, err := remoteConfigClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified && cached != nil {
+		return cached.Body, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxRemoteConfigSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(body) > maxRemoteConfigSize {
+		return nil, fmt.Errorf("config exceeds %d bytes", maxRemoteConfigSize)
+	}
+
+	// The cache only saves downloads, so failing to write it is not an error
+	if cachePath != "" {
+		saveRemoteConfigCache(cachePath, &remoteConfigCache{
+			URL:          configURL,
+			ETag:         resp.Header.Get("ETag"),
+			LastModified: resp.Header.Get("Last-Modified"),
+			Body:         body,
+		})
+	}
+	return body, nil
+}
+
+// remoteConfigAuth returns the Authorization header sent with remote config requests, e.g. "Bearer <token>"
+func remoteConfigAuth() (string, error) {
+	if auth := os.Getenv("MINIFLUX_RULES_AUTH"); auth != "" {
+		return auth, nil
+	}
+	if authFile := os.Getenv("MINIFLUX_RULES_AUTH_FILE"); authFile != "" {
+		data, err := os.ReadFile(authFile)
+		if err != nil {
+			return "", fmt.Errorf("failed to read rules auth file: %w", err)
+		}
+		return strings.TrimSpace(string(data)), nil
+	}
+	return "", nil
+}
+
+// remoteConfigCachePath returns the cache file of a remote config, or "" without a cache directory
+func remoteConfigCachePath(configURL string) string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(configURL))
+	return filepath.Join(dir, "miniflux-jobs", "config-"+hex.EncodeToString(sum[:8])+".json")
+}
+
+// loadRemoteConfigCache reads the cached copy of a remote config, if any
+func loadRemoteConfigCache(path, configURL string) *remoteConfigCache {
+	if path == "" {
+		return nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	var cached remoteConfigCache
+	if err := json.Unmarshal(data, &cached); err != nil || cached.URL != configURL {
+		return nil
+	}
+	return &cached
+}
+
+// saveRemoteConfigCache writes the cached copy of a remote config, readable only by the owner
+// since the config may hold secrets
+func saveRemoteConfigCache(path string, cached *remoteConfigCache) {
+	data, err := json.Marshal(cached)
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return
+	}
+	os.WriteFile(path, data, 0600)
+}
+
+// pollRemoteConfig signals periodically that a remote config should be checked for changes
+func pollRemoteConfig() <-chan struct{} {
+	changes := make(chan struct{}, 1)
+	go func() {
+		ticker := time.NewTicker(remoteConfigPollInterval)
+		defer ticker.Stop()
+		for range ticker.C {
+			select {
+			case changes <- struct{}{}:
+			default:
+			}
+		}
+	}()
+	return changes
+}
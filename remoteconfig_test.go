@@ -0,0 +1,48 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestLoadRemoteConfig(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	t.Setenv("MINIFLUX_RULES_AUTH", "Bearer fleet-token")
+
+	var downloads, revalidations int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer fleet-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			revalidations++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		downloads++
+		w.Header().Set("ETag", `"v1"`)
+		w.Write([]byte(`{"miniflux_url": "https://miniflux.example.com", "rules": [{"name": "Promos", "title": "Promo", "action": "read"}]}`))
+	}))
+	defer server.Close()
+
+	for range 2 {
+		config, err := LoadConfig(server.URL + "/rules.json?fleet=a")
+		if err != nil {
+			t.Fatalf("Failed to load remote config: %v", err)
+		}
+		if len(config.Rules) != 1 || config.Rules[0].Name != "Promos" {
+			t.Errorf("Unexpected rules %+v", config.Rules)
+		}
+	}
+	if downloads != 1 || revalidations != 1 {
+		t.Errorf("Expected 1 download and 1 revalidation, got %d and %d", downloads, revalidations)
+	}
+
+	t.Setenv("MINIFLUX_RULES_AUTH", "")
+	if _, err := LoadConfig(server.URL + "/rules.json"); err == nil || !strings.Contains(err.Error(), "401") {
+		t.Errorf("Expected an unauthorized request to fail, got %v", err)
+	}
+}
@@ -0,0 +1,196 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"time"
+
+	miniflux "miniflux.app/v2/client"
+)
+
+// Default read report settings when not configured
+const (
+	defaultReadReportInterval    = 30 * 24 * time.Hour
+	defaultReadReportMinEntries  = 10
+	defaultReadReportUnsubscribe = 0.1
+	defaultReadReportDowngrade   = 0.3
+)
+
+// FeedReadStats summarizes how many of a feed's entries the user actually read
+// Entries handled by a rule are excluded since they never reached the user
+type FeedReadStats struct {
+	FeedID     int64
+	FeedTitle  string
+	Read       int
+	Total      int
+	Suggestion string // "unsubscribe", "downgrade" or empty
+}
+
+// Ratio returns the share of the feed's entries that were read
+func (s FeedReadStats) Ratio() float64 {
+	if s.Total == 0 {
+		return 0
+	}
+	return float64(s.Read) / float64(s.Total)
+}
+
+// interval returns the report interval, applying the default
+func (c ReadReportConfig) interval() time.Duration {
+	if c.Interval == 0 {
+		return defaultReadReportInterval
+	}
+	return c.Interval
+}
+
+// minEntries returns the minimum entries to rate a feed, applying the default
+func (c ReadReportConfig) minEntries() int {
+	if c.MinEntries == 0 {
+		return defaultReadReportMinEntries
+	}
+	return c.MinEntries
+}
+
+// suggestion returns the advice for a feed with the given read ratio
+func (c ReadReportConfig) suggestion(ratio float64) string {
+	unsubscribe := c.UnsubscribeBelow
+	if unsubscribe == 0 {
+		unsubscribe = defaultReadReportUnsubscribe
+	}
+	downgrade := c.DowngradeBelow
+	if downgrade == 0 {
+		downgrade = defaultReadReportDowngrade
+	}
+
+	switch {
+	case ratio < unsubscribe:
+		return "unsubscribe"
+	case ratio < downgrade:
+		return "downgrade"
+	default:
+		return ""
+	}
+}
+
+// generateReadReport emits the read report once per configured interval
+func (p *Processor) generateReadReport(stats *ProcessStats) {
+	report := p.options.ReadReport
+	if p.state == nil || !report.Enabled {
+		return
+	}
+
+	now := p.now()
+	if !p.state.LastReadReport.IsZero() && now.Sub(p.state.LastReadReport) < report.interval() {
+		return
+	}
+
+	feeds, err := p.readStats(now.Add(-report.interval()))
+	if err != nil {
+		p.logger.Error("Failed to build read report", "error", err)
+		return
+	}
+
+	p.logger.Info("Read report", "interval", report.interval())
+	for _, feed := range feeds {
+		p.logFeedReadStats(feed)
+	}
+
+	stats.ReadReport = feeds
+	if !p.dryRun {
+		p.state.LastReadReport = now
+	}
+}
+
+// readStats computes per-feed read ratios for entries published since the cutoff
+// Feeds are sorted from least to most read
+func (p *Processor) readStats(cutoff time.Time) ([]FeedReadStats, error) {
+	report := p.options.ReadReport
+	byFeed := make(map[int64]*FeedReadStats)
+
+	filter := &miniflux.Filter{
+		Limit:          100,
+		Statuses:       []string{miniflux.EntryStatusUnread, miniflux.EntryStatusRead},
+		PublishedAfter: cutoff.Unix(),
+	}
+
+	offset := 0
+	for {
+		filter.Offset = offset
+		result, err := p.client.Entries(filter)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch entries: %w", err)
+		}
+		if len(result.Entries) == 0 {
+			break
+		}
+
+		for _, entry := range result.Entries {
+			feedID := entryFeedID(entry)
+			if feedID == 0 || p.handledByRule(feedID, entry.ID) {
+				continue
+			}
+
+			feed, ok := byFeed[feedID]
+			if !ok {
+				feed = &FeedReadStats{FeedID: feedID}
+				byFeed[feedID] = feed
+			}
+			if entry.Feed != nil && entry.Feed.Title != "" {
+				feed.FeedTitle = entry.Feed.Title
+			}
+
+			switch entry.Status {
+			case miniflux.EntryStatusRead:
+				feed.Read++
+				feed.Total++
+			case miniflux.EntryStatusUnread:
+				feed.Total++
+			}
+		}
+
+		offset += len(result.Entries)
+		if offset >= result.Total {
+			break
+		}
+	}
+
+	feeds := make([]FeedReadStats, 0, len(byFeed))
+	for _, feed := range byFeed {
+		if feed.Total < report.minEntries() {
+			continue
+		}
+		feed.Suggestion = report.suggestion(feed.Ratio())
+		feeds = append(feeds, *feed)
+	}
+
+	slices.SortFunc(feeds, func(a, b FeedReadStats) int {
+		return cmp.Or(cmp.Compare(a.Ratio(), b.Ratio()), cmp.Compare(a.FeedID, b.FeedID))
+	})
+
+	return feeds, nil
+}
+
+// handledByRule reports whether a rule acted on the entry when it was first processed
+func (p *Processor) handledByRule(feedID, entryID int64) bool {
+	activity, ok := p.state.FeedActivity[feedID][entryID]
+	return ok && activity.Rule != ""
+}
+
+// logFeedReadStats reports a single feed's line of the read report
+func (p *Processor) logFeedReadStats(feed FeedReadStats) {
+	logger := p.logger.With(
+		"feed_id", feed.FeedID,
+		"feed", feed.FeedTitle,
+		"read", feed.Read,
+		"total", feed.Total,
+		"ratio", feed.Ratio(),
+	)
+	switch feed.Suggestion {
+	case "unsubscribe":
+		logger.Info("Feed read stats, consider unsubscribing from the feed", "suggestion", feed.Suggestion)
+	case "downgrade":
+		logger.Info("Feed read stats, consider moving the feed to a lower-priority category", "suggestion", feed.Suggestion)
+	default:
+		logger.Info("Feed read stats")
+	}
+}
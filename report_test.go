@@ -0,0 +1,79 @@
+package main
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	miniflux "miniflux.app/v2/client"
+)
+
+func TestProcessorReadReport(t *testing.T) {
+	var entries []*miniflux.Entry
+	addEntries := func(feedID int64, title string, read, unread int) {
+		for i := 0; i < read+unread; i++ {
+			status := miniflux.EntryStatusUnread
+			if i < read {
+				status = miniflux.EntryStatusRead
+			}
+			entries = append(entries, &miniflux.Entry{
+				ID:     int64(len(entries) + 1),
+				Title:  title,
+				Status: status,
+				FeedID: feedID,
+				Feed:   &miniflux.Feed{ID: feedID, Title: "Feed"},
+			})
+		}
+	}
+	addEntries(1, "News", 1, 9)  // 10% read
+	addEntries(2, "News", 4, 6)  // 40% read
+	addEntries(3, "Spam", 0, 10) // handled by a rule, not rated
+	addEntries(4, "News", 0, 2)  // too few entries
+
+	mockClient := &MockClient{entries: entries}
+
+	matcher, err := NewMatcher([]Rule{
+		{Name: "Spam", Title: "Spam", Action: "read"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create matcher: %v", err)
+	}
+
+	state, err := LoadState(filepath.Join(t.TempDir(), "state.json"))
+	if err != nil {
+		t.Fatalf("Failed to load state: %v", err)
+	}
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	processor := NewProcessor(mockClient, matcher, logger, false)
+	processor.SetState(state)
+	processor.SetOptions(ProcessorOptions{
+		ReadReport: ReadReportConfig{Enabled: true, Interval: 24 * time.Hour, MinEntries: 5},
+	})
+
+	stats, err := processor.Process()
+	if err != nil {
+		t.Fatalf("Process failed: %v", err)
+	}
+
+	if len(stats.ReadReport) != 2 {
+		t.Fatalf("Expected 2 rated feeds, got %+v", stats.ReadReport)
+	}
+	if feed := stats.ReadReport[0]; feed.FeedID != 1 || feed.Read != 1 || feed.Total != 10 || feed.Suggestion != "downgrade" {
+		t.Errorf("Unexpected stats for feed 1: %+v", feed)
+	}
+	if feed := stats.ReadReport[1]; feed.FeedID != 2 || feed.Suggestion != "" {
+		t.Errorf("Unexpected stats for feed 2: %+v", feed)
+	}
+
+	// The report is not repeated within the interval
+	stats, err = processor.Process()
+	if err != nil {
+		t.Fatalf("Process failed: %v", err)
+	}
+	if stats.ReadReport != nil {
+		t.Errorf("Expected no repeated report, got %+v", stats.ReadReport)
+	}
+}
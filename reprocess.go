@@ -0,0 +1,190 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	miniflux "miniflux.app/v2/client"
+)
+
+// reprocessCommand runs the reprocess subcommand:
+// miniflux-jobs reprocess --rule "Remove promos" --since 7d
+func reprocessCommand(args []string) {
+	flags := flag.NewFlagSet("reprocess", flag.ExitOnError)
+	configPath := flags.String("config", defaultConfigPath(), "Path to the rules configuration file")
+	ruleName := flags.String("rule", "", "Only reprocess entries acted on by this rule (default: all rules)")
+	sinceValue := flags.String("since", "7d", "How far back to reprocess, e.g. 7d or 12h")
+	dryRun := flags.Bool("dry-run", false, "Show corrections without applying them")
+	flags.Parse(args)
+
+	logger := commandLogger(os.Stdout)
+
+	since, err := parseSince(*sinceValue)
+	if err != nil {
+		fatal(logger, "Invalid -since", "error", err)
+	}
+
+	config, err := LoadConfig(*configPath)
+	if err != nil {
+		fatal(logger, "Failed to load config", "error", err)
+	}
+	if !config.HasState() {
+		fatal(logger, "Reprocessing requires state_file or state_backend to be set")
+	}
+
+	apiKey, err := GetAPIKey()
+	if err != nil {
+		fatal(logger, "Failed to get API key", "error", err)
+	}
+	client := NewClientWrapper(config.MinifluxURL, apiKey, config.MaxResponseSize)
+
+	matcher, err := buildMatcher(config, client, logger)
+	if err != nil {
+		fatal(logger, "Failed to compile rules", "error", err)
+	}
+
+	store, err := OpenStateStore(config)
+	if err != nil {
+		fatal(logger, "Failed to open state store", "error", err)
+	}
+	defer store.Close()
+	state, err := LoadStateFrom(store)
+	if err != nil {
+		fatal(logger, "Failed to load state", "error", err)
+	}
+
+	processor := NewProcessor(client, matcher, logger, *dryRun)
+	processor.SetOptions(processorOptions(config))
+	processor.SetState(state)
+
+	stats, err := processor.Reprocess(*ruleName, time.Now().Add(-since))
+	if err != nil {
+		logger.Error("Reprocessing error", "error", err)
+	}
+	logStats(logger, stats)
+}
+
+// parseSince parses a lookback such as 7d, or any Go duration such as 12h
+func parseSince(value string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("invalid number of days: %s", value)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	return time.ParseDuration(value)
+}
+
+// Reprocess re-evaluates entries that rule (any rule if empty) acted on since the given time
+// Their recorded actions are reverted and the current rules applied instead,
+// so fixing a rule and reprocessing restores entries it wrongly removed
+func (p *Processor) Reprocess(rule string, since time.Time) (*ProcessStats, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	stats := &ProcessStats{ConfigHash: p.options.ConfigHash}
+	if p.state == nil {
+		return stats, fmt.Errorf("reprocessing requires state")
+	}
+
+	p.disableConsumedRules()
+	p.runStarted = p.now()
+
+	var entryIDs []int64
+	for _, action := range p.state.ActionsSince(rule, since) {
+		if !slices.Contains(entryIDs, action.EntryID) {
+			entryIDs = append(entryIDs, action.EntryID)
+		}
+	}
+	p.logger.Info("Reprocessing entries", "entries", len(entryIDs))
+
+	for _, entryID := range entryIDs {
+		entry, err := p.client.Entry(entryID)
+		if err != nil {
+			p.logger.Error("Failed to fetch entry, it cannot be restored", "entry_id", entryID, "error", err)
+			stats.Errors++
+			continue
+		}
+		stats.TotalEntries++
+
+		// Every rule is matched again, so every rule's actions are reverted
+		if !p.revertActions(entry, "", since, stats) {
+			continue
+		}
+		if !p.dryRun {
+			p.state.ForgetActions(entry.ID, "", since)
+		}
+		p.enrichEntries([]*miniflux.Entry{entry})
+		p.processEntry(entry, p.matcher.MatchAll(entry), stats)
+	}
+
+	if err := p.saveState(); err != nil {
+		return stats, err
+	}
+	return stats, nil
+}
+
+// revertActions undoes the entry's recorded actions by rule (any rule if empty) since the
+// given time, newest first
+// It returns false if an action could not be reverted
+func (p *Processor) revertActions(entry *miniflux.Entry, rule string, since time.Time, stats *ProcessStats) bool {
+	actions := slices.DeleteFunc(p.state.ActionsSince(rule, since), func(action AppliedAction) bool {
+		return action.EntryID != entry.ID
+	})
+
+	for _, action := range slices.Backward(actions) {
+		logger := p.logger.With("rule", action.Rule, "entry_id", entry.ID, "action", "revert_"+action.Action)
+		var err error
+		switch action.Action {
+		case "read", "unread", "remove":
+			if action.PreviousStatus == "" || entry.Status == action.PreviousStatus {
+				continue
+			}
+			logger.Info("Restoring entry status", "status", action.PreviousStatus)
+			if !p.dryRun {
+				err = p.client.UpdateEntries([]int64{entry.ID}, action.PreviousStatus)
+			}
+			if err == nil {
+				entry.Status = action.PreviousStatus
+				if !p.dryRun {
+					p.state.Release(entry.ID)
+				}
+			}
+		case "star", "unstar":
+			if entry.Starred != (action.Action == "star") {
+				continue
+			}
+			logger.Info("Reverting action")
+			if !p.dryRun {
+				err = p.client.ToggleStarred(entry.ID)
+			}
+			if err == nil {
+				entry.Starred = !entry.Starred
+			}
+		case "rewrite_title":
+			if action.PreviousTitle == "" || entry.Title == action.PreviousTitle {
+				continue
+			}
+			logger.Info("Restoring title", "title", action.PreviousTitle)
+			if !p.dryRun {
+				err = p.client.UpdateEntryTitle(entry.ID, action.PreviousTitle)
+			}
+			if err == nil {
+				entry.Title = action.PreviousTitle
+			}
+		}
+		if err != nil {
+			logger.Error("Failed to revert entry", "error", err)
+			stats.Errors++
+			return false
+		}
+		p.audit(entry, "revert_"+action.Action, action.Rule)
+	}
+	return true
+}
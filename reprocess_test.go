@@ -0,0 +1,91 @@
+package main
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	miniflux "miniflux.app/v2/client"
+)
+
+func TestProcessorReprocessRestoresRemovedEntries(t *testing.T) {
+	mockClient := &MockClient{
+		entries: []*miniflux.Entry{
+			{ID: 1, Title: "Promo: new release notes", Status: miniflux.EntryStatusUnread},
+			{ID: 2, Title: "Promo: 50% off", Status: miniflux.EntryStatusUnread},
+		},
+	}
+
+	state, err := LoadState(filepath.Join(t.TempDir(), "state.json"))
+	if err != nil {
+		t.Fatalf("Failed to load state: %v", err)
+	}
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+
+	// The original rule is too broad and removes both entries
+	matcher, err := NewMatcher([]Rule{
+		{Name: "Remove promos", Title: "Promo", Action: "remove"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create matcher: %v", err)
+	}
+	processor := NewProcessor(mockClient, matcher, logger, false)
+	processor.SetState(state)
+	if _, err := processor.Process(); err != nil {
+		t.Fatalf("Process failed: %v", err)
+	}
+	if len(state.Actions) != 2 {
+		t.Fatalf("Expected 2 recorded actions, got %d", len(state.Actions))
+	}
+
+	// The fixed rule only removes discount offers
+	matcher, err = NewMatcher([]Rule{
+		{Name: "Remove promos", Title: `\d+% off`, Action: "remove"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create matcher: %v", err)
+	}
+	processor = NewProcessor(mockClient, matcher, logger, false)
+	processor.SetState(state)
+
+	stats, err := processor.Reprocess("Remove promos", time.Now().Add(-time.Hour))
+	if err != nil {
+		t.Fatalf("Reprocess failed: %v", err)
+	}
+
+	if stats.TotalEntries != 2 || stats.Removed != 1 {
+		t.Errorf("Expected 2 entries reprocessed and 1 removed again, got %+v", stats)
+	}
+	if status := mockClient.entries[0].Status; status != miniflux.EntryStatusUnread {
+		t.Errorf("Expected entry 1 to be restored to unread, got %s", status)
+	}
+	if status := mockClient.entries[1].Status; status != miniflux.EntryStatusRemoved {
+		t.Errorf("Expected entry 2 to stay removed, got %s", status)
+	}
+	if len(state.Actions) != 1 || state.Actions[0].EntryID != 2 {
+		t.Errorf("Expected only the re-applied action to remain, got %+v", state.Actions)
+	}
+}
+
+func TestParseSince(t *testing.T) {
+	tests := map[string]time.Duration{
+		"7d":  7 * 24 * time.Hour,
+		"12h": 12 * time.Hour,
+		"0d":  0,
+	}
+	for value, expected := range tests {
+		got, err := parseSince(value)
+		if err != nil {
+			t.Errorf("parseSince(%q) failed: %v", value, err)
+			continue
+		}
+		if got != expected {
+			t.Errorf("parseSince(%q) = %s, expected %s", value, got, expected)
+		}
+	}
+	if _, err := parseSince("xd"); err == nil {
+		t.Error("Expected error for invalid days")
+	}
+}
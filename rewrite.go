@@ -0,0 +1,72 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	miniflux "miniflux.app/v2/client"
+)
+
+// TitleRewrite configures the rewrite_title action
+type TitleRewrite struct {
+	Find    string `yaml:"find"`    // regex matched against the entry title
+	Replace string `yaml:"replace"` // replacement, may reference groups as $1 or ${name}
+}
+
+// validate checks the find pattern
+func (t *TitleRewrite) validate() error {
+	if t.Find == "" {
+		return fmt.Errorf("rewrite_title find is required")
+	}
+	if _, err := regexp.Compile(t.Find); err != nil {
+		return fmt.Errorf("invalid rewrite_title find pattern: %w", err)
+	}
+	return nil
+}
+
+// rewrite returns the title with every match of find replaced and surrounding space trimmed
+func (t *TitleRewrite) rewrite(title string) (string, error) {
+	re, err := regexp.Compile(t.Find)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(re.ReplaceAllString(title, t.Replace)), nil
+}
+
+// applyRewriteTitle updates the entry title in Miniflux using the rule's find/replace
+// Entries whose title is unchanged or would become empty are left alone
+func (p *Processor) applyRewriteTitle(entry *miniflux.Entry, rule *Rule, stats *ProcessStats) bool {
+	if rule.RewriteTitle == nil {
+		p.entryLogger(entry, rule).Warn("Rule has no rewrite_title settings", "action", "rewrite_title")
+		stats.Errors++
+		return false
+	}
+	title, err := rule.RewriteTitle.rewrite(entry.Title)
+	if err != nil {
+		p.entryLogger(entry, rule).Error("Failed to rewrite title", "action", "rewrite_title", "error", err)
+		stats.Errors++
+		return false
+	}
+	if title == entry.Title || title == "" {
+		return true
+	}
+
+	if p.dryRun {
+		p.entryLogger(entry, rule).Info("Dry run: would rewrite title", "action", "rewrite_title", "title", entry.Title, "new_title", title)
+		p.audit(entry, "rewrite_title", rule.Name)
+		return true
+	}
+	if err := p.client.UpdateEntryTitle(entry.ID, title); err != nil {
+		p.entryLogger(entry, rule).Error("Failed to update entry", "action", "rewrite_title", "error", err)
+		stats.Errors++
+		return false
+	}
+
+	p.entryLogger(entry, rule).Info("Rewrote title", "action", "rewrite_title", "title", entry.Title, "new_title", title)
+	p.recordAction(entry, "rewrite_title", rule)
+	p.audit(entry, "rewrite_title", rule.Name)
+	entry.Title = title
+	stats.Retitled++
+	return true
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	miniflux "miniflux.app/v2/client"
+)
+
+func TestProcessorRewriteTitle(t *testing.T) {
+	mockClient := &MockClient{
+		entries: []*miniflux.Entry{
+			{ID: 1, Title: "[Sponsored] A new laptop | Example News", Status: miniflux.EntryStatusUnread},
+			{ID: 2, Title: "A clean title", Status: miniflux.EntryStatusUnread},
+		},
+	}
+
+	matcher, err := NewMatcher([]Rule{
+		{
+			Name:         "Strip noise",
+			Title:        ".",
+			Action:       "rewrite_title",
+			RewriteTitle: &TitleRewrite{Find: `^\[Sponsored\]|\| Example News$`},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create matcher: %v", err)
+	}
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	processor := NewProcessor(mockClient, matcher, logger, false)
+	stats, err := processor.Process()
+	if err != nil {
+		t.Fatalf("Process failed: %v", err)
+	}
+
+	if got := mockClient.titles[1]; got != "A new laptop" {
+		t.Errorf("Expected entry 1 to be retitled 'A new laptop', got %q", got)
+	}
+	if _, ok := mockClient.titles[2]; ok {
+		t.Error("Expected the unchanged title of entry 2 not to be updated")
+	}
+	if stats.Retitled != 1 {
+		t.Errorf("Expected 1 entry retitled, got %d", stats.Retitled)
+	}
+	if mockClient.entries[0].Status != miniflux.EntryStatusUnread {
+		t.Error("Expected the retitled entry to stay unread")
+	}
+}
+
+func TestTitleRewriteGroups(t *testing.T) {
+	rewrite := &TitleRewrite{Find: `^(?P<site>\w+): (.*)$`, Replace: "$2 (${site})"}
+	got, err := rewrite.rewrite("Example: Launch day")
+	if err != nil {
+		t.Fatalf("rewrite failed: %v", err)
+	}
+	if got != "Launch day (Example)" {
+		t.Errorf("Expected 'Launch day (Example)', got %q", got)
+	}
+}
+
+func TestLoadConfigRewriteTitle(t *testing.T) {
+	tests := []struct {
+		rule   string
+		errMsg string
+	}{
+		{"{name: Strip, title: x, action: rewrite_title, rewrite_title: {find: '^\\[Ad\\] '}}", ""},
+		{"{name: Strip, title: x, action: rewrite_title}", "requires the rewrite_title block"},
+		{"{name: Strip, title: x, action: rewrite_title, rewrite_title: {replace: y}}", "find is required"},
+		{"{name: Strip, title: x, action: rewrite_title, rewrite_title: {find: '('}}", "invalid rewrite_title find pattern"},
+	}
+
+	for _, tt := range tests {
+		configPath := filepath.Join(t.TempDir(), "rules.yaml")
+		content := "miniflux_url: https://miniflux.example.com\nrules:\n  - " + tt.rule + "\n"
+		if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write config: %v", err)
+		}
+
+		_, err := LoadConfig(configPath)
+		if tt.errMsg == "" {
+			if err != nil {
+				t.Errorf("%s: unexpected error: %v", tt.rule, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), tt.errMsg) {
+			t.Errorf("%s: expected error containing %q, got %v", tt.rule, tt.errMsg, err)
+		}
+	}
+}
@@ -0,0 +1,359 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+	miniflux "miniflux.app/v2/client"
+)
+
+// wizardSampleSize is the number of recent entries a new rule is tried against
+const wizardSampleSize = 100
+
+// wizardFields are the entry fields a rule added by the wizard can match
+var wizardFields = []string{"title", "content", "author", "comments_url"}
+
+// ruleWizard prompts for a new rule and tries it against recent entries
+type ruleWizard struct {
+	in     *bufio.Reader
+	out    io.Writer
+	client MinifluxClient
+	config *Config
+}
+
+// rule returns the rule a simpleRule describes
+func (r simpleRule) rule() Rule {
+	return Rule{
+		Name:        r.Name,
+		Description: r.Description,
+		FeedID:      IDList(r.FeedID),
+		Title:       r.Title,
+		Content:     r.Content,
+		Author:      r.Author,
+		CommentsURL: r.CommentsURL,
+		Action:      r.Action,
+	}
+}
+
+// ask prompts for a value, returning def when the answer is empty
+func (w *ruleWizard) ask(question, def string) (string, error) {
+	if def != "" {
+		fmt.Fprintf(w.out, "%s [%s]: ", question, def)
+	} else {
+		fmt.Fprintf(w.out, "%s: ", question)
+	}
+	line, err := w.in.ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		if err == io.EOF {
+			return "", io.ErrUnexpectedEOF
+		}
+		return "", err
+	}
+	if answer := strings.TrimSpace(line); answer != "" {
+		return answer, nil
+	}
+	return def, nil
+}
+
+// run prompts for the feed, field, pattern, action and name of a rule, tries it against
+// the feed's recent entries and reports whether it should be added
+func (w *ruleWizard) run() (simpleRule, bool, error) {
+	var rule simpleRule
+
+	feeds, err := w.client.Feeds()
+	if err != nil {
+		return rule, false, fmt.Errorf("failed to fetch feeds: %w", err)
+	}
+	for i, feed := range feeds {
+		category := ""
+		if feed.Category != nil {
+			category = " [" + feed.Category.Title + "]"
+		}
+		fmt.Fprintf(w.out, "%3d) %s%s\n", i+1, feed.Title, category)
+	}
+	var feed *miniflux.Feed
+	for {
+		answer, err := w.ask("Feed number (empty for all feeds)", "")
+		if err != nil {
+			return rule, false, err
+		}
+		if answer == "" {
+			break
+		}
+		if n, err := strconv.Atoi(answer); err == nil && n >= 1 && n <= len(feeds) {
+			feed = feeds[n-1]
+			rule.FeedID = []int64{feed.ID}
+			break
+		}
+		fmt.Fprintf(w.out, "Enter a number between 1 and %d\n", len(feeds))
+	}
+
+	field, err := w.askChoice("Field to match ("+strings.Join(wizardFields, ", ")+")", wizardFields)
+	if err != nil {
+		return rule, false, err
+	}
+
+	var pattern string
+	for {
+		if pattern, err = w.ask("Pattern (regular expression)", ""); err != nil {
+			return rule, false, err
+		}
+		if pattern == "" {
+			continue
+		}
+		if _, err := regexp.Compile(pattern); err != nil {
+			fmt.Fprintf(w.out, "Invalid pattern: %v\n", err)
+			continue
+		}
+		break
+	}
+	switch field {
+	case "title":
+		rule.Title = pattern
+	case "content":
+		rule.Content = pattern
+	case "author":
+		rule.Author = pattern
+	case "comments_url":
+		rule.CommentsURL = pattern
+	}
+
+	scope := "all feeds"
+	if feed != nil {
+		scope = feed.Title
+	}
+	for {
+		if rule.Action, err = w.ask("Action", "read"); err != nil {
+			return rule, false, err
+		}
+		if rule.Name, err = w.ask("Rule name", fmt.Sprintf("%s %s on %s", strings.ReplaceAll(field, "_", " "), pattern, scope)); err != nil {
+			return rule, false, err
+		}
+		if err = w.validate(rule); err == nil {
+			break
+		}
+		fmt.Fprintf(w.out, "Invalid rule: %v\n", err)
+	}
+
+	if err := w.tryRule(rule); err != nil {
+		return rule, false, err
+	}
+
+	answer, err := w.ask("Add this rule? (y/n)", "n")
+	if err != nil {
+		return rule, false, err
+	}
+	return rule, strings.EqualFold(answer, "y") || strings.EqualFold(answer, "yes"), nil
+}
+
+// askChoice prompts until one of the choices is entered, defaulting to the first
+func (w *ruleWizard) askChoice(question string, choices []string) (string, error) {
+	for {
+		answer, err := w.ask(question, choices[0])
+		if err != nil {
+			return "", err
+		}
+		if slices.Contains(choices, strings.ToLower(answer)) {
+			return strings.ToLower(answer), nil
+		}
+		fmt.Fprintf(w.out, "Enter one of: %s\n", strings.Join(choices, ", "))
+	}
+}
+
+// validate checks the rule along with the config's rules, so actions such as macros
+// and webhook targets resolve as they will when the rule runs
+func (w *ruleWizard) validate(rule simpleRule) error {
+	if slices.ContainsFunc(w.config.Rules, func(r Rule) bool { return r.Name == rule.Name }) {
+		return fmt.Errorf("rule '%s' is already defined", rule.Name)
+	}
+	config := *w.config
+	config.Rules = append(slices.Clip(w.config.Rules), rule.rule())
+	return config.Validate()
+}
+
+// tryRule shows which of the most recent entries in the rule's scope it matches
+func (w *ruleWizard) tryRule(rule simpleRule) error {
+	matcher, err := NewMatcher([]Rule{rule.rule()})
+	if err != nil {
+		return err
+	}
+	filter := &miniflux.Filter{Limit: wizardSampleSize, Order: "published_at", Direction: "desc"}
+	if len(rule.FeedID) > 0 {
+		filter.FeedID = rule.FeedID[0]
+	}
+	result, err := w.client.Entries(filter)
+	if err != nil {
+		return fmt.Errorf("failed to fetch entries: %w", err)
+	}
+
+	var matched []*miniflux.Entry
+	for _, entry := range result.Entries {
+		if matcher.Match(entry).Matched {
+			matched = append(matched, entry)
+		}
+	}
+	fmt.Fprintf(w.out, "Matches %d of the %d most recent entries\n", len(matched), len(result.Entries))
+	for i, entry := range matched {
+		if i == 10 {
+			fmt.Fprintf(w.out, "  ... and %d more\n", len(matched)-i)
+			break
+		}
+		fmt.Fprintf(w.out, "  %s\n", entry.Title)
+	}
+	return nil
+}
+
+// appendRuleYAML adds a rule at the end of the rules list of a YAML config or rules file,
+// indented like the rules before it and leaving the rest of the file as written
+func appendRuleYAML(data []byte, rule simpleRule) ([]byte, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode([]simpleRule{rule}); err != nil {
+		return nil, err
+	}
+	encoder.Close()
+	rendered := strings.SplitAfter(buf.String(), "\n")
+	rendered = rendered[:len(rendered)-1]
+
+	text := string(data)
+	if text != "" && !strings.HasSuffix(text, "\n") {
+		text += "\n"
+	}
+	lines := strings.SplitAfter(text, "\n")
+	lines = lines[:len(lines)-1] // the empty string after the final newline
+
+	var root *yaml.Node
+	if len(doc.Content) > 0 {
+		root = doc.Content[0]
+	}
+	if root != nil && root.Kind != yaml.MappingNode {
+		return nil, errors.New("the file is not a mapping of settings")
+	}
+
+	// Without a rules list, one is started at the end of the file
+	rules := -1
+	if root != nil {
+		for i := 0; i < len(root.Content); i += 2 {
+			if root.Content[i].Value == "rules" {
+				rules = i
+			}
+		}
+	}
+	if rules < 0 {
+		added := []string{"rules:\n"}
+		for _, line := range rendered {
+			added = append(added, "  "+line)
+		}
+		return []byte(text + strings.Join(added, "")), nil
+	}
+
+	list := root.Content[rules+1]
+	indent := root.Content[rules].Column - 1 + 2
+	switch {
+	case list.Kind == yaml.SequenceNode && list.Style&yaml.FlowStyle == 0 && len(list.Content) > 0:
+		indent = list.Column - 1
+	case list.Kind == yaml.ScalarNode && list.Tag == "!!null" && list.Value == "":
+	default:
+		return nil, errors.New("rules must be a block list to add a rule to it")
+	}
+
+	// The rule goes after the last line of the list, before the next setting and any
+	// blank or comment lines preceding it
+	end := len(lines)
+	if rules+2 < len(root.Content) {
+		end = root.Content[rules+2].Line - 1
+	}
+	for end > root.Content[rules].Line {
+		if trimmed := strings.TrimSpace(lines[end-1]); trimmed != "" && !strings.HasPrefix(trimmed, "#") {
+			break
+		}
+		end--
+	}
+
+	var added []string
+	if len(list.Content) > 1 {
+		// Rules separated by blank lines get one before the new rule too
+		if second := list.Content[1].Line - 1; second >= 1 && strings.TrimSpace(lines[second-1]) == "" {
+			added = append(added, "\n")
+		}
+	}
+	for _, line := range rendered {
+		added = append(added, strings.Repeat(" ", indent)+line)
+	}
+	updated := slices.Concat(lines[:end], added, lines[end:])
+	return []byte(strings.Join(updated, "")), nil
+}
+
+// ruleCommand runs the rule subcommand:
+// miniflux-jobs rule add
+// miniflux-jobs rule add --file rules.d/promos.yaml
+func ruleCommand(args []string) {
+	logger := commandLogger(os.Stderr)
+	if len(args) == 0 || args[0] != "add" {
+		fatal(logger, "Usage: miniflux-jobs rule add [-config path] [-file path]")
+	}
+
+	flags := flag.NewFlagSet("rule add", flag.ExitOnError)
+	configPath := flags.String("config", defaultConfigPath(), "Path to the rules configuration file")
+	rulesFile := flags.String("file", "", "YAML file the rule is added to, e.g. an included rules file (default: the config file)")
+	flags.Parse(args[1:])
+
+	config, err := LoadConfig(*configPath)
+	if err != nil {
+		fatal(logger, "Failed to load config", "error", err)
+	}
+	if *rulesFile == "" {
+		*rulesFile = *configPath
+	}
+	if isRemoteConfig(*rulesFile) {
+		fatal(logger, "Rules can only be added to a local file")
+	}
+	if format, _ := detectConfigFormat(*rulesFile, ""); format != formatYAML || strings.HasSuffix(*rulesFile, ".age") {
+		fatal(logger, "Rules can only be added to a plain YAML file")
+	}
+
+	apiKey, err := GetAPIKey()
+	if err != nil {
+		fatal(logger, "Failed to get API key", "error", err)
+	}
+	client := NewClientWrapper(config.MinifluxURL, apiKey, config.MaxResponseSize)
+
+	wizard := &ruleWizard{in: bufio.NewReader(os.Stdin), out: os.Stdout, client: client, config: config}
+	rule, add, err := wizard.run()
+	if err != nil {
+		fatal(logger, "Failed to add rule", "error", err)
+	}
+	if !add {
+		logger.Info("Rule not added")
+		return
+	}
+
+	data, err := os.ReadFile(*rulesFile)
+	if err != nil && !os.IsNotExist(err) {
+		fatal(logger, "Failed to read the rules file", "file", *rulesFile, "error", err)
+	}
+	updated, err := appendRuleYAML(data, rule)
+	if err != nil {
+		fatal(logger, "Failed to add rule", "file", *rulesFile, "error", err)
+	}
+	if err := os.WriteFile(*rulesFile, updated, 0644); err != nil {
+		fatal(logger, "Failed to write the rules file", "file", *rulesFile, "error", err)
+	}
+	logger.Info("Added rule", "rule", rule.Name, "file", *rulesFile)
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"bufio"
+	"strings"
+	"testing"
+
+	miniflux "miniflux.app/v2/client"
+)
+
+func TestRuleWizard(t *testing.T) {
+	mockClient := &MockClient{
+		feeds: miniflux.Feeds{
+			{ID: 7, Title: "Tech Daily", Category: &miniflux.Category{ID: 1, Title: "News"}},
+			{ID: 8, Title: "Gadgets"},
+		},
+		entries: []*miniflux.Entry{
+			{ID: 1, FeedID: 7, Title: "Sponsored: a phone"},
+			{ID: 2, FeedID: 7, Title: "A review"},
+			{ID: 3, FeedID: 8, Title: "Sponsored: a tablet"},
+		},
+	}
+	config := &Config{
+		MinifluxURL: "https://miniflux.example.com",
+		Rules:       []Rule{{Name: "Promos", Title: "Promo", Action: "read"}},
+	}
+
+	// Invalid answers are asked again: feed 5, field "url", pattern "(", action "archive", name "Promos"
+	input := "5\n1\nurl\n\n(\n^Sponsored\narchive\n\nread\nPromos\nread\nSponsored news\ny\n"
+	var out strings.Builder
+	wizard := &ruleWizard{in: bufio.NewReader(strings.NewReader(input)), out: &out, client: mockClient, config: config}
+
+	rule, add, err := wizard.run()
+	if err != nil {
+		t.Fatalf("Wizard failed: %v\n%s", err, out.String())
+	}
+	if !add {
+		t.Error("Expected the rule to be confirmed")
+	}
+	expected := simpleRule{Name: "Sponsored news", FeedID: []int64{7}, Title: "^Sponsored", Action: "read"}
+	if rule.Name != expected.Name || rule.Title != expected.Title || rule.Action != expected.Action || len(rule.FeedID) != 1 || rule.FeedID[0] != 7 {
+		t.Errorf("Expected %+v, got %+v", expected, rule)
+	}
+
+	printed := out.String()
+	for _, want := range []string{"  1) Tech Daily [News]", "Invalid pattern", "Invalid rule", "already defined", "Matches 1 of the 2 most recent entries", "  Sponsored: a phone"} {
+		if !strings.Contains(printed, want) {
+			t.Errorf("Expected %q in the wizard output:\n%s", want, printed)
+		}
+	}
+	if mockClient.lastFilter.FeedID != 7 || mockClient.lastFilter.Direction != "desc" {
+		t.Errorf("Expected the feed's most recent entries to be tried, got %+v", mockClient.lastFilter)
+	}
+}
+
+func TestAppendRuleYAML(t *testing.T) {
+	rule := simpleRule{Name: "Sponsored news", FeedID: []int64{7}, Title: "^Sponsored", Action: "read"}
+	tests := []struct {
+		name     string
+		config   string
+		expected string
+	}{
+		{
+			name: "end of rules",
+			config: `miniflux_url: https://miniflux.example.com
+rules:
+    # Promotions
+    - name: Promos
+      title: Promo
+      action: read
+
+    - name: Deals
+      title: Deal
+      action: star
+
+# Settings below
+interval: 300
+`,
+			expected: `miniflux_url: https://miniflux.example.com
+rules:
+    # Promotions
+    - name: Promos
+      title: Promo
+      action: read
+
+    - name: Deals
+      title: Deal
+      action: star
+
+    - name: Sponsored news
+      feed_id: [7]
+      title: ^Sponsored
+      action: read
+
+# Settings below
+interval: 300
+`,
+		},
+		{
+			name:   "empty rules",
+			config: "miniflux_url: https://miniflux.example.com\nrules:\ninterval: 300\n",
+			expected: `miniflux_url: https://miniflux.example.com
+rules:
+  - name: Sponsored news
+    feed_id: [7]
+    title: ^Sponsored
+    action: read
+interval: 300
+`,
+		},
+		{
+			name:   "no rules",
+			config: "miniflux_url: https://miniflux.example.com",
+			expected: `miniflux_url: https://miniflux.example.com
+rules:
+  - name: Sponsored news
+    feed_id: [7]
+    title: ^Sponsored
+    action: read
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		updated, err := appendRuleYAML([]byte(tt.config), rule)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.name, err)
+			continue
+		}
+		if string(updated) != tt.expected {
+			t.Errorf("%s: expected\n%s\ngot\n%s", tt.name, tt.expected, updated)
+		}
+	}
+
+	if _, err := appendRuleYAML([]byte("rules: []\n"), rule); err == nil {
+		t.Error("Expected a flow list of rules to be rejected")
+	}
+}
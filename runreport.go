@@ -0,0 +1,199 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Run report formats
+const (
+	reportHTML     = "html"
+	reportJSON     = "json"
+	reportMarkdown = "markdown"
+)
+
+// reportExtensions maps each report format to the extension of its default file
+var reportExtensions = map[string]string{
+	reportHTML:     ".html",
+	reportJSON:     ".json",
+	reportMarkdown: ".md",
+}
+
+// reportTarget is where and in which format each run's report is written
+// The zero value writes no report
+type reportTarget struct {
+	Path   string
+	Format string
+}
+
+// defaultReportFile returns the report path used when -report-file is not set
+func defaultReportFile(format string) string {
+	return "miniflux-jobs-report" + reportExtensions[format]
+}
+
+// jsonReport is the machine-readable run report
+type jsonReport struct {
+	Generated  time.Time         `json:"generated"`
+	DryRun     bool              `json:"dry_run"`
+	ConfigHash string            `json:"config_hash,omitempty"`
+	Warnings   []string          `json:"warnings,omitempty"`
+	Summary    jsonReportSummary `json:"summary"`
+	Rules      []jsonReportRule  `json:"rules"`
+}
+
+// jsonReportSummary holds the run's main counters
+type jsonReportSummary struct {
+	EntriesChecked int `json:"entries_checked"`
+	Matched        int `json:"matched"`
+	Errors         int `json:"errors"`
+}
+
+// jsonReportRule lists the entries matched by one rule and the action it takes on them
+type jsonReportRule struct {
+	Name    string            `json:"name"`
+	Action  string            `json:"action"`
+	Entries []jsonReportEntry `json:"entries"`
+}
+
+// jsonReportEntry is an entry matched by a rule
+type jsonReportEntry struct {
+	EntryID   int64  `json:"entry_id"`
+	FeedTitle string `json:"feed_title,omitempty"`
+	Title     string `json:"title"`
+	URL       string `json:"url,omitempty"`
+}
+
+// writeJSONReport writes the run report as indented JSON
+func writeJSONReport(w io.Writer, stats *ProcessStats, runErr error, dryRun bool, generated time.Time) error {
+	report := buildHTMLReport(stats, runErr, dryRun, generated)
+	out := jsonReport{
+		Generated:  report.Generated,
+		DryRun:     report.DryRun,
+		ConfigHash: stats.ConfigHash,
+		Warnings:   report.Warnings,
+		Summary: jsonReportSummary{
+			EntriesChecked: stats.TotalEntries,
+			Matched:        stats.MatchedEntries,
+			Errors:         stats.Errors,
+		},
+		Rules: []jsonReportRule{},
+	}
+	for _, rule := range report.Rules {
+		outRule := jsonReportRule{Name: rule.Name, Action: rule.Action}
+		for _, match := range rule.Entries {
+			outRule.Entries = append(outRule.Entries, jsonReportEntry{
+				EntryID:   match.EntryID,
+				FeedTitle: match.FeedTitle,
+				Title:     match.Title,
+				URL:       match.URL,
+			})
+		}
+		out.Rules = append(out.Rules, outRule)
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(out)
+}
+
+// diffPrefix marks an entry line by what the rule's action does to it:
+// "-" when the entry leaves the unread list, "+" when it is kept or highlighted
+func diffPrefix(action string) string {
+	steps := strings.Split(action, ", ")
+	switch {
+	case slices.Contains(steps, "remove") || slices.Contains(steps, "read"):
+		return "-"
+	case slices.Contains(steps, "unread") || slices.Contains(steps, "star"):
+		return "+"
+	default:
+		return " "
+	}
+}
+
+// writeMarkdownReport writes the run report as Markdown with a diff block per rule
+func writeMarkdownReport(w io.Writer, stats *ProcessStats, runErr error, dryRun bool, generated time.Time) error {
+	report := buildHTMLReport(stats, runErr, dryRun, generated)
+
+	var b strings.Builder
+	b.WriteString("# miniflux-jobs run report\n\n")
+	fmt.Fprintf(&b, "Generated %s", generated.Format("2006-01-02 15:04:05 MST"))
+	if stats.ConfigHash != "" {
+		fmt.Fprintf(&b, " with config hash `%s`", stats.ConfigHash)
+	}
+	b.WriteString("\n")
+	if dryRun {
+		b.WriteString("\n> **Dry run:** the actions below were proposed, not applied.\n")
+	}
+	if len(report.Warnings) > 0 {
+		b.WriteString("\n## Warnings\n\n")
+		for _, warning := range report.Warnings {
+			fmt.Fprintf(&b, "- %s\n", warning)
+		}
+	}
+
+	b.WriteString("\n## Summary\n\n")
+	fmt.Fprintf(&b, "- Entries checked: %d\n", stats.TotalEntries)
+	fmt.Fprintf(&b, "- Matched: %d\n", stats.MatchedEntries)
+	fmt.Fprintf(&b, "- Errors: %d\n", stats.Errors)
+
+	for _, rule := range report.Rules {
+		fmt.Fprintf(&b, "\n## %s\n\n", rule.Name)
+		fmt.Fprintf(&b, "Action `%s` on %d entries\n\n", rule.Action, len(rule.Entries))
+		b.WriteString("```diff\n")
+		prefix := diffPrefix(rule.Action)
+		for _, match := range rule.Entries {
+			fmt.Fprintf(&b, "%s #%d", prefix, match.EntryID)
+			if match.FeedTitle != "" {
+				fmt.Fprintf(&b, " [%s]", match.FeedTitle)
+			}
+			fmt.Fprintf(&b, " %s", match.Title)
+			if match.URL != "" {
+				fmt.Fprintf(&b, " <%s>", match.URL)
+			}
+			b.WriteString("\n")
+		}
+		b.WriteString("```\n")
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// writeReport renders the run report in the given format
+func writeReport(w io.Writer, format string, stats *ProcessStats, runErr error, dryRun bool, generated time.Time) error {
+	switch format {
+	case reportJSON:
+		return writeJSONReport(w, stats, runErr, dryRun, generated)
+	case reportMarkdown:
+		return writeMarkdownReport(w, stats, runErr, dryRun, generated)
+	default:
+		return writeHTMLReport(w, stats, runErr, dryRun, generated)
+	}
+}
+
+// saveReport writes the run report to path, replacing any previous report
+func saveReport(path, format string, stats *ProcessStats, runErr error, dryRun bool) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".report-*")
+	if err != nil {
+		return fmt.Errorf("failed to create report file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := writeReport(tmp, format, stats, runErr, dryRun, time.Now()); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write report: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write report: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to replace report file: %w", err)
+	}
+	return nil
+}
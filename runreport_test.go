@@ -0,0 +1,93 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	miniflux "miniflux.app/v2/client"
+)
+
+func dryRunReportStats(t *testing.T) *ProcessStats {
+	t.Helper()
+	mockClient := &MockClient{
+		entries: []*miniflux.Entry{
+			{ID: 1, Title: "Sponsored deal", URL: "https://example.com/deal", Feed: &miniflux.Feed{Title: "Blog"}, Status: miniflux.EntryStatusUnread},
+			{ID: 2, Title: "Release 2.0", Status: miniflux.EntryStatusUnread},
+			{ID: 3, Title: "Regular post", Status: miniflux.EntryStatusUnread},
+		},
+	}
+	matcher, err := NewMatcher([]Rule{
+		{Name: "Sponsored", Title: "Sponsored", Action: "remove"},
+		{Name: "Releases", Title: "Release", Action: "star"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create matcher: %v", err)
+	}
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	stats, err := NewProcessor(mockClient, matcher, logger, true).Process()
+	if err != nil {
+		t.Fatalf("Process failed: %v", err)
+	}
+	return stats
+}
+
+func TestMarkdownReport(t *testing.T) {
+	var buf bytes.Buffer
+	if err := writeReport(&buf, reportMarkdown, dryRunReportStats(t), nil, true, time.Now()); err != nil {
+		t.Fatalf("Failed to write report: %v", err)
+	}
+
+	output := buf.String()
+	for _, expected := range []string{
+		"> **Dry run:** the actions below were proposed, not applied.",
+		"## Sponsored\n\nAction `remove` on 1 entries\n\n```diff\n- #1 [Blog] Sponsored deal <https://example.com/deal>\n```",
+		"## Releases\n\nAction `star` on 1 entries\n\n```diff\n+ #2 Release 2.0\n```",
+	} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("Expected report to contain %q, got:\n%s", expected, output)
+		}
+	}
+	if strings.Contains(output, "Regular post") {
+		t.Error("Expected unmatched entries to be left out of the report")
+	}
+}
+
+func TestJSONReport(t *testing.T) {
+	var buf bytes.Buffer
+	if err := writeReport(&buf, reportJSON, dryRunReportStats(t), nil, true, time.Now()); err != nil {
+		t.Fatalf("Failed to write report: %v", err)
+	}
+
+	var report jsonReport
+	if err := json.Unmarshal(buf.Bytes(), &report); err != nil {
+		t.Fatalf("Invalid JSON report: %v", err)
+	}
+	if !report.DryRun || report.Summary.EntriesChecked != 3 || report.Summary.Matched != 2 {
+		t.Errorf("Unexpected report header: %+v", report)
+	}
+	if len(report.Rules) != 2 || report.Rules[0].Name != "Sponsored" || report.Rules[0].Action != "remove" ||
+		len(report.Rules[0].Entries) != 1 || report.Rules[0].Entries[0].URL != "https://example.com/deal" {
+		t.Errorf("Unexpected rules: %+v", report.Rules)
+	}
+}
+
+func TestDiffPrefix(t *testing.T) {
+	tests := map[string]string{
+		"remove":        "-",
+		"export, read":  "-",
+		"star":          "+",
+		"unread":        "+",
+		"webhook:slack": " ",
+	}
+	for action, expected := range tests {
+		if got := diffPrefix(action); got != expected {
+			t.Errorf("diffPrefix(%q) = %q, expected %q", action, got, expected)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// defaultSafeModeWindow applies when safe_mode omits a window
+const defaultSafeModeWindow = 10 * time.Minute
+
+// safeModeAlerter sends the alert raised when safe mode starts
+type safeModeAlerter func(title, message string) error
+
+// newSafeModeAlerter alerts through the notifier and email configured in safe_mode,
+// returning nil when neither is
+func newSafeModeAlerter(config *Config) safeModeAlerter {
+	notifier, hasNotifier := findNotifier(config.Notifiers, config.SafeMode.Notifier)
+	hasNotifier = hasNotifier && config.SafeMode.Notifier != ""
+	if !hasNotifier && !config.SafeMode.Email {
+		return nil
+	}
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	return func(title, message string) error {
+		var errs []error
+		if hasNotifier {
+			errs = append(errs, sendNotification(client, notifier, notification{Title: title, Message: message}))
+		}
+		if config.SafeMode.Email {
+			errs = append(errs, sendSMTP(config.Email, title, message))
+		}
+		return errors.Join(errs...)
+	}
+}
+
+// enterSafeMode records this startup and reports whether the process restarted
+// too often without a clean exit, in which case it must run in dry-run mode
+// Entering safe mode is reported through alert, if set.
+func enterSafeMode(state *State, cfg SafeModeConfig, alert safeModeAlerter, logger *slog.Logger) bool {
+	if cfg.MaxRestarts == 0 {
+		return false
+	}
+
+	window := cfg.Window
+	if window == 0 {
+		window = defaultSafeModeWindow
+	}
+
+	now := time.Now()
+	restarts := state.RecordStartup(now, now.Add(-window)) - 1
+	if err := state.Save(); err != nil {
+		logger.Error("Failed to save state", "error", err)
+	}
+
+	if restarts <= cfg.MaxRestarts {
+		return false
+	}
+
+	logger.Error(
+		"ALERT: restarted too often without a clean exit, starting in safe mode: no changes will be applied",
+		"restarts", restarts, "window", window,
+	)
+	if alert != nil {
+		message := fmt.Sprintf("Restarted %d times within %s without a clean exit, starting in safe mode: no changes will be applied", restarts, window)
+		if err := alert("miniflux-jobs safe mode", message); err != nil {
+			logger.Error("Failed to send safe mode alert", "error", err)
+		}
+	}
+	return true
+}
+
+// markCleanExit records an orderly shutdown so the next start is not counted as a crash
+func markCleanExit(state *State, logger *slog.Logger) {
+	if state == nil || len(state.Startups) == 0 {
+		return
+	}
+
+	state.MarkCleanExit()
+	if err := state.Save(); err != nil {
+		logger.Error("Failed to save state", "error", err)
+	}
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEnterSafeMode(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	cfg := SafeModeConfig{MaxRestarts: 2, Window: time.Hour}
+	var alerts []string
+	alert := func(title, message string) error {
+		alerts = append(alerts, message)
+		return nil
+	}
+
+	// Each start reloads the state as a restarted process would
+	for i := 0; i < 3; i++ {
+		state, err := LoadState(path)
+		if err != nil {
+			t.Fatalf("Failed to load state: %v", err)
+		}
+		if enterSafeMode(state, cfg, alert, logger) {
+			t.Fatalf("Expected start %d not to enter safe mode", i+1)
+		}
+	}
+
+	state, err := LoadState(path)
+	if err != nil {
+		t.Fatalf("Failed to load state: %v", err)
+	}
+	if !enterSafeMode(state, cfg, alert, logger) {
+		t.Fatal("Expected safe mode after 3 unclean restarts")
+	}
+	if len(alerts) != 1 || !strings.Contains(alerts[0], "Restarted 3 times") {
+		t.Errorf("Expected one safe mode alert, got %q", alerts)
+	}
+
+	// A clean exit resets the crash counter
+	markCleanExit(state, logger)
+	state, err = LoadState(path)
+	if err != nil {
+		t.Fatalf("Failed to load state: %v", err)
+	}
+	if enterSafeMode(state, cfg, alert, logger) {
+		t.Error("Expected no safe mode after a clean exit")
+	}
+}
+
+func TestSafeModeAlerter(t *testing.T) {
+	if alert := newSafeModeAlerter(&Config{}); alert != nil {
+		t.Error("Expected no alerter without a notifier or email")
+	}
+
+	var pushed []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		pushed = append(pushed, r.URL.Path+" "+r.Header.Get("Title")+": "+string(body))
+	}))
+	defer server.Close()
+
+	config := &Config{
+		SafeMode:  SafeModeConfig{MaxRestarts: 1, Notifier: "Ops"},
+		Notifiers: map[string]NotifierConfig{"ops": {Type: "ntfy", Server: server.URL, Topic: "alerts"}},
+	}
+	alert := newSafeModeAlerter(config)
+	if alert == nil {
+		t.Fatal("Expected an alerter for the safe_mode notifier")
+	}
+	if err := alert("miniflux-jobs safe mode", "starting in safe mode"); err != nil {
+		t.Fatalf("Failed to send alert: %v", err)
+	}
+	if len(pushed) != 1 || pushed[0] != "/alerts miniflux-jobs safe mode: starting in safe mode" {
+		t.Errorf("Unexpected pushes: %q", pushed)
+	}
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// loopSchedule decides when the processing loop runs next
+type loopSchedule interface {
+	Next(after time.Time) time.Time
+}
+
+// intervalSchedule runs at a fixed interval
+type intervalSchedule time.Duration
+
+// Next returns the time one interval after the previous run was due
+func (s intervalSchedule) Next(after time.Time) time.Time {
+	return after.Add(time.Duration(s))
+}
+
+// cronSchedule runs at the times of a cron expression in the configured timezone
+type cronSchedule struct {
+	schedule cron.Schedule
+	location *time.Location
+}
+
+// Next returns the first time of the expression after the given time
+func (s cronSchedule) Next(after time.Time) time.Time {
+	return s.schedule.Next(after.In(s.location))
+}
+
+// newLoopSchedule returns the schedule of the processing loop from schedule or interval
+func newLoopSchedule(config *Config) (loopSchedule, error) {
+	if config.Schedule == "" {
+		return intervalSchedule(time.Duration(config.Interval) * time.Second), nil
+	}
+	schedule, err := cron.ParseStandard(config.Schedule)
+	if err != nil {
+		return nil, err
+	}
+	return cronSchedule{schedule: schedule, location: config.Location()}, nil
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestLoopSchedule(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skipf("Timezone data unavailable: %v", err)
+	}
+
+	schedule, err := newLoopSchedule(&Config{Schedule: "*/15 7-23 * * *", Timezone: "Europe/Berlin"})
+	if err != nil {
+		t.Fatalf("Failed to create schedule: %v", err)
+	}
+	tests := []struct {
+		after time.Time
+		want  time.Time
+	}{
+		{time.Date(2026, 3, 2, 9, 5, 0, 0, berlin), time.Date(2026, 3, 2, 9, 15, 0, 0, berlin)},
+		{time.Date(2026, 3, 2, 23, 50, 0, 0, berlin), time.Date(2026, 3, 3, 7, 0, 0, 0, berlin)},
+		// Times are read in the configured timezone: 05:50 UTC is 06:50 in Berlin
+		{time.Date(2026, 3, 2, 5, 50, 0, 0, time.UTC), time.Date(2026, 3, 2, 7, 0, 0, 0, berlin)},
+	}
+	for _, tt := range tests {
+		if got := schedule.Next(tt.after); !got.Equal(tt.want) {
+			t.Errorf("Next(%s) = %s, want %s", tt.after, got, tt.want)
+		}
+	}
+
+	interval, err := newLoopSchedule(&Config{Interval: 300})
+	if err != nil {
+		t.Fatalf("Failed to create schedule: %v", err)
+	}
+	start := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	if got := interval.Next(start); !got.Equal(start.Add(5 * time.Minute)) {
+		t.Errorf("Expected the next run one interval later, got %s", got)
+	}
+}
+
+func TestRunLoopWaitsForSchedule(t *testing.T) {
+	schedule, err := newLoopSchedule(&Config{Schedule: "0 3 * * *"})
+	if err != nil {
+		t.Fatalf("Failed to create schedule: %v", err)
+	}
+	mockClient := &MockClient{}
+	matcher, err := NewMatcher(nil)
+	if err != nil {
+		t.Fatalf("Failed to create matcher: %v", err)
+	}
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	instances := []*instance{{processor: NewProcessor(mockClient, matcher, logger, true), logger: logger}}
+
+	sigChan := make(chan os.Signal, 1)
+	sigChan <- syscall.SIGTERM
+	if err := runLoop(instances, logger, schedule, sigChan, nil, reportTarget{}); err != nil {
+		t.Fatalf("runLoop failed: %v", err)
+	}
+	if mockClient.lastFilter != nil {
+		t.Error("Expected no run before the first scheduled time")
+	}
+}
+
+func TestLoadConfigSchedule(t *testing.T) {
+	tests := []struct {
+		settings string
+		errMsg   string
+	}{
+		{"schedule: \"*/15 7-23 * * *\"\n", ""},
+		{"schedule: \"@hourly\"\n", ""},
+		{"schedule: \"*/15 7-23 * *\"\n", "invalid schedule"},
+		{"schedule: \"@hourly\"\ninterval: 60\n", "schedule and interval are mutually exclusive"},
+	}
+
+	for _, tt := range tests {
+		configPath := filepath.Join(t.TempDir(), "rules.yaml")
+		content := "miniflux_url: https://miniflux.example.com\n" + tt.settings
+		if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write config: %v", err)
+		}
+
+		_, err := LoadConfig(configPath)
+		if tt.errMsg == "" {
+			if err != nil {
+				t.Errorf("%q: unexpected error: %v", tt.settings, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), tt.errMsg) {
+			t.Errorf("%q: expected error containing %q, got %v", tt.settings, tt.errMsg, err)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"os"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// jsonSchemaDraft is the JSON Schema version of the generated schema
+const jsonSchemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+// schemaCommand runs the schema subcommand, printing a JSON Schema of the config for editors:
+// miniflux-jobs schema > rules.schema.json
+func schemaCommand(args []string) {
+	flags := flag.NewFlagSet("schema", flag.ExitOnError)
+	flags.Parse(args)
+
+	logger := commandLogger(os.Stderr)
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(configSchema()); err != nil {
+		fatal(logger, "Failed to write schema", "error", err)
+	}
+}
+
+// configSchema builds the JSON Schema of the config file
+// Fields are read by reflection so new options show up without changes here
+func configSchema() map[string]any {
+	defs := make(map[string]any)
+	root := structSchema(reflect.TypeOf(Config{}), defs)
+	root["$schema"] = jsonSchemaDraft
+	root["title"] = "miniflux-jobs config"
+	root["$defs"] = defs
+	return root
+}
+
+// schemaFor returns the schema of a config type, adding named structs to defs
+func schemaFor(t reflect.Type, defs map[string]any) map[string]any {
+	switch t {
+	case reflect.TypeOf(StringList{}):
+		return map[string]any{"oneOf": []any{
+			map[string]any{"type": "string"},
+			map[string]any{"type": "array", "items": map[string]any{"type": "string"}},
+		}}
+	case reflect.TypeOf(IDList{}):
+		return map[string]any{"oneOf": []any{
+			map[string]any{"type": "integer"},
+			map[string]any{"type": "array", "items": map[string]any{"type": "integer"}},
+		}}
+	case reflect.TypeOf(time.Duration(0)):
+		return map[string]any{"type": []string{"string", "integer"}, "description": "duration, e.g. 90s, 15m or 1h"}
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		return schemaFor(t.Elem(), defs)
+	case reflect.Struct:
+		// Profiles take the settings of the config itself
+		if t == reflect.TypeOf(Config{}) {
+			return map[string]any{"$ref": "#"}
+		}
+		if _, ok := defs[t.Name()]; !ok {
+			defs[t.Name()] = nil // reserved while the fields are built, for recursive types
+			defs[t.Name()] = structSchema(t, defs)
+		}
+		return map[string]any{"$ref": "#/$defs/" + t.Name()}
+	case reflect.Slice, reflect.Array:
+		return map[string]any{"type": "array", "items": schemaFor(t.Elem(), defs)}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": schemaFor(t.Elem(), defs)}
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	}
+	return map[string]any{}
+}
+
+// structSchema returns the schema of a struct, which takes no keys besides its fields
+func structSchema(t reflect.Type, defs map[string]any) map[string]any {
+	properties := make(map[string]any)
+	for _, field := range reflect.VisibleFields(t) {
+		if name, ok := yamlFieldName(field); ok {
+			properties[name] = schemaFor(field.Type, defs)
+		}
+	}
+	return map[string]any{
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": false,
+	}
+}
+
+// yamlFieldName returns the config key of a struct field, as decoded by yaml
+func yamlFieldName(field reflect.StructField) (string, bool) {
+	if !field.IsExported() || field.Anonymous {
+		return "", false
+	}
+	name, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+	switch name {
+	case "-":
+		return "", false
+	case "":
+		return strings.ToLower(field.Name), true
+	}
+	return name, true
+}
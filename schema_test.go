@@ -0,0 +1,54 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadConfigRejectsUnknownKeys(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "rules.yaml")
+	content := "miniflux_url: https://miniflux.example.com\nrules:\n  - name: Promos\n    autor: Ads Team\n    action: read\n"
+	if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	_, err := LoadConfig(configPath)
+	if err == nil || !strings.Contains(err.Error(), "line 4: unknown key 'autor' (did you mean 'author'?)") {
+		t.Errorf("Expected the misspelt key to be reported, got %v", err)
+	}
+}
+
+func TestConfigSchema(t *testing.T) {
+	data, err := json.Marshal(configSchema())
+	if err != nil {
+		t.Fatalf("Failed to encode schema: %v", err)
+	}
+
+	var schema struct {
+		Properties map[string]json.RawMessage `json:"properties"`
+		Defs       map[string]struct {
+			Properties           map[string]json.RawMessage `json:"properties"`
+			AdditionalProperties bool                       `json:"additionalProperties"`
+		} `json:"$defs"`
+	}
+	if err := json.Unmarshal(data, &schema); err != nil {
+		t.Fatalf("Failed to decode schema: %v", err)
+	}
+
+	if _, ok := schema.Properties["miniflux_url"]; !ok {
+		t.Error("Expected miniflux_url in the schema")
+	}
+	if got := string(schema.Properties["rules"]); got != `{"items":{"$ref":"#/$defs/Rule"},"type":"array"}` {
+		t.Errorf("Expected rules to reference the rule schema, got %s", got)
+	}
+	rule := schema.Defs["Rule"]
+	if _, ok := rule.Properties["author"]; !ok || rule.AdditionalProperties {
+		t.Errorf("Expected a closed rule schema with author, got %+v", rule)
+	}
+	if got := string(rule.Properties["feed_id"]); !strings.Contains(got, `"type":"integer"`) {
+		t.Errorf("Expected feed_id to take IDs, got %s", got)
+	}
+}
@@ -0,0 +1,201 @@
+package main
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+	"gopkg.in/yaml.v3"
+)
+
+// Headers of files encrypted with age, in the binary and armored encodings
+const (
+	ageHeader      = "age-encryption.org/v1"
+	ageArmorHeader = "-----BEGIN AGE ENCRYPTED FILE-----"
+)
+
+// sopsMetadataKey holds the metadata sops adds to the documents it encrypts
+const sopsMetadataKey = "sops"
+
+// sopsValue matches a value encrypted by sops
+var sopsValue = regexp.MustCompile(`^ENC\[AES256_GCM,data:(.+),iv:(.+),tag:(.+),type:(.+)\]$`)
+
+// ageIdentities loads the age keys that decrypt configs from SOPS_AGE_KEY or SOPS_AGE_KEY_FILE,
+// the variables sops itself reads
+func ageIdentities() ([]age.Identity, error) {
+	keys := os.Getenv("SOPS_AGE_KEY")
+	if keys == "" {
+		keyFile := os.Getenv("SOPS_AGE_KEY_FILE")
+		if keyFile == "" {
+			return nil, fmt.Errorf("SOPS_AGE_KEY or SOPS_AGE_KEY_FILE environment variable is required to decrypt the config")
+		}
+		data, err := os.ReadFile(keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read age key file: %w", err)
+		}
+		keys = string(data)
+	}
+
+	identities, err := age.ParseIdentities(strings.NewReader(keys))
+	if err != nil {
+		return nil, fmt.Errorf("invalid age key: %w", err)
+	}
+	return identities, nil
+}
+
+// decryptAge decrypts a file encrypted as a whole with age, returning other data unchanged
+func decryptAge(data []byte) ([]byte, error) {
+	var encrypted io.Reader
+	switch {
+	case bytes.HasPrefix(data, []byte(ageHeader)):
+		encrypted = bytes.NewReader(data)
+	case bytes.HasPrefix(bytes.TrimSpace(data), []byte(ageArmorHeader)):
+		encrypted = armor.NewReader(bytes.NewReader(bytes.TrimSpace(data)))
+	default:
+		return data, nil
+	}
+
+	identities, err := ageIdentities()
+	if err != nil {
+		return nil, err
+	}
+	plain, err := age.Decrypt(encrypted, identities...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt config: %w", err)
+	}
+	return io.ReadAll(plain)
+}
+
+// sopsMetadata is the part of the sops metadata needed to decrypt with age
+type sopsMetadata struct {
+	Age []struct {
+		Recipient string `yaml:"recipient"`
+		Enc       string `yaml:"enc"`
+	} `yaml:"age"`
+}
+
+// decryptSOPS decrypts the values of a document encrypted by sops with age and drops its metadata,
+// reporting whether the document was encrypted. Each value is authenticated with its key path,
+// as sops does; the MAC over the whole document is not checked.
+func decryptSOPS(doc *yaml.Node) (bool, error) {
+	if doc.Kind != yaml.DocumentNode || len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return false, nil
+	}
+	root := doc.Content[0]
+	var metadata *yaml.Node
+	for i := 0; i < len(root.Content); i += 2 {
+		if root.Content[i].Value == sopsMetadataKey {
+			metadata = root.Content[i+1]
+			root.Content = append(root.Content[:i:i], root.Content[i+2:]...)
+			break
+		}
+	}
+	if metadata == nil {
+		return false, nil
+	}
+
+	key, err := sopsDataKey(metadata)
+	if err != nil {
+		return true, err
+	}
+	return true, decryptSOPSValues(root, nil, key)
+}
+
+// sopsDataKey decrypts the data key of a sops document with the available age identities
+func sopsDataKey(metadata *yaml.Node) ([]byte, error) {
+	var meta sopsMetadata
+	if err := metadata.Decode(&meta); err != nil {
+		return nil, fmt.Errorf("invalid sops metadata: %w", err)
+	}
+	if len(meta.Age) == 0 {
+		return nil, fmt.Errorf("the config is encrypted by sops without an age recipient; only age is supported")
+	}
+
+	identities, err := ageIdentities()
+	if err != nil {
+		return nil, err
+	}
+	for _, recipient := range meta.Age {
+		plain, err := age.Decrypt(armor.NewReader(strings.NewReader(recipient.Enc)), identities...)
+		if err != nil {
+			continue
+		}
+		return io.ReadAll(plain)
+	}
+	return nil, fmt.Errorf("no age key matches the recipients of the config")
+}
+
+// decryptSOPSValues decrypts the encrypted scalars below node, whose map keys form the path
+// that sops authenticates each value with
+func decryptSOPSValues(node *yaml.Node, path []string, key []byte) error {
+	switch node.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if err := decryptSOPSValues(node.Content[i+1], append(path, node.Content[i].Value), key); err != nil {
+				return err
+			}
+		}
+	case yaml.SequenceNode:
+		for _, item := range node.Content {
+			if err := decryptSOPSValues(item, path, key); err != nil {
+				return err
+			}
+		}
+	case yaml.ScalarNode:
+		if !sopsValue.MatchString(node.Value) {
+			return nil
+		}
+		value, tag, err := decryptSOPSValue(node.Value, key, strings.Join(path, ":")+":")
+		if err != nil {
+			return fmt.Errorf("failed to decrypt %s: %w", strings.Join(path, "."), err)
+		}
+		node.Value, node.Tag, node.Style = value, tag, 0
+	}
+	return nil
+}
+
+// decryptSOPSValue decrypts one sops value, returning it with the yaml tag of its type
+func decryptSOPSValue(encrypted string, key []byte, additionalData string) (string, string, error) {
+	match := sopsValue.FindStringSubmatch(encrypted)
+	var parts [3][]byte
+	for i := range parts {
+		part, err := base64.StdEncoding.DecodeString(match[i+1])
+		if err != nil {
+			return "", "", err
+		}
+		parts[i] = part
+	}
+	data, iv, tag := parts[0], parts[1], parts[2]
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return "", "", err
+	}
+	gcm, err := cipher.NewGCMWithNonceSize(block, len(iv))
+	if err != nil {
+		return "", "", err
+	}
+	plain, err := gcm.Open(nil, iv, append(data, tag...), []byte(additionalData))
+	if err != nil {
+		return "", "", errors.New("wrong key or tampered value")
+	}
+
+	switch match[4] {
+	case "int":
+		return string(plain), "!!int", nil
+	case "float":
+		return string(plain), "!!float", nil
+	case "bool":
+		return string(plain), "!!bool", nil
+	}
+	return string(plain), "!!str", nil
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+)
+
+// ageEncrypt encrypts data to the identity's recipient as an armored age file
+func ageEncrypt(t *testing.T, identity *age.X25519Identity, data []byte) string {
+	var out bytes.Buffer
+	armored := armor.NewWriter(&out)
+	w, err := age.Encrypt(armored, identity.Recipient())
+	if err != nil {
+		t.Fatalf("Failed to encrypt: %v", err)
+	}
+	w.Write(data)
+	w.Close()
+	armored.Close()
+	return out.String()
+}
+
+// sopsEncrypt encrypts a value the way sops does, authenticated with its key path
+func sopsEncrypt(t *testing.T, key []byte, value, valueType, path string) string {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		t.Fatalf("Failed to create cipher: %v", err)
+	}
+	gcm, err := cipher.NewGCMWithNonceSize(block, 32)
+	if err != nil {
+		t.Fatalf("Failed to create GCM: %v", err)
+	}
+	iv := make([]byte, 32)
+	io.ReadFull(rand.Reader, iv)
+	sealed := gcm.Seal(nil, iv, []byte(value), []byte(path))
+	data, tag := sealed[:len(sealed)-gcm.Overhead()], sealed[len(sealed)-gcm.Overhead():]
+	return fmt.Sprintf("ENC[AES256_GCM,data:%s,iv:%s,tag:%s,type:%s]", base64.StdEncoding.EncodeToString(data),
+		base64.StdEncoding.EncodeToString(iv), base64.StdEncoding.EncodeToString(tag), valueType)
+}
+
+func TestLoadConfigAgeEncrypted(t *testing.T) {
+	identity, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	t.Setenv("SOPS_AGE_KEY", identity.String())
+
+	configPath := filepath.Join(t.TempDir(), "rules.toml.age")
+	content := ageEncrypt(t, identity, []byte("miniflux_url = \"https://miniflux.example.com\"\ninterval = 300\n"))
+	if err := os.WriteFile(configPath, []byte(content), 0600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	config, err := LoadConfig(configPath)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if config.MinifluxURL != "https://miniflux.example.com" || config.Interval != 300 {
+		t.Errorf("Unexpected config %+v", config)
+	}
+}
+
+func TestLoadConfigSOPSEncrypted(t *testing.T) {
+	identity, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	dataKey := make([]byte, 32)
+	io.ReadFull(rand.Reader, dataKey)
+
+	enc := ageEncrypt(t, identity, dataKey)
+	content := fmt.Sprintf(`miniflux_url: %s
+interval: %s
+webhooks:
+    chat:
+        url: %s
+rules:
+    - name: %s
+      title: %s
+      action: %s
+sops:
+    age:
+        - recipient: %s
+          enc: |
+%s
+    lastmodified: "2026-10-01T12:00:00Z"
+    mac: ENC[AES256_GCM,data:AAAA,iv:AAAA,tag:AAAA,type:str]
+    unencrypted_suffix: _unencrypted
+    version: 3.9.4
+`,
+		sopsEncrypt(t, dataKey, "https://miniflux.example.com", "str", "miniflux_url:"),
+		sopsEncrypt(t, dataKey, "300", "int", "interval:"),
+		sopsEncrypt(t, dataKey, "https://hooks.example.com/s3cret", "str", "webhooks:chat:url:"),
+		sopsEncrypt(t, dataKey, "Promos", "str", "rules:name:"),
+		sopsEncrypt(t, dataKey, "Promo", "str", "rules:title:"),
+		sopsEncrypt(t, dataKey, "read", "str", "rules:action:"),
+		identity.Recipient(),
+		"            "+strings.ReplaceAll(strings.TrimSpace(enc), "\n", "\n            "),
+	)
+	configPath := filepath.Join(t.TempDir(), "rules.enc.yaml")
+	if err := os.WriteFile(configPath, []byte(content), 0600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	t.Setenv("SOPS_AGE_KEY", identity.String())
+	config, err := LoadConfig(configPath)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if config.MinifluxURL != "https://miniflux.example.com" || config.Interval != 300 {
+		t.Errorf("Expected the settings to be decrypted, got %q and %d", config.MinifluxURL, config.Interval)
+	}
+	if got := config.Webhooks["chat"].URL; got != "https://hooks.example.com/s3cret" {
+		t.Errorf("Expected the webhook URL to be decrypted, got %q", got)
+	}
+	if len(config.Rules) != 1 || config.Rules[0].Title != "Promo" {
+		t.Errorf("Expected the rule to be decrypted, got %+v", config.Rules)
+	}
+
+	other, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	t.Setenv("SOPS_AGE_KEY", other.String())
+	if _, err := LoadConfig(configPath); err == nil || !strings.Contains(err.Error(), "no age key matches") {
+		t.Errorf("Expected another key to be rejected, got %v", err)
+	}
+}
@@ -0,0 +1,219 @@
+package main
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"time"
+)
+
+// defaultReleasesURL is the GitHub API endpoint of the latest release
+const defaultReleasesURL = "https://api.github.com/repos/iamwehi/miniflux-jobs/releases/latest"
+
+// checksumsAsset lists the SHA-256 of every binary in a release, in sha256sum format
+const checksumsAsset = "checksums.txt"
+
+// release is the subset of a GitHub release needed to update
+type release struct {
+	TagName string         `json:"tag_name"`
+	Assets  []releaseAsset `json:"assets"`
+}
+
+// releaseAsset is a file attached to a release
+type releaseAsset struct {
+	Name string `json:"name"`
+	URL  string `json:"browser_download_url"`
+}
+
+// assetURL returns the download URL of the named asset
+func (r release) assetURL(name string) (string, bool) {
+	for _, asset := range r.Assets {
+		if asset.Name == name {
+			return asset.URL, true
+		}
+	}
+	return "", false
+}
+
+// binaryAssetName returns the release asset built for this platform, e.g. miniflux-jobs-linux-armv7
+func binaryAssetName() string {
+	arch := runtime.GOARCH
+	if arch == "arm" {
+		goarm := "6"
+		if info, ok := debug.ReadBuildInfo(); ok {
+			for _, setting := range info.Settings {
+				if setting.Key == "GOARM" && setting.Value != "" {
+					goarm = setting.Value
+				}
+			}
+		}
+		arch = "armv" + strings.TrimSuffix(goarm, ",softfloat")
+	}
+	return fmt.Sprintf("miniflux-jobs-%s-%s", runtime.GOOS, arch)
+}
+
+// fetchRelease reads the latest release description
+func fetchRelease(client *http.Client, url string) (release, error) {
+	var rel release
+	resp, err := client.Get(url)
+	if err != nil {
+		return rel, fmt.Errorf("failed to fetch release: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return rel, fmt.Errorf("failed to fetch release: status %d", resp.StatusCode)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&rel); err != nil {
+		return rel, fmt.Errorf("invalid release response: %w", err)
+	}
+	return rel, nil
+}
+
+// expectedChecksum looks up an asset's SHA-256 in the release checksums file
+func expectedChecksum(client *http.Client, url, name string) (string, error) {
+	resp, err := client.Get(url)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch checksums: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to fetch checksums: status %d", resp.StatusCode)
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 2 && strings.TrimPrefix(fields[1], "*") == name {
+			return strings.ToLower(fields[0]), nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", fmt.Errorf("failed to read checksums: %w", err)
+	}
+	return "", fmt.Errorf("no checksum for %s", name)
+}
+
+// downloadBinary writes the asset next to path and verifies its checksum
+// It returns the path of the verified temporary file
+func downloadBinary(client *http.Client, url, path, checksum string) (string, error) {
+	resp, err := client.Get(url)
+	if err != nil {
+		return "", fmt.Errorf("failed to download binary: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to download binary: status %d", resp.StatusCode)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".miniflux-jobs-update-*")
+	if err != nil {
+		return "", fmt.Errorf("failed to create update file: %w", err)
+	}
+
+	hash := sha256.New()
+	_, err = io.Copy(io.MultiWriter(tmp, hash), resp.Body)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return "", fmt.Errorf("failed to download binary: %w", err)
+	}
+
+	if sum := hex.EncodeToString(hash.Sum(nil)); sum != checksum {
+		os.Remove(tmp.Name())
+		return "", fmt.Errorf("checksum mismatch: expected %s, got %s", checksum, sum)
+	}
+	return tmp.Name(), nil
+}
+
+// selfUpdate replaces the binary at path with the latest release for this platform
+// It returns the release tag, and whether the binary was replaced
+func selfUpdate(client *http.Client, releasesURL, path string, checkOnly bool) (string, bool, error) {
+	rel, err := fetchRelease(client, releasesURL)
+	if err != nil {
+		return "", false, err
+	}
+	if rel.TagName == version || checkOnly {
+		return rel.TagName, false, nil
+	}
+
+	name := binaryAssetName()
+	binaryURL, ok := rel.assetURL(name)
+	if !ok {
+		return rel.TagName, false, fmt.Errorf("release %s has no binary %s", rel.TagName, name)
+	}
+	checksumsURL, ok := rel.assetURL(checksumsAsset)
+	if !ok {
+		return rel.TagName, false, fmt.Errorf("release %s has no %s", rel.TagName, checksumsAsset)
+	}
+
+	checksum, err := expectedChecksum(client, checksumsURL, name)
+	if err != nil {
+		return rel.TagName, false, err
+	}
+	tmp, err := downloadBinary(client, binaryURL, path, checksum)
+	if err != nil {
+		return rel.TagName, false, err
+	}
+
+	// Keep the mode of the current binary so it stays executable
+	mode := os.FileMode(0755)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+	if err := os.Chmod(tmp, mode); err != nil {
+		os.Remove(tmp)
+		return rel.TagName, false, fmt.Errorf("failed to set permissions: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return rel.TagName, false, fmt.Errorf("failed to replace binary: %w", err)
+	}
+	return rel.TagName, true, nil
+}
+
+// selfUpdateCommand runs the self-update subcommand
+func selfUpdateCommand(args []string) {
+	flags := flag.NewFlagSet("self-update", flag.ExitOnError)
+	checkOnly := flags.Bool("check", false, "Only report whether a newer release is available")
+	flags.Parse(args)
+
+	logger := commandLogger(os.Stdout)
+
+	path, err := os.Executable()
+	if err != nil {
+		fatal(logger, "Failed to locate binary", "error", err)
+	}
+	if path, err = filepath.EvalSymlinks(path); err != nil {
+		fatal(logger, "Failed to locate binary", "error", err)
+	}
+
+	client := &http.Client{Timeout: 5 * time.Minute}
+	tag, updated, err := selfUpdate(client, defaultReleasesURL, path, *checkOnly)
+	if err != nil {
+		fatal(logger, "Self-update failed", "error", err)
+	}
+
+	switch {
+	case tag == version:
+		logger.Info("Already running the latest release", "version", version)
+	case updated:
+		logger.Info("Updated", "path", path, "from", version, "to", tag)
+	default:
+		logger.Info("Release available", "release", tag, "running", version)
+	}
+}
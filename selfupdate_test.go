@@ -0,0 +1,105 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// releaseServer serves a release whose binary has the given content and listed checksum
+func releaseServer(t *testing.T, tag, binary, checksum string) *httptest.Server {
+	t.Helper()
+	name := binaryAssetName()
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/latest":
+			fmt.Fprintf(w, `{"tag_name": %q, "assets": [
+				{"name": %q, "browser_download_url": "%s/binary"},
+				{"name": "checksums.txt", "browser_download_url": "%s/checksums"}
+			]}`, tag, name, server.URL, server.URL)
+		case "/binary":
+			fmt.Fprint(w, binary)
+		case "/checksums":
+			fmt.Fprintf(w, "%s  miniflux-jobs-other-arch\n%s  %s\n", strings.Repeat("0", 64), checksum, name)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestSelfUpdate(t *testing.T) {
+	sum := sha256.Sum256([]byte("new binary"))
+	server := releaseServer(t, "v9.9.9", "new binary", hex.EncodeToString(sum[:]))
+
+	path := filepath.Join(t.TempDir(), "miniflux-jobs")
+	if err := os.WriteFile(path, []byte("old binary"), 0750); err != nil {
+		t.Fatalf("Failed to write binary: %v", err)
+	}
+
+	tag, updated, err := selfUpdate(server.Client(), server.URL+"/latest", path, false)
+	if err != nil {
+		t.Fatalf("selfUpdate failed: %v", err)
+	}
+	if tag != "v9.9.9" || !updated {
+		t.Errorf("Expected update to v9.9.9, got %s (updated %v)", tag, updated)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read binary: %v", err)
+	}
+	if string(data) != "new binary" {
+		t.Errorf("Expected the binary to be replaced, got %q", data)
+	}
+	if info, _ := os.Stat(path); info.Mode().Perm() != 0750 {
+		t.Errorf("Expected mode 0750 to be kept, got %v", info.Mode().Perm())
+	}
+}
+
+func TestSelfUpdateChecksumMismatch(t *testing.T) {
+	server := releaseServer(t, "v9.9.9", "tampered binary", strings.Repeat("a", 64))
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "miniflux-jobs")
+	if err := os.WriteFile(path, []byte("old binary"), 0755); err != nil {
+		t.Fatalf("Failed to write binary: %v", err)
+	}
+
+	_, updated, err := selfUpdate(server.Client(), server.URL+"/latest", path, false)
+	if err == nil || !strings.Contains(err.Error(), "checksum mismatch") || updated {
+		t.Fatalf("Expected a checksum mismatch, got %v (updated %v)", err, updated)
+	}
+
+	data, _ := os.ReadFile(path)
+	if string(data) != "old binary" {
+		t.Errorf("Expected the binary to be kept, got %q", data)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+		t.Errorf("Expected the temporary download to be removed, got %d files", len(entries))
+	}
+}
+
+func TestSelfUpdateCurrentOrCheckOnly(t *testing.T) {
+	server := releaseServer(t, version, "same binary", strings.Repeat("a", 64))
+	path := filepath.Join(t.TempDir(), "miniflux-jobs")
+
+	tag, updated, err := selfUpdate(server.Client(), server.URL+"/latest", path, false)
+	if err != nil || tag != version || updated {
+		t.Errorf("Expected no update when already current, got %s, %v, %v", tag, updated, err)
+	}
+
+	server = releaseServer(t, "v9.9.9", "new binary", strings.Repeat("a", 64))
+	tag, updated, err = selfUpdate(server.Client(), server.URL+"/latest", path, true)
+	if err != nil || tag != "v9.9.9" || updated {
+		t.Errorf("Expected -check to only report v9.9.9, got %s, %v, %v", tag, updated, err)
+	}
+}
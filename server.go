@@ -0,0 +1,126 @@
+package main
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	miniflux "miniflux.app/v2/client"
+)
+
+// maxEvaluateBodySize limits the size of entries submitted for evaluation
+const maxEvaluateBodySize = 4 << 20
+
+// Server exposes the processor over HTTP in serve mode
+type Server struct {
+	processor *Processor
+	logger    *slog.Logger
+
+	webhookSecret string    // verifies webhooks posted by Miniflux (empty = endpoint disabled)
+	admin         *adminAPI // optional admin routes of loop mode
+	health        *healthCheck
+	instances     []*instance // accounts whose metrics are served (default: the processor's)
+}
+
+// NewServer creates a Server for the given processor
+func NewServer(processor *Processor, logger *slog.Logger) *Server {
+	return &Server{processor: processor, logger: logger}
+}
+
+// SetWebhookSecret enables POST /miniflux/webhook for Miniflux webhooks signed with the secret
+func (s *Server) SetWebhookSecret(secret string) {
+	s.webhookSecret = secret
+}
+
+// SetInstances serves the metrics of every account the daemon maintains
+func (s *Server) SetInstances(instances []*instance) {
+	s.instances = instances
+}
+
+// SetHealth serves the liveness and readiness probes
+func (s *Server) SetHealth(health *healthCheck) {
+	s.health = health
+}
+
+// SetAdmin serves the admin API along with the other routes
+func (s *Server) SetAdmin(admin *adminAPI) {
+	s.admin = admin
+}
+
+// Handler returns the HTTP routes served by the daemon
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /evaluate", s.handleEvaluate)
+	mux.HandleFunc("GET /metrics", s.handleMetrics)
+	if s.webhookSecret != "" {
+		mux.HandleFunc("POST /miniflux/webhook", s.handleMinifluxWebhook)
+	}
+	if s.admin != nil {
+		s.admin.register(mux)
+	}
+	if s.health != nil {
+		s.health.register(mux)
+	}
+	return mux
+}
+
+// EvaluateResponse is the match decision returned by POST /evaluate
+type EvaluateResponse struct {
+	Matched bool            `json:"matched"`
+	Rules   []EvaluatedRule `json:"rules"`
+}
+
+// EvaluatedRule describes a rule matching an evaluated entry
+type EvaluatedRule struct {
+	Name   string   `json:"name"`
+	Action string   `json:"action"`
+	Steps  []string `json:"steps"` // primitive actions the rule would perform
+}
+
+// handleEvaluate matches a Miniflux entry JSON against the rules without applying anything
+func (s *Server) handleEvaluate(w http.ResponseWriter, r *http.Request) {
+	var entry miniflux.Entry
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxEvaluateBodySize)).Decode(&entry); err != nil {
+		http.Error(w, "invalid entry JSON: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	response := EvaluateResponse{Rules: []EvaluatedRule{}}
+	for _, evaluation := range s.processor.Evaluate(&entry) {
+		response.Matched = true
+		response.Rules = append(response.Rules, EvaluatedRule{
+			Name:   evaluation.Result.Rule.Name,
+			Action: evaluation.Result.Action,
+			Steps:  evaluation.Steps,
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		s.logger.Error("Failed to write evaluate response", "error", err)
+	}
+}
+
+// MetricsHandler serves only the Prometheus metrics, for metrics_listen
+func (s *Server) MetricsHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /metrics", s.handleMetrics)
+	return mux
+}
+
+// writeJSONResponse writes a JSON response with the given status
+func writeJSONResponse(w http.ResponseWriter, status int, value any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(value)
+}
+
+// handleMetrics serves run, match, action and Miniflux API error metrics for Prometheus
+func (s *Server) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	instances := s.instances
+	if instances == nil {
+		instances = []*instance{{processor: s.processor}}
+	}
+	writeMetrics(w, instances)
+}
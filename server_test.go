@@ -0,0 +1,86 @@
+package main
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestServerEvaluate(t *testing.T) {
+	mockClient := &MockClient{}
+	matcher, err := NewMatcher([]Rule{
+		{Name: "Sponsored", Title: "(?i)sponsored", Action: "read_later"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create matcher: %v", err)
+	}
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	processor := NewProcessor(mockClient, matcher, logger, false)
+	handler := NewServer(processor, logger).Handler()
+
+	body := `{"id": 1, "title": "Sponsored: buy now", "feed": {"title": "News"}}`
+	req := httptest.NewRequest(http.MethodPost, "/evaluate", strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	var response EvaluateResponse
+	if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if !response.Matched || len(response.Rules) != 1 || response.Rules[0].Name != "Sponsored" {
+		t.Errorf("Unexpected response: %+v", response)
+	}
+	if steps := response.Rules[0].Steps; len(steps) != 2 || steps[0] != "star" {
+		t.Errorf("Expected read_later steps, got %v", steps)
+	}
+	if len(mockClient.updatedIDs) != 0 || len(mockClient.starredIDs) != 0 {
+		t.Error("Expected evaluation not to apply any action")
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/evaluate", strings.NewReader("not json"))
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for invalid JSON, got %d", rec.Code)
+	}
+}
+
+func TestServerEvaluateAfterReload(t *testing.T) {
+	matcher, err := NewMatcher(nil)
+	if err != nil {
+		t.Fatalf("Failed to create matcher: %v", err)
+	}
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	processor := NewProcessor(&MockClient{}, matcher, logger, false)
+	handler := NewServer(processor, logger).Handler()
+
+	// Rules and macros are swapped together, so steps come from the reloaded config
+	reloaded, err := NewMatcher([]Rule{{Name: "Sponsored", Title: "(?i)sponsored", Action: "bury"}})
+	if err != nil {
+		t.Fatalf("Failed to create matcher: %v", err)
+	}
+	processor.Reload(reloaded, ProcessorOptions{Macros: map[string][]string{"bury": {"read", "unstar"}}})
+
+	body := `{"id": 1, "title": "Sponsored: buy now", "feed": {"title": "News"}}`
+	req := httptest.NewRequest(http.MethodPost, "/evaluate", strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	var response EvaluateResponse
+	if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if len(response.Rules) != 1 || strings.Join(response.Rules[0].Steps, ",") != "read,unstar" {
+		t.Errorf("Expected the reloaded macro's steps, got %+v", response)
+	}
+}
@@ -0,0 +1,473 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// seenRetention is how long entries stay in the seen-cache after last being processed
+const seenRetention = 30 * 24 * time.Hour
+
+// State holds data persisted between processing runs
+type State struct {
+	ConsumedRules map[string]time.Time `json:"consumed_rules,omitempty"`
+	Seen          map[int64]SeenEntry  `json:"seen,omitempty"`
+	SeenKeys      map[string]SeenKey   `json:"seen_keys,omitempty"` // normalized titles, URLs and content hashes
+
+	// FeedActivity maps feed ID to entry ID to what happened when the entry was first processed
+	FeedActivity    map[int64]map[int64]FeedActivity `json:"feed_activity,omitempty"`
+	AggregateAlerts map[string]time.Time             `json:"aggregate_alerts,omitempty"`
+	LastReadReport  time.Time                        `json:"last_read_report,omitzero"`
+
+	LastFlushHistory time.Time `json:"last_flush_history,omitzero"`
+
+	// LastEvaluatedID is the newest entry evaluated by skip_evaluated runs under EvaluatedConfigHash,
+	// and LastEvaluatedAt when the last of those or fetch_since runs started
+	LastEvaluatedID     int64     `json:"last_evaluated_id,omitempty"`
+	LastEvaluatedAt     time.Time `json:"last_evaluated_at,omitzero"`
+	EvaluatedConfigHash string    `json:"evaluated_config_hash,omitempty"`
+
+	// ResumeAfterID is the last entry handled by a run stopped by max_entries_per_run
+	ResumeAfterID int64 `json:"resume_after_id,omitempty"`
+
+	// Notifications maps hashes of notifications already sent to when they were sent
+	Notifications map[string]time.Time `json:"notifications,omitempty"`
+
+	// Pending maps delayed rule matches, keyed by pendingKey, to when they become due
+	Pending map[string]time.Time `json:"pending,omitempty"`
+
+	// Exceptions maps rule names to entries learned from manual overrides that the rule must skip
+	Exceptions map[string][]RuleException `json:"exceptions,omitempty"`
+
+	// Actions lists state-changing actions applied to entries, oldest first
+	Actions []AppliedAction `json:"actions,omitempty"`
+
+	// Quarantined maps entries marked read instead of removed in quarantine mode to when and why
+	Quarantined map[int64]QuarantinedEntry `json:"quarantined,omitempty"`
+
+	// Startups lists process starts not yet followed by a clean exit
+	Startups []time.Time `json:"startups,omitempty"`
+
+	store StateStore
+}
+
+// SeenEntry records what an entry looked like when it was last processed
+type SeenEntry struct {
+	ContentHash string    `json:"content_hash"`
+	LastSeen    time.Time `json:"last_seen"`
+}
+
+// SeenKey records which entry last carried a normalized title or URL
+type SeenKey struct {
+	EntryID  int64     `json:"entry_id"`
+	LastSeen time.Time `json:"last_seen"`
+}
+
+// AppliedAction records an action a rule applied to an entry, so it can be reprocessed
+type AppliedAction struct {
+	EntryID        int64     `json:"entry_id"`
+	Rule           string    `json:"rule"`
+	Action         string    `json:"action"`
+	PreviousStatus string    `json:"previous_status,omitempty"`
+	PreviousTitle  string    `json:"previous_title,omitempty"` // set for rewrite_title
+	At             time.Time `json:"at"`
+	Run            time.Time `json:"run,omitzero"` // start of the run that applied the action
+}
+
+// QuarantinedEntry records a remove action held back by quarantine mode until purged
+type QuarantinedEntry struct {
+	Rule string    `json:"rule"`
+	At   time.Time `json:"at"`
+}
+
+// RuleException stops a rule from matching an entry URL or author again
+type RuleException struct {
+	URL     string    `json:"url,omitempty" yaml:"url,omitempty"` // canonical entry URL
+	Author  string    `json:"author,omitempty" yaml:"author,omitempty"`
+	EntryID int64     `json:"entry_id" yaml:"entry_id"` // entry whose override taught the exception
+	Added   time.Time `json:"added" yaml:"added"`
+}
+
+// FeedActivity records the first processing of an entry for aggregate rules
+type FeedActivity struct {
+	At   time.Time `json:"at"`
+	Rule string    `json:"rule,omitempty"` // matching rule, empty when nothing matched
+}
+
+// LoadState reads the state file at the given path
+// A missing file yields an empty state that will be created on first Save
+func LoadState(path string) (*State, error) {
+	return LoadStateFrom(NewFileStore(path))
+}
+
+// LoadStateFrom reads state from the given store
+// A store without saved state yields an empty state
+func LoadStateFrom(store StateStore) (*State, error) {
+	state := &State{store: store}
+
+	data, err := store.Load()
+	if err != nil {
+		return nil, err
+	}
+	if data != nil {
+		if err := json.Unmarshal(data, state); err != nil {
+			return nil, fmt.Errorf("failed to parse state: %w", err)
+		}
+	}
+	state.init()
+
+	return state, nil
+}
+
+// Reload replaces the state with the latest saved copy, e.g. after another replica wrote it
+func (s *State) Reload() error {
+	fresh, err := LoadStateFrom(s.store)
+	if err != nil {
+		return err
+	}
+	*s = *fresh
+	return nil
+}
+
+// init ensures all maps are allocated
+func (s *State) init() {
+	if s.ConsumedRules == nil {
+		s.ConsumedRules = make(map[string]time.Time)
+	}
+	if s.Seen == nil {
+		s.Seen = make(map[int64]SeenEntry)
+	}
+	if s.SeenKeys == nil {
+		s.SeenKeys = make(map[string]SeenKey)
+	}
+	if s.FeedActivity == nil {
+		s.FeedActivity = make(map[int64]map[int64]FeedActivity)
+	}
+	if s.AggregateAlerts == nil {
+		s.AggregateAlerts = make(map[string]time.Time)
+	}
+	if s.Notifications == nil {
+		s.Notifications = make(map[string]time.Time)
+	}
+	if s.Exceptions == nil {
+		s.Exceptions = make(map[string][]RuleException)
+	}
+	if s.Pending == nil {
+		s.Pending = make(map[string]time.Time)
+	}
+	if s.Quarantined == nil {
+		s.Quarantined = make(map[int64]QuarantinedEntry)
+	}
+}
+
+// Save writes the state to its store
+func (s *State) Save() error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode state: %w", err)
+	}
+	return s.store.Save(data)
+}
+
+// IsConsumed reports whether a one-off rule has already been applied
+func (s *State) IsConsumed(ruleName string) bool {
+	_, ok := s.ConsumedRules[ruleName]
+	return ok
+}
+
+// MarkConsumed records that a one-off rule has been applied
+func (s *State) MarkConsumed(ruleName string, at time.Time) {
+	s.ConsumedRules[ruleName] = at
+}
+
+// ContentChanged reports whether a previously seen entry now has a different content hash
+// Entries not in the seen-cache are reported as unchanged
+func (s *State) ContentChanged(entryID int64, hash string) bool {
+	seen, ok := s.Seen[entryID]
+	return ok && seen.ContentHash != hash
+}
+
+// RecordSeen stores the entry's current content hash in the seen-cache
+func (s *State) RecordSeen(entryID int64, hash string, at time.Time) {
+	s.Seen[entryID] = SeenEntry{ContentHash: hash, LastSeen: at}
+}
+
+// SeenBefore reports whether another entry was already processed with the same title or URL
+func (s *State) SeenBefore(entryID int64, title, url string) bool {
+	for _, key := range seenKeys(title, url) {
+		if seen, ok := s.SeenKeys[key]; ok && seen.EntryID != entryID {
+			return true
+		}
+	}
+	return false
+}
+
+// RecordSeenKeys stores the entry's normalized title and URL
+// The first entry to carry a key keeps it so reposts keep matching
+func (s *State) RecordSeenKeys(entryID int64, title, url string, at time.Time) {
+	for _, key := range seenKeys(title, url) {
+		s.recordSeenKey(key, entryID, at)
+	}
+}
+
+// DuplicateContent reports whether another entry was already processed with the same normalized content
+func (s *State) DuplicateContent(entryID int64, hash string) bool {
+	seen, ok := s.SeenKeys["content:"+hash]
+	return ok && seen.EntryID != entryID
+}
+
+// RecordContentKey stores the entry's normalized content hash
+func (s *State) RecordContentKey(entryID int64, hash string, at time.Time) {
+	s.recordSeenKey("content:"+hash, entryID, at)
+}
+
+// recordSeenKey refreshes a seen-cache key, keeping the entry that first carried it
+func (s *State) recordSeenKey(key string, entryID int64, at time.Time) {
+	seen, ok := s.SeenKeys[key]
+	if !ok {
+		seen.EntryID = entryID
+	}
+	seen.LastSeen = at
+	s.SeenKeys[key] = seen
+}
+
+// PruneSeen drops seen-cache records not refreshed since the cutoff
+func (s *State) PruneSeen(cutoff time.Time) {
+	for id, seen := range s.Seen {
+		if seen.LastSeen.Before(cutoff) {
+			delete(s.Seen, id)
+		}
+	}
+	for key, seen := range s.SeenKeys {
+		if seen.LastSeen.Before(cutoff) {
+			delete(s.SeenKeys, key)
+		}
+	}
+}
+
+// seenKeys returns the seen-cache keys for an entry title and URL
+func seenKeys(title, url string) []string {
+	var keys []string
+	if title = strings.Join(strings.Fields(strings.ToLower(title)), " "); title != "" {
+		keys = append(keys, "title:"+title)
+	}
+	if url != "" {
+		keys = append(keys, "url:"+url)
+	}
+	return keys
+}
+
+// RecordFeedActivity stores the first match outcome of an entry in its feed
+func (s *State) RecordFeedActivity(feedID, entryID int64, rule string, at time.Time) {
+	entries, ok := s.FeedActivity[feedID]
+	if !ok {
+		entries = make(map[int64]FeedActivity)
+		s.FeedActivity[feedID] = entries
+	}
+	if _, seen := entries[entryID]; !seen {
+		entries[entryID] = FeedActivity{At: at, Rule: rule}
+	}
+}
+
+// PruneFeedActivity drops activity older than the cutoff
+func (s *State) PruneFeedActivity(cutoff time.Time) {
+	for feedID, entries := range s.FeedActivity {
+		for entryID, activity := range entries {
+			if activity.At.Before(cutoff) {
+				delete(entries, entryID)
+			}
+		}
+		if len(entries) == 0 {
+			delete(s.FeedActivity, feedID)
+		}
+	}
+}
+
+// NotificationSent reports whether a notification with the given hash was already sent
+func (s *State) NotificationSent(hash string) bool {
+	_, ok := s.Notifications[hash]
+	return ok
+}
+
+// RecordNotification stores the hash of a sent notification
+func (s *State) RecordNotification(hash string, at time.Time) {
+	s.Notifications[hash] = at
+}
+
+// PruneNotifications drops notification hashes recorded before the cutoff
+func (s *State) PruneNotifications(cutoff time.Time) {
+	for hash, at := range s.Notifications {
+		if at.Before(cutoff) {
+			delete(s.Notifications, hash)
+		}
+	}
+}
+
+// AddException records that rule must no longer match the exception's URL or author
+func (s *State) AddException(rule string, exception RuleException) {
+	for _, existing := range s.Exceptions[rule] {
+		if existing.URL == exception.URL && existing.Author == exception.Author {
+			return
+		}
+	}
+	s.Exceptions[rule] = append(s.Exceptions[rule], exception)
+}
+
+// IsException reports whether rule learned to skip the canonical URL or author
+func (s *State) IsException(rule, url, author string) bool {
+	for _, exception := range s.Exceptions[rule] {
+		if (exception.URL != "" && exception.URL == url) || (exception.Author != "" && strings.EqualFold(exception.Author, author)) {
+			return true
+		}
+	}
+	return false
+}
+
+// PendingDue returns when a delayed match of the entry by rule becomes due
+func (s *State) PendingDue(entryID int64, rule string) (time.Time, bool) {
+	due, ok := s.Pending[pendingKey(entryID, rule)]
+	return due, ok
+}
+
+// AddPending queues a delayed match until due
+func (s *State) AddPending(entryID int64, rule string, due time.Time) {
+	s.Pending[pendingKey(entryID, rule)] = due
+}
+
+// RemovePending drops a delayed match once it has been handled
+func (s *State) RemovePending(entryID int64, rule string) {
+	delete(s.Pending, pendingKey(entryID, rule))
+}
+
+// PrunePending drops delayed matches that became due before the cutoff,
+// typically entries read before their delay elapsed and no longer fetched
+func (s *State) PrunePending(cutoff time.Time) {
+	for key, due := range s.Pending {
+		if due.Before(cutoff) {
+			delete(s.Pending, key)
+		}
+	}
+}
+
+// pendingKey identifies a delayed match of an entry by a rule
+func pendingKey(entryID int64, rule string) string {
+	return fmt.Sprintf("%d:%s", entryID, rule)
+}
+
+// RecordAction appends an applied action to the history
+func (s *State) RecordAction(action AppliedAction) {
+	s.Actions = append(s.Actions, action)
+}
+
+// LastAction returns the most recent recorded action on the entry
+func (s *State) LastAction(entryID int64) (AppliedAction, bool) {
+	for i := len(s.Actions) - 1; i >= 0; i-- {
+		if s.Actions[i].EntryID == entryID {
+			return s.Actions[i], true
+		}
+	}
+	return AppliedAction{}, false
+}
+
+// ActionsSince returns actions applied at or after since, by the named rule or by any rule if empty
+func (s *State) ActionsSince(rule string, since time.Time) []AppliedAction {
+	var actions []AppliedAction
+	for _, action := range s.Actions {
+		if action.At.Before(since) || (rule != "" && action.Rule != rule) {
+			continue
+		}
+		actions = append(actions, action)
+	}
+	return actions
+}
+
+// LastRun returns the start of the most recent run that applied an action, or zero if none did
+func (s *State) LastRun() time.Time {
+	var last time.Time
+	for _, action := range s.Actions {
+		if action.Run.After(last) {
+			last = action.Run
+		}
+	}
+	return last
+}
+
+// ForgetActions drops the entry's actions applied at or after since, by the named rule or
+// by any rule if empty
+func (s *State) ForgetActions(entryID int64, rule string, since time.Time) {
+	s.Actions = slices.DeleteFunc(s.Actions, func(action AppliedAction) bool {
+		return action.EntryID == entryID && !action.At.Before(since) && (rule == "" || action.Rule == rule)
+	})
+}
+
+// PruneActions drops actions applied before the cutoff
+func (s *State) PruneActions(cutoff time.Time) {
+	s.Actions = slices.DeleteFunc(s.Actions, func(action AppliedAction) bool {
+		return action.At.Before(cutoff)
+	})
+}
+
+// Quarantine records an entry marked read in place of a removal
+func (s *State) Quarantine(entryID int64, entry QuarantinedEntry) {
+	s.Quarantined[entryID] = entry
+}
+
+// Release forgets a quarantined entry, once purged or restored
+func (s *State) Release(entryID int64) {
+	delete(s.Quarantined, entryID)
+}
+
+// QuarantinedBefore returns the entries quarantined before the cutoff, in ID order
+func (s *State) QuarantinedBefore(cutoff time.Time) []int64 {
+	var ids []int64
+	for id, entry := range s.Quarantined {
+		if entry.At.Before(cutoff) {
+			ids = append(ids, id)
+		}
+	}
+	slices.Sort(ids)
+	return ids
+}
+
+// notificationHash identifies a notification by its kind, entry and payload
+func notificationHash(kind string, entryID int64, payload string) string {
+	return contentHash(fmt.Sprintf("%s\x00%d\x00%s", kind, entryID, payload))
+}
+
+// RecordStartup records a process start and returns the unclean starts since the cutoff, including this one
+func (s *State) RecordStartup(at, cutoff time.Time) int {
+	startups := s.Startups[:0]
+	for _, startup := range s.Startups {
+		if !startup.Before(cutoff) {
+			startups = append(startups, startup)
+		}
+	}
+	s.Startups = append(startups, at)
+	return len(s.Startups)
+}
+
+// MarkCleanExit forgets recorded startups after an orderly shutdown
+func (s *State) MarkCleanExit() {
+	s.Startups = nil
+}
+
+// contentHash returns a stable hex digest of entry content
+func contentHash(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+// normalizedContentHash hashes the entry's plain text, ignoring markup, case and whitespace
+// It returns "" for entries without text so empty bodies are never duplicates
+func normalizedContentHash(content string) string {
+	text := strings.ToLower(stripHTML(content))
+	if text == "" {
+		return ""
+	}
+	return contentHash(text)
+}
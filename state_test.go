@@ -0,0 +1,88 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLoadStateMissingFile(t *testing.T) {
+	state, err := LoadState(filepath.Join(t.TempDir(), "state.json"))
+	if err != nil {
+		t.Fatalf("Failed to load state: %v", err)
+	}
+	if state.IsConsumed("anything") {
+		t.Error("Expected empty state to have no consumed rules")
+	}
+}
+
+func TestStateSaveAndReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+
+	state, err := LoadState(path)
+	if err != nil {
+		t.Fatalf("Failed to load state: %v", err)
+	}
+	state.MarkConsumed("Purge old feed", time.Now())
+	if err := state.Save(); err != nil {
+		t.Fatalf("Failed to save state: %v", err)
+	}
+
+	reloaded, err := LoadState(path)
+	if err != nil {
+		t.Fatalf("Failed to reload state: %v", err)
+	}
+	if !reloaded.IsConsumed("Purge old feed") {
+		t.Error("Expected rule to be consumed after reload")
+	}
+}
+
+func TestStateSeenBefore(t *testing.T) {
+	state, err := LoadState(filepath.Join(t.TempDir(), "state.json"))
+	if err != nil {
+		t.Fatalf("Failed to load state: %v", err)
+	}
+
+	state.RecordSeenKeys(1, "Weekly  Recap: Item One", "https://example.com/one", time.Now())
+
+	testCases := []struct {
+		name     string
+		entryID  int64
+		title    string
+		url      string
+		expected bool
+	}{
+		{"same entry", 1, "Weekly Recap: Item One", "https://example.com/one", false},
+		{"repost by title", 2, "weekly recap: item one", "https://example.com/recap", true},
+		{"repost by url", 3, "Different title", "https://example.com/one", true},
+		{"new item", 4, "Item Two", "https://example.com/two", false},
+	}
+
+	for _, tc := range testCases {
+		if seen := state.SeenBefore(tc.entryID, tc.title, tc.url); seen != tc.expected {
+			t.Errorf("%s: expected seen=%v, got %v", tc.name, tc.expected, seen)
+		}
+	}
+}
+
+func TestStateDuplicateContent(t *testing.T) {
+	state, err := LoadState(filepath.Join(t.TempDir(), "state.json"))
+	if err != nil {
+		t.Fatalf("Failed to load state: %v", err)
+	}
+
+	state.RecordContentKey(1, normalizedContentHash("<p>Hello   <b>World</b></p>"), time.Now())
+
+	if state.DuplicateContent(1, normalizedContentHash("<p>Hello World</p>")) {
+		t.Error("Expected the original entry not to be its own duplicate")
+	}
+	if !state.DuplicateContent(2, normalizedContentHash("<div>hello world</div>")) {
+		t.Error("Expected mirrored content to be a duplicate")
+	}
+	if state.DuplicateContent(3, normalizedContentHash("<p>Something else</p>")) {
+		t.Error("Expected different content not to be a duplicate")
+	}
+	if normalizedContentHash("<img src=\"x.png\">") != "" {
+		t.Error("Expected content without text to have no hash")
+	}
+}
@@ -0,0 +1,288 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	bolt "go.etcd.io/bbolt"
+	_ "modernc.org/sqlite"
+)
+
+// Supported state backends
+const (
+	backendFile   = "file"
+	backendSQLite = "sqlite"
+	backendBolt   = "bbolt"
+	backendRedis  = "redis"
+)
+
+// defaultStateKey names the state document in key-value backends
+const defaultStateKey = "miniflux-jobs:state"
+
+// redisTimeout bounds each Redis round trip
+const redisTimeout = 10 * time.Second
+
+// StateBackendConfig selects where persistent state is stored
+type StateBackendConfig struct {
+	Type string `yaml:"type"` // "file", "sqlite", "bbolt" or "redis"
+	Path string `yaml:"path"` // database or file path for file, sqlite and bbolt
+	URL  string `yaml:"url"`  // connection URL for redis, e.g. redis://localhost:6379/0
+	Key  string `yaml:"key"`  // key or row holding the state (default miniflux-jobs:state)
+}
+
+// StateStore persists the encoded state document
+type StateStore interface {
+	Load() ([]byte, error) // returns nil data when nothing has been saved yet
+	Save(data []byte) error
+	Close() error
+}
+
+// OpenStateStore creates the store configured by state_backend, falling back to state_file
+func OpenStateStore(config *Config) (StateStore, error) {
+	backend := config.StateBackend
+	if backend.Type == "" {
+		return NewFileStore(config.StateFile), nil
+	}
+
+	key := backend.Key
+	if key == "" {
+		key = defaultStateKey
+	}
+
+	switch backend.Type {
+	case backendFile:
+		return NewFileStore(backend.Path), nil
+	case backendSQLite:
+		return NewSQLiteStore(backend.Path, key)
+	case backendBolt:
+		return NewBoltStore(backend.Path, key)
+	case backendRedis:
+		return NewRedisStore(backend.URL, key)
+	default:
+		return nil, fmt.Errorf("unknown state backend: %s", backend.Type)
+	}
+}
+
+// validate checks that the backend has the settings it needs
+func (b StateBackendConfig) validate() error {
+	switch b.Type {
+	case "":
+		return nil
+	case backendFile, backendSQLite, backendBolt:
+		if b.Path == "" {
+			return fmt.Errorf("state_backend %s requires path", b.Type)
+		}
+	case backendRedis:
+		if b.URL == "" {
+			return fmt.Errorf("state_backend redis requires url")
+		}
+	default:
+		return fmt.Errorf("unknown state_backend type: %s", b.Type)
+	}
+	return nil
+}
+
+// FileStore keeps state in a single JSON file
+type FileStore struct {
+	path string
+}
+
+// NewFileStore creates a store backed by the file at path
+func NewFileStore(path string) *FileStore {
+	return &FileStore{path: path}
+}
+
+// Load reads the state file, returning nil data if it does not exist
+func (f *FileStore) Load() ([]byte, error) {
+	data, err := os.ReadFile(f.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read state file: %w", err)
+	}
+	return data, nil
+}
+
+// Save writes the state file atomically
+func (f *FileStore) Save(data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(f.path), ".state-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary state file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write state file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write state file: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), f.path); err != nil {
+		return fmt.Errorf("failed to replace state file: %w", err)
+	}
+
+	return nil
+}
+
+// Close is a no-op for file stores
+func (f *FileStore) Close() error {
+	return nil
+}
+
+// SQLiteStore keeps state as a row in a SQLite database
+type SQLiteStore struct {
+	db  *sql.DB
+	key string
+}
+
+// NewSQLiteStore opens or creates the SQLite database at path
+func NewSQLiteStore(path, key string) (*SQLiteStore, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open sqlite state: %w", err)
+	}
+	if _, err := db.Exec(`CREATE TABLE IF NOT EXISTS state (key TEXT PRIMARY KEY, data BLOB NOT NULL)`); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create sqlite state table: %w", err)
+	}
+	return &SQLiteStore{db: db, key: key}, nil
+}
+
+// Load reads the state row, returning nil data if it does not exist
+func (s *SQLiteStore) Load() ([]byte, error) {
+	var data []byte
+	err := s.db.QueryRow(`SELECT data FROM state WHERE key = ?`, s.key).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read sqlite state: %w", err)
+	}
+	return data, nil
+}
+
+// Save replaces the state row
+func (s *SQLiteStore) Save(data []byte) error {
+	_, err := s.db.Exec(`INSERT INTO state (key, data) VALUES (?, ?) ON CONFLICT(key) DO UPDATE SET data = excluded.data`, s.key, data)
+	if err != nil {
+		return fmt.Errorf("failed to write sqlite state: %w", err)
+	}
+	return nil
+}
+
+// Close closes the database
+func (s *SQLiteStore) Close() error {
+	return s.db.Close()
+}
+
+// boltBucket holds the state document in bbolt databases
+var boltBucket = []byte("miniflux-jobs")
+
+// BoltStore keeps state as a key in a bbolt database
+type BoltStore struct {
+	db  *bolt.DB
+	key []byte
+}
+
+// NewBoltStore opens or creates the bbolt database at path
+func NewBoltStore(path, key string) (*BoltStore, error) {
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open bbolt state: %w", err)
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(boltBucket)
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create bbolt state bucket: %w", err)
+	}
+	return &BoltStore{db: db, key: []byte(key)}, nil
+}
+
+// Load reads the state key, returning nil data if it does not exist
+func (b *BoltStore) Load() ([]byte, error) {
+	var data []byte
+	err := b.db.View(func(tx *bolt.Tx) error {
+		if value := tx.Bucket(boltBucket).Get(b.key); value != nil {
+			data = append([]byte(nil), value...)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read bbolt state: %w", err)
+	}
+	return data, nil
+}
+
+// Save replaces the state key
+func (b *BoltStore) Save(data []byte) error {
+	err := b.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(boltBucket).Put(b.key, data)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write bbolt state: %w", err)
+	}
+	return nil
+}
+
+// Close closes the database
+func (b *BoltStore) Close() error {
+	return b.db.Close()
+}
+
+// RedisStore keeps state as a key in Redis so replicas can share it
+type RedisStore struct {
+	client *redis.Client
+	key    string
+}
+
+// NewRedisStore connects to the Redis server at url
+func NewRedisStore(url, key string) (*RedisStore, error) {
+	options, err := redis.ParseURL(url)
+	if err != nil {
+		return nil, fmt.Errorf("invalid redis url: %w", err)
+	}
+	return &RedisStore{client: redis.NewClient(options), key: key}, nil
+}
+
+// Load reads the state key, returning nil data if it does not exist
+func (r *RedisStore) Load() ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+	defer cancel()
+
+	data, err := r.client.Get(ctx, r.key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read redis state: %w", err)
+	}
+	return data, nil
+}
+
+// Save replaces the state key
+func (r *RedisStore) Save(data []byte) error {
+	ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+	defer cancel()
+
+	if err := r.client.Set(ctx, r.key, data, 0).Err(); err != nil {
+		return fmt.Errorf("failed to write redis state: %w", err)
+	}
+	return nil
+}
+
+// Close closes the connection pool
+func (r *RedisStore) Close() error {
+	return r.client.Close()
+}
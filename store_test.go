@@ -0,0 +1,64 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestStateStoreBackends(t *testing.T) {
+	dir := t.TempDir()
+
+	backends := []StateBackendConfig{
+		{Type: backendFile, Path: filepath.Join(dir, "state.json")},
+		{Type: backendSQLite, Path: filepath.Join(dir, "state.db")},
+		{Type: backendBolt, Path: filepath.Join(dir, "state.bolt")},
+	}
+
+	for _, backend := range backends {
+		t.Run(backend.Type, func(t *testing.T) {
+			store, err := OpenStateStore(&Config{StateBackend: backend})
+			if err != nil {
+				t.Fatalf("Failed to open store: %v", err)
+			}
+			defer store.Close()
+
+			state, err := LoadStateFrom(store)
+			if err != nil {
+				t.Fatalf("Failed to load empty state: %v", err)
+			}
+			if len(state.ConsumedRules) != 0 {
+				t.Errorf("Expected empty state, got %v", state.ConsumedRules)
+			}
+
+			at := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+			state.MarkConsumed("Purge", at)
+			if err := state.Save(); err != nil {
+				t.Fatalf("Failed to save state: %v", err)
+			}
+
+			reloaded, err := LoadStateFrom(store)
+			if err != nil {
+				t.Fatalf("Failed to reload state: %v", err)
+			}
+			if !reloaded.IsConsumed("Purge") {
+				t.Error("Expected consumed rule to survive a reload")
+			}
+		})
+	}
+}
+
+func TestStateBackendValidate(t *testing.T) {
+	if err := (StateBackendConfig{Type: backendRedis}).validate(); err == nil {
+		t.Error("Expected error for redis backend without url")
+	}
+	if err := (StateBackendConfig{Type: backendBolt}).validate(); err == nil {
+		t.Error("Expected error for bbolt backend without path")
+	}
+	if err := (StateBackendConfig{Type: "etcd", Path: "x"}).validate(); err == nil {
+		t.Error("Expected error for unknown backend")
+	}
+	if err := (StateBackendConfig{Type: backendRedis, URL: "redis://localhost:6379/0"}).validate(); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"html"
+	"strings"
+	"unicode"
+)
+
+// minAllCapsLetters keeps short acronyms like "AI" from counting as shouting
+const minAllCapsLetters = 4
+
+// stripHTML removes markup from content and returns its plain text
+// Tags are replaced by spaces so words on either side stay separate
+func stripHTML(content string) string {
+	var b strings.Builder
+	b.Grow(len(content))
+
+	inTag := false
+	for _, r := range content {
+		switch {
+		case r == '<':
+			inTag = true
+			b.WriteByte(' ')
+		case r == '>' && inTag:
+			inTag = false
+		case !inTag:
+			b.WriteRune(r)
+		}
+	}
+
+	return strings.Join(strings.Fields(html.UnescapeString(b.String())), " ")
+}
+
+// wordCount returns the number of words in the plain text of content
+func wordCount(content string) int {
+	return len(strings.Fields(stripHTML(content)))
+}
+
+// isAllCaps reports whether text has enough letters and none of them is lower case
+func isAllCaps(text string) bool {
+	letters := 0
+	for _, r := range text {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		if unicode.IsLower(r) {
+			return false
+		}
+		letters++
+	}
+	return letters >= minAllCapsLetters
+}
+
+// emojiCount returns the number of emoji code points in text
+func emojiCount(text string) int {
+	count := 0
+	for _, r := range text {
+		if (r >= 0x1F000 && r <= 0x1FAFF) || (r >= 0x2600 && r <= 0x27BF) {
+			count++
+		}
+	}
+	return count
+}
+
+// transliterations maps lower-case Cyrillic and Greek letters to Latin
+var transliterations = map[rune]string{
+	// Cyrillic (Russian, Ukrainian, Belarusian)
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'ґ': "g", 'д': "d", 'е': "e", 'ё': "e", 'є': "ye",
+	'ж': "zh", 'з': "z", 'и': "i", 'і': "i", 'ї': "yi", 'й': "y", 'к': "k", 'л': "l", 'м': "m",
+	'н': "n", 'о': "o", 'п': "p", 'р': "r", 'с': "s", 'т': "t", 'у': "u", 'ў': "u", 'ф': "f",
+	'х': "kh", 'ц': "ts", 'ч': "ch", 'ш': "sh", 'щ': "shch", 'ъ': "", 'ы': "y", 'ь': "",
+	'э': "e", 'ю': "yu", 'я': "ya",
+
+	// Greek, including accented vowels
+	'α': "a", 'ά': "a", 'β': "v", 'γ': "g", 'δ': "d", 'ε': "e", 'έ': "e", 'ζ': "z", 'η': "i",
+	'ή': "i", 'θ': "th", 'ι': "i", 'ί': "i", 'ϊ': "i", 'ΐ': "i", 'κ': "k", 'λ': "l", 'μ': "m",
+	'ν': "n", 'ξ': "x", 'ο': "o", 'ό': "o", 'π': "p", 'ρ': "r", 'σ': "s", 'ς': "s", 'τ': "t",
+	'υ': "y", 'ύ': "y", 'ϋ': "y", 'ΰ': "y", 'φ': "f", 'χ': "ch", 'ψ': "ps", 'ω': "o", 'ώ': "o",
+}
+
+// transliterate converts Cyrillic and Greek letters in text to Latin script
+// Upper-case letters keep their case on the first Latin letter, e.g. "Щука" becomes "Shchuka"
+func transliterate(text string) string {
+	var b strings.Builder
+	b.Grow(len(text))
+
+	for _, r := range text {
+		latin, ok := transliterations[unicode.ToLower(r)]
+		switch {
+		case !ok:
+			b.WriteRune(r)
+		case unicode.IsUpper(r) && latin != "":
+			b.WriteString(strings.ToUpper(latin[:1]) + latin[1:])
+		default:
+			b.WriteString(latin)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// defaultTopicModel is the bundled keyword model used when no topic_model is configured
+//
+//go:embed topics.json
+var defaultTopicModel []byte
+
+// TopicModel is a linear bag-of-words classifier, in the spirit of fastText
+// Each label scores a text by summing the weights of its tokens
+type TopicModel struct {
+	MinScore float64               `json:"min_score"` // minimum winning score to assign a topic
+	Labels   map[string]TopicLabel `json:"labels"`
+}
+
+// TopicLabel holds the token weights for a single topic
+type TopicLabel struct {
+	Bias    float64            `json:"bias"`
+	Weights map[string]float64 `json:"weights"`
+}
+
+// LoadTopicModel reads a topic model from path, or the bundled model if path is empty
+func LoadTopicModel(path string) (*TopicModel, error) {
+	data := defaultTopicModel
+	if path != "" {
+		var err error
+		data, err = os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read topic model: %w", err)
+		}
+	}
+
+	var model TopicModel
+	if err := json.Unmarshal(data, &model); err != nil {
+		return nil, fmt.Errorf("failed to parse topic model: %w", err)
+	}
+	if len(model.Labels) == 0 {
+		return nil, fmt.Errorf("topic model has no labels")
+	}
+
+	return &model, nil
+}
+
+// Classify returns the best scoring topic for text, or "" if none is confident enough
+func (m *TopicModel) Classify(text string) string {
+	tokens := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	// Labels are scored in sorted order so ties resolve deterministically
+	labels := slices.Sorted(maps.Keys(m.Labels))
+
+	best, bestScore := "", 0.0
+	for _, label := range labels {
+		weights := m.Labels[label]
+		score := weights.Bias
+		for _, token := range tokens {
+			score += weights.Weights[token]
+		}
+		if score > 0 && score >= m.MinScore && (best == "" || score > bestScore) {
+			best, bestScore = label, score
+		}
+	}
+
+	return best
+}
+
+// CheckRules verifies that every topic referenced by the rules exists in the model
+func (m *TopicModel) CheckRules(rules []Rule) error {
+	for _, rule := range rules {
+		for _, topic := range rule.Topics() {
+			if _, ok := m.Labels[topic]; !ok {
+				return fmt.Errorf("rule '%s': unknown topic '%s'", rule.Name, topic)
+			}
+		}
+	}
+	return nil
+}
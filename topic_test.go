@@ -0,0 +1,77 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	miniflux "miniflux.app/v2/client"
+)
+
+func TestTopicModelClassify(t *testing.T) {
+	model, err := LoadTopicModel("")
+	if err != nil {
+		t.Fatalf("Failed to load bundled topic model: %v", err)
+	}
+
+	tests := []struct {
+		text     string
+		expected string
+	}{
+		{"Bitcoin and Ethereum rally as crypto exchange Coinbase lists new tokens", "crypto"},
+		{"Striker scored twice as the league season ends with a playoff match", "sports"},
+		{"Senate vote on the election bill splits the party", "politics"},
+		{"A quiet afternoon in the garden", ""},
+	}
+
+	for _, tt := range tests {
+		if topic := model.Classify(tt.text); topic != tt.expected {
+			t.Errorf("Classify(%q) = %q, expected %q", tt.text, topic, tt.expected)
+		}
+	}
+}
+
+func TestLoadTopicModelFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "topics.json")
+	model := `{"min_score": 1, "labels": {"gardening": {"weights": {"garden": 1}}}}`
+	if err := os.WriteFile(path, []byte(model), 0o644); err != nil {
+		t.Fatalf("Failed to write model: %v", err)
+	}
+
+	loaded, err := LoadTopicModel(path)
+	if err != nil {
+		t.Fatalf("Failed to load topic model: %v", err)
+	}
+	if topic := loaded.Classify("A quiet afternoon in the garden"); topic != "gardening" {
+		t.Errorf("Expected 'gardening', got %q", topic)
+	}
+
+	if err := loaded.CheckRules([]Rule{{Name: "Sports", Topic: StringList{"sports"}}}); err == nil {
+		t.Error("Expected unknown topic to be rejected")
+	}
+}
+
+func TestMatcherTopic(t *testing.T) {
+	model, err := LoadTopicModel("")
+	if err != nil {
+		t.Fatalf("Failed to load bundled topic model: %v", err)
+	}
+
+	matcher, err := NewMatcher([]Rule{
+		{Name: "No sports or crypto", Topic: StringList{"sports|crypto"}, Action: "read"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create matcher: %v", err)
+	}
+	matcher.SetTopicModel(model)
+
+	sports := &miniflux.Entry{ID: 1, Title: "Playoff recap", Content: "<p>The striker scored in the final match of the season</p>"}
+	if !matcher.Match(sports).Matched {
+		t.Error("Expected sports entry to match")
+	}
+
+	politics := &miniflux.Entry{ID: 2, Title: "Senate vote", Content: "<p>The election bill splits the party</p>"}
+	if matcher.Match(politics).Matched {
+		t.Error("Expected politics entry not to match")
+	}
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"slices"
+	"time"
+)
+
+// undoCommand runs the undo subcommand:
+// miniflux-jobs undo --last-run
+// miniflux-jobs undo --since 2h --rule "Remove promos"
+func undoCommand(args []string) {
+	flags := flag.NewFlagSet("undo", flag.ExitOnError)
+	configPath := flags.String("config", defaultConfigPath(), "Path to the rules configuration file")
+	lastRun := flags.Bool("last-run", false, "Undo the actions of the most recent run that applied any")
+	sinceValue := flags.String("since", "", "Undo the actions applied this far back, e.g. 7d or 12h")
+	ruleName := flags.String("rule", "", "Only undo actions of this rule (default: all rules)")
+	dryRun := flags.Bool("dry-run", false, "Show what would be restored without changing anything")
+	flags.Parse(args)
+
+	logger := commandLogger(os.Stdout)
+
+	if *lastRun == (*sinceValue != "") {
+		fatal(logger, "Exactly one of -last-run or -since is required")
+	}
+
+	config, err := LoadConfig(*configPath)
+	if err != nil {
+		fatal(logger, "Failed to load config", "error", err)
+	}
+	if !config.HasState() {
+		fatal(logger, "Undo requires state_file or state_backend to be set")
+	}
+
+	apiKey, err := GetAPIKey()
+	if err != nil {
+		fatal(logger, "Failed to get API key", "error", err)
+	}
+	client := NewClientWrapper(config.MinifluxURL, apiKey, config.MaxResponseSize)
+
+	store, err := OpenStateStore(config)
+	if err != nil {
+		fatal(logger, "Failed to open state store", "error", err)
+	}
+	defer store.Close()
+	state, err := LoadStateFrom(store)
+	if err != nil {
+		fatal(logger, "Failed to load state", "error", err)
+	}
+
+	var since time.Time
+	if *lastRun {
+		since = state.LastRun()
+		if since.IsZero() {
+			fatal(logger, "No recorded run applied any action")
+		}
+		logger.Info("Undoing the last run", "started", since)
+	} else {
+		lookback, err := parseSince(*sinceValue)
+		if err != nil {
+			fatal(logger, "Invalid -since", "error", err)
+		}
+		since = time.Now().Add(-lookback)
+	}
+
+	matcher, err := NewMatcher(nil)
+	if err != nil {
+		fatal(logger, "Failed to create matcher", "error", err)
+	}
+	processor := NewProcessor(client, matcher, logger, *dryRun)
+	processor.SetOptions(processorOptions(config))
+	processor.SetState(state)
+
+	stats, err := processor.Undo(*ruleName, since)
+	if err != nil {
+		logger.Error("Undo error", "error", err)
+	}
+	logger.Info("Restored entries", "entries", stats.TotalEntries, "errors", stats.Errors)
+	if stats.TotalEntries > 0 && !*dryRun {
+		logger.Warn("Rules that still match these entries will act on them again; fix or disable them before the next run")
+	}
+}
+
+// Undo reverts the actions rule (any rule if empty) applied since the given time and forgets them
+// Unlike Reprocess, the entries are not matched again
+func (p *Processor) Undo(rule string, since time.Time) (*ProcessStats, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	stats := &ProcessStats{ConfigHash: p.options.ConfigHash}
+	if p.state == nil {
+		return stats, fmt.Errorf("undo requires state")
+	}
+
+	var entryIDs []int64
+	for _, action := range p.state.ActionsSince(rule, since) {
+		if !slices.Contains(entryIDs, action.EntryID) {
+			entryIDs = append(entryIDs, action.EntryID)
+		}
+	}
+	p.logger.Info("Undoing actions", "entries", len(entryIDs))
+
+	for _, entryID := range entryIDs {
+		entry, err := p.client.Entry(entryID)
+		if err != nil {
+			p.logger.Error("Failed to fetch entry, it cannot be restored", "entry_id", entryID, "error", err)
+			stats.Errors++
+			continue
+		}
+
+		if !p.revertActions(entry, rule, since, stats) {
+			continue
+		}
+		stats.TotalEntries++
+		if !p.dryRun {
+			p.state.ForgetActions(entry.ID, rule, since)
+		}
+	}
+
+	if err := p.saveState(); err != nil {
+		return stats, err
+	}
+	return stats, nil
+}
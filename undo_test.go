@@ -0,0 +1,121 @@
+package main
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+
+	miniflux "miniflux.app/v2/client"
+)
+
+func TestProcessorUndoLastRun(t *testing.T) {
+	mockClient := &MockClient{
+		entries: []*miniflux.Entry{
+			{ID: 1, Title: "Promo: 50% off", Status: miniflux.EntryStatusUnread},
+		},
+	}
+
+	state, err := LoadState(filepath.Join(t.TempDir(), "state.json"))
+	if err != nil {
+		t.Fatalf("Failed to load state: %v", err)
+	}
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+
+	matcher, err := NewMatcher([]Rule{
+		{Name: "Remove promos", Title: "Promo", Action: "remove", Status: StringList{"unread"}},
+		{Name: "Strip tags", Title: `^\[`, Action: "rewrite_title", RewriteTitle: &TitleRewrite{Find: `^\[\w+\] `}},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create matcher: %v", err)
+	}
+	processor := NewProcessor(mockClient, matcher, logger, false)
+	processor.SetState(state)
+	if _, err := processor.Process(); err != nil {
+		t.Fatalf("Process failed: %v", err)
+	}
+
+	// The second run removes and retitles new entries
+	mockClient.entries = append(mockClient.entries,
+		&miniflux.Entry{ID: 2, Title: "Promo: free shipping", Status: miniflux.EntryStatusUnread},
+		&miniflux.Entry{ID: 3, Title: "[Video] Launch recap", Status: miniflux.EntryStatusUnread},
+	)
+	if _, err := processor.Process(); err != nil {
+		t.Fatalf("Process failed: %v", err)
+	}
+	if mockClient.titles[3] != "Launch recap" {
+		t.Fatalf("Expected entry 3 to be retitled, got %q", mockClient.titles[3])
+	}
+	mockClient.entries[2].Title = mockClient.titles[3]
+
+	stats, err := processor.Undo("", state.LastRun())
+	if err != nil {
+		t.Fatalf("Undo failed: %v", err)
+	}
+
+	if stats.TotalEntries != 2 {
+		t.Errorf("Expected 2 entries restored, got %d", stats.TotalEntries)
+	}
+	if status := mockClient.entries[0].Status; status != miniflux.EntryStatusRemoved {
+		t.Errorf("Expected entry 1 from the first run to stay removed, got %s", status)
+	}
+	if status := mockClient.entries[1].Status; status != miniflux.EntryStatusUnread {
+		t.Errorf("Expected entry 2 to be restored to unread, got %s", status)
+	}
+	if got := mockClient.titles[3]; got != "[Video] Launch recap" {
+		t.Errorf("Expected the title of entry 3 to be restored, got %q", got)
+	}
+	if len(state.Actions) != 1 || state.Actions[0].EntryID != 1 {
+		t.Errorf("Expected only the first run's action to remain, got %+v", state.Actions)
+	}
+}
+
+func TestProcessorUndoRule(t *testing.T) {
+	mockClient := &MockClient{
+		entries: []*miniflux.Entry{
+			{ID: 1, Title: "[Ad] Promo: 50% off", Status: miniflux.EntryStatusUnread},
+		},
+	}
+
+	state, err := LoadState(filepath.Join(t.TempDir(), "state.json"))
+	if err != nil {
+		t.Fatalf("Failed to load state: %v", err)
+	}
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+
+	// Both rules act on the same entry
+	matcher, err := NewMatcher([]Rule{
+		{Name: "Strip tags", Title: `^\[`, Action: "rewrite_title", RewriteTitle: &TitleRewrite{Find: `^\[\w+\] `}, Continue: true},
+		{Name: "Remove promos", Title: "Promo", Action: "remove"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create matcher: %v", err)
+	}
+	processor := NewProcessor(mockClient, matcher, logger, false)
+	processor.SetState(state)
+	if _, err := processor.Process(); err != nil {
+		t.Fatalf("Process failed: %v", err)
+	}
+	if mockClient.titles[1] != "Promo: 50% off" || mockClient.entries[0].Status != miniflux.EntryStatusRemoved {
+		t.Fatalf("Expected entry 1 to be retitled and removed, got %q (%s)", mockClient.titles[1], mockClient.entries[0].Status)
+	}
+	mockClient.entries[0].Title = mockClient.titles[1]
+
+	stats, err := processor.Undo("Remove promos", state.LastRun())
+	if err != nil {
+		t.Fatalf("Undo failed: %v", err)
+	}
+
+	if stats.TotalEntries != 1 {
+		t.Errorf("Expected 1 entry restored, got %d", stats.TotalEntries)
+	}
+	if status := mockClient.entries[0].Status; status != miniflux.EntryStatusUnread {
+		t.Errorf("Expected entry 1 to be restored to unread, got %s", status)
+	}
+	if got := mockClient.titles[1]; got != "Promo: 50% off" {
+		t.Errorf("Expected the other rule's title rewrite to stay, got %q", got)
+	}
+	if len(state.Actions) != 1 || state.Actions[0].Rule != "Strip tags" {
+		t.Errorf("Expected only the other rule's action to remain, got %+v", state.Actions)
+	}
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"runtime"
+	"runtime/debug"
+)
+
+// version is the release this binary was built from, set with -ldflags "-X main.version=v1.2.3"
+var version = "dev"
+
+// commit and buildDate describe the build, set with -ldflags "-X main.commit=... -X main.buildDate=..."
+// When left empty they are read from the VCS information Go embeds in the binary.
+var (
+	commit    string
+	buildDate string
+)
+
+// buildInfo describes the binary for bug reports and logs
+type buildInfo struct {
+	Version   string
+	Commit    string
+	BuildDate string
+	Modified  bool // built from a checkout with uncommitted changes
+	GoVersion string
+	Platform  string
+}
+
+// currentBuildInfo combines the values set at link time with those embedded by the Go toolchain
+func currentBuildInfo() buildInfo {
+	info := buildInfo{
+		Version:   version,
+		Commit:    commit,
+		BuildDate: buildDate,
+		GoVersion: runtime.Version(),
+		Platform:  runtime.GOOS + "/" + runtime.GOARCH,
+	}
+	embedded, ok := debug.ReadBuildInfo()
+	if !ok {
+		return info
+	}
+	// go install module@version records the module version
+	if info.Version == "dev" && embedded.Main.Version != "" && embedded.Main.Version != "(devel)" {
+		info.Version = embedded.Main.Version
+	}
+	for _, setting := range embedded.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			if info.Commit == "" {
+				info.Commit = setting.Value
+			}
+		case "vcs.time":
+			if info.BuildDate == "" {
+				info.BuildDate = setting.Value
+			}
+		case "vcs.modified":
+			info.Modified = setting.Value == "true"
+		}
+	}
+	return info
+}
+
+// String formats the build info on one line, e.g.
+// v1.2.3 (commit 0123abcd, built 2024-05-01T10:00:00Z, go1.24.0 linux/amd64)
+func (b buildInfo) String() string {
+	commit := b.Commit
+	if len(commit) > 12 {
+		commit = commit[:12]
+	}
+	if commit == "" {
+		commit = "unknown"
+	}
+	if b.Modified {
+		commit += "+dirty"
+	}
+	built := ""
+	if b.BuildDate != "" {
+		built = ", built " + b.BuildDate
+	}
+	return fmt.Sprintf("%s (commit %s%s, %s %s)", b.Version, commit, built, b.GoVersion, b.Platform)
+}
+
+// LogValue logs the build info as a group of fields
+func (b buildInfo) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.String("version", b.Version),
+		slog.String("commit", b.Commit),
+		slog.String("build_date", b.BuildDate),
+		slog.Bool("modified", b.Modified),
+		slog.String("go", b.GoVersion),
+		slog.String("platform", b.Platform),
+	)
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBuildInfoString(t *testing.T) {
+	info := buildInfo{
+		Version:   "v1.2.3",
+		Commit:    "0123456789abcdef0123",
+		BuildDate: "2024-05-01T10:00:00Z",
+		GoVersion: "go1.24.0",
+		Platform:  "linux/amd64",
+	}
+	if got, expected := info.String(), "v1.2.3 (commit 0123456789ab, built 2024-05-01T10:00:00Z, go1.24.0 linux/amd64)"; got != expected {
+		t.Errorf("Expected %q, got %q", expected, got)
+	}
+
+	info = buildInfo{Version: "dev", Modified: true, GoVersion: "go1.24.0", Platform: "darwin/arm64"}
+	if got, expected := info.String(), "dev (commit unknown+dirty, go1.24.0 darwin/arm64)"; got != expected {
+		t.Errorf("Expected %q, got %q", expected, got)
+	}
+}
+
+func TestCurrentBuildInfoPrefersLinkedValues(t *testing.T) {
+	defer func(v, c, d string) { version, commit, buildDate = v, c, d }(version, commit, buildDate)
+	version, commit, buildDate = "v2.0.0", "abcdef", "2024-06-01"
+
+	info := currentBuildInfo()
+	if info.Version != "v2.0.0" || info.Commit != "abcdef" || info.BuildDate != "2024-06-01" {
+		t.Errorf("Expected the values set at link time, got %+v", info)
+	}
+	if !strings.Contains(info.Platform, "/") || info.GoVersion == "" {
+		t.Errorf("Expected the Go version and platform, got %+v", info)
+	}
+}
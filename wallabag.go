@@ -0,0 +1,163 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	miniflux "miniflux.app/v2/client"
+)
+
+// wallabagTokenMargin renews access tokens this long before they expire
+const wallabagTokenMargin = time.Minute
+
+// WallabagConfig configures the Wallabag server used by wallabag actions
+type WallabagConfig struct {
+	URL          string     `yaml:"url"` // base URL, e.g. https://app.wallabag.it
+	ClientID     string     `yaml:"client_id"`
+	ClientSecret string     `yaml:"client_secret"`
+	Username     string     `yaml:"username"`
+	Password     string     `yaml:"password"`
+	Tags         StringList `yaml:"tags"` // tags added to every saved entry
+}
+
+// validate checks that the API client and user credentials are set
+func (w WallabagConfig) validate() error {
+	if w.URL == "" || w.ClientID == "" || w.ClientSecret == "" || w.Username == "" || w.Password == "" {
+		return fmt.Errorf("wallabag requires url, client_id, client_secret, username and password")
+	}
+	return nil
+}
+
+// wallabagClient saves entries through the Wallabag API, reusing its access token
+type wallabagClient struct {
+	cfg        WallabagConfig
+	httpClient *http.Client
+
+	mu      sync.Mutex
+	token   string
+	expires time.Time
+}
+
+// newWallabagClient creates a client for the configured server
+func newWallabagClient(httpClient *http.Client, cfg WallabagConfig) *wallabagClient {
+	return &wallabagClient{cfg: cfg, httpClient: httpClient}
+}
+
+// endpoint returns the URL of an API path on the configured server
+func (w *wallabagClient) endpoint(path string) string {
+	return strings.TrimSuffix(w.cfg.URL, "/") + path
+}
+
+// accessToken returns a valid token, requesting a new one with the password grant when needed
+func (w *wallabagClient) accessToken() (string, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.token != "" && time.Now().Before(w.expires) {
+		return w.token, nil
+	}
+
+	form := url.Values{
+		"grant_type":    {"password"},
+		"client_id":     {w.cfg.ClientID},
+		"client_secret": {w.cfg.ClientSecret},
+		"username":      {w.cfg.Username},
+		"password":      {w.cfg.Password},
+	}
+	resp, err := w.httpClient.PostForm(w.endpoint("/oauth/v2/token"), form)
+	if err != nil {
+		return "", fmt.Errorf("wallabag token request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("wallabag token request failed: status %d", resp.StatusCode)
+	}
+
+	var token struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return "", fmt.Errorf("invalid wallabag token response: %w", err)
+	}
+	if token.AccessToken == "" {
+		return "", fmt.Errorf("invalid wallabag token response: no access_token")
+	}
+
+	w.token = token.AccessToken
+	w.expires = time.Now().Add(time.Duration(token.ExpiresIn)*time.Second - wallabagTokenMargin)
+	return w.token, nil
+}
+
+// Save adds the entry's URL to Wallabag with the configured tags
+func (w *wallabagClient) Save(entry *miniflux.Entry) error {
+	token, err := w.accessToken()
+	if err != nil {
+		return err
+	}
+
+	form := url.Values{
+		"url":   {entry.URL},
+		"title": {entry.Title},
+	}
+	if len(w.cfg.Tags) > 0 {
+		form.Set("tags", strings.Join(w.cfg.Tags, ","))
+	}
+
+	req, err := http.NewRequest(http.MethodPost, w.endpoint("/api/entries.json"), strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := w.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("wallabag request failed: %w", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("wallabag request failed: status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// applyWallabag saves an entry to Wallabag, once per entry
+func (p *Processor) applyWallabag(entry *miniflux.Entry, rule *Rule, stats *ProcessStats) bool {
+	if entry.URL == "" {
+		p.entryLogger(entry, rule).Warn("Cannot save entry without a URL to Wallabag", "action", "wallabag")
+		stats.Errors++
+		return false
+	}
+
+	if !p.shouldNotify("wallabag", entry, "") {
+		return true
+	}
+
+	if p.dryRun {
+		p.entryLogger(entry, rule).Info("Dry run: would save entry to Wallabag", "action", "wallabag")
+		p.audit(entry, "wallabag", rule.Name)
+		return true
+	}
+
+	if p.wallabag == nil {
+		p.wallabag = newWallabagClient(p.httpClient, p.options.Wallabag)
+	}
+	if err := p.wallabag.Save(entry); err != nil {
+		p.entryLogger(entry, rule).Error("Failed to save entry to Wallabag", "action", "wallabag", "error", err)
+		stats.Errors++
+		return false
+	}
+
+	stats.Saved++
+	p.entryLogger(entry, rule).Info("Saved entry to Wallabag", "action", "wallabag")
+	p.audit(entry, "wallabag", rule.Name)
+	return true
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	miniflux "miniflux.app/v2/client"
+)
+
+func TestProcessorWallabagAction(t *testing.T) {
+	tokens, saved := 0, 0
+	var problem string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/oauth/v2/token":
+			tokens++
+			if r.FormValue("grant_type") != "password" || r.FormValue("client_id") != "id" || r.FormValue("username") != "me" {
+				problem = "unexpected token request"
+			}
+			fmt.Fprint(w, `{"access_token": "secret", "expires_in": 3600}`)
+		case "/api/entries.json":
+			saved++
+			if r.Header.Get("Authorization") != "Bearer secret" || r.FormValue("tags") != "miniflux,longform" {
+				problem = "unexpected save request"
+			}
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	mockClient := &MockClient{
+		entries: []*miniflux.Entry{
+			{ID: 1, Title: "Longform: the history of RSS", URL: "https://example.com/rss", Status: miniflux.EntryStatusUnread},
+			{ID: 2, Title: "Longform: feeds at scale", URL: "https://example.com/scale", Status: miniflux.EntryStatusUnread},
+		},
+	}
+
+	state, err := LoadState(filepath.Join(t.TempDir(), "state.json"))
+	if err != nil {
+		t.Fatalf("Failed to load state: %v", err)
+	}
+	matcher, err := NewMatcher([]Rule{
+		{Name: "Longform", Title: "Longform", Action: "wallabag"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create matcher: %v", err)
+	}
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	processor := NewProcessor(mockClient, matcher, logger, false)
+	processor.SetState(state)
+	processor.SetOptions(ProcessorOptions{Wallabag: WallabagConfig{
+		URL:          server.URL,
+		ClientID:     "id",
+		ClientSecret: "shh",
+		Username:     "me",
+		Password:     "pw",
+		Tags:         StringList{"miniflux", "longform"},
+	}})
+
+	// The second run must not save the same entries again
+	for range 2 {
+		stats, err := processor.Process()
+		if err != nil {
+			t.Fatalf("Process failed: %v", err)
+		}
+		if stats.Errors != 0 {
+			t.Fatalf("Expected no errors, got %d", stats.Errors)
+		}
+	}
+
+	if problem != "" {
+		t.Error(problem)
+	}
+	if tokens != 1 || saved != 2 {
+		t.Errorf("Expected 1 token request and 2 saves, got %d and %d", tokens, saved)
+	}
+}
+
+func TestProcessorWallabagFailure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "invalid_grant", http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	mockClient := &MockClient{
+		entries: []*miniflux.Entry{
+			{ID: 1, Title: "Longform", URL: "https://example.com/rss", Status: miniflux.EntryStatusUnread},
+		},
+	}
+
+	matcher, err := NewMatcher([]Rule{
+		{Name: "Longform", Title: "Longform", Actions: []ActionStep{{Action: "wallabag"}, {Action: "read"}}},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create matcher: %v", err)
+	}
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	processor := NewProcessor(mockClient, matcher, logger, false)
+	processor.SetOptions(ProcessorOptions{Wallabag: WallabagConfig{URL: server.URL}})
+
+	stats, err := processor.Process()
+	if err != nil {
+		t.Fatalf("Process failed: %v", err)
+	}
+	if stats.Errors != 1 || stats.MarkedRead != 0 {
+		t.Errorf("Expected the failed save to stop the rule, got %+v", stats)
+	}
+}
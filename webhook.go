@@ -0,0 +1,146 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	miniflux "miniflux.app/v2/client"
+)
+
+// webhookStepPrefix marks action steps that post to a configured webhook, e.g. "webhook:discord"
+const webhookStepPrefix = "webhook:"
+
+// WebhookConfig defines a webhook target and how entries are rendered into its payload
+type WebhookConfig struct {
+	URL              string `yaml:"url"`
+	IncludeContent   bool   `yaml:"include_content"`    // send entry content (default: omitted)
+	MaxContentLength int    `yaml:"max_content_length"` // truncate content to this many characters (0 = no limit)
+	StripHTML        bool   `yaml:"strip_html"`         // send content as plain text
+}
+
+// WebhookPayload is the JSON body posted to webhooks
+type WebhookPayload struct {
+	Rule  string       `json:"rule"`
+	Entry WebhookEntry `json:"entry"`
+	Sent  time.Time    `json:"sent_at"`
+}
+
+// WebhookEntry is the subset of an entry sent in webhook payloads
+type WebhookEntry struct {
+	ID          int64     `json:"id"`
+	FeedID      int64     `json:"feed_id"`
+	FeedTitle   string    `json:"feed_title"`
+	Title       string    `json:"title"`
+	URL         string    `json:"url"`
+	Author      string    `json:"author,omitempty"`
+	PublishedAt time.Time `json:"published_at"`
+	Content     string    `json:"content,omitempty"`
+}
+
+// webhookName returns the webhook referenced by an action step, if any
+func webhookName(step string) (string, bool) {
+	return strings.CutPrefix(step, webhookStepPrefix)
+}
+
+// findWebhook looks up a webhook by case-insensitive name
+func findWebhook(webhooks map[string]WebhookConfig, name string) (WebhookConfig, bool) {
+	for key, webhook := range webhooks {
+		if strings.EqualFold(key, name) {
+			return webhook, true
+		}
+	}
+	return WebhookConfig{}, false
+}
+
+// buildWebhookEntry renders an entry according to the webhook's payload controls
+func buildWebhookEntry(entry *miniflux.Entry, cfg WebhookConfig) WebhookEntry {
+	payload := WebhookEntry{
+		ID:          entry.ID,
+		FeedID:      entryFeedID(entry),
+		Title:       entry.Title,
+		URL:         entry.URL,
+		Author:      entry.Author,
+		PublishedAt: entry.Date,
+	}
+	if entry.Feed != nil {
+		payload.FeedTitle = entry.Feed.Title
+	}
+
+	if cfg.IncludeContent {
+		content := entry.Content
+		if cfg.StripHTML {
+			content = stripHTML(content)
+		}
+		payload.Content = truncate(content, cfg.MaxContentLength)
+	}
+
+	return payload
+}
+
+// truncate shortens s to at most n characters, marking the cut with an ellipsis
+func truncate(s string, n int) string {
+	if n <= 0 || utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	runes := []rune(s)
+	if n == 1 {
+		return "…"
+	}
+	return string(runes[:n-1]) + "…"
+}
+
+// sendWebhook posts a JSON payload to the webhook URL
+func sendWebhook(client *http.Client, url string, payload any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode webhook payload: %w", err)
+	}
+
+	resp, err := client.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("webhook request failed: %w", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook request failed: status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// applyWebhook posts an entry to the named webhook, once per entry and rule
+func (p *Processor) applyWebhook(entry *miniflux.Entry, name string, rule *Rule, stats *ProcessStats) bool {
+	cfg, ok := findWebhook(p.options.Webhooks, name)
+	if !ok {
+		p.entryLogger(entry, rule).Warn("Unknown webhook", "action", webhookStepPrefix+name)
+		stats.Errors++
+		return false
+	}
+
+	if !p.shouldNotify(webhookStepPrefix+name, entry, rule.Name) {
+		return true
+	}
+
+	if p.dryRun {
+		p.entryLogger(entry, rule).Info("Dry run: would send entry to webhook", "action", webhookStepPrefix+name)
+		p.audit(entry, webhookStepPrefix+name, rule.Name)
+		return true
+	}
+
+	payload := WebhookPayload{Rule: rule.Name, Entry: buildWebhookEntry(entry, cfg), Sent: p.now()}
+	if err := sendWebhook(p.httpClient, cfg.URL, payload); err != nil {
+		p.entryLogger(entry, rule).Error("Failed to send entry to webhook", "action", webhookStepPrefix+name, "error", err)
+		stats.Errors++
+		return false
+	}
+
+	stats.Notified++
+	p.entryLogger(entry, rule).Info("Sent entry to webhook", "action", webhookStepPrefix+name)
+	p.audit(entry, webhookStepPrefix+name, rule.Name)
+	return true
+}
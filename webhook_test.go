@@ -0,0 +1,79 @@
+package main
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	miniflux "miniflux.app/v2/client"
+)
+
+func TestBuildWebhookEntry(t *testing.T) {
+	entry := &miniflux.Entry{
+		ID:      1,
+		Title:   "Release notes",
+		Content: "<p>Hello <b>world</b>, this is a long post</p>",
+		Feed:    &miniflux.Feed{ID: 2, Title: "Blog"},
+	}
+
+	if payload := buildWebhookEntry(entry, WebhookConfig{}); payload.Content != "" {
+		t.Errorf("Expected content to be omitted by default, got '%s'", payload.Content)
+	}
+
+	payload := buildWebhookEntry(entry, WebhookConfig{IncludeContent: true, StripHTML: true, MaxContentLength: 12})
+	if payload.Content != "Hello world…" {
+		t.Errorf("Expected stripped and truncated content, got '%s'", payload.Content)
+	}
+	if payload.FeedID != 2 || payload.FeedTitle != "Blog" {
+		t.Errorf("Unexpected feed fields: %+v", payload)
+	}
+}
+
+func TestProcessorWebhookAction(t *testing.T) {
+	var received []WebhookPayload
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload WebhookPayload
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("Failed to decode payload: %v", err)
+		}
+		received = append(received, payload)
+	}))
+	defer server.Close()
+
+	mockClient := &MockClient{
+		entries: []*miniflux.Entry{
+			{ID: 1, Title: "Security advisory", Content: strings.Repeat("x", 5000)},
+		},
+	}
+
+	matcher, err := NewMatcher([]Rule{
+		{Name: "Advisories", Title: "(?i)advisory", Action: "webhook:chat"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create matcher: %v", err)
+	}
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	processor := NewProcessor(mockClient, matcher, logger, false)
+	processor.SetOptions(ProcessorOptions{
+		Webhooks: map[string]WebhookConfig{
+			"chat": {URL: server.URL, IncludeContent: true, MaxContentLength: 2000},
+		},
+	})
+
+	stats, err := processor.Process()
+	if err != nil {
+		t.Fatalf("Process failed: %v", err)
+	}
+
+	if stats.Notified != 1 || len(received) != 1 {
+		t.Fatalf("Expected 1 webhook call, got %d", len(received))
+	}
+	if payload := received[0]; payload.Rule != "Advisories" || len([]rune(payload.Entry.Content)) != 2000 {
+		t.Errorf("Unexpected payload: rule '%s', %d content characters", payload.Rule, len(payload.Entry.Content))
+	}
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultYouTubeWatchURL is the page fetched to read a video's duration
+const defaultYouTubeWatchURL = "https://www.youtube.com/watch?v="
+
+// maxWatchPageSize limits how much of a watch page is read when looking for metadata
+const maxWatchPageSize = 4 << 20
+
+var (
+	// isoDurationPattern matches the itemprop duration meta tag, e.g. PT1H2M3S
+	isoDurationPattern = regexp.MustCompile(`itemprop="duration" content="PT(?:(\d+)H)?(?:(\d+)M)?(?:(\d+)S)?"`)
+	// lengthSecondsPattern matches the player response length, e.g. "lengthSeconds":"123"
+	lengthSecondsPattern = regexp.MustCompile(`"lengthSeconds":"(\d+)"`)
+)
+
+// VideoDurations looks up YouTube video durations from watch page metadata
+// Durations never change, so results are cached for the lifetime of the process
+type VideoDurations struct {
+	client   *http.Client
+	watchURL string
+	logger   *slog.Logger
+
+	mu    sync.Mutex
+	cache map[string]time.Duration
+}
+
+// NewVideoDurations creates a VideoDurations using the given client, or a default one if nil
+func NewVideoDurations(client *http.Client, logger *slog.Logger) *VideoDurations {
+	if client == nil {
+		client = &http.Client{Timeout: 15 * time.Second}
+	}
+	return &VideoDurations{
+		client:   client,
+		watchURL: defaultYouTubeWatchURL,
+		logger:   logger,
+		cache:    make(map[string]time.Duration),
+	}
+}
+
+// Duration returns the duration of the YouTube video at entryURL
+// ok is false for non-YouTube URLs and when the duration cannot be determined
+func (v *VideoDurations) Duration(entryURL string) (duration time.Duration, ok bool) {
+	id := youtubeVideoID(entryURL)
+	if id == "" {
+		return 0, false
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if duration, ok := v.cache[id]; ok {
+		return duration, true
+	}
+
+	duration, err := v.fetch(id)
+	if err != nil {
+		v.logger.Warn("Failed to fetch video duration", "video_id", id, "error", err)
+		return 0, false
+	}
+	v.cache[id] = duration
+
+	return duration, true
+}
+
+// fetch reads the video's watch page and extracts its duration
+func (v *VideoDurations) fetch(id string) (time.Duration, error) {
+	resp, err := v.client.Get(v.watchURL + url.QueryEscape(id))
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("status %d", resp.StatusCode)
+	}
+
+	page, err := io.ReadAll(io.LimitReader(resp.Body, maxWatchPageSize))
+	if err != nil {
+		return 0, err
+	}
+
+	if m := isoDurationPattern.FindSubmatch(page); m != nil {
+		var duration time.Duration
+		for i, unit := range []time.Duration{time.Hour, time.Minute, time.Second} {
+			if n, err := strconv.Atoi(string(m[i+1])); err == nil {
+				duration += time.Duration(n) * unit
+			}
+		}
+		return duration, nil
+	}
+	if m := lengthSecondsPattern.FindSubmatch(page); m != nil {
+		seconds, _ := strconv.Atoi(string(m[1]))
+		return time.Duration(seconds) * time.Second, nil
+	}
+
+	return 0, fmt.Errorf("no duration metadata found")
+}
+
+// youtubeVideoID extracts the video ID from watch, short and youtu.be URLs
+func youtubeVideoID(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+
+	host := strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+	host = strings.TrimPrefix(host, "m.")
+	switch host {
+	case "youtu.be":
+		return strings.Trim(u.Path, "/")
+	case "youtube.com":
+		if u.Path == "/watch" {
+			return u.Query().Get("v")
+		}
+		if id, ok := strings.CutPrefix(u.Path, "/shorts/"); ok {
+			return strings.Trim(id, "/")
+		}
+	}
+	return ""
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	miniflux "miniflux.app/v2/client"
+)
+
+func TestYouTubeVideoID(t *testing.T) {
+	testCases := []struct {
+		url      string
+		expected string
+	}{
+		{"https://www.youtube.com/watch?v=abc123&t=10", "abc123"},
+		{"https://youtu.be/abc123", "abc123"},
+		{"https://m.youtube.com/shorts/abc123", "abc123"},
+		{"https://example.com/watch?v=abc123", ""},
+	}
+
+	for _, tc := range testCases {
+		if id := youtubeVideoID(tc.url); id != tc.expected {
+			t.Errorf("URL '%s': expected '%s', got '%s'", tc.url, tc.expected, id)
+		}
+	}
+}
+
+func TestMatcherVideoDuration(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		switch r.URL.Query().Get("v") {
+		case "short":
+			fmt.Fprint(w, `<meta itemprop="duration" content="PT0M45S">`)
+		case "stream":
+			fmt.Fprint(w, `{"videoDetails":{"lengthSeconds":"10800"}}`)
+		default:
+			fmt.Fprint(w, `<meta itemprop="duration" content="PT12M3S">`)
+		}
+	}))
+	defer server.Close()
+
+	videos := NewVideoDurations(server.Client(), slog.New(slog.NewTextHandler(os.Stdout, nil)))
+	videos.watchURL = server.URL + "/watch?v="
+
+	matcher, err := NewMatcher([]Rule{
+		{Name: "Shorts", VideoDurationLT: time.Minute, Action: "read"},
+		{Name: "Streams", VideoDurationGT: 2 * time.Hour, Action: "read"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create matcher: %v", err)
+	}
+	matcher.SetVideoDurations(videos)
+
+	testCases := []struct {
+		url      string
+		expected string
+	}{
+		{"https://youtube.com/shorts/short", "Shorts"},
+		{"https://www.youtube.com/watch?v=stream", "Streams"},
+		{"https://www.youtube.com/watch?v=regular", ""},
+		{"https://example.com/article", ""},
+	}
+
+	for _, tc := range testCases {
+		result := matcher.Match(&miniflux.Entry{ID: 1, URL: tc.url})
+		name := ""
+		if result.Matched {
+			name = result.Rule.Name
+		}
+		if name != tc.expected {
+			t.Errorf("URL '%s': expected rule '%s', got '%s'", tc.url, tc.expected, name)
+		}
+	}
+
+	if requests != 3 {
+		t.Errorf("Expected one request per video, got %d", requests)
+	}
+}